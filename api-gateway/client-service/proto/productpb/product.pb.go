@@ -9,6 +9,7 @@ package productpb
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,34 +22,1653 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Запланированное изменение цены
+type PriceScheduleKind int32
+
+const (
+	PriceScheduleKind_PRICE_SCHEDULE_KIND_SALE    PriceScheduleKind = 0
+	PriceScheduleKind_PRICE_SCHEDULE_KIND_REGULAR PriceScheduleKind = 1
+)
+
+// Enum value maps for PriceScheduleKind.
+var (
+	PriceScheduleKind_name = map[int32]string{
+		0: "PRICE_SCHEDULE_KIND_SALE",
+		1: "PRICE_SCHEDULE_KIND_REGULAR",
+	}
+	PriceScheduleKind_value = map[string]int32{
+		"PRICE_SCHEDULE_KIND_SALE":    0,
+		"PRICE_SCHEDULE_KIND_REGULAR": 1,
+	}
+)
+
+func (x PriceScheduleKind) Enum() *PriceScheduleKind {
+	p := new(PriceScheduleKind)
+	*p = x
+	return p
+}
+
+func (x PriceScheduleKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PriceScheduleKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_product_proto_enumTypes[0].Descriptor()
+}
+
+func (PriceScheduleKind) Type() protoreflect.EnumType {
+	return &file_proto_product_proto_enumTypes[0]
+}
+
+func (x PriceScheduleKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PriceScheduleKind.Descriptor instead.
+func (PriceScheduleKind) EnumDescriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{0}
+}
+
+// Bulk import / export
+type CatalogFormat int32
+
+const (
+	CatalogFormat_CATALOG_FORMAT_CSV   CatalogFormat = 0
+	CatalogFormat_CATALOG_FORMAT_JSONL CatalogFormat = 1
+)
+
+// Enum value maps for CatalogFormat.
+var (
+	CatalogFormat_name = map[int32]string{
+		0: "CATALOG_FORMAT_CSV",
+		1: "CATALOG_FORMAT_JSONL",
+	}
+	CatalogFormat_value = map[string]int32{
+		"CATALOG_FORMAT_CSV":   0,
+		"CATALOG_FORMAT_JSONL": 1,
+	}
+)
+
+func (x CatalogFormat) Enum() *CatalogFormat {
+	p := new(CatalogFormat)
+	*p = x
+	return p
+}
+
+func (x CatalogFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CatalogFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_product_proto_enumTypes[1].Descriptor()
+}
+
+func (CatalogFormat) Type() protoreflect.EnumType {
+	return &file_proto_product_proto_enumTypes[1]
+}
+
+func (x CatalogFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CatalogFormat.Descriptor instead.
+func (CatalogFormat) EnumDescriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{1}
+}
+
 type Product struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name           string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description    string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Category       string                 `protobuf:"bytes,4,opt,name=category,proto3" json:"category,omitempty"`
+	Price          float64                `protobuf:"fixed64,5,opt,name=price,proto3" json:"price,omitempty"`
+	Quantity       int32                  `protobuf:"varint,6,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	UserId         string                 `protobuf:"bytes,7,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Skus           []*SKU                 `protobuf:"bytes,8,rep,name=skus,proto3" json:"skus,omitempty"`
+	ExternalSku    string                 `protobuf:"bytes,9,opt,name=external_sku,json=externalSku,proto3" json:"external_sku,omitempty"`
+	Images         []*Image               `protobuf:"bytes,10,rep,name=images,proto3" json:"images,omitempty"`
+	EffectivePrice float64                `protobuf:"fixed64,11,opt,name=effective_price,json=effectivePrice,proto3" json:"effective_price,omitempty"`
+	PriceSchedules []*PriceSchedule       `protobuf:"bytes,12,rep,name=price_schedules,json=priceSchedules,proto3" json:"price_schedules,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Product) Reset() {
+	*x = Product{}
+	mi := &file_proto_product_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Product) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Product) ProtoMessage() {}
+
+func (x *Product) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Product.ProtoReflect.Descriptor instead.
+func (*Product) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Product) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Product) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Product) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Product) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Product) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Product) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *Product) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Product) GetSkus() []*SKU {
+	if x != nil {
+		return x.Skus
+	}
+	return nil
+}
+
+func (x *Product) GetExternalSku() string {
+	if x != nil {
+		return x.ExternalSku
+	}
+	return ""
+}
+
+func (x *Product) GetImages() []*Image {
+	if x != nil {
+		return x.Images
+	}
+	return nil
+}
+
+func (x *Product) GetEffectivePrice() float64 {
+	if x != nil {
+		return x.EffectivePrice
+	}
+	return 0
+}
+
+func (x *Product) GetPriceSchedules() []*PriceSchedule {
+	if x != nil {
+		return x.PriceSchedules
+	}
+	return nil
+}
+
+type PriceSchedule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Kind          PriceScheduleKind      `protobuf:"varint,2,opt,name=kind,proto3,enum=product.PriceScheduleKind" json:"kind,omitempty"`
+	Price         float64                `protobuf:"fixed64,3,opt,name=price,proto3" json:"price,omitempty"`
+	StartsAt      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=starts_at,json=startsAt,proto3" json:"starts_at,omitempty"`
+	EndsAt        *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=ends_at,json=endsAt,proto3" json:"ends_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PriceSchedule) Reset() {
+	*x = PriceSchedule{}
+	mi := &file_proto_product_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PriceSchedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceSchedule) ProtoMessage() {}
+
+func (x *PriceSchedule) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriceSchedule.ProtoReflect.Descriptor instead.
+func (*PriceSchedule) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PriceSchedule) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PriceSchedule) GetKind() PriceScheduleKind {
+	if x != nil {
+		return x.Kind
+	}
+	return PriceScheduleKind_PRICE_SCHEDULE_KIND_SALE
+}
+
+func (x *PriceSchedule) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *PriceSchedule) GetStartsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartsAt
+	}
+	return nil
+}
+
+func (x *PriceSchedule) GetEndsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndsAt
+	}
+	return nil
+}
+
+// Вариант товара (SKU)
+type SKU struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Size          string                 `protobuf:"bytes,2,opt,name=size,proto3" json:"size,omitempty"`
+	Weight        float64                `protobuf:"fixed64,3,opt,name=weight,proto3" json:"weight,omitempty"`
+	Unit          string                 `protobuf:"bytes,4,opt,name=unit,proto3" json:"unit,omitempty"`
+	Price         float64                `protobuf:"fixed64,5,opt,name=price,proto3" json:"price,omitempty"`
+	Quantity      int32                  `protobuf:"varint,6,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Barcode       string                 `protobuf:"bytes,7,opt,name=barcode,proto3" json:"barcode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SKU) Reset() {
+	*x = SKU{}
+	mi := &file_proto_product_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SKU) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SKU) ProtoMessage() {}
+
+func (x *SKU) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SKU.ProtoReflect.Descriptor instead.
+func (*SKU) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SKU) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SKU) GetSize() string {
+	if x != nil {
+		return x.Size
+	}
+	return ""
+}
+
+func (x *SKU) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *SKU) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *SKU) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *SKU) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *SKU) GetBarcode() string {
+	if x != nil {
+		return x.Barcode
+	}
+	return ""
+}
+
+// Изображение товара; порядок в Product.images — порядок показа
+type Image struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	ContentType   string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Width         int32                  `protobuf:"varint,4,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32                  `protobuf:"varint,5,opt,name=height,proto3" json:"height,omitempty"`
+	Position      int32                  `protobuf:"varint,6,opt,name=position,proto3" json:"position,omitempty"`
+	ThumbnailUrls map[string]string      `protobuf:"bytes,7,rep,name=thumbnail_urls,json=thumbnailUrls,proto3" json:"thumbnail_urls,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Image) Reset() {
+	*x = Image{}
+	mi := &file_proto_product_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Image) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Image) ProtoMessage() {}
+
+func (x *Image) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Image.ProtoReflect.Descriptor instead.
+func (*Image) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Image) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Image) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Image) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Image) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Image) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Image) GetPosition() int32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *Image) GetThumbnailUrls() map[string]string {
+	if x != nil {
+		return x.ThumbnailUrls
+	}
+	return nil
+}
+
+// Create
+type CreateProductRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateProductRequest) Reset() {
+	*x = CreateProductRequest{}
+	mi := &file_proto_product_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateProductRequest) ProtoMessage() {}
+
+func (x *CreateProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateProductRequest.ProtoReflect.Descriptor instead.
+func (*CreateProductRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateProductRequest) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+type CreateProductResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateProductResponse) Reset() {
+	*x = CreateProductResponse{}
+	mi := &file_proto_product_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateProductResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateProductResponse) ProtoMessage() {}
+
+func (x *CreateProductResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateProductResponse.ProtoReflect.Descriptor instead.
+func (*CreateProductResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateProductResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Get by ID
+type GetProductRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductRequest) Reset() {
+	*x = GetProductRequest{}
+	mi := &file_proto_product_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductRequest) ProtoMessage() {}
+
+func (x *GetProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductRequest.ProtoReflect.Descriptor instead.
+func (*GetProductRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetProductRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetProductResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductResponse) Reset() {
+	*x = GetProductResponse{}
+	mi := &file_proto_product_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductResponse) ProtoMessage() {}
+
+func (x *GetProductResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductResponse.ProtoReflect.Descriptor instead.
+func (*GetProductResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetProductResponse) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+// Delete
+type DeleteProductRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProductRequest) Reset() {
+	*x = DeleteProductRequest{}
+	mi := &file_proto_product_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProductRequest) ProtoMessage() {}
+
+func (x *DeleteProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProductRequest.ProtoReflect.Descriptor instead.
+func (*DeleteProductRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteProductRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteProductResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProductResponse) Reset() {
+	*x = DeleteProductResponse{}
+	mi := &file_proto_product_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProductResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProductResponse) ProtoMessage() {}
+
+func (x *DeleteProductResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProductResponse.ProtoReflect.Descriptor instead.
+func (*DeleteProductResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeleteProductResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// List all
+type ListProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProductsRequest) Reset() {
+	*x = ListProductsRequest{}
+	mi := &file_proto_product_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProductsRequest) ProtoMessage() {}
+
+func (x *ListProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProductsRequest.ProtoReflect.Descriptor instead.
+func (*ListProductsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{10}
+}
+
+type ListProductsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Products      []*Product             `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProductsResponse) Reset() {
+	*x = ListProductsResponse{}
+	mi := &file_proto_product_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProductsResponse) ProtoMessage() {}
+
+func (x *ListProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProductsResponse.ProtoReflect.Descriptor instead.
+func (*ListProductsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListProductsResponse) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+// Get by category
+type GetProductsByCategoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Category      string                 `protobuf:"bytes,1,opt,name=category,proto3" json:"category,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductsByCategoryRequest) Reset() {
+	*x = GetProductsByCategoryRequest{}
+	mi := &file_proto_product_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductsByCategoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductsByCategoryRequest) ProtoMessage() {}
+
+func (x *GetProductsByCategoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductsByCategoryRequest.ProtoReflect.Descriptor instead.
+func (*GetProductsByCategoryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetProductsByCategoryRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+type GetProductsByCategoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Products      []*Product             `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductsByCategoryResponse) Reset() {
+	*x = GetProductsByCategoryResponse{}
+	mi := &file_proto_product_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductsByCategoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductsByCategoryResponse) ProtoMessage() {}
+
+func (x *GetProductsByCategoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductsByCategoryResponse.ProtoReflect.Descriptor instead.
+func (*GetProductsByCategoryResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetProductsByCategoryResponse) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+// Get by seller; пустой seller_id — товары вызывающего продавца
+type ListProductsBySellerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SellerId      string                 `protobuf:"bytes,1,opt,name=seller_id,json=sellerId,proto3" json:"seller_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProductsBySellerRequest) Reset() {
+	*x = ListProductsBySellerRequest{}
+	mi := &file_proto_product_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProductsBySellerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProductsBySellerRequest) ProtoMessage() {}
+
+func (x *ListProductsBySellerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProductsBySellerRequest.ProtoReflect.Descriptor instead.
+func (*ListProductsBySellerRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListProductsBySellerRequest) GetSellerId() string {
+	if x != nil {
+		return x.SellerId
+	}
+	return ""
+}
+
+type ListProductsBySellerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Products      []*Product             `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProductsBySellerResponse) Reset() {
+	*x = ListProductsBySellerResponse{}
+	mi := &file_proto_product_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProductsBySellerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProductsBySellerResponse) ProtoMessage() {}
+
+func (x *ListProductsBySellerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProductsBySellerResponse.ProtoReflect.Descriptor instead.
+func (*ListProductsBySellerResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListProductsBySellerResponse) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+// Get by SKU
+type GetSKURequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SkuId         string                 `protobuf:"bytes,1,opt,name=sku_id,json=skuId,proto3" json:"sku_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSKURequest) Reset() {
+	*x = GetSKURequest{}
+	mi := &file_proto_product_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSKURequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSKURequest) ProtoMessage() {}
+
+func (x *GetSKURequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSKURequest.ProtoReflect.Descriptor instead.
+func (*GetSKURequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetSKURequest) GetSkuId() string {
+	if x != nil {
+		return x.SkuId
+	}
+	return ""
+}
+
+type GetSKUResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	Sku           *SKU                   `protobuf:"bytes,2,opt,name=sku,proto3" json:"sku,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSKUResponse) Reset() {
+	*x = GetSKUResponse{}
+	mi := &file_proto_product_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSKUResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSKUResponse) ProtoMessage() {}
+
+func (x *GetSKUResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSKUResponse.ProtoReflect.Descriptor instead.
+func (*GetSKUResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetSKUResponse) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+func (x *GetSKUResponse) GetSku() *SKU {
+	if x != nil {
+		return x.Sku
+	}
+	return nil
+}
+
+// Stock reservation
+type ReserveStockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SkuId         string                 `protobuf:"bytes,1,opt,name=sku_id,json=skuId,proto3" json:"sku_id,omitempty"`
+	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReserveStockRequest) Reset() {
+	*x = ReserveStockRequest{}
+	mi := &file_proto_product_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReserveStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReserveStockRequest) ProtoMessage() {}
+
+func (x *ReserveStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReserveStockRequest.ProtoReflect.Descriptor instead.
+func (*ReserveStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ReserveStockRequest) GetSkuId() string {
+	if x != nil {
+		return x.SkuId
+	}
+	return ""
+}
+
+func (x *ReserveStockRequest) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type ReserveStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SkuId         string                 `protobuf:"bytes,1,opt,name=sku_id,json=skuId,proto3" json:"sku_id,omitempty"`
+	Remaining     int32                  `protobuf:"varint,2,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReserveStockResponse) Reset() {
+	*x = ReserveStockResponse{}
+	mi := &file_proto_product_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReserveStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReserveStockResponse) ProtoMessage() {}
+
+func (x *ReserveStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReserveStockResponse.ProtoReflect.Descriptor instead.
+func (*ReserveStockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ReserveStockResponse) GetSkuId() string {
+	if x != nil {
+		return x.SkuId
+	}
+	return ""
+}
+
+func (x *ReserveStockResponse) GetRemaining() int32 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+type ReleaseStockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SkuId         string                 `protobuf:"bytes,1,opt,name=sku_id,json=skuId,proto3" json:"sku_id,omitempty"`
+	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseStockRequest) Reset() {
+	*x = ReleaseStockRequest{}
+	mi := &file_proto_product_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseStockRequest) ProtoMessage() {}
+
+func (x *ReleaseStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseStockRequest.ProtoReflect.Descriptor instead.
+func (*ReleaseStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ReleaseStockRequest) GetSkuId() string {
+	if x != nil {
+		return x.SkuId
+	}
+	return ""
+}
+
+func (x *ReleaseStockRequest) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type ReleaseStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SkuId         string                 `protobuf:"bytes,1,opt,name=sku_id,json=skuId,proto3" json:"sku_id,omitempty"`
+	Remaining     int32                  `protobuf:"varint,2,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseStockResponse) Reset() {
+	*x = ReleaseStockResponse{}
+	mi := &file_proto_product_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseStockResponse) ProtoMessage() {}
+
+func (x *ReleaseStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseStockResponse.ProtoReflect.Descriptor instead.
+func (*ReleaseStockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ReleaseStockResponse) GetSkuId() string {
+	if x != nil {
+		return x.SkuId
+	}
+	return ""
+}
+
+func (x *ReleaseStockResponse) GetRemaining() int32 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+// format и dry_run читаются из первого сообщения потока, chunk — из всех
+type ImportProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Format        CatalogFormat          `protobuf:"varint,1,opt,name=format,proto3,enum=product.CatalogFormat" json:"format,omitempty"`
+	DryRun        bool                   `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Chunk         []byte                 `protobuf:"bytes,3,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportProductsRequest) Reset() {
+	*x = ImportProductsRequest{}
+	mi := &file_proto_product_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportProductsRequest) ProtoMessage() {}
+
+func (x *ImportProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportProductsRequest.ProtoReflect.Descriptor instead.
+func (*ImportProductsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ImportProductsRequest) GetFormat() CatalogFormat {
+	if x != nil {
+		return x.Format
+	}
+	return CatalogFormat_CATALOG_FORMAT_CSV
+}
+
+func (x *ImportProductsRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *ImportProductsRequest) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+type ImportRowError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Row           int32                  `protobuf:"varint,1,opt,name=row,proto3" json:"row,omitempty"`
+	ExternalSku   string                 `protobuf:"bytes,2,opt,name=external_sku,json=externalSku,proto3" json:"external_sku,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportRowError) Reset() {
+	*x = ImportRowError{}
+	mi := &file_proto_product_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportRowError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportRowError) ProtoMessage() {}
+
+func (x *ImportRowError) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportRowError.ProtoReflect.Descriptor instead.
+func (*ImportRowError) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ImportRowError) GetRow() int32 {
+	if x != nil {
+		return x.Row
+	}
+	return 0
+}
+
+func (x *ImportRowError) GetExternalSku() string {
+	if x != nil {
+		return x.ExternalSku
+	}
+	return ""
+}
+
+func (x *ImportRowError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ImportProductsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Total         int32                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Created       int32                  `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`
+	Updated       int32                  `protobuf:"varint,3,opt,name=updated,proto3" json:"updated,omitempty"`
+	Failed        int32                  `protobuf:"varint,4,opt,name=failed,proto3" json:"failed,omitempty"`
+	DryRun        bool                   `protobuf:"varint,5,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Errors        []*ImportRowError      `protobuf:"bytes,6,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportProductsResponse) Reset() {
+	*x = ImportProductsResponse{}
+	mi := &file_proto_product_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportProductsResponse) ProtoMessage() {}
+
+func (x *ImportProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportProductsResponse.ProtoReflect.Descriptor instead.
+func (*ImportProductsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ImportProductsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ImportProductsResponse) GetCreated() int32 {
+	if x != nil {
+		return x.Created
+	}
+	return 0
+}
+
+func (x *ImportProductsResponse) GetUpdated() int32 {
+	if x != nil {
+		return x.Updated
+	}
+	return 0
+}
+
+func (x *ImportProductsResponse) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *ImportProductsResponse) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *ImportProductsResponse) GetErrors() []*ImportRowError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type ExportProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Format        CatalogFormat          `protobuf:"varint,1,opt,name=format,proto3,enum=product.CatalogFormat" json:"format,omitempty"`
+	Category      string                 `protobuf:"bytes,2,opt,name=category,proto3" json:"category,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportProductsRequest) Reset() {
+	*x = ExportProductsRequest{}
+	mi := &file_proto_product_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportProductsRequest) ProtoMessage() {}
+
+func (x *ExportProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportProductsRequest.ProtoReflect.Descriptor instead.
+func (*ExportProductsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ExportProductsRequest) GetFormat() CatalogFormat {
+	if x != nil {
+		return x.Format
+	}
+	return CatalogFormat_CATALOG_FORMAT_CSV
+}
+
+func (x *ExportProductsRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+type ExportProductsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chunk         []byte                 `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportProductsResponse) Reset() {
+	*x = ExportProductsResponse{}
+	mi := &file_proto_product_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportProductsResponse) ProtoMessage() {}
+
+func (x *ExportProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportProductsResponse.ProtoReflect.Descriptor instead.
+func (*ExportProductsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ExportProductsResponse) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+// Media
+// product_id и content_type читаются из первого сообщения потока
+type UploadProductImageRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Category      string                 `protobuf:"bytes,4,opt,name=category,proto3" json:"category,omitempty"`
-	Price         float64                `protobuf:"fixed64,5,opt,name=price,proto3" json:"price,omitempty"`
-	Quantity      int32                  `protobuf:"varint,6,opt,name=quantity,proto3" json:"quantity,omitempty"`
-	UserId        string                 `protobuf:"bytes,7,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	ContentType   string                 `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Chunk         []byte                 `protobuf:"bytes,3,opt,name=chunk,proto3" json:"chunk,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Product) Reset() {
-	*x = Product{}
-	mi := &file_proto_product_proto_msgTypes[0]
+func (x *UploadProductImageRequest) Reset() {
+	*x = UploadProductImageRequest{}
+	mi := &file_proto_product_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Product) String() string {
+func (x *UploadProductImageRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Product) ProtoMessage() {}
+func (*UploadProductImageRequest) ProtoMessage() {}
 
-func (x *Product) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_proto_msgTypes[0]
+func (x *UploadProductImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -59,83 +1679,100 @@ func (x *Product) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Product.ProtoReflect.Descriptor instead.
-func (*Product) Descriptor() ([]byte, []int) {
-	return file_proto_product_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use UploadProductImageRequest.ProtoReflect.Descriptor instead.
+func (*UploadProductImageRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{27}
 }
 
-func (x *Product) GetId() string {
+func (x *UploadProductImageRequest) GetProductId() string {
 	if x != nil {
-		return x.Id
+		return x.ProductId
 	}
 	return ""
 }
 
-func (x *Product) GetName() string {
+func (x *UploadProductImageRequest) GetContentType() string {
 	if x != nil {
-		return x.Name
+		return x.ContentType
 	}
 	return ""
 }
 
-func (x *Product) GetDescription() string {
+func (x *UploadProductImageRequest) GetChunk() []byte {
 	if x != nil {
-		return x.Description
+		return x.Chunk
 	}
-	return ""
+	return nil
 }
 
-func (x *Product) GetCategory() string {
-	if x != nil {
-		return x.Category
-	}
-	return ""
+type UploadProductImageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Image         *Image                 `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Product) GetPrice() float64 {
-	if x != nil {
-		return x.Price
-	}
-	return 0
+func (x *UploadProductImageResponse) Reset() {
+	*x = UploadProductImageResponse{}
+	mi := &file_proto_product_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *Product) GetQuantity() int32 {
+func (x *UploadProductImageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadProductImageResponse) ProtoMessage() {}
+
+func (x *UploadProductImageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[28]
 	if x != nil {
-		return x.Quantity
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *Product) GetUserId() string {
+// Deprecated: Use UploadProductImageResponse.ProtoReflect.Descriptor instead.
+func (*UploadProductImageResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *UploadProductImageResponse) GetImage() *Image {
 	if x != nil {
-		return x.UserId
+		return x.Image
 	}
-	return ""
+	return nil
 }
 
-// Create
-type CreateProductRequest struct {
+type GetProductImageRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	ImageId       string                 `protobuf:"bytes,2,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
+	Size          string                 `protobuf:"bytes,3,opt,name=size,proto3" json:"size,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateProductRequest) Reset() {
-	*x = CreateProductRequest{}
-	mi := &file_proto_product_proto_msgTypes[1]
+func (x *GetProductImageRequest) Reset() {
+	*x = GetProductImageRequest{}
+	mi := &file_proto_product_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateProductRequest) String() string {
+func (x *GetProductImageRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateProductRequest) ProtoMessage() {}
+func (*GetProductImageRequest) ProtoMessage() {}
 
-func (x *CreateProductRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_proto_msgTypes[1]
+func (x *GetProductImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -146,40 +1783,55 @@ func (x *CreateProductRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateProductRequest.ProtoReflect.Descriptor instead.
-func (*CreateProductRequest) Descriptor() ([]byte, []int) {
-	return file_proto_product_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use GetProductImageRequest.ProtoReflect.Descriptor instead.
+func (*GetProductImageRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *CreateProductRequest) GetProduct() *Product {
+func (x *GetProductImageRequest) GetProductId() string {
 	if x != nil {
-		return x.Product
+		return x.ProductId
 	}
-	return nil
+	return ""
 }
 
-type CreateProductResponse struct {
+func (x *GetProductImageRequest) GetImageId() string {
+	if x != nil {
+		return x.ImageId
+	}
+	return ""
+}
+
+func (x *GetProductImageRequest) GetSize() string {
+	if x != nil {
+		return x.Size
+	}
+	return ""
+}
+
+type GetProductImageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ContentType   string                 `protobuf:"bytes,1,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Chunk         []byte                 `protobuf:"bytes,2,opt,name=chunk,proto3" json:"chunk,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateProductResponse) Reset() {
-	*x = CreateProductResponse{}
-	mi := &file_proto_product_proto_msgTypes[2]
+func (x *GetProductImageResponse) Reset() {
+	*x = GetProductImageResponse{}
+	mi := &file_proto_product_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateProductResponse) String() string {
+func (x *GetProductImageResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateProductResponse) ProtoMessage() {}
+func (*GetProductImageResponse) ProtoMessage() {}
 
-func (x *CreateProductResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_proto_msgTypes[2]
+func (x *GetProductImageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -190,41 +1842,48 @@ func (x *CreateProductResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateProductResponse.ProtoReflect.Descriptor instead.
-func (*CreateProductResponse) Descriptor() ([]byte, []int) {
-	return file_proto_product_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use GetProductImageResponse.ProtoReflect.Descriptor instead.
+func (*GetProductImageResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *CreateProductResponse) GetId() string {
+func (x *GetProductImageResponse) GetContentType() string {
 	if x != nil {
-		return x.Id
+		return x.ContentType
 	}
 	return ""
 }
 
-// Get by ID
-type GetProductRequest struct {
+func (x *GetProductImageResponse) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+type DeleteProductImageRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	ImageId       string                 `protobuf:"bytes,2,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetProductRequest) Reset() {
-	*x = GetProductRequest{}
-	mi := &file_proto_product_proto_msgTypes[3]
+func (x *DeleteProductImageRequest) Reset() {
+	*x = DeleteProductImageRequest{}
+	mi := &file_proto_product_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetProductRequest) String() string {
+func (x *DeleteProductImageRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetProductRequest) ProtoMessage() {}
+func (*DeleteProductImageRequest) ProtoMessage() {}
 
-func (x *GetProductRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_proto_msgTypes[3]
+func (x *DeleteProductImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -235,40 +1894,47 @@ func (x *GetProductRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetProductRequest.ProtoReflect.Descriptor instead.
-func (*GetProductRequest) Descriptor() ([]byte, []int) {
-	return file_proto_product_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use DeleteProductImageRequest.ProtoReflect.Descriptor instead.
+func (*DeleteProductImageRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{31}
 }
 
-func (x *GetProductRequest) GetId() string {
+func (x *DeleteProductImageRequest) GetProductId() string {
 	if x != nil {
-		return x.Id
+		return x.ProductId
 	}
 	return ""
 }
 
-type GetProductResponse struct {
+func (x *DeleteProductImageRequest) GetImageId() string {
+	if x != nil {
+		return x.ImageId
+	}
+	return ""
+}
+
+type DeleteProductImageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	Images        []*Image               `protobuf:"bytes,1,rep,name=images,proto3" json:"images,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetProductResponse) Reset() {
-	*x = GetProductResponse{}
-	mi := &file_proto_product_proto_msgTypes[4]
+func (x *DeleteProductImageResponse) Reset() {
+	*x = DeleteProductImageResponse{}
+	mi := &file_proto_product_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetProductResponse) String() string {
+func (x *DeleteProductImageResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetProductResponse) ProtoMessage() {}
+func (*DeleteProductImageResponse) ProtoMessage() {}
 
-func (x *GetProductResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_proto_msgTypes[4]
+func (x *DeleteProductImageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -279,41 +1945,41 @@ func (x *GetProductResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetProductResponse.ProtoReflect.Descriptor instead.
-func (*GetProductResponse) Descriptor() ([]byte, []int) {
-	return file_proto_product_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use DeleteProductImageResponse.ProtoReflect.Descriptor instead.
+func (*DeleteProductImageResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{32}
 }
 
-func (x *GetProductResponse) GetProduct() *Product {
+func (x *DeleteProductImageResponse) GetImages() []*Image {
 	if x != nil {
-		return x.Product
+		return x.Images
 	}
 	return nil
 }
 
-// Delete
-type DeleteProductRequest struct {
+type ReorderProductImagesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	ImageIds      []string               `protobuf:"bytes,2,rep,name=image_ids,json=imageIds,proto3" json:"image_ids,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteProductRequest) Reset() {
-	*x = DeleteProductRequest{}
-	mi := &file_proto_product_proto_msgTypes[5]
+func (x *ReorderProductImagesRequest) Reset() {
+	*x = ReorderProductImagesRequest{}
+	mi := &file_proto_product_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteProductRequest) String() string {
+func (x *ReorderProductImagesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteProductRequest) ProtoMessage() {}
+func (*ReorderProductImagesRequest) ProtoMessage() {}
 
-func (x *DeleteProductRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_proto_msgTypes[5]
+func (x *ReorderProductImagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -324,40 +1990,47 @@ func (x *DeleteProductRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteProductRequest.ProtoReflect.Descriptor instead.
-func (*DeleteProductRequest) Descriptor() ([]byte, []int) {
-	return file_proto_product_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use ReorderProductImagesRequest.ProtoReflect.Descriptor instead.
+func (*ReorderProductImagesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *DeleteProductRequest) GetId() string {
+func (x *ReorderProductImagesRequest) GetProductId() string {
 	if x != nil {
-		return x.Id
+		return x.ProductId
 	}
 	return ""
 }
 
-type DeleteProductResponse struct {
+func (x *ReorderProductImagesRequest) GetImageIds() []string {
+	if x != nil {
+		return x.ImageIds
+	}
+	return nil
+}
+
+type ReorderProductImagesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Images        []*Image               `protobuf:"bytes,1,rep,name=images,proto3" json:"images,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteProductResponse) Reset() {
-	*x = DeleteProductResponse{}
-	mi := &file_proto_product_proto_msgTypes[6]
+func (x *ReorderProductImagesResponse) Reset() {
+	*x = ReorderProductImagesResponse{}
+	mi := &file_proto_product_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteProductResponse) String() string {
+func (x *ReorderProductImagesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteProductResponse) ProtoMessage() {}
+func (*ReorderProductImagesResponse) ProtoMessage() {}
 
-func (x *DeleteProductResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_proto_msgTypes[6]
+func (x *ReorderProductImagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -368,40 +2041,42 @@ func (x *DeleteProductResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteProductResponse.ProtoReflect.Descriptor instead.
-func (*DeleteProductResponse) Descriptor() ([]byte, []int) {
-	return file_proto_product_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use ReorderProductImagesResponse.ProtoReflect.Descriptor instead.
+func (*ReorderProductImagesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *DeleteProductResponse) GetMessage() string {
+func (x *ReorderProductImagesResponse) GetImages() []*Image {
 	if x != nil {
-		return x.Message
+		return x.Images
 	}
-	return ""
+	return nil
 }
 
-// List all
-type ListProductsRequest struct {
+// Pricing
+type SchedulePriceRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Schedule      *PriceSchedule         `protobuf:"bytes,2,opt,name=schedule,proto3" json:"schedule,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListProductsRequest) Reset() {
-	*x = ListProductsRequest{}
-	mi := &file_proto_product_proto_msgTypes[7]
+func (x *SchedulePriceRequest) Reset() {
+	*x = SchedulePriceRequest{}
+	mi := &file_proto_product_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListProductsRequest) String() string {
+func (x *SchedulePriceRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListProductsRequest) ProtoMessage() {}
+func (*SchedulePriceRequest) ProtoMessage() {}
 
-func (x *ListProductsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_proto_msgTypes[7]
+func (x *SchedulePriceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -412,33 +2087,47 @@ func (x *ListProductsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListProductsRequest.ProtoReflect.Descriptor instead.
-func (*ListProductsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_product_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use SchedulePriceRequest.ProtoReflect.Descriptor instead.
+func (*SchedulePriceRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{35}
 }
 
-type ListProductsResponse struct {
+func (x *SchedulePriceRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *SchedulePriceRequest) GetSchedule() *PriceSchedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+type SchedulePriceResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Products      []*Product             `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListProductsResponse) Reset() {
-	*x = ListProductsResponse{}
-	mi := &file_proto_product_proto_msgTypes[8]
+func (x *SchedulePriceResponse) Reset() {
+	*x = SchedulePriceResponse{}
+	mi := &file_proto_product_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListProductsResponse) String() string {
+func (x *SchedulePriceResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListProductsResponse) ProtoMessage() {}
+func (*SchedulePriceResponse) ProtoMessage() {}
 
-func (x *ListProductsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_proto_msgTypes[8]
+func (x *SchedulePriceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -449,41 +2138,41 @@ func (x *ListProductsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListProductsResponse.ProtoReflect.Descriptor instead.
-func (*ListProductsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_product_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use SchedulePriceResponse.ProtoReflect.Descriptor instead.
+func (*SchedulePriceResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *ListProductsResponse) GetProducts() []*Product {
+func (x *SchedulePriceResponse) GetProduct() *Product {
 	if x != nil {
-		return x.Products
+		return x.Product
 	}
 	return nil
 }
 
-// Get by category
-type GetProductsByCategoryRequest struct {
+type CancelPriceScheduleRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Category      string                 `protobuf:"bytes,1,opt,name=category,proto3" json:"category,omitempty"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	ScheduleId    string                 `protobuf:"bytes,2,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetProductsByCategoryRequest) Reset() {
-	*x = GetProductsByCategoryRequest{}
-	mi := &file_proto_product_proto_msgTypes[9]
+func (x *CancelPriceScheduleRequest) Reset() {
+	*x = CancelPriceScheduleRequest{}
+	mi := &file_proto_product_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetProductsByCategoryRequest) String() string {
+func (x *CancelPriceScheduleRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetProductsByCategoryRequest) ProtoMessage() {}
+func (*CancelPriceScheduleRequest) ProtoMessage() {}
 
-func (x *GetProductsByCategoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_proto_msgTypes[9]
+func (x *CancelPriceScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -494,40 +2183,47 @@ func (x *GetProductsByCategoryRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetProductsByCategoryRequest.ProtoReflect.Descriptor instead.
-func (*GetProductsByCategoryRequest) Descriptor() ([]byte, []int) {
-	return file_proto_product_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use CancelPriceScheduleRequest.ProtoReflect.Descriptor instead.
+func (*CancelPriceScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *GetProductsByCategoryRequest) GetCategory() string {
+func (x *CancelPriceScheduleRequest) GetProductId() string {
 	if x != nil {
-		return x.Category
+		return x.ProductId
 	}
 	return ""
 }
 
-type GetProductsByCategoryResponse struct {
+func (x *CancelPriceScheduleRequest) GetScheduleId() string {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return ""
+}
+
+type CancelPriceScheduleResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Products      []*Product             `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetProductsByCategoryResponse) Reset() {
-	*x = GetProductsByCategoryResponse{}
-	mi := &file_proto_product_proto_msgTypes[10]
+func (x *CancelPriceScheduleResponse) Reset() {
+	*x = CancelPriceScheduleResponse{}
+	mi := &file_proto_product_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetProductsByCategoryResponse) String() string {
+func (x *CancelPriceScheduleResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetProductsByCategoryResponse) ProtoMessage() {}
+func (*CancelPriceScheduleResponse) ProtoMessage() {}
 
-func (x *GetProductsByCategoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_proto_msgTypes[10]
+func (x *CancelPriceScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -538,14 +2234,14 @@ func (x *GetProductsByCategoryResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetProductsByCategoryResponse.ProtoReflect.Descriptor instead.
-func (*GetProductsByCategoryResponse) Descriptor() ([]byte, []int) {
-	return file_proto_product_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use CancelPriceScheduleResponse.ProtoReflect.Descriptor instead.
+func (*CancelPriceScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_proto_rawDescGZIP(), []int{38}
 }
 
-func (x *GetProductsByCategoryResponse) GetProducts() []*Product {
+func (x *CancelPriceScheduleResponse) GetProduct() *Product {
 	if x != nil {
-		return x.Products
+		return x.Product
 	}
 	return nil
 }
@@ -554,7 +2250,7 @@ var File_proto_product_proto protoreflect.FileDescriptor
 
 const file_proto_product_proto_rawDesc = "" +
 	"\n" +
-	"\x13proto/product.proto\x12\aproduct\"\xb6\x01\n" +
+	"\x13proto/product.proto\x12\aproduct\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8d\x03\n" +
 	"\aProduct\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
@@ -562,7 +2258,38 @@ const file_proto_product_proto_rawDesc = "" +
 	"\bcategory\x18\x04 \x01(\tR\bcategory\x12\x14\n" +
 	"\x05price\x18\x05 \x01(\x01R\x05price\x12\x1a\n" +
 	"\bquantity\x18\x06 \x01(\x05R\bquantity\x12\x17\n" +
-	"\auser_id\x18\a \x01(\tR\x06userId\"B\n" +
+	"\auser_id\x18\a \x01(\tR\x06userId\x12 \n" +
+	"\x04skus\x18\b \x03(\v2\f.product.SKUR\x04skus\x12!\n" +
+	"\fexternal_sku\x18\t \x01(\tR\vexternalSku\x12&\n" +
+	"\x06images\x18\n" +
+	" \x03(\v2\x0e.product.ImageR\x06images\x12'\n" +
+	"\x0feffective_price\x18\v \x01(\x01R\x0eeffectivePrice\x12?\n" +
+	"\x0fprice_schedules\x18\f \x03(\v2\x16.product.PriceScheduleR\x0epriceSchedules\"\xd3\x01\n" +
+	"\rPriceSchedule\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12.\n" +
+	"\x04kind\x18\x02 \x01(\x0e2\x1a.product.PriceScheduleKindR\x04kind\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x01R\x05price\x127\n" +
+	"\tstarts_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\bstartsAt\x123\n" +
+	"\aends_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x06endsAt\"\xa1\x01\n" +
+	"\x03SKU\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\tR\x04size\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\x01R\x06weight\x12\x12\n" +
+	"\x04unit\x18\x04 \x01(\tR\x04unit\x12\x14\n" +
+	"\x05price\x18\x05 \x01(\x01R\x05price\x12\x1a\n" +
+	"\bquantity\x18\x06 \x01(\x05R\bquantity\x12\x18\n" +
+	"\abarcode\x18\a \x01(\tR\abarcode\"\xa2\x02\n" +
+	"\x05Image\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x14\n" +
+	"\x05width\x18\x04 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x05 \x01(\x05R\x06height\x12\x1a\n" +
+	"\bposition\x18\x06 \x01(\x05R\bposition\x12H\n" +
+	"\x0ethumbnail_urls\x18\a \x03(\v2!.product.Image.ThumbnailUrlsEntryR\rthumbnailUrls\x1a@\n" +
+	"\x12ThumbnailUrlsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"B\n" +
 	"\x14CreateProductRequest\x12*\n" +
 	"\aproduct\x18\x01 \x01(\v2\x10.product.ProductR\aproduct\"'\n" +
 	"\x15CreateProductResponse\x12\x0e\n" +
@@ -581,14 +2308,113 @@ const file_proto_product_proto_rawDesc = "" +
 	"\x1cGetProductsByCategoryRequest\x12\x1a\n" +
 	"\bcategory\x18\x01 \x01(\tR\bcategory\"M\n" +
 	"\x1dGetProductsByCategoryResponse\x12,\n" +
-	"\bproducts\x18\x01 \x03(\v2\x10.product.ProductR\bproducts2\xac\x03\n" +
+	"\bproducts\x18\x01 \x03(\v2\x10.product.ProductR\bproducts\":\n" +
+	"\x1bListProductsBySellerRequest\x12\x1b\n" +
+	"\tseller_id\x18\x01 \x01(\tR\bsellerId\"L\n" +
+	"\x1cListProductsBySellerResponse\x12,\n" +
+	"\bproducts\x18\x01 \x03(\v2\x10.product.ProductR\bproducts\"&\n" +
+	"\rGetSKURequest\x12\x15\n" +
+	"\x06sku_id\x18\x01 \x01(\tR\x05skuId\"\\\n" +
+	"\x0eGetSKUResponse\x12*\n" +
+	"\aproduct\x18\x01 \x01(\v2\x10.product.ProductR\aproduct\x12\x1e\n" +
+	"\x03sku\x18\x02 \x01(\v2\f.product.SKUR\x03sku\"H\n" +
+	"\x13ReserveStockRequest\x12\x15\n" +
+	"\x06sku_id\x18\x01 \x01(\tR\x05skuId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"K\n" +
+	"\x14ReserveStockResponse\x12\x15\n" +
+	"\x06sku_id\x18\x01 \x01(\tR\x05skuId\x12\x1c\n" +
+	"\tremaining\x18\x02 \x01(\x05R\tremaining\"H\n" +
+	"\x13ReleaseStockRequest\x12\x15\n" +
+	"\x06sku_id\x18\x01 \x01(\tR\x05skuId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"K\n" +
+	"\x14ReleaseStockResponse\x12\x15\n" +
+	"\x06sku_id\x18\x01 \x01(\tR\x05skuId\x12\x1c\n" +
+	"\tremaining\x18\x02 \x01(\x05R\tremaining\"v\n" +
+	"\x15ImportProductsRequest\x12.\n" +
+	"\x06format\x18\x01 \x01(\x0e2\x16.product.CatalogFormatR\x06format\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\x12\x14\n" +
+	"\x05chunk\x18\x03 \x01(\fR\x05chunk\"_\n" +
+	"\x0eImportRowError\x12\x10\n" +
+	"\x03row\x18\x01 \x01(\x05R\x03row\x12!\n" +
+	"\fexternal_sku\x18\x02 \x01(\tR\vexternalSku\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"\xc4\x01\n" +
+	"\x16ImportProductsResponse\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x05R\x05total\x12\x18\n" +
+	"\acreated\x18\x02 \x01(\x05R\acreated\x12\x18\n" +
+	"\aupdated\x18\x03 \x01(\x05R\aupdated\x12\x16\n" +
+	"\x06failed\x18\x04 \x01(\x05R\x06failed\x12\x17\n" +
+	"\adry_run\x18\x05 \x01(\bR\x06dryRun\x12/\n" +
+	"\x06errors\x18\x06 \x03(\v2\x17.product.ImportRowErrorR\x06errors\"c\n" +
+	"\x15ExportProductsRequest\x12.\n" +
+	"\x06format\x18\x01 \x01(\x0e2\x16.product.CatalogFormatR\x06format\x12\x1a\n" +
+	"\bcategory\x18\x02 \x01(\tR\bcategory\".\n" +
+	"\x16ExportProductsResponse\x12\x14\n" +
+	"\x05chunk\x18\x01 \x01(\fR\x05chunk\"s\n" +
+	"\x19UploadProductImageRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\x12\x14\n" +
+	"\x05chunk\x18\x03 \x01(\fR\x05chunk\"B\n" +
+	"\x1aUploadProductImageResponse\x12$\n" +
+	"\x05image\x18\x01 \x01(\v2\x0e.product.ImageR\x05image\"f\n" +
+	"\x16GetProductImageRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x19\n" +
+	"\bimage_id\x18\x02 \x01(\tR\aimageId\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\tR\x04size\"R\n" +
+	"\x17GetProductImageResponse\x12!\n" +
+	"\fcontent_type\x18\x01 \x01(\tR\vcontentType\x12\x14\n" +
+	"\x05chunk\x18\x02 \x01(\fR\x05chunk\"U\n" +
+	"\x19DeleteProductImageRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x19\n" +
+	"\bimage_id\x18\x02 \x01(\tR\aimageId\"D\n" +
+	"\x1aDeleteProductImageResponse\x12&\n" +
+	"\x06images\x18\x01 \x03(\v2\x0e.product.ImageR\x06images\"Y\n" +
+	"\x1bReorderProductImagesRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1b\n" +
+	"\timage_ids\x18\x02 \x03(\tR\bimageIds\"F\n" +
+	"\x1cReorderProductImagesResponse\x12&\n" +
+	"\x06images\x18\x01 \x03(\v2\x0e.product.ImageR\x06images\"i\n" +
+	"\x14SchedulePriceRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x122\n" +
+	"\bschedule\x18\x02 \x01(\v2\x16.product.PriceScheduleR\bschedule\"C\n" +
+	"\x15SchedulePriceResponse\x12*\n" +
+	"\aproduct\x18\x01 \x01(\v2\x10.product.ProductR\aproduct\"\\\n" +
+	"\x1aCancelPriceScheduleRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1f\n" +
+	"\vschedule_id\x18\x02 \x01(\tR\n" +
+	"scheduleId\"I\n" +
+	"\x1bCancelPriceScheduleResponse\x12*\n" +
+	"\aproduct\x18\x01 \x01(\v2\x10.product.ProductR\aproduct*R\n" +
+	"\x11PriceScheduleKind\x12\x1c\n" +
+	"\x18PRICE_SCHEDULE_KIND_SALE\x10\x00\x12\x1f\n" +
+	"\x1bPRICE_SCHEDULE_KIND_REGULAR\x10\x01*A\n" +
+	"\rCatalogFormat\x12\x16\n" +
+	"\x12CATALOG_FORMAT_CSV\x10\x00\x12\x18\n" +
+	"\x14CATALOG_FORMAT_JSONL\x10\x012\xbf\v\n" +
 	"\x0eProductService\x12N\n" +
 	"\rCreateProduct\x12\x1d.product.CreateProductRequest\x1a\x1e.product.CreateProductResponse\x12E\n" +
 	"\n" +
 	"GetProduct\x12\x1a.product.GetProductRequest\x1a\x1b.product.GetProductResponse\x12N\n" +
 	"\rDeleteProduct\x12\x1d.product.DeleteProductRequest\x1a\x1e.product.DeleteProductResponse\x12K\n" +
 	"\fListProducts\x12\x1c.product.ListProductsRequest\x1a\x1d.product.ListProductsResponse\x12f\n" +
-	"\x15GetProductsByCategory\x12%.product.GetProductsByCategoryRequest\x1a&.product.GetProductsByCategoryResponseB Z\x1eclient-service/proto/productpbb\x06proto3"
+	"\x15GetProductsByCategory\x12%.product.GetProductsByCategoryRequest\x1a&.product.GetProductsByCategoryResponse\x12c\n" +
+	"\x14ListProductsBySeller\x12$.product.ListProductsBySellerRequest\x1a%.product.ListProductsBySellerResponse\x129\n" +
+	"\x06GetSKU\x12\x16.product.GetSKURequest\x1a\x17.product.GetSKUResponse\x12K\n" +
+	"\fReserveStock\x12\x1c.product.ReserveStockRequest\x1a\x1d.product.ReserveStockResponse\x12K\n" +
+	"\fReleaseStock\x12\x1c.product.ReleaseStockRequest\x1a\x1d.product.ReleaseStockResponse\x12S\n" +
+	"\x0eImportProducts\x12\x1e.product.ImportProductsRequest\x1a\x1f.product.ImportProductsResponse(\x01\x12S\n" +
+	"\x0eExportProducts\x12\x1e.product.ExportProductsRequest\x1a\x1f.product.ExportProductsResponse0\x01\x12_\n" +
+	"\x12UploadProductImage\x12\".product.UploadProductImageRequest\x1a#.product.UploadProductImageResponse(\x01\x12V\n" +
+	"\x0fGetProductImage\x12\x1f.product.GetProductImageRequest\x1a .product.GetProductImageResponse0\x01\x12]\n" +
+	"\x12DeleteProductImage\x12\".product.DeleteProductImageRequest\x1a#.product.DeleteProductImageResponse\x12c\n" +
+	"\x14ReorderProductImages\x12$.product.ReorderProductImagesRequest\x1a%.product.ReorderProductImagesResponse\x12N\n" +
+	"\rSchedulePrice\x12\x1d.product.SchedulePriceRequest\x1a\x1e.product.SchedulePriceResponse\x12`\n" +
+	"\x13CancelPriceSchedule\x12#.product.CancelPriceScheduleRequest\x1a$.product.CancelPriceScheduleResponseB Z\x1eclient-service/proto/productpbb\x06proto3"
 
 var (
 	file_proto_product_proto_rawDescOnce sync.Once
@@ -602,40 +2428,116 @@ func file_proto_product_proto_rawDescGZIP() []byte {
 	return file_proto_product_proto_rawDescData
 }
 
-var file_proto_product_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_proto_product_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_product_proto_msgTypes = make([]protoimpl.MessageInfo, 40)
 var file_proto_product_proto_goTypes = []any{
-	(*Product)(nil),                       // 0: product.Product
-	(*CreateProductRequest)(nil),          // 1: product.CreateProductRequest
-	(*CreateProductResponse)(nil),         // 2: product.CreateProductResponse
-	(*GetProductRequest)(nil),             // 3: product.GetProductRequest
-	(*GetProductResponse)(nil),            // 4: product.GetProductResponse
-	(*DeleteProductRequest)(nil),          // 5: product.DeleteProductRequest
-	(*DeleteProductResponse)(nil),         // 6: product.DeleteProductResponse
-	(*ListProductsRequest)(nil),           // 7: product.ListProductsRequest
-	(*ListProductsResponse)(nil),          // 8: product.ListProductsResponse
-	(*GetProductsByCategoryRequest)(nil),  // 9: product.GetProductsByCategoryRequest
-	(*GetProductsByCategoryResponse)(nil), // 10: product.GetProductsByCategoryResponse
+	(PriceScheduleKind)(0),                // 0: product.PriceScheduleKind
+	(CatalogFormat)(0),                    // 1: product.CatalogFormat
+	(*Product)(nil),                       // 2: product.Product
+	(*PriceSchedule)(nil),                 // 3: product.PriceSchedule
+	(*SKU)(nil),                           // 4: product.SKU
+	(*Image)(nil),                         // 5: product.Image
+	(*CreateProductRequest)(nil),          // 6: product.CreateProductRequest
+	(*CreateProductResponse)(nil),         // 7: product.CreateProductResponse
+	(*GetProductRequest)(nil),             // 8: product.GetProductRequest
+	(*GetProductResponse)(nil),            // 9: product.GetProductResponse
+	(*DeleteProductRequest)(nil),          // 10: product.DeleteProductRequest
+	(*DeleteProductResponse)(nil),         // 11: product.DeleteProductResponse
+	(*ListProductsRequest)(nil),           // 12: product.ListProductsRequest
+	(*ListProductsResponse)(nil),          // 13: product.ListProductsResponse
+	(*GetProductsByCategoryRequest)(nil),  // 14: product.GetProductsByCategoryRequest
+	(*GetProductsByCategoryResponse)(nil), // 15: product.GetProductsByCategoryResponse
+	(*ListProductsBySellerRequest)(nil),   // 16: product.ListProductsBySellerRequest
+	(*ListProductsBySellerResponse)(nil),  // 17: product.ListProductsBySellerResponse
+	(*GetSKURequest)(nil),                 // 18: product.GetSKURequest
+	(*GetSKUResponse)(nil),                // 19: product.GetSKUResponse
+	(*ReserveStockRequest)(nil),           // 20: product.ReserveStockRequest
+	(*ReserveStockResponse)(nil),          // 21: product.ReserveStockResponse
+	(*ReleaseStockRequest)(nil),           // 22: product.ReleaseStockRequest
+	(*ReleaseStockResponse)(nil),          // 23: product.ReleaseStockResponse
+	(*ImportProductsRequest)(nil),         // 24: product.ImportProductsRequest
+	(*ImportRowError)(nil),                // 25: product.ImportRowError
+	(*ImportProductsResponse)(nil),        // 26: product.ImportProductsResponse
+	(*ExportProductsRequest)(nil),         // 27: product.ExportProductsRequest
+	(*ExportProductsResponse)(nil),        // 28: product.ExportProductsResponse
+	(*UploadProductImageRequest)(nil),     // 29: product.UploadProductImageRequest
+	(*UploadProductImageResponse)(nil),    // 30: product.UploadProductImageResponse
+	(*GetProductImageRequest)(nil),        // 31: product.GetProductImageRequest
+	(*GetProductImageResponse)(nil),       // 32: product.GetProductImageResponse
+	(*DeleteProductImageRequest)(nil),     // 33: product.DeleteProductImageRequest
+	(*DeleteProductImageResponse)(nil),    // 34: product.DeleteProductImageResponse
+	(*ReorderProductImagesRequest)(nil),   // 35: product.ReorderProductImagesRequest
+	(*ReorderProductImagesResponse)(nil),  // 36: product.ReorderProductImagesResponse
+	(*SchedulePriceRequest)(nil),          // 37: product.SchedulePriceRequest
+	(*SchedulePriceResponse)(nil),         // 38: product.SchedulePriceResponse
+	(*CancelPriceScheduleRequest)(nil),    // 39: product.CancelPriceScheduleRequest
+	(*CancelPriceScheduleResponse)(nil),   // 40: product.CancelPriceScheduleResponse
+	nil,                                   // 41: product.Image.ThumbnailUrlsEntry
+	(*timestamppb.Timestamp)(nil),         // 42: google.protobuf.Timestamp
 }
 var file_proto_product_proto_depIdxs = []int32{
-	0,  // 0: product.CreateProductRequest.product:type_name -> product.Product
-	0,  // 1: product.GetProductResponse.product:type_name -> product.Product
-	0,  // 2: product.ListProductsResponse.products:type_name -> product.Product
-	0,  // 3: product.GetProductsByCategoryResponse.products:type_name -> product.Product
-	1,  // 4: product.ProductService.CreateProduct:input_type -> product.CreateProductRequest
-	3,  // 5: product.ProductService.GetProduct:input_type -> product.GetProductRequest
-	5,  // 6: product.ProductService.DeleteProduct:input_type -> product.DeleteProductRequest
-	7,  // 7: product.ProductService.ListProducts:input_type -> product.ListProductsRequest
-	9,  // 8: product.ProductService.GetProductsByCategory:input_type -> product.GetProductsByCategoryRequest
-	2,  // 9: product.ProductService.CreateProduct:output_type -> product.CreateProductResponse
-	4,  // 10: product.ProductService.GetProduct:output_type -> product.GetProductResponse
-	6,  // 11: product.ProductService.DeleteProduct:output_type -> product.DeleteProductResponse
-	8,  // 12: product.ProductService.ListProducts:output_type -> product.ListProductsResponse
-	10, // 13: product.ProductService.GetProductsByCategory:output_type -> product.GetProductsByCategoryResponse
-	9,  // [9:14] is the sub-list for method output_type
-	4,  // [4:9] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	4,  // 0: product.Product.skus:type_name -> product.SKU
+	5,  // 1: product.Product.images:type_name -> product.Image
+	3,  // 2: product.Product.price_schedules:type_name -> product.PriceSchedule
+	0,  // 3: product.PriceSchedule.kind:type_name -> product.PriceScheduleKind
+	42, // 4: product.PriceSchedule.starts_at:type_name -> google.protobuf.Timestamp
+	42, // 5: product.PriceSchedule.ends_at:type_name -> google.protobuf.Timestamp
+	41, // 6: product.Image.thumbnail_urls:type_name -> product.Image.ThumbnailUrlsEntry
+	2,  // 7: product.CreateProductRequest.product:type_name -> product.Product
+	2,  // 8: product.GetProductResponse.product:type_name -> product.Product
+	2,  // 9: product.ListProductsResponse.products:type_name -> product.Product
+	2,  // 10: product.GetProductsByCategoryResponse.products:type_name -> product.Product
+	2,  // 11: product.ListProductsBySellerResponse.products:type_name -> product.Product
+	2,  // 12: product.GetSKUResponse.product:type_name -> product.Product
+	4,  // 13: product.GetSKUResponse.sku:type_name -> product.SKU
+	1,  // 14: product.ImportProductsRequest.format:type_name -> product.CatalogFormat
+	25, // 15: product.ImportProductsResponse.errors:type_name -> product.ImportRowError
+	1,  // 16: product.ExportProductsRequest.format:type_name -> product.CatalogFormat
+	5,  // 17: product.UploadProductImageResponse.image:type_name -> product.Image
+	5,  // 18: product.DeleteProductImageResponse.images:type_name -> product.Image
+	5,  // 19: product.ReorderProductImagesResponse.images:type_name -> product.Image
+	3,  // 20: product.SchedulePriceRequest.schedule:type_name -> product.PriceSchedule
+	2,  // 21: product.SchedulePriceResponse.product:type_name -> product.Product
+	2,  // 22: product.CancelPriceScheduleResponse.product:type_name -> product.Product
+	6,  // 23: product.ProductService.CreateProduct:input_type -> product.CreateProductRequest
+	8,  // 24: product.ProductService.GetProduct:input_type -> product.GetProductRequest
+	10, // 25: product.ProductService.DeleteProduct:input_type -> product.DeleteProductRequest
+	12, // 26: product.ProductService.ListProducts:input_type -> product.ListProductsRequest
+	14, // 27: product.ProductService.GetProductsByCategory:input_type -> product.GetProductsByCategoryRequest
+	16, // 28: product.ProductService.ListProductsBySeller:input_type -> product.ListProductsBySellerRequest
+	18, // 29: product.ProductService.GetSKU:input_type -> product.GetSKURequest
+	20, // 30: product.ProductService.ReserveStock:input_type -> product.ReserveStockRequest
+	22, // 31: product.ProductService.ReleaseStock:input_type -> product.ReleaseStockRequest
+	24, // 32: product.ProductService.ImportProducts:input_type -> product.ImportProductsRequest
+	27, // 33: product.ProductService.ExportProducts:input_type -> product.ExportProductsRequest
+	29, // 34: product.ProductService.UploadProductImage:input_type -> product.UploadProductImageRequest
+	31, // 35: product.ProductService.GetProductImage:input_type -> product.GetProductImageRequest
+	33, // 36: product.ProductService.DeleteProductImage:input_type -> product.DeleteProductImageRequest
+	35, // 37: product.ProductService.ReorderProductImages:input_type -> product.ReorderProductImagesRequest
+	37, // 38: product.ProductService.SchedulePrice:input_type -> product.SchedulePriceRequest
+	39, // 39: product.ProductService.CancelPriceSchedule:input_type -> product.CancelPriceScheduleRequest
+	7,  // 40: product.ProductService.CreateProduct:output_type -> product.CreateProductResponse
+	9,  // 41: product.ProductService.GetProduct:output_type -> product.GetProductResponse
+	11, // 42: product.ProductService.DeleteProduct:output_type -> product.DeleteProductResponse
+	13, // 43: product.ProductService.ListProducts:output_type -> product.ListProductsResponse
+	15, // 44: product.ProductService.GetProductsByCategory:output_type -> product.GetProductsByCategoryResponse
+	17, // 45: product.ProductService.ListProductsBySeller:output_type -> product.ListProductsBySellerResponse
+	19, // 46: product.ProductService.GetSKU:output_type -> product.GetSKUResponse
+	21, // 47: product.ProductService.ReserveStock:output_type -> product.ReserveStockResponse
+	23, // 48: product.ProductService.ReleaseStock:output_type -> product.ReleaseStockResponse
+	26, // 49: product.ProductService.ImportProducts:output_type -> product.ImportProductsResponse
+	28, // 50: product.ProductService.ExportProducts:output_type -> product.ExportProductsResponse
+	30, // 51: product.ProductService.UploadProductImage:output_type -> product.UploadProductImageResponse
+	32, // 52: product.ProductService.GetProductImage:output_type -> product.GetProductImageResponse
+	34, // 53: product.ProductService.DeleteProductImage:output_type -> product.DeleteProductImageResponse
+	36, // 54: product.ProductService.ReorderProductImages:output_type -> product.ReorderProductImagesResponse
+	38, // 55: product.ProductService.SchedulePrice:output_type -> product.SchedulePriceResponse
+	40, // 56: product.ProductService.CancelPriceSchedule:output_type -> product.CancelPriceScheduleResponse
+	40, // [40:57] is the sub-list for method output_type
+	23, // [23:40] is the sub-list for method input_type
+	23, // [23:23] is the sub-list for extension type_name
+	23, // [23:23] is the sub-list for extension extendee
+	0,  // [0:23] is the sub-list for field type_name
 }
 
 func init() { file_proto_product_proto_init() }
@@ -648,13 +2550,14 @@ func file_proto_product_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_product_proto_rawDesc), len(file_proto_product_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   11,
+			NumEnums:      2,
+			NumMessages:   40,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_product_proto_goTypes,
 		DependencyIndexes: file_proto_product_proto_depIdxs,
+		EnumInfos:         file_proto_product_proto_enumTypes,
 		MessageInfos:      file_proto_product_proto_msgTypes,
 	}.Build()
 	File_proto_product_proto = out.File
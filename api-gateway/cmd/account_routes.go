@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	userpb "user-service/proto/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registerAccountRoutes serves password resets and changes, email
+// verification, two-factor setup, the profile and the export and erasure of
+// the user's data. authed has to authenticate the caller and set user_id.
+func registerAccountRoutes(r *gin.Engine, authed gin.HandlerFunc, client userpb.UserServiceClient) {
+	reply := func(c *gin.Context, res interface{}, err error) {
+		if err != nil {
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+
+	// The answer is the same whether or not the email is registered.
+	r.POST("/auth/password/reset", func(c *gin.Context) {
+		var req struct {
+			Email string `json:"email" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		res, err := client.RequestPasswordReset(context.Background(), &userpb.RequestPasswordResetRequest{
+			Email:     req.Email,
+			IpAddress: c.ClientIP(),
+		})
+		reply(c, res, err)
+	})
+
+	r.POST("/auth/password/reset/confirm", func(c *gin.Context) {
+		var req struct {
+			Token       string `json:"token" binding:"required"`
+			NewPassword string `json:"new_password" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		res, err := client.ConfirmPasswordReset(context.Background(), &userpb.ConfirmPasswordResetRequest{
+			Token:       req.Token,
+			NewPassword: req.NewPassword,
+		})
+		reply(c, res, err)
+	})
+
+	r.POST("/auth/password/change", authed, func(c *gin.Context) {
+		var req struct {
+			CurrentPassword string `json:"current_password" binding:"required"`
+			NewPassword     string `json:"new_password" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		res, err := client.ChangePassword(context.Background(), &userpb.ChangePasswordRequest{
+			UserId:          c.GetString("user_id"),
+			CurrentPassword: req.CurrentPassword,
+			NewPassword:     req.NewPassword,
+		})
+		reply(c, res, err)
+	})
+
+	r.POST("/auth/email/verify", func(c *gin.Context) {
+		var req struct {
+			Token string `json:"token" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		res, err := client.VerifyEmail(context.Background(), &userpb.VerifyEmailRequest{Token: req.Token})
+		reply(c, res, err)
+	})
+
+	// Like /auth/password/reset, the answer does not depend on the email.
+	r.POST("/auth/email/resend", func(c *gin.Context) {
+		var req struct {
+			Email string `json:"email" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		res, err := client.ResendVerification(context.Background(), &userpb.ResendVerificationRequest{Email: req.Email})
+		reply(c, res, err)
+	})
+
+	// Enrolling returns the secret to add to an authenticator app; it is
+	// only enabled once /auth/2fa/confirm gets a code from the app.
+	r.POST("/auth/2fa/enroll", authed, func(c *gin.Context) {
+		res, err := client.EnrollTwoFactor(context.Background(), &userpb.EnrollTwoFactorRequest{
+			UserId: c.GetString("user_id"),
+		})
+		reply(c, res, err)
+	})
+
+	r.POST("/auth/2fa/confirm", authed, func(c *gin.Context) {
+		var req struct {
+			Code string `json:"code" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		res, err := client.ConfirmTwoFactor(context.Background(), &userpb.ConfirmTwoFactorRequest{
+			UserId: c.GetString("user_id"),
+			Code:   req.Code,
+		})
+		reply(c, res, err)
+	})
+
+	r.POST("/auth/2fa/disable", authed, func(c *gin.Context) {
+		var req struct {
+			Code string `json:"code" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		res, err := client.DisableTwoFactor(context.Background(), &userpb.DisableTwoFactorRequest{
+			UserId: c.GetString("user_id"),
+			Code:   req.Code,
+		})
+		reply(c, res, err)
+	})
+
+	r.GET("/profile", authed, func(c *gin.Context) {
+		res, err := client.GetProfile(context.Background(), &userpb.GetProfileRequest{
+			UserId: c.GetString("user_id"),
+		})
+		reply(c, res, err)
+	})
+
+	// The body replaces the whole profile; fields left out are cleared.
+	r.PUT("/profile", authed, func(c *gin.Context) {
+		var req struct {
+			Phone              string   `json:"phone"`
+			Locale             string   `json:"locale"`
+			Currency           string   `json:"currency"`
+			DietaryPreferences []string `json:"dietary_preferences"`
+			MutedChannels      []string `json:"muted_channels"`
+			MarketingConsent   bool     `json:"marketing_consent"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		res, err := client.UpdateProfile(context.Background(), &userpb.UpdateProfileRequest{
+			UserId:             c.GetString("user_id"),
+			Phone:              req.Phone,
+			Locale:             req.Locale,
+			Currency:           req.Currency,
+			DietaryPreferences: req.DietaryPreferences,
+			MutedChannels:      req.MutedChannels,
+			MarketingConsent:   req.MarketingConsent,
+			IpAddress:          c.ClientIP(),
+			UserAgent:          c.Request.UserAgent(),
+		})
+		reply(c, res, err)
+	})
+
+	// The archive holds the user's data from every service that answered in
+	// time; it names the ones that did not under "missing".
+	r.GET("/account/export", authed, func(c *gin.Context) {
+		res, err := client.ExportMyData(context.Background(), &userpb.ExportMyDataRequest{
+			UserId: c.GetString("user_id"),
+		})
+		if err != nil {
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", `attachment; filename="yurt-mart-data.json"`)
+		c.Data(http.StatusOK, "application/json", res.Archive)
+	})
+
+	// Erasure runs in the background in every service; the answer carries
+	// its progress as it was when the request was accepted.
+	r.POST("/account/erase", authed, func(c *gin.Context) {
+		var req struct {
+			Password string `json:"password" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		res, err := client.EraseMyData(context.Background(), &userpb.EraseMyDataRequest{
+			UserId:   c.GetString("user_id"),
+			Password: req.Password,
+		})
+		if err != nil {
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, res)
+	})
+}
@@ -2,14 +2,33 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	orderpb "github.com/hsibAD/order-service/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	productpb "api-gateway/client-service/proto/productpb"
+	"api-gateway/internal/auth"
+	"api-gateway/internal/config/order"
 	cartpb "api-gateway/shopping-cart-service/proto/cartpb"
+	authpb "user-service/proto/auth"
+	userpb "user-service/proto/user"
+)
+
+// Guest carts are identified by an opaque token kept in this cookie (or sent
+// in the X-Cart-Token header by clients without cookies).
+const (
+	guestCartCookie = "cart_token"
+	guestCartPrefix = "guest:"
+	guestCartMaxAge = 7 * 24 * 60 * 60
 )
 
 func main() {
@@ -30,13 +49,137 @@ func main() {
 
 	client := cartpb.NewCartServiceClient(conn)
 
+	cfg := order.Load()
+	authClient, err := auth.NewAuthServiceClient("localhost:50053")
+	if err != nil {
+		log.Fatalf("failed to connect to user-service: %v", err)
+	}
+	jwtAuth := auth.NewJWTAuth(&cfg.Auth, authClient)
+
+	userConn, err := grpc.Dial("localhost:50053", grpc.WithInsecure())
+	if err != nil {
+		log.Fatalf("failed to connect to user-service: %v", err)
+	}
+	defer userConn.Close()
+
+	loginClient := authpb.NewAuthServiceClient(userConn)
+
+	orderConn, err := grpc.Dial(cfg.Services.OrderServiceURL, grpc.WithInsecure())
+	if err != nil {
+		log.Fatalf("failed to connect to order-service: %v", err)
+	}
+	defer orderConn.Close()
+
 	r := gin.Default()
 
+	// Product mutations need a seller token; the caller identity is passed on
+	// to product-service, which checks ownership.
+	seller := r.Group("/", jwtAuth.Middleware(), jwtAuth.RequirePermission(auth.PermissionProductsWrite))
+
+	// cartAuth lets a request change the logged-in user's cart or, without
+	// a token, the guest cart in the cookie; cartID names the one it is.
+	authed := jwtAuth.Middleware()
+	cartAuth := func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" && guestCartFromRequest(c) != "" {
+			c.Next()
+			return
+		}
+		authed(c)
+	}
+
+	r.POST("/cart/guest", func(c *gin.Context) {
+		cartID, err := newGuestCartID()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.SetCookie(guestCartCookie, cartID, guestCartMaxAge, "/", "", false, true)
+		c.JSON(http.StatusOK, gin.H{"cart_id": cartID})
+	})
+
+	// A guest who logs in keeps what they put into the cart. For products
+	// already in the user's cart the larger quantity wins, so adding the
+	// same item on two devices does not double it.
+	mergeGuestCart := func(c *gin.Context, userID string) {
+		guestCartID := guestCartFromRequest(c)
+		if guestCartID == "" {
+			return
+		}
+		_, err := client.MergeCart(context.Background(), &cartpb.MergeCartRequest{
+			SourceUserId: guestCartID,
+			TargetUserId: userID,
+			Strategy:     cartpb.MergeStrategy_MERGE_STRATEGY_MAX,
+		})
+		if err != nil {
+			log.Printf("failed to merge guest cart %s into user %s: %v", guestCartID, userID, err)
+			return
+		}
+		c.SetCookie(guestCartCookie, "", -1, "/", "", false, true)
+	}
+
+	r.POST("/auth/login", func(c *gin.Context) {
+		var req struct {
+			Email    string `json:"email"`
+			Password string `json:"password"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		resp, err := loginClient.Login(context.Background(), &authpb.LoginRequest{
+			Email:     req.Email,
+			Password:  req.Password,
+			UserAgent: c.Request.UserAgent(),
+			IpAddress: c.ClientIP(),
+		})
+		if err != nil {
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		// With two-factor authentication the user is only logged in by
+		// /auth/2fa/verify.
+		if resp.Token != "" {
+			mergeGuestCart(c, resp.UserId)
+		}
+
+		c.JSON(http.StatusOK, resp)
+	})
+
+	// Second login step for users with two-factor authentication: the
+	// challenge token from /auth/login and a TOTP or recovery code.
+	r.POST("/auth/2fa/verify", func(c *gin.Context) {
+		var req struct {
+			ChallengeToken string `json:"challenge_token" binding:"required"`
+			Code           string `json:"code" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		resp, err := loginClient.VerifyTwoFactor(context.Background(), &authpb.VerifyTwoFactorRequest{
+			ChallengeToken: req.ChallengeToken,
+			Code:           req.Code,
+			UserAgent:      c.Request.UserAgent(),
+			IpAddress:      c.ClientIP(),
+		})
+		if err != nil {
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		mergeGuestCart(c, resp.UserId)
+		c.JSON(http.StatusOK, resp)
+	})
+
 	r.POST("/cart", func(c *gin.Context) {
 		var req struct {
 			UserID string `json:"user_id"`
 			Items  []struct {
 				ProductID string `json:"product_id"`
+				SKUID     string `json:"sku_id"`
 				Quantity  int32  `json:"quantity"`
 			} `json:"items"`
 		}
@@ -50,6 +193,7 @@ func main() {
 		for _, item := range req.Items {
 			items = append(items, &cartpb.CartItem{
 				ProductId: item.ProductID,
+				SkuId:     item.SKUID,
 				Quantity:  item.Quantity,
 			})
 		}
@@ -80,13 +224,61 @@ func main() {
 		c.JSON(http.StatusOK, res)
 	})
 
-	r.DELETE("/cart/:user_id/:product_id", func(c *gin.Context) {
-		userID := c.Param("user_id")
-		productID := c.Param("product_id")
+	// ?auto_fix=true also corrects the cart instead of only reporting issues.
+	r.POST("/cart/validate", cartAuth, func(c *gin.Context) {
+		res, err := client.ValidateCart(context.Background(), &cartpb.ValidateCartRequest{
+			UserId:  cartID(c),
+			AutoFix: c.Query("auto_fix") == "true",
+		})
+		if err != nil {
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, res)
+	})
+
+	// Coupons go on the logged-in user's cart, so per-user promotion limits
+	// are counted against the user in the token.
+	r.POST("/cart/coupons", jwtAuth.Middleware(), func(c *gin.Context) {
+		var req struct {
+			Code string `json:"code"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
+		res, err := client.ApplyCoupon(context.Background(), &cartpb.ApplyCouponRequest{
+			UserId: c.GetString("user_id"),
+			Code:   req.Code,
+		})
+		if err != nil {
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, res)
+	})
+
+	r.DELETE("/cart/coupons/:code", jwtAuth.Middleware(), func(c *gin.Context) {
+		res, err := client.RemoveCoupon(context.Background(), &cartpb.RemoveCouponRequest{
+			UserId: c.GetString("user_id"),
+			Code:   c.Param("code"),
+		})
+		if err != nil {
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, res)
+	})
+
+	r.DELETE("/cart/items/:product_id", cartAuth, func(c *gin.Context) {
 		res, err := client.RemoveFromCart(context.Background(), &cartpb.RemoveFromCartRequest{
-			UserId:    userID,
-			ProductId: productID,
+			UserId:    cartID(c),
+			ProductId: c.Param("product_id"),
+			SkuId:     c.Query("sku_id"),
 		})
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -96,6 +288,82 @@ func main() {
 		c.JSON(http.StatusOK, res)
 	})
 
+	r.PUT("/cart/items/:product_id", cartAuth, func(c *gin.Context) {
+		var req struct {
+			SKUID    string `json:"sku_id"`
+			Quantity int32  `json:"quantity"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		res, err := client.UpdateCartItem(context.Background(), &cartpb.UpdateCartItemRequest{
+			UserId:    cartID(c),
+			ProductId: c.Param("product_id"),
+			SkuId:     req.SKUID,
+			Quantity:  req.Quantity,
+		})
+		if err != nil {
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, res)
+	})
+
+	// Clears the cart of the logged-in user. Guest carts are merged into it
+	// by mergeGuestCart when the guest logs in, never through a public route.
+	r.DELETE("/cart", jwtAuth.Middleware(), func(c *gin.Context) {
+		res, err := client.ClearCart(context.Background(), &cartpb.ClearCartRequest{
+			UserId: c.GetString("user_id"),
+		})
+		if err != nil {
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, res)
+	})
+
+	// Placing an order needs the orders:create permission and, unless
+	// REQUIRE_VERIFIED_EMAIL_FOR_CHECKOUT is off, a verified email.
+	checkout := r.Group("/", jwtAuth.Middleware(), jwtAuth.RequirePermission(auth.PermissionOrdersCreate))
+	if cfg.Auth.CheckoutRequiresVerifiedEmail {
+		checkout.Use(jwtAuth.RequireVerifiedEmail())
+	}
+	registerOrderRoutes(checkout, orderpb.NewOrderServiceClient(orderConn))
+	registerWishlistRoutes(r, jwtAuth.Middleware(), cartpb.NewWishlistServiceClient(conn))
+	registerAccountRoutes(r, jwtAuth.Middleware(), userpb.NewUserServiceClient(userConn))
+	registerSessionRoutes(r, jwtAuth.Middleware(), loginClient)
+	admin := r.Group("/admin", jwtAuth.Middleware())
+	registerPromotionRoutes(admin.Group("", jwtAuth.RequirePermission(auth.PermissionPromotionsManage)), cartpb.NewPromotionServiceClient(conn))
+	registerRoleRoutes(admin.Group("", jwtAuth.RequirePermission(auth.PermissionUsersManageRoles)), userpb.NewUserServiceClient(userConn))
+
+	// Ends a lockout after failed logins before it runs out.
+	admin.POST("/users/:user_id/unlock", jwtAuth.RequirePermission(auth.PermissionUsersUnlock), func(c *gin.Context) {
+		res, err := userpb.NewUserServiceClient(userConn).UnlockAccount(context.Background(), &userpb.UnlockAccountRequest{
+			UserId: c.Param("user_id"),
+		})
+		if err != nil {
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	})
+
+	// Shows which services have erased a user's data so far.
+	admin.GET("/erasures/:erasure_id", jwtAuth.RequirePermission(auth.PermissionUsersRead), func(c *gin.Context) {
+		res, err := userpb.NewUserServiceClient(userConn).GetErasureStatus(context.Background(), &userpb.GetErasureStatusRequest{
+			ErasureId: c.Param("erasure_id"),
+		})
+		if err != nil {
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	})
+
 	r.GET("/product/:id", func(c *gin.Context) {
 		id := c.Param("id")
 		resp, err := productClient.GetProduct(context.Background(), &productpb.GetProductRequest{Id: id})
@@ -106,25 +374,83 @@ func main() {
 		c.JSON(http.StatusOK, resp)
 	})
 
-	r.POST("/product", func(c *gin.Context) {
+	r.GET("/product/sku/:sku_id", func(c *gin.Context) {
+		skuID := c.Param("sku_id")
+		resp, err := productClient.GetSKU(context.Background(), &productpb.GetSKURequest{SkuId: skuID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+
+	r.GET("/product/:id/images/:image_id", func(c *gin.Context) {
+		stream, err := productClient.GetProductImage(c.Request.Context(), &productpb.GetProductImageRequest{
+			ProductId: c.Param("id"),
+			ImageId:   c.Param("image_id"),
+			Size:      c.Query("size"),
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		// The first message decides between an error response and the image.
+		first, err := stream.Recv()
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Header("Content-Type", first.ContentType)
+		c.Header("Cache-Control", "public, max-age=86400")
+		c.Status(http.StatusOK)
+		c.Writer.Write(first.Chunk)
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				log.Printf("image stream interrupted: %v", err)
+				return
+			}
+			c.Writer.Write(resp.Chunk)
+		}
+	})
+
+	seller.POST("/product", func(c *gin.Context) {
 		var req productpb.Product
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		resp, err := productClient.CreateProduct(context.Background(), &productpb.CreateProductRequest{Product: &req})
+		resp, err := productClient.CreateProduct(auth.OutgoingContext(c), &productpb.CreateProductRequest{Product: &req})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
 			return
 		}
 		c.JSON(http.StatusOK, resp)
 	})
 
-	r.DELETE("/product/:id", func(c *gin.Context) {
+	seller.DELETE("/product/:id", func(c *gin.Context) {
 		id := c.Param("id")
-		resp, err := productClient.DeleteProduct(context.Background(), &productpb.DeleteProductRequest{Id: id})
+		resp, err := productClient.DeleteProduct(auth.OutgoingContext(c), &productpb.DeleteProductRequest{Id: id})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+
+	seller.GET("/seller/products", func(c *gin.Context) {
+		resp, err := productClient.ListProductsBySeller(auth.OutgoingContext(c), &productpb.ListProductsBySellerRequest{})
+		if err != nil {
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
 			return
 		}
 		c.JSON(http.StatusOK, resp)
@@ -139,6 +465,17 @@ func main() {
 		c.JSON(http.StatusOK, resp)
 	})
 
+	r.GET("/products/seller/:seller_id", func(c *gin.Context) {
+		resp, err := productClient.ListProductsBySeller(context.Background(), &productpb.ListProductsBySellerRequest{
+			SellerId: c.Param("seller_id"),
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+
 	r.GET("/products/category/:category", func(c *gin.Context) {
 		category := c.Param("category")
 		resp, err := productClient.GetProductsByCategory(context.Background(), &productpb.GetProductsByCategoryRequest{
@@ -154,3 +491,48 @@ func main() {
 	log.Println("API Gateway started at http://localhost:8080")
 	r.Run(":8080")
 }
+
+func grpcToHTTPStatus(err error) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.FailedPrecondition:
+		return http.StatusUnprocessableEntity
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func newGuestCartID() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return guestCartPrefix + hex.EncodeToString(token), nil
+}
+
+func cartID(c *gin.Context) string {
+	if userID := c.GetString("user_id"); userID != "" {
+		return userID
+	}
+	return guestCartFromRequest(c)
+}
+
+func guestCartFromRequest(c *gin.Context) string {
+	cartID := c.GetHeader("X-Cart-Token")
+	if cartID == "" {
+		cartID, _ = c.Cookie(guestCartCookie)
+	}
+	if !strings.HasPrefix(cartID, guestCartPrefix) {
+		return ""
+	}
+	return cartID
+}
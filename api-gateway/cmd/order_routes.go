@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	orderpb "github.com/hsibAD/order-service/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// registerOrderRoutes lets the logged-in user check out their cart. The
+// checkout group has to authenticate the caller, set user_id and decide who
+// may order.
+func registerOrderRoutes(checkout *gin.RouterGroup, client orderpb.OrderServiceClient) {
+	checkout.POST("/orders", func(c *gin.Context) {
+		var req struct {
+			DeliveryAddress orderpb.DeliveryAddress `json:"delivery_address"`
+			DeliveryTime    int64                   `json:"delivery_time"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Carts are keyed by user, so the order is placed for the cart of
+		// the user in the token.
+		userID := c.GetString("user_id")
+		req.DeliveryAddress.UserId = userID
+		orderReq := &orderpb.CreateOrderRequest{
+			CartId:          userID,
+			DeliveryAddress: &req.DeliveryAddress,
+		}
+		if req.DeliveryTime != 0 {
+			orderReq.DeliveryTime = timestamppb.New(time.Unix(req.DeliveryTime, 0))
+		}
+
+		res, err := client.CreateOrder(context.Background(), orderReq)
+		if err != nil {
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, res)
+	})
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	cartpb "api-gateway/shopping-cart-service/proto/cartpb"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var promotionKinds = map[string]cartpb.PromotionKind{
+	"percentage":   cartpb.PromotionKind_PROMOTION_KIND_PERCENTAGE,
+	"fixed_amount": cartpb.PromotionKind_PROMOTION_KIND_FIXED_AMOUNT,
+	"buy_x_get_y":  cartpb.PromotionKind_PROMOTION_KIND_BUY_X_GET_Y,
+	"category":     cartpb.PromotionKind_PROMOTION_KIND_CATEGORY,
+}
+
+// registerPromotionRoutes serves the promotion administration under admin,
+// which has to let only users with the promotions:manage permission through.
+func registerPromotionRoutes(admin *gin.RouterGroup, client cartpb.PromotionServiceClient) {
+	reply := func(c *gin.Context, res interface{}, err error) {
+		if err != nil {
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+
+	admin.GET("/promotions", func(c *gin.Context) {
+		res, err := client.ListPromotions(context.Background(), &cartpb.ListPromotionsRequest{
+			ActiveOnly: c.Query("active") == "true",
+		})
+		reply(c, res, err)
+	})
+
+	// A promotion without a code applies to every eligible cart.
+	admin.POST("/promotions", func(c *gin.Context) {
+		var req struct {
+			Code           string     `json:"code"`
+			Description    string     `json:"description"`
+			Kind           string     `json:"kind"`
+			Percent        float64    `json:"percent"`
+			Amount         float64    `json:"amount"`
+			ProductID      string     `json:"product_id"`
+			BuyQuantity    int32      `json:"buy_quantity"`
+			FreeQuantity   int32      `json:"free_quantity"`
+			Category       string     `json:"category"`
+			MinSpend       float64    `json:"min_spend"`
+			MaxUses        int64      `json:"max_uses"`
+			MaxUsesPerUser int64      `json:"max_uses_per_user"`
+			StartsAt       *time.Time `json:"starts_at"`
+			EndsAt         *time.Time `json:"ends_at"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		kind, ok := promotionKinds[req.Kind]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be percentage, fixed_amount, buy_x_get_y or category"})
+			return
+		}
+
+		promo := &cartpb.Promotion{
+			Code:           req.Code,
+			Description:    req.Description,
+			Kind:           kind,
+			Percent:        req.Percent,
+			Amount:         req.Amount,
+			ProductId:      req.ProductID,
+			BuyQuantity:    req.BuyQuantity,
+			FreeQuantity:   req.FreeQuantity,
+			Category:       req.Category,
+			MinSpend:       req.MinSpend,
+			MaxUses:        req.MaxUses,
+			MaxUsesPerUser: req.MaxUsesPerUser,
+		}
+		if req.StartsAt != nil {
+			promo.StartsAt = timestamppb.New(*req.StartsAt)
+		}
+		if req.EndsAt != nil {
+			promo.EndsAt = timestamppb.New(*req.EndsAt)
+		}
+
+		res, err := client.CreatePromotion(context.Background(), promo)
+		reply(c, res, err)
+	})
+
+	admin.GET("/promotions/:id", func(c *gin.Context) {
+		res, err := client.GetPromotion(context.Background(), &cartpb.GetPromotionRequest{Id: c.Param("id")})
+		reply(c, res, err)
+	})
+
+	admin.DELETE("/promotions/:id", func(c *gin.Context) {
+		res, err := client.DeactivatePromotion(context.Background(), &cartpb.GetPromotionRequest{Id: c.Param("id")})
+		reply(c, res, err)
+	})
+}
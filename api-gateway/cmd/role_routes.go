@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	userpb "user-service/proto/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registerRoleRoutes serves role administration under admin, which has to let
+// only users with the users:manage_roles permission through.
+func registerRoleRoutes(admin *gin.RouterGroup, client userpb.UserServiceClient) {
+	reply := func(c *gin.Context, res interface{}, err error) {
+		if err != nil {
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+
+	admin.GET("/users/:user_id/roles", func(c *gin.Context) {
+		res, err := client.GetUser(context.Background(), &userpb.GetUserRequest{UserId: c.Param("user_id")})
+		if err != nil {
+			reply(c, nil, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"user_id": res.UserId, "roles": res.Roles})
+	})
+
+	admin.POST("/users/:user_id/roles", func(c *gin.Context) {
+		var req struct {
+			Role string `json:"role" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		res, err := client.AssignRole(context.Background(), &userpb.AssignRoleRequest{
+			UserId: c.Param("user_id"),
+			Role:   req.Role,
+		})
+		reply(c, res, err)
+	})
+
+	admin.DELETE("/users/:user_id/roles/:role", func(c *gin.Context) {
+		res, err := client.RevokeRole(context.Background(), &userpb.RevokeRoleRequest{
+			UserId: c.Param("user_id"),
+			Role:   c.Param("role"),
+		})
+		reply(c, res, err)
+	})
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	authpb "user-service/proto/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registerSessionRoutes serves token refresh, logout and the sessions of the
+// caller. authed has to authenticate the caller and set user_id and
+// session_id.
+func registerSessionRoutes(r *gin.Engine, authed gin.HandlerFunc, client authpb.AuthServiceClient) {
+	reply := func(c *gin.Context, res interface{}, err error) {
+		if err != nil {
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+
+	// Each refresh token works once; the response carries its successor.
+	r.POST("/auth/refresh", func(c *gin.Context) {
+		var req struct {
+			RefreshToken string `json:"refresh_token" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		res, err := client.Refresh(context.Background(), &authpb.RefreshRequest{
+			RefreshToken: req.RefreshToken,
+			UserAgent:    c.Request.UserAgent(),
+			IpAddress:    c.ClientIP(),
+		})
+		reply(c, res, err)
+	})
+
+	r.POST("/auth/logout", authed, func(c *gin.Context) {
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		res, err := client.Logout(context.Background(), &authpb.LogoutRequest{Token: token})
+		reply(c, res, err)
+	})
+
+	r.GET("/auth/sessions", authed, func(c *gin.Context) {
+		res, err := client.ListSessions(context.Background(), &authpb.ListSessionsRequest{
+			UserId: c.GetString("user_id"),
+		})
+		if err != nil {
+			reply(c, nil, err)
+			return
+		}
+		current := c.GetString("session_id")
+		sessions := make([]gin.H, 0, len(res.Sessions))
+		for _, s := range res.Sessions {
+			sessions = append(sessions, gin.H{
+				"session_id":   s.SessionId,
+				"user_agent":   s.UserAgent,
+				"ip_address":   s.IpAddress,
+				"created_at":   s.CreatedAt.AsTime(),
+				"last_used_at": s.LastUsedAt.AsTime(),
+				"expires_at":   s.ExpiresAt.AsTime(),
+				"current":      s.SessionId == current,
+			})
+		}
+		c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+	})
+
+	r.DELETE("/auth/sessions/:session_id", authed, func(c *gin.Context) {
+		res, err := client.RevokeSession(context.Background(), &authpb.RevokeSessionRequest{
+			UserId:    c.GetString("user_id"),
+			SessionId: c.Param("session_id"),
+		})
+		reply(c, res, err)
+	})
+
+	// ?keep_current=true logs out every other device.
+	r.DELETE("/auth/sessions", authed, func(c *gin.Context) {
+		req := &authpb.RevokeAllSessionsRequest{UserId: c.GetString("user_id")}
+		if c.Query("keep_current") == "true" {
+			req.KeepSessionId = c.GetString("session_id")
+		}
+		res, err := client.RevokeAllSessions(context.Background(), req)
+		reply(c, res, err)
+	})
+}
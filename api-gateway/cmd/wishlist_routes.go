@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	cartpb "api-gateway/shopping-cart-service/proto/cartpb"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultListID in a path stands for the list the cart service falls back to
+// for an empty list ID: the wishlist, or save-for-later for moves.
+const defaultListID = "default"
+
+func listID(c *gin.Context) string {
+	if id := c.Param("list_id"); id != defaultListID {
+		return id
+	}
+	return ""
+}
+
+// registerWishlistRoutes serves the logged-in user's lists. authed has to
+// authenticate the caller and set user_id; only shared lists are read
+// without it.
+func registerWishlistRoutes(r *gin.Engine, authed gin.HandlerFunc, client cartpb.WishlistServiceClient) {
+	reply := func(c *gin.Context, res interface{}, err error) {
+		if err != nil {
+			c.JSON(grpcToHTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+
+	lists := r.Group("/wishlists", authed)
+
+	lists.GET("", func(c *gin.Context) {
+		res, err := client.ListLists(context.Background(), &cartpb.ListListsRequest{UserId: c.GetString("user_id")})
+		reply(c, res, err)
+	})
+
+	lists.POST("", func(c *gin.Context) {
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		res, err := client.CreateList(context.Background(), &cartpb.CreateListRequest{
+			UserId: c.GetString("user_id"),
+			Name:   req.Name,
+		})
+		reply(c, res, err)
+	})
+
+	lists.GET("/:list_id", func(c *gin.Context) {
+		res, err := client.GetList(context.Background(), &cartpb.GetListRequest{
+			UserId: c.GetString("user_id"),
+			ListId: listID(c),
+		})
+		reply(c, res, err)
+	})
+
+	lists.PUT("/:list_id", func(c *gin.Context) {
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		res, err := client.RenameList(context.Background(), &cartpb.RenameListRequest{
+			UserId: c.GetString("user_id"),
+			ListId: listID(c),
+			Name:   req.Name,
+		})
+		reply(c, res, err)
+	})
+
+	lists.DELETE("/:list_id", func(c *gin.Context) {
+		res, err := client.DeleteList(context.Background(), &cartpb.DeleteListRequest{
+			UserId: c.GetString("user_id"),
+			ListId: listID(c),
+		})
+		reply(c, res, err)
+	})
+
+	lists.POST("/:list_id/items", func(c *gin.Context) {
+		var req struct {
+			ProductID string `json:"product_id"`
+			SKUID     string `json:"sku_id"`
+			Quantity  int32  `json:"quantity"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		res, err := client.AddListItem(context.Background(), &cartpb.AddListItemRequest{
+			UserId:    c.GetString("user_id"),
+			ListId:    listID(c),
+			ProductId: req.ProductID,
+			SkuId:     req.SKUID,
+			Quantity:  req.Quantity,
+		})
+		reply(c, res, err)
+	})
+
+	lists.DELETE("/:list_id/items/:product_id", func(c *gin.Context) {
+		res, err := client.RemoveListItem(context.Background(), &cartpb.RemoveListItemRequest{
+			UserId:    c.GetString("user_id"),
+			ListId:    listID(c),
+			ProductId: c.Param("product_id"),
+			SkuId:     c.Query("sku_id"),
+		})
+		reply(c, res, err)
+	})
+
+	move := func(call func(context.Context, *cartpb.MoveListItemRequest) (*cartpb.MoveListItemResponse, error)) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			var req struct {
+				ProductID string `json:"product_id"`
+				SKUID     string `json:"sku_id"`
+			}
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			res, err := call(context.Background(), &cartpb.MoveListItemRequest{
+				UserId:    c.GetString("user_id"),
+				ListId:    listID(c),
+				ProductId: req.ProductID,
+				SkuId:     req.SKUID,
+			})
+			reply(c, res, err)
+		}
+	}
+	lists.POST("/:list_id/move-to-cart", move(func(ctx context.Context, req *cartpb.MoveListItemRequest) (*cartpb.MoveListItemResponse, error) {
+		return client.MoveToCart(ctx, req)
+	}))
+	lists.POST("/:list_id/move-from-cart", move(func(ctx context.Context, req *cartpb.MoveListItemRequest) (*cartpb.MoveListItemResponse, error) {
+		return client.MoveFromCart(ctx, req)
+	}))
+
+	lists.POST("/:list_id/share", func(c *gin.Context) {
+		res, err := client.ShareList(context.Background(), &cartpb.ShareListRequest{
+			UserId: c.GetString("user_id"),
+			ListId: listID(c),
+		})
+		reply(c, res, err)
+	})
+
+	lists.DELETE("/:list_id/share", func(c *gin.Context) {
+		res, err := client.UnshareList(context.Background(), &cartpb.ShareListRequest{
+			UserId: c.GetString("user_id"),
+			ListId: listID(c),
+		})
+		reply(c, res, err)
+	})
+
+	// Read-only link for anyone the owner shared the list with.
+	r.GET("/shared/wishlists/:token", func(c *gin.Context) {
+		res, err := client.GetSharedList(context.Background(), &cartpb.GetSharedListRequest{ShareToken: c.Param("token")})
+		reply(c, res, err)
+	})
+}
@@ -7,7 +7,16 @@ import (
 )
 
 type AuthServiceClient interface {
-	IsTokenBlacklisted(token string) (bool, error)
+	CheckToken(token string) (*TokenStatus, error)
+}
+
+// TokenStatus is what user-service currently knows about a token and its
+// user, which can differ from the claims the token was issued with.
+type TokenStatus struct {
+	Revoked       bool
+	EmailVerified bool
+	Roles         []string
+	Permissions   []string
 }
 
 type authServiceClient struct {
@@ -27,19 +36,22 @@ func NewAuthServiceClient(address string) (AuthServiceClient, error) {
 	}, nil
 }
 
-func (c *authServiceClient) IsTokenBlacklisted(token string) (bool, error) {
-	// For now, we'll just check if the token is valid
-	// In a real implementation, you would make a gRPC call to the auth service
-	// to check if the token is blacklisted
-	_, err := c.client.Logout(context.Background(), &pb.LogoutRequest{
+// CheckToken asks user-service whether the token was logged out or revoked,
+// e.g. by a password change, and for the current state of its user.
+func (c *authServiceClient) CheckToken(token string) (*TokenStatus, error) {
+	resp, err := c.client.ValidateToken(context.Background(), &pb.ValidateTokenRequest{
 		Token: token,
 	})
-
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	return false, nil
+	return &TokenStatus{
+		Revoked:       !resp.Valid,
+		EmailVerified: resp.EmailVerified,
+		Roles:         resp.Roles,
+		Permissions:   resp.Permissions,
+	}, nil
 }
 
 func (c *authServiceClient) Close() error {
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningMethods are the algorithms user-service signs access tokens with.
+var SigningMethods = []string{"EdDSA", "RS256"}
+
+var ErrUnknownKey = errors.New("token signed with an unknown key")
+
+// unknownKidRefetch limits how often a token with an unknown kid makes
+// JWKS fetch the key set again before its cache expires.
+const unknownKidRefetch = 10 * time.Second
+
+type publicKey struct {
+	alg string
+	key interface{}
+}
+
+// JWKS caches the public keys user-service publishes. The gateway only
+// verifies tokens, so it never holds a key that can sign them.
+type JWKS struct {
+	url    string
+	ttl    time.Duration
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]publicKey
+	fetchedAt time.Time
+}
+
+func NewJWKS(url string, ttl time.Duration) *JWKS {
+	return &JWKS{
+		url:    url,
+		ttl:    ttl,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Keyfunc returns the key named by the token's kid header. The key set is
+// fetched again once the cache expires, or early for a kid it does not know
+// yet.
+func (j *JWKS) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	key, known := j.keys[kid]
+	age := time.Since(j.fetchedAt)
+	if age > j.ttl || (!known && age > unknownKidRefetch) {
+		if err := j.refresh(); err != nil {
+			// Keep verifying with the cached keys while user-service is
+			// unreachable.
+			log.Printf("failed to fetch JWKS from %s: %v", j.url, err)
+		}
+		key, known = j.keys[kid]
+	}
+
+	if !known {
+		return nil, ErrUnknownKey
+	}
+	if key.alg != token.Method.Alg() {
+		return nil, fmt.Errorf("key %s is not for %s", kid, token.Method.Alg())
+	}
+	return key.key, nil
+}
+
+func (j *JWKS) refresh() error {
+	// A failed fetch also waits for the next attempt, so an outage does
+	// not cost a request to user-service per token.
+	j.fetchedAt = time.Now()
+
+	resp, err := j.client.Get(j.url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Alg string `json:"alg"`
+			Kid string `json:"kid"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return err
+	}
+
+	keys := make(map[string]publicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		switch {
+		case k.Kty == "OKP" && k.Crv == "Ed25519":
+			x, err := base64.RawURLEncoding.DecodeString(k.X)
+			if err != nil || len(x) != ed25519.PublicKeySize {
+				continue
+			}
+			keys[k.Kid] = publicKey{alg: k.Alg, key: ed25519.PublicKey(x)}
+		case k.Kty == "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(k.N)
+			e, errE := base64.RawURLEncoding.DecodeString(k.E)
+			if errN != nil || errE != nil {
+				continue
+			}
+			keys[k.Kid] = publicKey{alg: k.Alg, key: &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}}
+		}
+	}
+	j.keys = keys
+	return nil
+}
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"net/http"
 	"strings"
-	"time"
 
 	"api-gateway/internal/config/order"
 	"github.com/gin-gonic/gin"
@@ -19,43 +18,32 @@ var (
 
 type Claims struct {
 	UserID string `json:"user_id"`
-	Role   string `json:"role"`
+	// EmailVerified, Roles and Permissions are set from user-service by
+	// ValidateToken, so changes since the token was issued count right away.
+	EmailVerified bool     `json:"email_verified"`
+	Roles         []string `json:"roles"`
+	Permissions   []string `json:"permissions"`
+	// SessionID is the user-service session the token belongs to.
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
 type JWTAuth struct {
 	config     *order.AuthConfig
 	authClient AuthServiceClient
+	keys       *JWKS
 }
 
 func NewJWTAuth(config *order.AuthConfig, authClient AuthServiceClient) *JWTAuth {
 	return &JWTAuth{
 		config:     config,
 		authClient: authClient,
+		keys:       NewJWKS(config.JWKSURL, config.JWKSCacheTTL),
 	}
 }
 
-func (j *JWTAuth) GenerateToken(userID, role string) (string, error) {
-	claims := &Claims{
-		UserID: userID,
-		Role:   role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.config.TokenExpiration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.config.JWTSecret))
-}
-
 func (j *JWTAuth) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, ErrInvalidToken
-		}
-		return []byte(j.config.JWTSecret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, j.keys.Keyfunc, jwt.WithValidMethods(SigningMethods))
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -66,13 +54,16 @@ func (j *JWTAuth) ValidateToken(tokenString string) (*Claims, error) {
 
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
 		// Check if token is blacklisted
-		isBlacklisted, err := j.authClient.IsTokenBlacklisted(tokenString)
+		tokenStatus, err := j.authClient.CheckToken(tokenString)
 		if err != nil {
 			return nil, err
 		}
-		if isBlacklisted {
+		if tokenStatus.Revoked {
 			return nil, ErrBlacklistedToken
 		}
+		claims.EmailVerified = tokenStatus.EmailVerified
+		claims.Roles = tokenStatus.Roles
+		claims.Permissions = tokenStatus.Permissions
 		return claims, nil
 	}
 
@@ -107,21 +98,55 @@ func (j *JWTAuth) Middleware() gin.HandlerFunc {
 		}
 
 		c.Set("user_id", claims.UserID)
-		c.Set("role", claims.Role)
+		c.Set("roles", claims.Roles)
+		c.Set("permissions", claims.Permissions)
+		c.Set("email_verified", claims.EmailVerified)
+		c.Set("session_id", claims.SessionID)
 		c.Next()
 	}
 }
 
-func (j *JWTAuth) AdminOnly() gin.HandlerFunc {
+// RequirePermission refuses users who lack any of the permissions. It must
+// run after Middleware.
+func (j *JWTAuth) RequirePermission(permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("role")
+		granted, exists := c.Get("permissions")
 		if !exists {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
 		}
 
-		if role != "admin" {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		for _, permission := range permissions {
+			if !hasPermission(granted.([]string), permission) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "missing permission " + permission})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func hasPermission(granted []string, permission string) bool {
+	for _, p := range granted {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireVerifiedEmail refuses users who have not verified their email. It
+// must run after Middleware.
+func (j *JWTAuth) RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("user_id"); !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		if !c.GetBool("email_verified") {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "email address is not verified"})
 			return
 		}
 
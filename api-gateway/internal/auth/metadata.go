@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataAuthorization is the gRPC metadata key that carries the caller's
+// access token. Downstream services verify it themselves rather than trust
+// an identity the gateway asserts.
+const MetadataAuthorization = "authorization"
+
+// OutgoingContext passes the bearer token that Middleware accepted on to
+// outgoing gRPC calls.
+func OutgoingContext(c *gin.Context) context.Context {
+	ctx := c.Request.Context()
+	if c.GetString("user_id") == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, MetadataAuthorization, c.GetHeader("Authorization"))
+}
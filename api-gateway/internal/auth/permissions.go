@@ -0,0 +1,14 @@
+package auth
+
+// Permissions user-service grants through roles. Routes declare the ones
+// they need with RequirePermission.
+const (
+	PermissionOrdersCreate     = "orders:create"
+	PermissionOrdersReadAny    = "orders:read_any"
+	PermissionProductsWrite    = "products:write"
+	PermissionDeliveriesUpdate = "deliveries:update"
+	PermissionPromotionsManage = "promotions:manage"
+	PermissionUsersRead        = "users:read"
+	PermissionUsersManageRoles = "users:manage_roles"
+	PermissionUsersUnlock      = "users:unlock"
+)
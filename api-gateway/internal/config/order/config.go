@@ -27,8 +27,14 @@ type ServicesConfig struct {
 }
 
 type AuthConfig struct {
-	JWTSecret       string
-	TokenExpiration time.Duration
+	// JWKSURL is where user-service publishes the keys tokens are signed
+	// with. JWKSCacheTTL has to stay below the time user-service publishes
+	// new keys ahead (JWT_KEY_PUBLISH_AHEAD).
+	JWKSURL      string
+	JWKSCacheTTL time.Duration
+	// CheckoutRequiresVerifiedEmail keeps users who have not verified their
+	// email from placing orders.
+	CheckoutRequiresVerifiedEmail bool
 }
 
 type RateLimitConfig struct {
@@ -55,8 +61,10 @@ func Load() *Config {
 			PaymentServiceURL: getEnv("PAYMENT_SERVICE_URL", "localhost:50052"),
 		},
 		Auth: AuthConfig{
-			JWTSecret:       getEnv("JWT_SECRET", "your-secret-key"),
-			TokenExpiration: time.Hour * 24,
+			JWKSURL:      getEnv("JWKS_URL", "http://localhost:8081/.well-known/jwks.json"),
+			JWKSCacheTTL: time.Minute * 10,
+
+			CheckoutRequiresVerifiedEmail: getEnvAsBool("REQUIRE_VERIFIED_EMAIL_FOR_CHECKOUT", true),
 		},
 		RateLimiting: RateLimitConfig{
 			RequestsPerMinute: getEnvAsInt("RATE_LIMIT", 60),
@@ -85,3 +93,12 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
@@ -74,11 +74,11 @@ func (s *Server) setupRoutes() {
 	api := s.router.Group("/api/v1")
 	{
 		// Public routes
-		auth := api.Group("/auth")
+		authRoutes := api.Group("/auth")
 		{
-			auth.POST("/login", s.handleLogin)
-			auth.POST("/register", s.handleRegister)
-			auth.POST("/logout", s.handleLogout)
+			authRoutes.POST("/login", s.handleLogin)
+			authRoutes.POST("/register", s.handleRegister)
+			authRoutes.POST("/logout", s.handleLogout)
 		}
 
 		// Protected routes
@@ -88,7 +88,7 @@ func (s *Server) setupRoutes() {
 			// Order routes
 			orders := protected.Group("/orders")
 			{
-				orders.POST("", orderHandler.CreateOrder)
+				orders.POST("", s.jwtAuth.RequirePermission(auth.PermissionOrdersCreate), s.checkoutPolicy(), orderHandler.CreateOrder)
 				orders.GET("/:id", orderHandler.GetOrder)
 				orders.PUT("/:id/status", orderHandler.UpdateOrderStatus)
 				orders.GET("/delivery-slots", orderHandler.GetAvailableDeliverySlots)
@@ -116,6 +116,14 @@ func (s *Server) setupRoutes() {
 	}
 }
 
+// checkoutPolicy decides who may place orders on top of being logged in.
+func (s *Server) checkoutPolicy() gin.HandlerFunc {
+	if s.config.Auth.CheckoutRequiresVerifiedEmail {
+		return s.jwtAuth.RequireVerifiedEmail()
+	}
+	return func(c *gin.Context) { c.Next() }
+}
+
 func (s *Server) handleLogin(c *gin.Context) {
 	// TODO: Implement user authentication
 	c.JSON(http.StatusOK, gin.H{"message": "login successful"})
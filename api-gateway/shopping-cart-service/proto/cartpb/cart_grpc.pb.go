@@ -22,6 +22,12 @@ const (
 	CartService_AddToCart_FullMethodName      = "/cartpb.CartService/AddToCart"
 	CartService_GetCart_FullMethodName        = "/cartpb.CartService/GetCart"
 	CartService_RemoveFromCart_FullMethodName = "/cartpb.CartService/RemoveFromCart"
+	CartService_UpdateCartItem_FullMethodName = "/cartpb.CartService/UpdateCartItem"
+	CartService_ClearCart_FullMethodName      = "/cartpb.CartService/ClearCart"
+	CartService_MergeCart_FullMethodName      = "/cartpb.CartService/MergeCart"
+	CartService_ValidateCart_FullMethodName   = "/cartpb.CartService/ValidateCart"
+	CartService_ApplyCoupon_FullMethodName    = "/cartpb.CartService/ApplyCoupon"
+	CartService_RemoveCoupon_FullMethodName   = "/cartpb.CartService/RemoveCoupon"
 )
 
 // CartServiceClient is the client API for CartService service.
@@ -31,6 +37,12 @@ type CartServiceClient interface {
 	AddToCart(ctx context.Context, in *AddToCartRequest, opts ...grpc.CallOption) (*CartResponse, error)
 	GetCart(ctx context.Context, in *GetCartRequest, opts ...grpc.CallOption) (*CartResponse, error)
 	RemoveFromCart(ctx context.Context, in *RemoveFromCartRequest, opts ...grpc.CallOption) (*CartResponse, error)
+	UpdateCartItem(ctx context.Context, in *UpdateCartItemRequest, opts ...grpc.CallOption) (*CartResponse, error)
+	ClearCart(ctx context.Context, in *ClearCartRequest, opts ...grpc.CallOption) (*CartResponse, error)
+	MergeCart(ctx context.Context, in *MergeCartRequest, opts ...grpc.CallOption) (*CartResponse, error)
+	ValidateCart(ctx context.Context, in *ValidateCartRequest, opts ...grpc.CallOption) (*ValidateCartResponse, error)
+	ApplyCoupon(ctx context.Context, in *ApplyCouponRequest, opts ...grpc.CallOption) (*CartResponse, error)
+	RemoveCoupon(ctx context.Context, in *RemoveCouponRequest, opts ...grpc.CallOption) (*CartResponse, error)
 }
 
 type cartServiceClient struct {
@@ -71,6 +83,66 @@ func (c *cartServiceClient) RemoveFromCart(ctx context.Context, in *RemoveFromCa
 	return out, nil
 }
 
+func (c *cartServiceClient) UpdateCartItem(ctx context.Context, in *UpdateCartItemRequest, opts ...grpc.CallOption) (*CartResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CartResponse)
+	err := c.cc.Invoke(ctx, CartService_UpdateCartItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cartServiceClient) ClearCart(ctx context.Context, in *ClearCartRequest, opts ...grpc.CallOption) (*CartResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CartResponse)
+	err := c.cc.Invoke(ctx, CartService_ClearCart_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cartServiceClient) MergeCart(ctx context.Context, in *MergeCartRequest, opts ...grpc.CallOption) (*CartResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CartResponse)
+	err := c.cc.Invoke(ctx, CartService_MergeCart_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cartServiceClient) ValidateCart(ctx context.Context, in *ValidateCartRequest, opts ...grpc.CallOption) (*ValidateCartResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidateCartResponse)
+	err := c.cc.Invoke(ctx, CartService_ValidateCart_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cartServiceClient) ApplyCoupon(ctx context.Context, in *ApplyCouponRequest, opts ...grpc.CallOption) (*CartResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CartResponse)
+	err := c.cc.Invoke(ctx, CartService_ApplyCoupon_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cartServiceClient) RemoveCoupon(ctx context.Context, in *RemoveCouponRequest, opts ...grpc.CallOption) (*CartResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CartResponse)
+	err := c.cc.Invoke(ctx, CartService_RemoveCoupon_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CartServiceServer is the server API for CartService service.
 // All implementations must embed UnimplementedCartServiceServer
 // for forward compatibility.
@@ -78,6 +150,12 @@ type CartServiceServer interface {
 	AddToCart(context.Context, *AddToCartRequest) (*CartResponse, error)
 	GetCart(context.Context, *GetCartRequest) (*CartResponse, error)
 	RemoveFromCart(context.Context, *RemoveFromCartRequest) (*CartResponse, error)
+	UpdateCartItem(context.Context, *UpdateCartItemRequest) (*CartResponse, error)
+	ClearCart(context.Context, *ClearCartRequest) (*CartResponse, error)
+	MergeCart(context.Context, *MergeCartRequest) (*CartResponse, error)
+	ValidateCart(context.Context, *ValidateCartRequest) (*ValidateCartResponse, error)
+	ApplyCoupon(context.Context, *ApplyCouponRequest) (*CartResponse, error)
+	RemoveCoupon(context.Context, *RemoveCouponRequest) (*CartResponse, error)
 	mustEmbedUnimplementedCartServiceServer()
 }
 
@@ -97,6 +175,24 @@ func (UnimplementedCartServiceServer) GetCart(context.Context, *GetCartRequest)
 func (UnimplementedCartServiceServer) RemoveFromCart(context.Context, *RemoveFromCartRequest) (*CartResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RemoveFromCart not implemented")
 }
+func (UnimplementedCartServiceServer) UpdateCartItem(context.Context, *UpdateCartItemRequest) (*CartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCartItem not implemented")
+}
+func (UnimplementedCartServiceServer) ClearCart(context.Context, *ClearCartRequest) (*CartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearCart not implemented")
+}
+func (UnimplementedCartServiceServer) MergeCart(context.Context, *MergeCartRequest) (*CartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MergeCart not implemented")
+}
+func (UnimplementedCartServiceServer) ValidateCart(context.Context, *ValidateCartRequest) (*ValidateCartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateCart not implemented")
+}
+func (UnimplementedCartServiceServer) ApplyCoupon(context.Context, *ApplyCouponRequest) (*CartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApplyCoupon not implemented")
+}
+func (UnimplementedCartServiceServer) RemoveCoupon(context.Context, *RemoveCouponRequest) (*CartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveCoupon not implemented")
+}
 func (UnimplementedCartServiceServer) mustEmbedUnimplementedCartServiceServer() {}
 func (UnimplementedCartServiceServer) testEmbeddedByValue()                     {}
 
@@ -172,6 +268,114 @@ func _CartService_RemoveFromCart_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CartService_UpdateCartItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCartItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CartServiceServer).UpdateCartItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CartService_UpdateCartItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CartServiceServer).UpdateCartItem(ctx, req.(*UpdateCartItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CartService_ClearCart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearCartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CartServiceServer).ClearCart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CartService_ClearCart_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CartServiceServer).ClearCart(ctx, req.(*ClearCartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CartService_MergeCart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MergeCartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CartServiceServer).MergeCart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CartService_MergeCart_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CartServiceServer).MergeCart(ctx, req.(*MergeCartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CartService_ValidateCart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateCartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CartServiceServer).ValidateCart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CartService_ValidateCart_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CartServiceServer).ValidateCart(ctx, req.(*ValidateCartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CartService_ApplyCoupon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyCouponRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CartServiceServer).ApplyCoupon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CartService_ApplyCoupon_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CartServiceServer).ApplyCoupon(ctx, req.(*ApplyCouponRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CartService_RemoveCoupon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveCouponRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CartServiceServer).RemoveCoupon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CartService_RemoveCoupon_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CartServiceServer).RemoveCoupon(ctx, req.(*RemoveCouponRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CartService_ServiceDesc is the grpc.ServiceDesc for CartService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -191,6 +395,780 @@ var CartService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RemoveFromCart",
 			Handler:    _CartService_RemoveFromCart_Handler,
 		},
+		{
+			MethodName: "UpdateCartItem",
+			Handler:    _CartService_UpdateCartItem_Handler,
+		},
+		{
+			MethodName: "ClearCart",
+			Handler:    _CartService_ClearCart_Handler,
+		},
+		{
+			MethodName: "MergeCart",
+			Handler:    _CartService_MergeCart_Handler,
+		},
+		{
+			MethodName: "ValidateCart",
+			Handler:    _CartService_ValidateCart_Handler,
+		},
+		{
+			MethodName: "ApplyCoupon",
+			Handler:    _CartService_ApplyCoupon_Handler,
+		},
+		{
+			MethodName: "RemoveCoupon",
+			Handler:    _CartService_RemoveCoupon_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/cart.proto",
+}
+
+const (
+	PromotionService_CreatePromotion_FullMethodName     = "/cartpb.PromotionService/CreatePromotion"
+	PromotionService_GetPromotion_FullMethodName        = "/cartpb.PromotionService/GetPromotion"
+	PromotionService_ListPromotions_FullMethodName      = "/cartpb.PromotionService/ListPromotions"
+	PromotionService_DeactivatePromotion_FullMethodName = "/cartpb.PromotionService/DeactivatePromotion"
+)
+
+// PromotionServiceClient is the client API for PromotionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Administration of promotion rules. Promotions without a code apply to every
+// eligible cart; the others are coupons shoppers apply with ApplyCoupon.
+type PromotionServiceClient interface {
+	CreatePromotion(ctx context.Context, in *Promotion, opts ...grpc.CallOption) (*Promotion, error)
+	GetPromotion(ctx context.Context, in *GetPromotionRequest, opts ...grpc.CallOption) (*Promotion, error)
+	ListPromotions(ctx context.Context, in *ListPromotionsRequest, opts ...grpc.CallOption) (*ListPromotionsResponse, error)
+	DeactivatePromotion(ctx context.Context, in *GetPromotionRequest, opts ...grpc.CallOption) (*Promotion, error)
+}
+
+type promotionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPromotionServiceClient(cc grpc.ClientConnInterface) PromotionServiceClient {
+	return &promotionServiceClient{cc}
+}
+
+func (c *promotionServiceClient) CreatePromotion(ctx context.Context, in *Promotion, opts ...grpc.CallOption) (*Promotion, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Promotion)
+	err := c.cc.Invoke(ctx, PromotionService_CreatePromotion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *promotionServiceClient) GetPromotion(ctx context.Context, in *GetPromotionRequest, opts ...grpc.CallOption) (*Promotion, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Promotion)
+	err := c.cc.Invoke(ctx, PromotionService_GetPromotion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *promotionServiceClient) ListPromotions(ctx context.Context, in *ListPromotionsRequest, opts ...grpc.CallOption) (*ListPromotionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPromotionsResponse)
+	err := c.cc.Invoke(ctx, PromotionService_ListPromotions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *promotionServiceClient) DeactivatePromotion(ctx context.Context, in *GetPromotionRequest, opts ...grpc.CallOption) (*Promotion, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Promotion)
+	err := c.cc.Invoke(ctx, PromotionService_DeactivatePromotion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PromotionServiceServer is the server API for PromotionService service.
+// All implementations must embed UnimplementedPromotionServiceServer
+// for forward compatibility.
+//
+// Administration of promotion rules. Promotions without a code apply to every
+// eligible cart; the others are coupons shoppers apply with ApplyCoupon.
+type PromotionServiceServer interface {
+	CreatePromotion(context.Context, *Promotion) (*Promotion, error)
+	GetPromotion(context.Context, *GetPromotionRequest) (*Promotion, error)
+	ListPromotions(context.Context, *ListPromotionsRequest) (*ListPromotionsResponse, error)
+	DeactivatePromotion(context.Context, *GetPromotionRequest) (*Promotion, error)
+	mustEmbedUnimplementedPromotionServiceServer()
+}
+
+// UnimplementedPromotionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPromotionServiceServer struct{}
+
+func (UnimplementedPromotionServiceServer) CreatePromotion(context.Context, *Promotion) (*Promotion, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePromotion not implemented")
+}
+func (UnimplementedPromotionServiceServer) GetPromotion(context.Context, *GetPromotionRequest) (*Promotion, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPromotion not implemented")
+}
+func (UnimplementedPromotionServiceServer) ListPromotions(context.Context, *ListPromotionsRequest) (*ListPromotionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPromotions not implemented")
+}
+func (UnimplementedPromotionServiceServer) DeactivatePromotion(context.Context, *GetPromotionRequest) (*Promotion, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeactivatePromotion not implemented")
+}
+func (UnimplementedPromotionServiceServer) mustEmbedUnimplementedPromotionServiceServer() {}
+func (UnimplementedPromotionServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafePromotionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PromotionServiceServer will
+// result in compilation errors.
+type UnsafePromotionServiceServer interface {
+	mustEmbedUnimplementedPromotionServiceServer()
+}
+
+func RegisterPromotionServiceServer(s grpc.ServiceRegistrar, srv PromotionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPromotionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PromotionService_ServiceDesc, srv)
+}
+
+func _PromotionService_CreatePromotion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Promotion)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PromotionServiceServer).CreatePromotion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PromotionService_CreatePromotion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PromotionServiceServer).CreatePromotion(ctx, req.(*Promotion))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PromotionService_GetPromotion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPromotionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PromotionServiceServer).GetPromotion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PromotionService_GetPromotion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PromotionServiceServer).GetPromotion(ctx, req.(*GetPromotionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PromotionService_ListPromotions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPromotionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PromotionServiceServer).ListPromotions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PromotionService_ListPromotions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PromotionServiceServer).ListPromotions(ctx, req.(*ListPromotionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PromotionService_DeactivatePromotion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPromotionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PromotionServiceServer).DeactivatePromotion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PromotionService_DeactivatePromotion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PromotionServiceServer).DeactivatePromotion(ctx, req.(*GetPromotionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PromotionService_ServiceDesc is the grpc.ServiceDesc for PromotionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PromotionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cartpb.PromotionService",
+	HandlerType: (*PromotionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreatePromotion",
+			Handler:    _PromotionService_CreatePromotion_Handler,
+		},
+		{
+			MethodName: "GetPromotion",
+			Handler:    _PromotionService_GetPromotion_Handler,
+		},
+		{
+			MethodName: "ListPromotions",
+			Handler:    _PromotionService_ListPromotions_Handler,
+		},
+		{
+			MethodName: "DeactivatePromotion",
+			Handler:    _PromotionService_DeactivatePromotion_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/cart.proto",
+}
+
+const (
+	WishlistService_ListLists_FullMethodName      = "/cartpb.WishlistService/ListLists"
+	WishlistService_CreateList_FullMethodName     = "/cartpb.WishlistService/CreateList"
+	WishlistService_GetList_FullMethodName        = "/cartpb.WishlistService/GetList"
+	WishlistService_RenameList_FullMethodName     = "/cartpb.WishlistService/RenameList"
+	WishlistService_DeleteList_FullMethodName     = "/cartpb.WishlistService/DeleteList"
+	WishlistService_AddListItem_FullMethodName    = "/cartpb.WishlistService/AddListItem"
+	WishlistService_RemoveListItem_FullMethodName = "/cartpb.WishlistService/RemoveListItem"
+	WishlistService_MoveToCart_FullMethodName     = "/cartpb.WishlistService/MoveToCart"
+	WishlistService_MoveFromCart_FullMethodName   = "/cartpb.WishlistService/MoveFromCart"
+	WishlistService_ShareList_FullMethodName      = "/cartpb.WishlistService/ShareList"
+	WishlistService_UnshareList_FullMethodName    = "/cartpb.WishlistService/UnshareList"
+	WishlistService_GetSharedList_FullMethodName  = "/cartpb.WishlistService/GetSharedList"
+)
+
+// WishlistServiceClient is the client API for WishlistService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Named lists of products parked outside the cart. Every user has a default
+// wishlist and a save-for-later list besides the lists they create.
+type WishlistServiceClient interface {
+	ListLists(ctx context.Context, in *ListListsRequest, opts ...grpc.CallOption) (*ListListsResponse, error)
+	CreateList(ctx context.Context, in *CreateListRequest, opts ...grpc.CallOption) (*Wishlist, error)
+	GetList(ctx context.Context, in *GetListRequest, opts ...grpc.CallOption) (*Wishlist, error)
+	RenameList(ctx context.Context, in *RenameListRequest, opts ...grpc.CallOption) (*Wishlist, error)
+	DeleteList(ctx context.Context, in *DeleteListRequest, opts ...grpc.CallOption) (*DeleteListResponse, error)
+	AddListItem(ctx context.Context, in *AddListItemRequest, opts ...grpc.CallOption) (*Wishlist, error)
+	RemoveListItem(ctx context.Context, in *RemoveListItemRequest, opts ...grpc.CallOption) (*Wishlist, error)
+	MoveToCart(ctx context.Context, in *MoveListItemRequest, opts ...grpc.CallOption) (*MoveListItemResponse, error)
+	MoveFromCart(ctx context.Context, in *MoveListItemRequest, opts ...grpc.CallOption) (*MoveListItemResponse, error)
+	ShareList(ctx context.Context, in *ShareListRequest, opts ...grpc.CallOption) (*ShareListResponse, error)
+	UnshareList(ctx context.Context, in *ShareListRequest, opts ...grpc.CallOption) (*Wishlist, error)
+	// Read-only view of a list for anyone holding its share token.
+	GetSharedList(ctx context.Context, in *GetSharedListRequest, opts ...grpc.CallOption) (*Wishlist, error)
+}
+
+type wishlistServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWishlistServiceClient(cc grpc.ClientConnInterface) WishlistServiceClient {
+	return &wishlistServiceClient{cc}
+}
+
+func (c *wishlistServiceClient) ListLists(ctx context.Context, in *ListListsRequest, opts ...grpc.CallOption) (*ListListsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListListsResponse)
+	err := c.cc.Invoke(ctx, WishlistService_ListLists_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wishlistServiceClient) CreateList(ctx context.Context, in *CreateListRequest, opts ...grpc.CallOption) (*Wishlist, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Wishlist)
+	err := c.cc.Invoke(ctx, WishlistService_CreateList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wishlistServiceClient) GetList(ctx context.Context, in *GetListRequest, opts ...grpc.CallOption) (*Wishlist, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Wishlist)
+	err := c.cc.Invoke(ctx, WishlistService_GetList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wishlistServiceClient) RenameList(ctx context.Context, in *RenameListRequest, opts ...grpc.CallOption) (*Wishlist, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Wishlist)
+	err := c.cc.Invoke(ctx, WishlistService_RenameList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wishlistServiceClient) DeleteList(ctx context.Context, in *DeleteListRequest, opts ...grpc.CallOption) (*DeleteListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteListResponse)
+	err := c.cc.Invoke(ctx, WishlistService_DeleteList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wishlistServiceClient) AddListItem(ctx context.Context, in *AddListItemRequest, opts ...grpc.CallOption) (*Wishlist, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Wishlist)
+	err := c.cc.Invoke(ctx, WishlistService_AddListItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wishlistServiceClient) RemoveListItem(ctx context.Context, in *RemoveListItemRequest, opts ...grpc.CallOption) (*Wishlist, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Wishlist)
+	err := c.cc.Invoke(ctx, WishlistService_RemoveListItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wishlistServiceClient) MoveToCart(ctx context.Context, in *MoveListItemRequest, opts ...grpc.CallOption) (*MoveListItemResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MoveListItemResponse)
+	err := c.cc.Invoke(ctx, WishlistService_MoveToCart_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wishlistServiceClient) MoveFromCart(ctx context.Context, in *MoveListItemRequest, opts ...grpc.CallOption) (*MoveListItemResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MoveListItemResponse)
+	err := c.cc.Invoke(ctx, WishlistService_MoveFromCart_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wishlistServiceClient) ShareList(ctx context.Context, in *ShareListRequest, opts ...grpc.CallOption) (*ShareListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ShareListResponse)
+	err := c.cc.Invoke(ctx, WishlistService_ShareList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wishlistServiceClient) UnshareList(ctx context.Context, in *ShareListRequest, opts ...grpc.CallOption) (*Wishlist, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Wishlist)
+	err := c.cc.Invoke(ctx, WishlistService_UnshareList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wishlistServiceClient) GetSharedList(ctx context.Context, in *GetSharedListRequest, opts ...grpc.CallOption) (*Wishlist, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Wishlist)
+	err := c.cc.Invoke(ctx, WishlistService_GetSharedList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WishlistServiceServer is the server API for WishlistService service.
+// All implementations must embed UnimplementedWishlistServiceServer
+// for forward compatibility.
+//
+// Named lists of products parked outside the cart. Every user has a default
+// wishlist and a save-for-later list besides the lists they create.
+type WishlistServiceServer interface {
+	ListLists(context.Context, *ListListsRequest) (*ListListsResponse, error)
+	CreateList(context.Context, *CreateListRequest) (*Wishlist, error)
+	GetList(context.Context, *GetListRequest) (*Wishlist, error)
+	RenameList(context.Context, *RenameListRequest) (*Wishlist, error)
+	DeleteList(context.Context, *DeleteListRequest) (*DeleteListResponse, error)
+	AddListItem(context.Context, *AddListItemRequest) (*Wishlist, error)
+	RemoveListItem(context.Context, *RemoveListItemRequest) (*Wishlist, error)
+	MoveToCart(context.Context, *MoveListItemRequest) (*MoveListItemResponse, error)
+	MoveFromCart(context.Context, *MoveListItemRequest) (*MoveListItemResponse, error)
+	ShareList(context.Context, *ShareListRequest) (*ShareListResponse, error)
+	UnshareList(context.Context, *ShareListRequest) (*Wishlist, error)
+	// Read-only view of a list for anyone holding its share token.
+	GetSharedList(context.Context, *GetSharedListRequest) (*Wishlist, error)
+	mustEmbedUnimplementedWishlistServiceServer()
+}
+
+// UnimplementedWishlistServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWishlistServiceServer struct{}
+
+func (UnimplementedWishlistServiceServer) ListLists(context.Context, *ListListsRequest) (*ListListsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLists not implemented")
+}
+func (UnimplementedWishlistServiceServer) CreateList(context.Context, *CreateListRequest) (*Wishlist, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateList not implemented")
+}
+func (UnimplementedWishlistServiceServer) GetList(context.Context, *GetListRequest) (*Wishlist, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetList not implemented")
+}
+func (UnimplementedWishlistServiceServer) RenameList(context.Context, *RenameListRequest) (*Wishlist, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenameList not implemented")
+}
+func (UnimplementedWishlistServiceServer) DeleteList(context.Context, *DeleteListRequest) (*DeleteListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteList not implemented")
+}
+func (UnimplementedWishlistServiceServer) AddListItem(context.Context, *AddListItemRequest) (*Wishlist, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddListItem not implemented")
+}
+func (UnimplementedWishlistServiceServer) RemoveListItem(context.Context, *RemoveListItemRequest) (*Wishlist, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveListItem not implemented")
+}
+func (UnimplementedWishlistServiceServer) MoveToCart(context.Context, *MoveListItemRequest) (*MoveListItemResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveToCart not implemented")
+}
+func (UnimplementedWishlistServiceServer) MoveFromCart(context.Context, *MoveListItemRequest) (*MoveListItemResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveFromCart not implemented")
+}
+func (UnimplementedWishlistServiceServer) ShareList(context.Context, *ShareListRequest) (*ShareListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShareList not implemented")
+}
+func (UnimplementedWishlistServiceServer) UnshareList(context.Context, *ShareListRequest) (*Wishlist, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnshareList not implemented")
+}
+func (UnimplementedWishlistServiceServer) GetSharedList(context.Context, *GetSharedListRequest) (*Wishlist, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSharedList not implemented")
+}
+func (UnimplementedWishlistServiceServer) mustEmbedUnimplementedWishlistServiceServer() {}
+func (UnimplementedWishlistServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeWishlistServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WishlistServiceServer will
+// result in compilation errors.
+type UnsafeWishlistServiceServer interface {
+	mustEmbedUnimplementedWishlistServiceServer()
+}
+
+func RegisterWishlistServiceServer(s grpc.ServiceRegistrar, srv WishlistServiceServer) {
+	// If the following call pancis, it indicates UnimplementedWishlistServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&WishlistService_ServiceDesc, srv)
+}
+
+func _WishlistService_ListLists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListListsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WishlistServiceServer).ListLists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WishlistService_ListLists_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WishlistServiceServer).ListLists(ctx, req.(*ListListsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WishlistService_CreateList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WishlistServiceServer).CreateList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WishlistService_CreateList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WishlistServiceServer).CreateList(ctx, req.(*CreateListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WishlistService_GetList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WishlistServiceServer).GetList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WishlistService_GetList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WishlistServiceServer).GetList(ctx, req.(*GetListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WishlistService_RenameList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WishlistServiceServer).RenameList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WishlistService_RenameList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WishlistServiceServer).RenameList(ctx, req.(*RenameListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WishlistService_DeleteList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WishlistServiceServer).DeleteList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WishlistService_DeleteList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WishlistServiceServer).DeleteList(ctx, req.(*DeleteListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WishlistService_AddListItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddListItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WishlistServiceServer).AddListItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WishlistService_AddListItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WishlistServiceServer).AddListItem(ctx, req.(*AddListItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WishlistService_RemoveListItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveListItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WishlistServiceServer).RemoveListItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WishlistService_RemoveListItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WishlistServiceServer).RemoveListItem(ctx, req.(*RemoveListItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WishlistService_MoveToCart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveListItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WishlistServiceServer).MoveToCart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WishlistService_MoveToCart_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WishlistServiceServer).MoveToCart(ctx, req.(*MoveListItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WishlistService_MoveFromCart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveListItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WishlistServiceServer).MoveFromCart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WishlistService_MoveFromCart_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WishlistServiceServer).MoveFromCart(ctx, req.(*MoveListItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WishlistService_ShareList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WishlistServiceServer).ShareList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WishlistService_ShareList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WishlistServiceServer).ShareList(ctx, req.(*ShareListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WishlistService_UnshareList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WishlistServiceServer).UnshareList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WishlistService_UnshareList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WishlistServiceServer).UnshareList(ctx, req.(*ShareListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WishlistService_GetSharedList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSharedListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WishlistServiceServer).GetSharedList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WishlistService_GetSharedList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WishlistServiceServer).GetSharedList(ctx, req.(*GetSharedListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// WishlistService_ServiceDesc is the grpc.ServiceDesc for WishlistService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WishlistService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cartpb.WishlistService",
+	HandlerType: (*WishlistServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListLists",
+			Handler:    _WishlistService_ListLists_Handler,
+		},
+		{
+			MethodName: "CreateList",
+			Handler:    _WishlistService_CreateList_Handler,
+		},
+		{
+			MethodName: "GetList",
+			Handler:    _WishlistService_GetList_Handler,
+		},
+		{
+			MethodName: "RenameList",
+			Handler:    _WishlistService_RenameList_Handler,
+		},
+		{
+			MethodName: "DeleteList",
+			Handler:    _WishlistService_DeleteList_Handler,
+		},
+		{
+			MethodName: "AddListItem",
+			Handler:    _WishlistService_AddListItem_Handler,
+		},
+		{
+			MethodName: "RemoveListItem",
+			Handler:    _WishlistService_RemoveListItem_Handler,
+		},
+		{
+			MethodName: "MoveToCart",
+			Handler:    _WishlistService_MoveToCart_Handler,
+		},
+		{
+			MethodName: "MoveFromCart",
+			Handler:    _WishlistService_MoveFromCart_Handler,
+		},
+		{
+			MethodName: "ShareList",
+			Handler:    _WishlistService_ShareList_Handler,
+		},
+		{
+			MethodName: "UnshareList",
+			Handler:    _WishlistService_UnshareList_Handler,
+		},
+		{
+			MethodName: "GetSharedList",
+			Handler:    _WishlistService_GetSharedList_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/cart.proto",
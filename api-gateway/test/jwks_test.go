@@ -0,0 +1,90 @@
+package test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"api-gateway/internal/auth"
+	"api-gateway/internal/config/order"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type fakeTokenChecker struct{}
+
+func (fakeTokenChecker) CheckToken(token string) (*auth.TokenStatus, error) {
+	return &auth.TokenStatus{EmailVerified: true}, nil
+}
+
+// jwksServer publishes the public keys the way user-service does and counts
+// the fetches.
+func jwksServer(t *testing.T, keys map[string]ed25519.PublicKey, fetches *int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*fetches++
+		var set []map[string]string
+		for kid, key := range keys {
+			set = append(set, map[string]string{
+				"kty": "OKP", "crv": "Ed25519", "alg": "EdDSA", "use": "sig", "kid": kid,
+				"x": base64.RawURLEncoding.EncodeToString(key),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": set})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func signEdDSA(t *testing.T, kid string, key ed25519.PrivateKey) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+		"user_id": "u1",
+		"sid":     "s1",
+		"exp":     time.Now().Add(time.Minute).Unix(),
+	})
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestJWTAuth_VerifiesWithJWKS(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	fetches := 0
+	keys := map[string]ed25519.PublicKey{"key-1": public}
+	server := jwksServer(t, keys, &fetches)
+
+	jwtAuth := auth.NewJWTAuth(&order.AuthConfig{JWKSURL: server.URL, JWKSCacheTTL: time.Hour}, fakeTokenChecker{})
+
+	claims, err := jwtAuth.ValidateToken(signEdDSA(t, "key-1", private))
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	if claims.UserID != "u1" || claims.SessionID != "s1" || !claims.EmailVerified {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+	if _, err := jwtAuth.ValidateToken(signEdDSA(t, "key-1", private)); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 1 {
+		t.Errorf("expected the JWKS to be cached, got %d fetches", fetches)
+	}
+
+	_, other, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err := jwtAuth.ValidateToken(signEdDSA(t, "key-1", other)); err == nil {
+		t.Error("a token signed with another key was accepted")
+	}
+
+	hs := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": "u1"})
+	hs.Header["kid"] = "key-1"
+	hsToken, _ := hs.SignedString([]byte("your-secret-key"))
+	if _, err := jwtAuth.ValidateToken(hsToken); err == nil {
+		t.Error("an HS256 token was accepted")
+	}
+}
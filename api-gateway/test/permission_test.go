@@ -0,0 +1,41 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"api-gateway/internal/auth"
+	"api-gateway/internal/config/order"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwtAuth := auth.NewJWTAuth(&order.AuthConfig{}, fakeTokenChecker{})
+
+	serve := func(permissions []string) int {
+		r := gin.New()
+		r.GET("/", func(c *gin.Context) {
+			if permissions != nil {
+				c.Set("permissions", permissions)
+			}
+		}, jwtAuth.RequirePermission(auth.PermissionProductsWrite), func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		})
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec.Code
+	}
+
+	if code := serve([]string{auth.PermissionOrdersCreate, auth.PermissionProductsWrite}); code != http.StatusNoContent {
+		t.Errorf("expected a seller to pass, got %d", code)
+	}
+	if code := serve([]string{auth.PermissionOrdersCreate}); code != http.StatusForbidden {
+		t.Errorf("expected a customer to be refused, got %d", code)
+	}
+	if code := serve(nil); code != http.StatusUnauthorized {
+		t.Errorf("expected an unauthenticated request to be refused, got %d", code)
+	}
+}
@@ -0,0 +1,132 @@
+package userdata
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	// UserDeletedSubject is published by user-service when a user's data is
+	// to be erased, again and again until every service acknowledges on
+	// ErasureCompletedSubject.
+	UserDeletedSubject      = "user.deleted"
+	ErasureCompletedSubject = "user.erasure.completed"
+	// UserExportSubject is a request for a service's data of a user.
+	UserExportSubject = "user.export.requested"
+)
+
+type UserDeletedEvent struct {
+	UserID      string    `json:"user_id"`
+	ErasureID   string    `json:"erasure_id"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// ErasureCompletedEvent acknowledges a UserDeletedEvent; Error is set when
+// the erasure failed.
+type ErasureCompletedEvent struct {
+	ErasureID   string    `json:"erasure_id"`
+	UserID      string    `json:"user_id"`
+	Service     string    `json:"service"`
+	CompletedAt time.Time `json:"completed_at"`
+	Error       string    `json:"error,omitempty"`
+}
+
+type UserExportRequest struct {
+	UserID string `json:"user_id"`
+}
+
+type UserExportReply struct {
+	Service string          `json:"service"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// Store is the data a service keeps of its users.
+type Store interface {
+	// Export returns the user's data, which is sent as JSON.
+	Export(ctx context.Context, userID string) (interface{}, error)
+	// Erase deletes the user's data for good and returns how many records
+	// it removed.
+	Erase(ctx context.Context, userID string) (int64, error)
+}
+
+// Subscriber exports and erases a user's data in store when user-service
+// asks for it, answering as service.
+type Subscriber struct {
+	nc      *nats.Conn
+	service string
+	store   Store
+}
+
+func NewSubscriber(nc *nats.Conn, service string, store Store) *Subscriber {
+	return &Subscriber{nc: nc, service: service, store: store}
+}
+
+func (s *Subscriber) Subscribe() error {
+	if _, err := s.nc.Subscribe(UserDeletedSubject, s.handleUserDeleted); err != nil {
+		return err
+	}
+	if _, err := s.nc.Subscribe(UserExportSubject, s.handleUserExport); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Subscriber) handleUserDeleted(msg *nats.Msg) {
+	var event UserDeletedEvent
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		log.Printf("Error unmarshaling user deletion: %v", err)
+		return
+	}
+
+	ack := ErasureCompletedEvent{ErasureID: event.ErasureID, UserID: event.UserID, Service: s.service}
+	erased, err := s.store.Erase(context.Background(), event.UserID)
+	if err != nil {
+		log.Printf("Error erasing data of user %s: %v", event.UserID, err)
+		ack.Error = err.Error()
+	} else {
+		log.Printf("Erased %d records of user %s", erased, event.UserID)
+	}
+	ack.CompletedAt = time.Now()
+
+	data, err := json.Marshal(ack)
+	if err != nil {
+		log.Printf("Error marshaling erasure acknowledgement: %v", err)
+		return
+	}
+	if err := s.nc.Publish(ErasureCompletedSubject, data); err != nil {
+		log.Printf("Error acknowledging erasure %s: %v", event.ErasureID, err)
+	}
+}
+
+func (s *Subscriber) handleUserExport(msg *nats.Msg) {
+	var req UserExportRequest
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		log.Printf("Error unmarshaling user export request: %v", err)
+		return
+	}
+
+	reply := UserExportReply{Service: s.service}
+	exported, err := s.store.Export(context.Background(), req.UserID)
+	if err == nil {
+		reply.Data, err = json.Marshal(exported)
+	}
+	if err != nil {
+		log.Printf("Error exporting data of user %s: %v", req.UserID, err)
+		reply.Data = nil
+		reply.Error = err.Error()
+	}
+
+	data, err := json.Marshal(reply)
+	if err != nil {
+		log.Printf("Error marshaling user export: %v", err)
+		return
+	}
+	if err := msg.Respond(data); err != nil {
+		log.Printf("Error responding to user export request: %v", err)
+	}
+}
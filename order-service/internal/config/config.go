@@ -13,6 +13,8 @@ type Config struct {
 	MongoURI       string
 	MongoDB        string
 	NatsURL        string
+	ProductAddr    string
+	InternalToken  string
 	JWTSecret      string
 	RateLimit      int
 	RateLimitBurst int
@@ -27,6 +29,8 @@ func Load() *Config {
 		MongoURI:       getEnv("MONGO_URI", "mongodb://mongodb:27017"),
 		MongoDB:        getEnv("MONGO_DB", "orders"),
 		NatsURL:        getEnv("NATS_URL", "nats://nats:4222"),
+		ProductAddr:    getEnv("PRODUCT_SERVICE_ADDR", "product-service:50054"),
+		InternalToken:  getEnv("INTERNAL_SERVICE_TOKEN", ""),
 		JWTSecret:      getEnv("JWT_SECRET", "your-secret-key"),
 		RateLimit:      getEnvAsInt("RATE_LIMIT", 60),
 		RateLimitBurst: getEnvAsInt("RATE_LIMIT_BURST", 10),
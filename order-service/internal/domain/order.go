@@ -12,8 +12,13 @@ var (
 	ErrInvalidTotalPrice   = errors.New("invalid total price")
 	ErrInvalidDeliveryTime = errors.New("invalid delivery time")
 	ErrOrderNotFound       = errors.New("order not found")
+	ErrInsufficientStock   = errors.New("insufficient stock")
 )
 
+// ErasedUserID replaces the user ID on the orders of a user whose data was
+// erased. The orders themselves are kept for accounting.
+const ErasedUserID = "erased"
+
 type OrderStatus string
 
 const (
@@ -29,6 +34,10 @@ type Order struct {
 	ID              string
 	UserID          string
 	CartID          string
+	Items           []OrderItem
+	Subtotal        float64
+	Discount        float64
+	Discounts       []OrderDiscount
 	TotalPrice      float64
 	Currency        string
 	Status          OrderStatus
@@ -40,12 +49,23 @@ type Order struct {
 
 type OrderItem struct {
 	ProductID   string
+	SKUID       string
 	ProductName string
 	Quantity    int32
 	UnitPrice   float64
 	TotalPrice  float64
 }
 
+// OrderDiscount is one line of the discount breakdown the order was charged
+// with, as priced by cart-service.
+type OrderDiscount struct {
+	Source      string
+	PromotionID string
+	Code        string
+	Description string
+	Amount      float64
+}
+
 func NewOrder(userID string, cartID string, address *DeliveryAddress, deliveryTime time.Time) (*Order, error) {
 	if userID == "" {
 		return nil, ErrInvalidUserID
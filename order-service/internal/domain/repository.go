@@ -9,6 +9,11 @@ type OrderRepository interface {
 	Update(ctx context.Context, order *Order) error
 	UpdateStatus(ctx context.Context, orderID string, status OrderStatus) error
 	Delete(ctx context.Context, id string) error
+	// AnonymizeUser replaces the user ID on the user's orders with
+	// ErasedUserID and clears the recipient's name, street, apartment,
+	// postal code and phone from their delivery addresses. It returns how
+	// many orders it changed.
+	AnonymizeUser(ctx context.Context, userID string) (int, error)
 }
 
 type DeliveryAddressRepository interface {
@@ -26,6 +31,13 @@ type DeliverySlotRepository interface {
 	ReleaseSlot(ctx context.Context, orderID string, slotID string) error
 }
 
+// StockReserver holds product-service stock of a SKU for an order.
+// ReserveStock returns ErrInsufficientStock when too little is left.
+type StockReserver interface {
+	ReserveStock(ctx context.Context, skuID string, quantity int32) error
+	ReleaseStock(ctx context.Context, skuID string, quantity int32) error
+}
+
 type Cache interface {
 	Set(ctx context.Context, key string, value interface{}, ttl int) error
 	Get(ctx context.Context, key string) (interface{}, error)
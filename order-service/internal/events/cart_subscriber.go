@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/nats-io/nats.go"
@@ -12,17 +14,129 @@ import (
 
 const (
 	CartInfoSubject = "cart.info"
+	// CartValidateSubject has three tokens so that the CARTS stream, which
+	// captures cart.*, does not answer the request with a publish ack.
+	CartValidateSubject = "cart.validation.get"
+	// CartPromotionsRedeemSubject redeems the promotions of a cart for an
+	// order; CartPromotionsReleaseSubject undoes that.
+	CartPromotionsRedeemSubject  = "cart.promotions.redeem"
+	CartPromotionsReleaseSubject = "cart.promotions.release"
+	// CartUpdatedSubject carries every new version of a cart. Every
+	// order-service instance reads it through its own ephemeral consumer,
+	// as each clears its own cache; updates sent while an instance was down
+	// do not matter, as it starts with an empty cache.
+	CartUpdatedSubject = "cart.updated"
+
+	// maxCachedCarts bounds the cart infos an instance keeps.
+	maxCachedCarts = 10000
+
+	// The CARTS stream limits, the same as cart-service applies to it.
+	cartsStreamMaxAge   = 24 * time.Hour
+	cartsStreamMaxBytes = 256 << 20
+
+	// GuestCartPrefix starts the cart IDs of anonymous shoppers. Any other
+	// cart ID is the ID of the user owning the cart.
+	GuestCartPrefix = "guest:"
 )
 
 type CartInfo struct {
-	CartID     string  `json:"cart_id"`
-	TotalPrice float64 `json:"total_price"`
-	Currency   string  `json:"currency"`
+	CartID     string         `json:"cart_id"`
+	TotalPrice float64        `json:"total_price"`
+	Currency   string         `json:"currency"`
+	IsGuest    bool           `json:"is_guest"`
+	Subtotal   float64        `json:"subtotal"`
+	Discount   float64        `json:"discount"`
+	Tax        float64        `json:"tax"`
+	Discounts  []CartDiscount `json:"discounts,omitempty"`
+	Items      []CartItem     `json:"items,omitempty"`
+}
+
+// CartItem is one available line of a cart, priced by cart-service. SKUID is
+// empty for products sold without variants.
+type CartItem struct {
+	ProductID string  `json:"product_id"`
+	SKUID     string  `json:"sku_id,omitempty"`
+	Name      string  `json:"name"`
+	Quantity  int32   `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	LineTotal float64 `json:"line_total"`
+}
+
+// CartDiscount is one line of a cart's discount breakdown: sale prices
+// ("sale") or a promotion ("promotion").
+type CartDiscount struct {
+	Source      string  `json:"source"`
+	PromotionID string  `json:"promotion_id,omitempty"`
+	Code        string  `json:"code,omitempty"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
+
+type promotionRedeemRequest struct {
+	CartID  string `json:"cart_id"`
+	OrderID string `json:"order_id"`
+}
+
+type promotionRedeemReply struct {
+	Cart  *CartInfo `json:"cart,omitempty"`
+	Error string    `json:"error,omitempty"`
+}
+
+// PromotionsRejectedError means cart-service refused to redeem the cart's
+// promotions, e.g. because a usage limit was reached in the meantime.
+type PromotionsRejectedError struct {
+	Reason string
+}
+
+func (e *PromotionsRejectedError) Error() string {
+	return "promotions could not be redeemed: " + e.Reason
+}
+
+// CartValidation is the cart-service's answer to cart.validation.get: whether
+// every line of the cart still matches product-service. Error is set when
+// cart-service could not validate the cart.
+type CartValidation struct {
+	CartID string                `json:"cart_id"`
+	Valid  bool                  `json:"valid"`
+	Issues []CartValidationIssue `json:"issues,omitempty"`
+	Error  string                `json:"error,omitempty"`
+}
+
+type CartValidationIssue struct {
+	ProductID string `json:"product_id"`
+	SKUID     string `json:"sku_id,omitempty"`
+	Kind      string `json:"kind"` // removed, insufficient_stock or price_changed
+}
+
+// CartUpdatedEvent is a cart as cart-service stored it after a change.
+// Versions of a cart go up by one with every change.
+type CartUpdatedEvent struct {
+	CartID    string            `json:"cart_id"`
+	Version   int64             `json:"version"`
+	Change    string            `json:"change"`
+	IsGuest   bool              `json:"is_guest"`
+	Items     []CartUpdatedItem `json:"items"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+type CartUpdatedItem struct {
+	ProductID  string    `json:"product_id"`
+	SKUID      string    `json:"sku_id,omitempty"`
+	Quantity   int32     `json:"quantity"`
+	AddedPrice float64   `json:"added_price,omitempty"`
+	AddedAt    time.Time `json:"added_at"`
+}
+
+func IsGuestCart(cartID string) bool {
+	return strings.HasPrefix(cartID, GuestCartPrefix)
 }
 
 type CartSubscriber struct {
-	nc            *nats.Conn
-	js            nats.JetStreamContext
+	nc *nats.Conn
+	js nats.JetStreamContext
+	mu sync.RWMutex
+	// cartInfoCache holds at most maxCachedCarts carts. A cart is dropped
+	// from it on every cart.updated.
 	cartInfoCache map[string]*CartInfo
 }
 
@@ -61,6 +175,8 @@ func NewCartSubscriber(url string) (*CartSubscriber, error) {
 	stream := &nats.StreamConfig{
 		Name:     "CARTS",
 		Subjects: []string{"cart.*"},
+		MaxAge:   cartsStreamMaxAge,
+		MaxBytes: cartsStreamMaxBytes,
 	}
 
 	if _, err := js.AddStream(stream); err != nil {
@@ -88,6 +204,13 @@ func NewCartSubscriber(url string) (*CartSubscriber, error) {
 			sub.js = nil
 		}
 	}
+	if sub.js != nil {
+		_, err := sub.js.Subscribe(CartUpdatedSubject, sub.handleCartUpdated,
+			nats.DeliverNew(), nats.ManualAck())
+		if err != nil {
+			log.Printf("[ERROR] Could not subscribe to cart updates: %v", err)
+		}
+	}
 
 	return sub, nil
 }
@@ -99,12 +222,48 @@ func (s *CartSubscriber) handleCartInfo(msg *nats.Msg) {
 		return
 	}
 
-	s.cartInfoCache[cartInfo.CartID] = &cartInfo
+	s.cache(&cartInfo)
+}
+
+// cache stores info, first dropping some other cart when the cache is full.
+func (s *CartSubscriber) cache(info *CartInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.cartInfoCache[info.CartID]; !ok && len(s.cartInfoCache) >= maxCachedCarts {
+		for cartID := range s.cartInfoCache {
+			delete(s.cartInfoCache, cartID)
+			break
+		}
+	}
+	s.cartInfoCache[info.CartID] = info
+}
+
+func (s *CartSubscriber) handleCartUpdated(msg *nats.Msg) {
+	var event CartUpdatedEvent
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		// Redelivering a message that cannot be read does not help.
+		log.Printf("Error unmarshaling cart update: %v", err)
+		msg.Term()
+		return
+	}
+
+	// A redelivered older version only costs one more cart.info request.
+	s.mu.Lock()
+	delete(s.cartInfoCache, event.CartID)
+	s.mu.Unlock()
+
+	if err := msg.Ack(); err != nil {
+		log.Printf("Error acking cart update of cart %s: %v", event.CartID, err)
+	}
 }
 
+// GetCartInfo resolves a user cart or a guest cart by its cart ID.
 func (s *CartSubscriber) GetCartInfo(ctx context.Context, cartID string) (*CartInfo, error) {
 	// First check the cache
-	if info, ok := s.cartInfoCache[cartID]; ok {
+	s.mu.RLock()
+	info, ok := s.cartInfoCache[cartID]
+	s.mu.RUnlock()
+	if ok {
 		return info, nil
 	}
 
@@ -124,11 +283,81 @@ func (s *CartSubscriber) GetCartInfo(ctx context.Context, cartID string) (*CartI
 		return nil, err
 	}
 
+	// Older cart-service versions do not send is_guest.
+	cartInfo.IsGuest = cartInfo.IsGuest || IsGuestCart(cartID)
+
 	// Cache the result
-	s.cartInfoCache[cartID] = &cartInfo
+	s.cache(&cartInfo)
 	return &cartInfo, nil
 }
 
+// ValidateCart asks cart-service to check the cart against live stock and
+// prices. It is never answered from the cache.
+func (s *CartSubscriber) ValidateCart(ctx context.Context, cartID string) (*CartValidation, error) {
+	if s.nc == nil {
+		return nil, ErrNATSUnavailable()
+	}
+
+	msg, err := s.nc.RequestWithContext(ctx, CartValidateSubject, []byte(cartID))
+	if err != nil {
+		return nil, err
+	}
+
+	var result CartValidation
+	if err := json.Unmarshal(msg.Data, &result); err != nil {
+		return nil, err
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("cart-service could not validate cart %s: %s", cartID, result.Error)
+	}
+	return &result, nil
+}
+
+// RedeemPromotions asks cart-service to record the redemption of every
+// promotion on the cart for the order and returns the cart as it is charged.
+// A refusal is returned as *PromotionsRejectedError.
+func (s *CartSubscriber) RedeemPromotions(ctx context.Context, cartID, orderID string) (*CartInfo, error) {
+	if s.nc == nil {
+		return nil, ErrNATSUnavailable()
+	}
+
+	data, err := json.Marshal(promotionRedeemRequest{CartID: cartID, OrderID: orderID})
+	if err != nil {
+		return nil, err
+	}
+	msg, err := s.nc.RequestWithContext(ctx, CartPromotionsRedeemSubject, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply promotionRedeemReply
+	if err := json.Unmarshal(msg.Data, &reply); err != nil {
+		return nil, err
+	}
+	if reply.Error != "" {
+		return nil, &PromotionsRejectedError{Reason: reply.Error}
+	}
+	if reply.Cart == nil {
+		return nil, fmt.Errorf("empty reply to %s", CartPromotionsRedeemSubject)
+	}
+	reply.Cart.IsGuest = reply.Cart.IsGuest || IsGuestCart(cartID)
+	return reply.Cart, nil
+}
+
+// ReleasePromotions gives back the promotion uses redeemed for an order that
+// was not placed after all.
+func (s *CartSubscriber) ReleasePromotions(cartID, orderID string) error {
+	if s.nc == nil {
+		return ErrNATSUnavailable()
+	}
+
+	data, err := json.Marshal(promotionRedeemRequest{CartID: cartID, OrderID: orderID})
+	if err != nil {
+		return err
+	}
+	return s.nc.Publish(CartPromotionsReleaseSubject, data)
+}
+
 func ErrNATSUnavailable() error {
 	return fmt.Errorf("NATS unavailable: CartSubscriber is in noop mode")
 }
@@ -0,0 +1,142 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/hsibAD/order-service/internal/domain"
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	// UserDeletedSubject is published by user-service when a user's data is
+	// to be erased, again and again until every service acknowledges on
+	// ErasureCompletedSubject.
+	UserDeletedSubject      = "user.deleted"
+	ErasureCompletedSubject = "user.erasure.completed"
+	// UserExportSubject is a request for this service's data of a user.
+	UserExportSubject = "user.export.requested"
+
+	// ServiceName is how order-service names itself to user-service.
+	ServiceName = "order-service"
+
+	exportPageSize = 100
+)
+
+type UserDeletedEvent struct {
+	UserID      string    `json:"user_id"`
+	ErasureID   string    `json:"erasure_id"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// ErasureCompletedEvent acknowledges a UserDeletedEvent; Error is set when
+// the erasure failed.
+type ErasureCompletedEvent struct {
+	ErasureID   string    `json:"erasure_id"`
+	UserID      string    `json:"user_id"`
+	Service     string    `json:"service"`
+	CompletedAt time.Time `json:"completed_at"`
+	Error       string    `json:"error,omitempty"`
+}
+
+type UserExportRequest struct {
+	UserID string `json:"user_id"`
+}
+
+type UserExportReply struct {
+	Service string          `json:"service"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// UserDataSubscriber exports and anonymizes the orders of a user when
+// user-service asks for it.
+type UserDataSubscriber struct {
+	nc     *nats.Conn
+	orders domain.OrderRepository
+}
+
+func NewUserDataSubscriber(nc *nats.Conn, orders domain.OrderRepository) *UserDataSubscriber {
+	return &UserDataSubscriber{nc: nc, orders: orders}
+}
+
+func (s *UserDataSubscriber) Subscribe() error {
+	if _, err := s.nc.Subscribe(UserDeletedSubject, s.handleUserDeleted); err != nil {
+		return err
+	}
+	if _, err := s.nc.Subscribe(UserExportSubject, s.handleUserExport); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *UserDataSubscriber) handleUserDeleted(msg *nats.Msg) {
+	var event UserDeletedEvent
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		log.Printf("Error unmarshaling user deletion: %v", err)
+		return
+	}
+
+	ack := ErasureCompletedEvent{ErasureID: event.ErasureID, UserID: event.UserID, Service: ServiceName}
+	anonymized, err := s.orders.AnonymizeUser(context.Background(), event.UserID)
+	if err != nil {
+		log.Printf("Error anonymizing orders of user %s: %v", event.UserID, err)
+		ack.Error = err.Error()
+	} else {
+		log.Printf("Anonymized %d orders of user %s", anonymized, event.UserID)
+	}
+	ack.CompletedAt = time.Now()
+
+	data, err := json.Marshal(ack)
+	if err != nil {
+		log.Printf("Error marshaling erasure acknowledgement: %v", err)
+		return
+	}
+	if err := s.nc.Publish(ErasureCompletedSubject, data); err != nil {
+		log.Printf("Error acknowledging erasure %s: %v", event.ErasureID, err)
+	}
+}
+
+func (s *UserDataSubscriber) handleUserExport(msg *nats.Msg) {
+	var req UserExportRequest
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		log.Printf("Error unmarshaling user export request: %v", err)
+		return
+	}
+
+	reply := UserExportReply{Service: ServiceName}
+	orders, err := s.userOrders(context.Background(), req.UserID)
+	if err == nil {
+		reply.Data, err = json.Marshal(map[string]interface{}{"orders": orders})
+	}
+	if err != nil {
+		log.Printf("Error exporting orders of user %s: %v", req.UserID, err)
+		reply.Data = nil
+		reply.Error = err.Error()
+	}
+
+	data, err := json.Marshal(reply)
+	if err != nil {
+		log.Printf("Error marshaling user export: %v", err)
+		return
+	}
+	if err := msg.Respond(data); err != nil {
+		log.Printf("Error responding to user export request: %v", err)
+	}
+}
+
+func (s *UserDataSubscriber) userOrders(ctx context.Context, userID string) ([]*domain.Order, error) {
+	all := []*domain.Order{}
+	for page := 1; ; page++ {
+		orders, total, err := s.orders.GetByUserID(ctx, userID, page, exportPageSize)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, orders...)
+		if len(orders) == 0 || len(all) >= total {
+			return all, nil
+		}
+	}
+}
@@ -2,13 +2,16 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/hsibAD/order-service/internal/domain"
 	"github.com/hsibAD/order-service/internal/events"
 	pb "github.com/hsibAD/order-service/proto"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -18,12 +21,14 @@ type OrderHandler struct {
 	pb.UnimplementedOrderServiceServer
 	orderRepo domain.OrderRepository
 	cartSub   *events.CartSubscriber
+	stock     domain.StockReserver
 }
 
-func NewOrderHandler(orderRepo domain.OrderRepository, cartSub *events.CartSubscriber) *OrderHandler {
+func NewOrderHandler(orderRepo domain.OrderRepository, cartSub *events.CartSubscriber, stock domain.StockReserver) *OrderHandler {
 	return &OrderHandler{
 		orderRepo: orderRepo,
 		cartSub:   cartSub,
+		stock:     stock,
 	}
 }
 
@@ -44,6 +49,16 @@ func (h *OrderHandler) CreateOrder(ctx context.Context, req *pb.CreateOrderReque
 		if err != nil {
 			return nil, status.Error(codes.Internal, "failed to get cart information")
 		}
+
+		// Refuse checkout while the cart has lines that no longer match
+		// product-service; the client has to call ValidateCart first.
+		validation, err := h.cartSub.ValidateCart(ctx, req.CartId)
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, "failed to validate cart")
+		}
+		if !validation.Valid {
+			return nil, status.Error(codes.FailedPrecondition, describeCartIssues(validation.Issues))
+		}
 	} else {
 		// Если cartSub не инициализирован, создаем заглушку для cartInfo
 		cartInfo = &events.CartInfo{
@@ -87,19 +102,43 @@ func (h *OrderHandler) CreateOrder(ctx context.Context, req *pb.CreateOrderReque
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	// Set price and currency from cart
-	order.TotalPrice = cartInfo.TotalPrice
-	order.Currency = cartInfo.Currency
+	// The ID is chosen up front so that the cart's promotions can be
+	// redeemed for the order before it is stored.
+	order.ID = primitive.NewObjectID().Hex()
+	if h.cartSub != nil {
+		redeemed, err := h.cartSub.RedeemPromotions(ctx, req.CartId, order.ID)
+		if err != nil {
+			var rejected *events.PromotionsRejectedError
+			if errors.As(err, &rejected) {
+				return nil, status.Error(codes.FailedPrecondition, rejected.Error())
+			}
+			return nil, status.Error(codes.Unavailable, "failed to redeem cart promotions")
+		}
+		cartInfo = redeemed
+	}
+
+	// Set items, price and currency from cart
+	applyCartTotals(order, cartInfo)
+
+	// Hold the stock of every line before the order is stored
+	reserved, err := h.reserveStock(ctx, order.Items)
+	if err != nil {
+		h.releasePromotions(order)
+		if errors.Is(err, domain.ErrInsufficientStock) {
+			return nil, status.Error(codes.FailedPrecondition, "some items are out of stock")
+		}
+		return nil, status.Error(codes.Unavailable, "failed to reserve stock")
+	}
 
 	// Добавляем проверку на nil для orderRepo
 	if h.orderRepo != nil {
 		// Save order using repository
 		if err := h.orderRepo.Create(ctx, order); err != nil {
+			h.releaseStock(reserved)
+			h.releasePromotions(order)
 			return nil, status.Error(codes.Internal, "failed to create order")
 		}
 	} else {
-		// Если репозиторий не инициализирован, генерируем ID для заказа
-		order.ID = fmt.Sprintf("demo-order-%d", time.Now().Unix())
 		log.Printf("Demo mode: Created order with ID: %s", order.ID)
 	}
 
@@ -121,6 +160,7 @@ func (h *OrderHandler) CreateOrder(ctx context.Context, req *pb.CreateOrderReque
 		Id:              order.ID,
 		UserId:          order.UserID,
 		CartId:          order.CartID,
+		Items:           toPbOrderItems(order.Items),
 		Status:          string(order.Status),
 		TotalPrice:      order.TotalPrice,
 		Currency:        order.Currency,
@@ -128,9 +168,121 @@ func (h *OrderHandler) CreateOrder(ctx context.Context, req *pb.CreateOrderReque
 		DeliveryTime:    timestamppb.New(order.DeliveryTime),
 		CreatedAt:       timestamppb.New(order.CreatedAt),
 		UpdatedAt:       timestamppb.New(order.UpdatedAt),
+		Subtotal:        order.Subtotal,
+		Discount:        order.Discount,
+		Discounts:       toPbOrderDiscounts(order.Discounts),
 	}, nil
 }
 
+// reserveStock holds the stock of every order item sold by SKU. On failure it
+// releases what it already held; otherwise it returns the items it reserved.
+func (h *OrderHandler) reserveStock(ctx context.Context, items []domain.OrderItem) ([]domain.OrderItem, error) {
+	if h.stock == nil {
+		return nil, nil
+	}
+
+	var reserved []domain.OrderItem
+	for _, item := range items {
+		if item.SKUID == "" {
+			continue
+		}
+		if err := h.stock.ReserveStock(ctx, item.SKUID, item.Quantity); err != nil {
+			h.releaseStock(reserved)
+			return nil, err
+		}
+		reserved = append(reserved, item)
+	}
+	return reserved, nil
+}
+
+// releaseStock gives back the stock reserveStock held. It does not use the
+// request's context, which may already be cancelled.
+func (h *OrderHandler) releaseStock(items []domain.OrderItem) {
+	for _, item := range items {
+		if err := h.stock.ReleaseStock(context.Background(), item.SKUID, item.Quantity); err != nil {
+			log.Printf("Could not release %d of SKU %s: %v", item.Quantity, item.SKUID, err)
+		}
+	}
+}
+
+// releasePromotions undoes the redemption of the cart's promotions for an
+// order that was not created.
+func (h *OrderHandler) releasePromotions(order *domain.Order) {
+	if h.cartSub == nil {
+		return
+	}
+	if err := h.cartSub.ReleasePromotions(order.CartID, order.ID); err != nil {
+		log.Printf("Could not release promotions of order %s: %v", order.ID, err)
+	}
+}
+
+// applyCartTotals charges the order what cart-service priced the cart at,
+// line by line.
+func applyCartTotals(order *domain.Order, cartInfo *events.CartInfo) {
+	order.Items = nil
+	for _, item := range cartInfo.Items {
+		order.Items = append(order.Items, domain.OrderItem{
+			ProductID:   item.ProductID,
+			SKUID:       item.SKUID,
+			ProductName: item.Name,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			TotalPrice:  item.LineTotal,
+		})
+	}
+	order.TotalPrice = cartInfo.TotalPrice
+	order.Currency = cartInfo.Currency
+	order.Subtotal = cartInfo.Subtotal
+	order.Discount = cartInfo.Discount
+	order.Discounts = nil
+	for _, d := range cartInfo.Discounts {
+		order.Discounts = append(order.Discounts, domain.OrderDiscount{
+			Source:      d.Source,
+			PromotionID: d.PromotionID,
+			Code:        d.Code,
+			Description: d.Description,
+			Amount:      d.Amount,
+		})
+	}
+}
+
+func toPbOrderDiscounts(discounts []domain.OrderDiscount) []*pb.OrderDiscount {
+	result := make([]*pb.OrderDiscount, 0, len(discounts))
+	for _, d := range discounts {
+		result = append(result, &pb.OrderDiscount{
+			Source:      d.Source,
+			PromotionId: d.PromotionID,
+			Code:        d.Code,
+			Description: d.Description,
+			Amount:      d.Amount,
+		})
+	}
+	return result
+}
+
+func describeCartIssues(issues []events.CartValidationIssue) string {
+	parts := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		parts = append(parts, fmt.Sprintf("%s: %s", issue.ProductID, issue.Kind))
+	}
+	return "cart has unresolved issues: " + strings.Join(parts, ", ")
+}
+
+func toPbOrderItems(items []domain.OrderItem) []*pb.OrderItem {
+	result := make([]*pb.OrderItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, &pb.OrderItem{
+			ProductId:   item.ProductID,
+			SkuId:       item.SKUID,
+			ProductName: item.ProductName,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			TotalPrice:  item.TotalPrice,
+		})
+	}
+	return result
+}
+
 func (h *OrderHandler) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.Order, error) {
 	// TODO: Implement get order logic
 	return nil, status.Error(codes.Unimplemented, "method GetOrder not implemented")
@@ -1,11 +1,17 @@
 package handler
 
 import (
+	"fmt"
+	"log"
+
 	"github.com/hsibAD/order-service/internal/config"
 	"github.com/hsibAD/order-service/internal/domain"
 	"github.com/hsibAD/order-service/internal/events"
+	"github.com/hsibAD/order-service/internal/infrastructure/stock"
 	pb "github.com/hsibAD/order-service/proto"
+	"github.com/nats-io/nats.go"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 func RegisterServices(server *grpc.Server, cfg *config.Config) error {
@@ -15,9 +21,35 @@ func RegisterServices(server *grpc.Server, cfg *config.Config) error {
 	// Отключаем CartSubscriber полностью для стабильного старта
 	var cartSub *events.CartSubscriber = nil
 
+	// Stock of the ordered SKUs is reserved in product-service
+	productConn, err := grpc.NewClient(cfg.ProductAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("failed to create product-service client: %v", err)
+	}
+
 	// Create and register order handler
-	orderHandler := NewOrderHandler(orderRepo, cartSub)
+	orderHandler := NewOrderHandler(orderRepo, cartSub, stock.NewProductStock(productConn, cfg.InternalToken))
 	pb.RegisterOrderServiceServer(server, orderHandler)
 
+	// Data exports and erasures need the orders
+	if orderRepo != nil {
+		startUserDataSubscriber(cfg.NatsURL, orderRepo)
+	}
+
 	return nil
 }
+
+// startUserDataSubscriber answers user-service's data export and erasure
+// requests. Without NATS they go unanswered and user-service keeps the
+// erasure pending.
+func startUserDataSubscriber(url string, orders domain.OrderRepository) {
+	nc, err := nats.Connect(url)
+	if err != nil {
+		log.Printf("[ERROR] Could not connect to NATS for user data requests: %v", err)
+		return
+	}
+	if err := events.NewUserDataSubscriber(nc, orders).Subscribe(); err != nil {
+		log.Printf("[ERROR] Could not subscribe to user data requests: %v", err)
+		nc.Close()
+	}
+}
@@ -0,0 +1,55 @@
+package stock
+
+import (
+	"context"
+	"time"
+
+	productpb "github.com/hsibAD/order-service/client-service/proto/productpb"
+	"github.com/hsibAD/order-service/internal/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// serviceTokenKey is the metadata key product-service reads the shared
+// secret of internal callers from.
+const serviceTokenKey = "x-service-token"
+
+// ProductStock reserves SKU stock through product-service's internal
+// ReserveStock and ReleaseStock RPCs.
+type ProductStock struct {
+	client productpb.ProductServiceClient
+	token  string
+}
+
+func NewProductStock(conn *grpc.ClientConn, internalToken string) *ProductStock {
+	return &ProductStock{
+		client: productpb.NewProductServiceClient(conn),
+		token:  internalToken,
+	}
+}
+
+func (s *ProductStock) ReserveStock(ctx context.Context, skuID string, quantity int32) error {
+	ctx, cancel := s.outgoing(ctx)
+	defer cancel()
+
+	_, err := s.client.ReserveStock(ctx, &productpb.ReserveStockRequest{SkuId: skuID, Quantity: quantity})
+	if status.Code(err) == codes.FailedPrecondition {
+		return domain.ErrInsufficientStock
+	}
+	return err
+}
+
+func (s *ProductStock) ReleaseStock(ctx context.Context, skuID string, quantity int32) error {
+	ctx, cancel := s.outgoing(ctx)
+	defer cancel()
+
+	_, err := s.client.ReleaseStock(ctx, &productpb.ReleaseStockRequest{SkuId: skuID, Quantity: quantity})
+	return err
+}
+
+func (s *ProductStock) outgoing(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx = metadata.AppendToOutgoingContext(ctx, serviceTokenKey, s.token)
+	return context.WithTimeout(ctx, 3*time.Second)
+}
@@ -24,6 +24,9 @@ type OrderModel struct {
 	ID              primitive.ObjectID `bson:"_id,omitempty"`
 	UserID          string            `bson:"user_id"`
 	Items           []OrderItemModel   `bson:"items"`
+	Subtotal        float64           `bson:"subtotal"`
+	Discount        float64           `bson:"discount"`
+	Discounts       []DiscountModel   `bson:"discounts,omitempty"`
 	TotalPrice      float64           `bson:"total_price"`
 	Currency        string            `bson:"currency"`
 	Status          string            `bson:"status"`
@@ -35,12 +38,21 @@ type OrderModel struct {
 
 type OrderItemModel struct {
 	ProductID   string  `bson:"product_id"`
+	SKUID       string  `bson:"sku_id"`
 	ProductName string  `bson:"product_name"`
 	Quantity    int32   `bson:"quantity"`
 	UnitPrice   float64 `bson:"unit_price"`
 	TotalPrice  float64 `bson:"total_price"`
 }
 
+type DiscountModel struct {
+	Source      string  `bson:"source"`
+	PromotionID string  `bson:"promotion_id,omitempty"`
+	Code        string  `bson:"code,omitempty"`
+	Description string  `bson:"description"`
+	Amount      float64 `bson:"amount"`
+}
+
 type AddressModel struct {
 	ID            string `bson:"id,omitempty"`
 	UserID        string `bson:"user_id"`
@@ -54,6 +66,8 @@ type AddressModel struct {
 func (r *OrderRepository) Create(ctx context.Context, order *domain.Order) error {
 	model := &OrderModel{
 		UserID:       order.UserID,
+		Subtotal:     order.Subtotal,
+		Discount:     order.Discount,
 		TotalPrice:   order.TotalPrice,
 		Currency:     order.Currency,
 		Status:       string(order.Status),
@@ -67,6 +81,7 @@ func (r *OrderRepository) Create(ctx context.Context, order *domain.Order) error
 	for i, item := range order.Items {
 		model.Items[i] = OrderItemModel{
 			ProductID:   item.ProductID,
+			SKUID:       item.SKUID,
 			ProductName: item.ProductName,
 			Quantity:    item.Quantity,
 			UnitPrice:   item.UnitPrice,
@@ -74,6 +89,18 @@ func (r *OrderRepository) Create(ctx context.Context, order *domain.Order) error
 		}
 	}
 
+	for _, d := range order.Discounts {
+		model.Discounts = append(model.Discounts, DiscountModel(d))
+	}
+
+	// Keep an ID chosen by the caller, e.g. to redeem promotions for the
+	// order before it is stored.
+	if order.ID != "" {
+		if objectID, err := primitive.ObjectIDFromHex(order.ID); err == nil {
+			model.ID = objectID
+		}
+	}
+
 	// Convert delivery address if present
 	if order.DeliveryAddress != nil {
 		model.DeliveryAddress = &AddressModel{
@@ -117,6 +144,7 @@ func (r *OrderRepository) Get(ctx context.Context, id string) (*domain.Order, er
 	for i, item := range model.Items {
 		items[i] = domain.OrderItem{
 			ProductID:   item.ProductID,
+			SKUID:       item.SKUID,
 			ProductName: item.ProductName,
 			Quantity:    item.Quantity,
 			UnitPrice:   item.UnitPrice,
@@ -138,10 +166,18 @@ func (r *OrderRepository) Get(ctx context.Context, id string) (*domain.Order, er
 		}
 	}
 
+	var discounts []domain.OrderDiscount
+	for _, d := range model.Discounts {
+		discounts = append(discounts, domain.OrderDiscount(d))
+	}
+
 	return &domain.Order{
 		ID:              model.ID.Hex(),
 		UserID:          model.UserID,
 		Items:           items,
+		Subtotal:        model.Subtotal,
+		Discount:        model.Discount,
+		Discounts:       discounts,
 		TotalPrice:      model.TotalPrice,
 		Currency:        model.Currency,
 		Status:          domain.OrderStatus(model.Status),
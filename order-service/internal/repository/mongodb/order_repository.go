@@ -21,6 +21,9 @@ type mongoOrder struct {
 	ID              primitive.ObjectID   `bson:"_id,omitempty"`
 	UserID          string              `bson:"user_id"`
 	Items           []mongoOrderItem    `bson:"items"`
+	Subtotal        float64             `bson:"subtotal"`
+	Discount        float64             `bson:"discount"`
+	Discounts       []mongoOrderDiscount `bson:"discounts,omitempty"`
 	TotalPrice      float64             `bson:"total_price"`
 	Currency        string              `bson:"currency"`
 	Status          string              `bson:"status"`
@@ -32,12 +35,21 @@ type mongoOrder struct {
 
 type mongoOrderItem struct {
 	ProductID   string  `bson:"product_id"`
+	SKUID       string  `bson:"sku_id"`
 	ProductName string  `bson:"product_name"`
 	Quantity    int32   `bson:"quantity"`
 	UnitPrice   float64 `bson:"unit_price"`
 	TotalPrice  float64 `bson:"total_price"`
 }
 
+type mongoOrderDiscount struct {
+	Source      string  `bson:"source"`
+	PromotionID string  `bson:"promotion_id,omitempty"`
+	Code        string  `bson:"code,omitempty"`
+	Description string  `bson:"description"`
+	Amount      float64 `bson:"amount"`
+}
+
 type mongoDeliveryAddress struct {
 	ID            primitive.ObjectID `bson:"_id,omitempty"`
 	UserID        string            `bson:"user_id"`
@@ -185,11 +197,44 @@ func (r *OrderRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (r *OrderRepository) AnonymizeUser(ctx context.Context, userID string) (int, error) {
+	// Orders without an address would fail the address update, so the
+	// addresses go first and the user ID, which selects the orders, last.
+	address := bson.M{
+		"$set": bson.M{
+			"delivery_address.user_id":        domain.ErasedUserID,
+			"delivery_address.full_name":      "",
+			"delivery_address.street_address": "",
+			"delivery_address.apartment":      "",
+			"delivery_address.postal_code":    "",
+			"delivery_address.phone":          "",
+		},
+	}
+	filter := bson.M{"user_id": userID, "delivery_address": bson.M{"$type": "object"}}
+	if _, err := r.collection.UpdateMany(ctx, filter, address); err != nil {
+		return 0, err
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"user_id":    domain.ErasedUserID,
+			"updated_at": time.Now(),
+		},
+	}
+	result, err := r.collection.UpdateMany(ctx, bson.M{"user_id": userID}, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.ModifiedCount), nil
+}
+
 func toMongoOrder(order *domain.Order) *mongoOrder {
 	items := make([]mongoOrderItem, len(order.Items))
 	for i, item := range order.Items {
 		items[i] = mongoOrderItem{
 			ProductID:   item.ProductID,
+			SKUID:       item.SKUID,
 			ProductName: item.ProductName,
 			Quantity:    item.Quantity,
 			UnitPrice:   item.UnitPrice,
@@ -221,6 +266,8 @@ func toMongoOrder(order *domain.Order) *mongoOrder {
 	mOrder := &mongoOrder{
 		UserID:          order.UserID,
 		Items:           items,
+		Subtotal:        order.Subtotal,
+		Discount:        order.Discount,
 		TotalPrice:      order.TotalPrice,
 		Currency:        order.Currency,
 		Status:          string(order.Status),
@@ -230,6 +277,10 @@ func toMongoOrder(order *domain.Order) *mongoOrder {
 		UpdatedAt:       order.UpdatedAt,
 	}
 
+	for _, d := range order.Discounts {
+		mOrder.Discounts = append(mOrder.Discounts, mongoOrderDiscount(d))
+	}
+
 	if order.ID != "" {
 		if objectID, err := primitive.ObjectIDFromHex(order.ID); err == nil {
 			mOrder.ID = objectID
@@ -244,6 +295,7 @@ func fromMongoOrder(mOrder *mongoOrder) *domain.Order {
 	for i, item := range mOrder.Items {
 		items[i] = domain.OrderItem{
 			ProductID:   item.ProductID,
+			SKUID:       item.SKUID,
 			ProductName: item.ProductName,
 			Quantity:    item.Quantity,
 			UnitPrice:   item.UnitPrice,
@@ -268,10 +320,18 @@ func fromMongoOrder(mOrder *mongoOrder) *domain.Order {
 		}
 	}
 
+	var discounts []domain.OrderDiscount
+	for _, d := range mOrder.Discounts {
+		discounts = append(discounts, domain.OrderDiscount(d))
+	}
+
 	return &domain.Order{
 		ID:              mOrder.ID.Hex(),
 		UserID:          mOrder.UserID,
 		Items:           items,
+		Subtotal:        mOrder.Subtotal,
+		Discount:        mOrder.Discount,
+		Discounts:       discounts,
 		TotalPrice:      mOrder.TotalPrice,
 		Currency:        mOrder.Currency,
 		Status:          domain.OrderStatus(mOrder.Status),
@@ -35,8 +35,13 @@ type Order struct {
 	DeliveryTime    *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=delivery_time,json=deliveryTime,proto3" json:"delivery_time,omitempty"`
 	CreatedAt       *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt       *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	Items           []*OrderItem           `protobuf:"bytes,11,rep,name=items,proto3" json:"items,omitempty"`
+	// Cart value before discounts; total_price is what is charged.
+	Subtotal      float64          `protobuf:"fixed64,12,opt,name=subtotal,proto3" json:"subtotal,omitempty"`
+	Discount      float64          `protobuf:"fixed64,13,opt,name=discount,proto3" json:"discount,omitempty"`
+	Discounts     []*OrderDiscount `protobuf:"bytes,14,rep,name=discounts,proto3" json:"discounts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Order) Reset() {
@@ -139,6 +144,112 @@ func (x *Order) GetUpdatedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *Order) GetItems() []*OrderItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *Order) GetSubtotal() float64 {
+	if x != nil {
+		return x.Subtotal
+	}
+	return 0
+}
+
+func (x *Order) GetDiscount() float64 {
+	if x != nil {
+		return x.Discount
+	}
+	return 0
+}
+
+func (x *Order) GetDiscounts() []*OrderDiscount {
+	if x != nil {
+		return x.Discounts
+	}
+	return nil
+}
+
+// One line of the discount breakdown: reduced sale prices ("sale") or a
+// promotion ("promotion").
+type OrderDiscount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	PromotionId   string                 `protobuf:"bytes,2,opt,name=promotion_id,json=promotionId,proto3" json:"promotion_id,omitempty"`
+	Code          string                 `protobuf:"bytes,3,opt,name=code,proto3" json:"code,omitempty"`
+	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Amount        float64                `protobuf:"fixed64,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderDiscount) Reset() {
+	*x = OrderDiscount{}
+	mi := &file_proto_order_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderDiscount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderDiscount) ProtoMessage() {}
+
+func (x *OrderDiscount) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_order_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderDiscount.ProtoReflect.Descriptor instead.
+func (*OrderDiscount) Descriptor() ([]byte, []int) {
+	return file_proto_order_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *OrderDiscount) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *OrderDiscount) GetPromotionId() string {
+	if x != nil {
+		return x.PromotionId
+	}
+	return ""
+}
+
+func (x *OrderDiscount) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *OrderDiscount) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *OrderDiscount) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
 type OrderItem struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
@@ -146,13 +257,14 @@ type OrderItem struct {
 	Quantity      int32                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
 	UnitPrice     float64                `protobuf:"fixed64,4,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
 	TotalPrice    float64                `protobuf:"fixed64,5,opt,name=total_price,json=totalPrice,proto3" json:"total_price,omitempty"`
+	SkuId         string                 `protobuf:"bytes,6,opt,name=sku_id,json=skuId,proto3" json:"sku_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *OrderItem) Reset() {
 	*x = OrderItem{}
-	mi := &file_proto_order_proto_msgTypes[1]
+	mi := &file_proto_order_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -164,7 +276,7 @@ func (x *OrderItem) String() string {
 func (*OrderItem) ProtoMessage() {}
 
 func (x *OrderItem) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_order_proto_msgTypes[1]
+	mi := &file_proto_order_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -177,7 +289,7 @@ func (x *OrderItem) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use OrderItem.ProtoReflect.Descriptor instead.
 func (*OrderItem) Descriptor() ([]byte, []int) {
-	return file_proto_order_proto_rawDescGZIP(), []int{1}
+	return file_proto_order_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *OrderItem) GetProductId() string {
@@ -215,6 +327,13 @@ func (x *OrderItem) GetTotalPrice() float64 {
 	return 0
 }
 
+func (x *OrderItem) GetSkuId() string {
+	if x != nil {
+		return x.SkuId
+	}
+	return ""
+}
+
 type DeliveryAddress struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -230,7 +349,7 @@ type DeliveryAddress struct {
 
 func (x *DeliveryAddress) Reset() {
 	*x = DeliveryAddress{}
-	mi := &file_proto_order_proto_msgTypes[2]
+	mi := &file_proto_order_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -242,7 +361,7 @@ func (x *DeliveryAddress) String() string {
 func (*DeliveryAddress) ProtoMessage() {}
 
 func (x *DeliveryAddress) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_order_proto_msgTypes[2]
+	mi := &file_proto_order_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -255,7 +374,7 @@ func (x *DeliveryAddress) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeliveryAddress.ProtoReflect.Descriptor instead.
 func (*DeliveryAddress) Descriptor() ([]byte, []int) {
-	return file_proto_order_proto_rawDescGZIP(), []int{2}
+	return file_proto_order_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *DeliveryAddress) GetId() string {
@@ -318,7 +437,7 @@ type CreateOrderRequest struct {
 
 func (x *CreateOrderRequest) Reset() {
 	*x = CreateOrderRequest{}
-	mi := &file_proto_order_proto_msgTypes[3]
+	mi := &file_proto_order_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -330,7 +449,7 @@ func (x *CreateOrderRequest) String() string {
 func (*CreateOrderRequest) ProtoMessage() {}
 
 func (x *CreateOrderRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_order_proto_msgTypes[3]
+	mi := &file_proto_order_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -343,7 +462,7 @@ func (x *CreateOrderRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateOrderRequest.ProtoReflect.Descriptor instead.
 func (*CreateOrderRequest) Descriptor() ([]byte, []int) {
-	return file_proto_order_proto_rawDescGZIP(), []int{3}
+	return file_proto_order_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *CreateOrderRequest) GetCartId() string {
@@ -376,7 +495,7 @@ type GetOrderRequest struct {
 
 func (x *GetOrderRequest) Reset() {
 	*x = GetOrderRequest{}
-	mi := &file_proto_order_proto_msgTypes[4]
+	mi := &file_proto_order_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -388,7 +507,7 @@ func (x *GetOrderRequest) String() string {
 func (*GetOrderRequest) ProtoMessage() {}
 
 func (x *GetOrderRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_order_proto_msgTypes[4]
+	mi := &file_proto_order_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -401,7 +520,7 @@ func (x *GetOrderRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetOrderRequest.ProtoReflect.Descriptor instead.
 func (*GetOrderRequest) Descriptor() ([]byte, []int) {
-	return file_proto_order_proto_rawDescGZIP(), []int{4}
+	return file_proto_order_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *GetOrderRequest) GetOrderId() string {
@@ -421,7 +540,7 @@ type UpdateOrderStatusRequest struct {
 
 func (x *UpdateOrderStatusRequest) Reset() {
 	*x = UpdateOrderStatusRequest{}
-	mi := &file_proto_order_proto_msgTypes[5]
+	mi := &file_proto_order_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -433,7 +552,7 @@ func (x *UpdateOrderStatusRequest) String() string {
 func (*UpdateOrderStatusRequest) ProtoMessage() {}
 
 func (x *UpdateOrderStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_order_proto_msgTypes[5]
+	mi := &file_proto_order_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -446,7 +565,7 @@ func (x *UpdateOrderStatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateOrderStatusRequest.ProtoReflect.Descriptor instead.
 func (*UpdateOrderStatusRequest) Descriptor() ([]byte, []int) {
-	return file_proto_order_proto_rawDescGZIP(), []int{5}
+	return file_proto_order_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *UpdateOrderStatusRequest) GetOrderId() string {
@@ -473,7 +592,7 @@ type DeleteAddressRequest struct {
 
 func (x *DeleteAddressRequest) Reset() {
 	*x = DeleteAddressRequest{}
-	mi := &file_proto_order_proto_msgTypes[6]
+	mi := &file_proto_order_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -485,7 +604,7 @@ func (x *DeleteAddressRequest) String() string {
 func (*DeleteAddressRequest) ProtoMessage() {}
 
 func (x *DeleteAddressRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_order_proto_msgTypes[6]
+	mi := &file_proto_order_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -498,7 +617,7 @@ func (x *DeleteAddressRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteAddressRequest.ProtoReflect.Descriptor instead.
 func (*DeleteAddressRequest) Descriptor() ([]byte, []int) {
-	return file_proto_order_proto_rawDescGZIP(), []int{6}
+	return file_proto_order_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *DeleteAddressRequest) GetAddressId() string {
@@ -524,7 +643,7 @@ type ListAddressesRequest struct {
 
 func (x *ListAddressesRequest) Reset() {
 	*x = ListAddressesRequest{}
-	mi := &file_proto_order_proto_msgTypes[7]
+	mi := &file_proto_order_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -536,7 +655,7 @@ func (x *ListAddressesRequest) String() string {
 func (*ListAddressesRequest) ProtoMessage() {}
 
 func (x *ListAddressesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_order_proto_msgTypes[7]
+	mi := &file_proto_order_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -549,7 +668,7 @@ func (x *ListAddressesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListAddressesRequest.ProtoReflect.Descriptor instead.
 func (*ListAddressesRequest) Descriptor() ([]byte, []int) {
-	return file_proto_order_proto_rawDescGZIP(), []int{7}
+	return file_proto_order_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ListAddressesRequest) GetUserId() string {
@@ -568,7 +687,7 @@ type ListAddressesResponse struct {
 
 func (x *ListAddressesResponse) Reset() {
 	*x = ListAddressesResponse{}
-	mi := &file_proto_order_proto_msgTypes[8]
+	mi := &file_proto_order_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -580,7 +699,7 @@ func (x *ListAddressesResponse) String() string {
 func (*ListAddressesResponse) ProtoMessage() {}
 
 func (x *ListAddressesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_order_proto_msgTypes[8]
+	mi := &file_proto_order_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -593,7 +712,7 @@ func (x *ListAddressesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListAddressesResponse.ProtoReflect.Descriptor instead.
 func (*ListAddressesResponse) Descriptor() ([]byte, []int) {
-	return file_proto_order_proto_rawDescGZIP(), []int{8}
+	return file_proto_order_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *ListAddressesResponse) GetAddresses() []*DeliveryAddress {
@@ -613,7 +732,7 @@ type SetDeliveryTimeRequest struct {
 
 func (x *SetDeliveryTimeRequest) Reset() {
 	*x = SetDeliveryTimeRequest{}
-	mi := &file_proto_order_proto_msgTypes[9]
+	mi := &file_proto_order_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -625,7 +744,7 @@ func (x *SetDeliveryTimeRequest) String() string {
 func (*SetDeliveryTimeRequest) ProtoMessage() {}
 
 func (x *SetDeliveryTimeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_order_proto_msgTypes[9]
+	mi := &file_proto_order_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -638,7 +757,7 @@ func (x *SetDeliveryTimeRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetDeliveryTimeRequest.ProtoReflect.Descriptor instead.
 func (*SetDeliveryTimeRequest) Descriptor() ([]byte, []int) {
-	return file_proto_order_proto_rawDescGZIP(), []int{9}
+	return file_proto_order_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *SetDeliveryTimeRequest) GetOrderId() string {
@@ -665,7 +784,7 @@ type DeliverySlotsRequest struct {
 
 func (x *DeliverySlotsRequest) Reset() {
 	*x = DeliverySlotsRequest{}
-	mi := &file_proto_order_proto_msgTypes[10]
+	mi := &file_proto_order_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -677,7 +796,7 @@ func (x *DeliverySlotsRequest) String() string {
 func (*DeliverySlotsRequest) ProtoMessage() {}
 
 func (x *DeliverySlotsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_order_proto_msgTypes[10]
+	mi := &file_proto_order_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -690,7 +809,7 @@ func (x *DeliverySlotsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeliverySlotsRequest.ProtoReflect.Descriptor instead.
 func (*DeliverySlotsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_order_proto_rawDescGZIP(), []int{10}
+	return file_proto_order_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *DeliverySlotsRequest) GetPostalCode() string {
@@ -718,7 +837,7 @@ type DeliverySlot struct {
 
 func (x *DeliverySlot) Reset() {
 	*x = DeliverySlot{}
-	mi := &file_proto_order_proto_msgTypes[11]
+	mi := &file_proto_order_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -730,7 +849,7 @@ func (x *DeliverySlot) String() string {
 func (*DeliverySlot) ProtoMessage() {}
 
 func (x *DeliverySlot) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_order_proto_msgTypes[11]
+	mi := &file_proto_order_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -743,7 +862,7 @@ func (x *DeliverySlot) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeliverySlot.ProtoReflect.Descriptor instead.
 func (*DeliverySlot) Descriptor() ([]byte, []int) {
-	return file_proto_order_proto_rawDescGZIP(), []int{11}
+	return file_proto_order_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *DeliverySlot) GetStartTime() *timestamppb.Timestamp {
@@ -778,7 +897,7 @@ type DeliverySlotsResponse struct {
 
 func (x *DeliverySlotsResponse) Reset() {
 	*x = DeliverySlotsResponse{}
-	mi := &file_proto_order_proto_msgTypes[12]
+	mi := &file_proto_order_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -790,7 +909,7 @@ func (x *DeliverySlotsResponse) String() string {
 func (*DeliverySlotsResponse) ProtoMessage() {}
 
 func (x *DeliverySlotsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_order_proto_msgTypes[12]
+	mi := &file_proto_order_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -803,7 +922,7 @@ func (x *DeliverySlotsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeliverySlotsResponse.ProtoReflect.Descriptor instead.
 func (*DeliverySlotsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_order_proto_rawDescGZIP(), []int{12}
+	return file_proto_order_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *DeliverySlotsResponse) GetPostalCode() string {
@@ -831,7 +950,7 @@ var File_proto_order_proto protoreflect.FileDescriptor
 
 const file_proto_order_proto_rawDesc = "" +
 	"\n" +
-	"\x11proto/order.proto\x12\x05order\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1bgoogle/protobuf/empty.proto\"\x98\x03\n" +
+	"\x11proto/order.proto\x12\x05order\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1bgoogle/protobuf/empty.proto\"\xac\x04\n" +
 	"\x05Order\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x17\n" +
@@ -846,7 +965,17 @@ const file_proto_order_proto_rawDesc = "" +
 	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
 	"updated_at\x18\n" +
-	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xa9\x01\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12&\n" +
+	"\x05items\x18\v \x03(\v2\x10.order.OrderItemR\x05items\x12\x1a\n" +
+	"\bsubtotal\x18\f \x01(\x01R\bsubtotal\x12\x1a\n" +
+	"\bdiscount\x18\r \x01(\x01R\bdiscount\x122\n" +
+	"\tdiscounts\x18\x0e \x03(\v2\x14.order.OrderDiscountR\tdiscounts\"\x98\x01\n" +
+	"\rOrderDiscount\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12!\n" +
+	"\fpromotion_id\x18\x02 \x01(\tR\vpromotionId\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\tR\x04code\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\x01R\x06amount\"\xc0\x01\n" +
 	"\tOrderItem\x12\x1d\n" +
 	"\n" +
 	"product_id\x18\x01 \x01(\tR\tproductId\x12!\n" +
@@ -855,7 +984,8 @@ const file_proto_order_proto_rawDesc = "" +
 	"\n" +
 	"unit_price\x18\x04 \x01(\x01R\tunitPrice\x12\x1f\n" +
 	"\vtotal_price\x18\x05 \x01(\x01R\n" +
-	"totalPrice\"\xb7\x01\n" +
+	"totalPrice\x12\x15\n" +
+	"\x06sku_id\x18\x06 \x01(\tR\x05skuId\"\xb7\x01\n" +
 	"\x0fDeliveryAddress\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x16\n" +
@@ -922,61 +1052,64 @@ func file_proto_order_proto_rawDescGZIP() []byte {
 	return file_proto_order_proto_rawDescData
 }
 
-var file_proto_order_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_proto_order_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_proto_order_proto_goTypes = []any{
 	(*Order)(nil),                    // 0: order.Order
-	(*OrderItem)(nil),                // 1: order.OrderItem
-	(*DeliveryAddress)(nil),          // 2: order.DeliveryAddress
-	(*CreateOrderRequest)(nil),       // 3: order.CreateOrderRequest
-	(*GetOrderRequest)(nil),          // 4: order.GetOrderRequest
-	(*UpdateOrderStatusRequest)(nil), // 5: order.UpdateOrderStatusRequest
-	(*DeleteAddressRequest)(nil),     // 6: order.DeleteAddressRequest
-	(*ListAddressesRequest)(nil),     // 7: order.ListAddressesRequest
-	(*ListAddressesResponse)(nil),    // 8: order.ListAddressesResponse
-	(*SetDeliveryTimeRequest)(nil),   // 9: order.SetDeliveryTimeRequest
-	(*DeliverySlotsRequest)(nil),     // 10: order.DeliverySlotsRequest
-	(*DeliverySlot)(nil),             // 11: order.DeliverySlot
-	(*DeliverySlotsResponse)(nil),    // 12: order.DeliverySlotsResponse
-	(*timestamppb.Timestamp)(nil),    // 13: google.protobuf.Timestamp
-	(*emptypb.Empty)(nil),            // 14: google.protobuf.Empty
+	(*OrderDiscount)(nil),            // 1: order.OrderDiscount
+	(*OrderItem)(nil),                // 2: order.OrderItem
+	(*DeliveryAddress)(nil),          // 3: order.DeliveryAddress
+	(*CreateOrderRequest)(nil),       // 4: order.CreateOrderRequest
+	(*GetOrderRequest)(nil),          // 5: order.GetOrderRequest
+	(*UpdateOrderStatusRequest)(nil), // 6: order.UpdateOrderStatusRequest
+	(*DeleteAddressRequest)(nil),     // 7: order.DeleteAddressRequest
+	(*ListAddressesRequest)(nil),     // 8: order.ListAddressesRequest
+	(*ListAddressesResponse)(nil),    // 9: order.ListAddressesResponse
+	(*SetDeliveryTimeRequest)(nil),   // 10: order.SetDeliveryTimeRequest
+	(*DeliverySlotsRequest)(nil),     // 11: order.DeliverySlotsRequest
+	(*DeliverySlot)(nil),             // 12: order.DeliverySlot
+	(*DeliverySlotsResponse)(nil),    // 13: order.DeliverySlotsResponse
+	(*timestamppb.Timestamp)(nil),    // 14: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),            // 15: google.protobuf.Empty
 }
 var file_proto_order_proto_depIdxs = []int32{
-	2,  // 0: order.Order.delivery_address:type_name -> order.DeliveryAddress
-	13, // 1: order.Order.delivery_time:type_name -> google.protobuf.Timestamp
-	13, // 2: order.Order.created_at:type_name -> google.protobuf.Timestamp
-	13, // 3: order.Order.updated_at:type_name -> google.protobuf.Timestamp
-	2,  // 4: order.CreateOrderRequest.delivery_address:type_name -> order.DeliveryAddress
-	13, // 5: order.CreateOrderRequest.delivery_time:type_name -> google.protobuf.Timestamp
-	2,  // 6: order.ListAddressesResponse.addresses:type_name -> order.DeliveryAddress
-	13, // 7: order.SetDeliveryTimeRequest.delivery_time:type_name -> google.protobuf.Timestamp
-	13, // 8: order.DeliverySlotsRequest.date:type_name -> google.protobuf.Timestamp
-	13, // 9: order.DeliverySlot.start_time:type_name -> google.protobuf.Timestamp
-	13, // 10: order.DeliverySlot.end_time:type_name -> google.protobuf.Timestamp
-	13, // 11: order.DeliverySlotsResponse.date:type_name -> google.protobuf.Timestamp
-	11, // 12: order.DeliverySlotsResponse.slots:type_name -> order.DeliverySlot
-	3,  // 13: order.OrderService.CreateOrder:input_type -> order.CreateOrderRequest
-	4,  // 14: order.OrderService.GetOrder:input_type -> order.GetOrderRequest
-	5,  // 15: order.OrderService.UpdateOrderStatus:input_type -> order.UpdateOrderStatusRequest
-	2,  // 16: order.OrderService.AddDeliveryAddress:input_type -> order.DeliveryAddress
-	2,  // 17: order.OrderService.UpdateDeliveryAddress:input_type -> order.DeliveryAddress
-	6,  // 18: order.OrderService.DeleteDeliveryAddress:input_type -> order.DeleteAddressRequest
-	7,  // 19: order.OrderService.ListDeliveryAddresses:input_type -> order.ListAddressesRequest
-	9,  // 20: order.OrderService.SetDeliveryTime:input_type -> order.SetDeliveryTimeRequest
-	10, // 21: order.OrderService.GetAvailableDeliverySlots:input_type -> order.DeliverySlotsRequest
-	0,  // 22: order.OrderService.CreateOrder:output_type -> order.Order
-	0,  // 23: order.OrderService.GetOrder:output_type -> order.Order
-	0,  // 24: order.OrderService.UpdateOrderStatus:output_type -> order.Order
-	2,  // 25: order.OrderService.AddDeliveryAddress:output_type -> order.DeliveryAddress
-	2,  // 26: order.OrderService.UpdateDeliveryAddress:output_type -> order.DeliveryAddress
-	14, // 27: order.OrderService.DeleteDeliveryAddress:output_type -> google.protobuf.Empty
-	8,  // 28: order.OrderService.ListDeliveryAddresses:output_type -> order.ListAddressesResponse
-	0,  // 29: order.OrderService.SetDeliveryTime:output_type -> order.Order
-	12, // 30: order.OrderService.GetAvailableDeliverySlots:output_type -> order.DeliverySlotsResponse
-	22, // [22:31] is the sub-list for method output_type
-	13, // [13:22] is the sub-list for method input_type
-	13, // [13:13] is the sub-list for extension type_name
-	13, // [13:13] is the sub-list for extension extendee
-	0,  // [0:13] is the sub-list for field type_name
+	3,  // 0: order.Order.delivery_address:type_name -> order.DeliveryAddress
+	14, // 1: order.Order.delivery_time:type_name -> google.protobuf.Timestamp
+	14, // 2: order.Order.created_at:type_name -> google.protobuf.Timestamp
+	14, // 3: order.Order.updated_at:type_name -> google.protobuf.Timestamp
+	2,  // 4: order.Order.items:type_name -> order.OrderItem
+	1,  // 5: order.Order.discounts:type_name -> order.OrderDiscount
+	3,  // 6: order.CreateOrderRequest.delivery_address:type_name -> order.DeliveryAddress
+	14, // 7: order.CreateOrderRequest.delivery_time:type_name -> google.protobuf.Timestamp
+	3,  // 8: order.ListAddressesResponse.addresses:type_name -> order.DeliveryAddress
+	14, // 9: order.SetDeliveryTimeRequest.delivery_time:type_name -> google.protobuf.Timestamp
+	14, // 10: order.DeliverySlotsRequest.date:type_name -> google.protobuf.Timestamp
+	14, // 11: order.DeliverySlot.start_time:type_name -> google.protobuf.Timestamp
+	14, // 12: order.DeliverySlot.end_time:type_name -> google.protobuf.Timestamp
+	14, // 13: order.DeliverySlotsResponse.date:type_name -> google.protobuf.Timestamp
+	12, // 14: order.DeliverySlotsResponse.slots:type_name -> order.DeliverySlot
+	4,  // 15: order.OrderService.CreateOrder:input_type -> order.CreateOrderRequest
+	5,  // 16: order.OrderService.GetOrder:input_type -> order.GetOrderRequest
+	6,  // 17: order.OrderService.UpdateOrderStatus:input_type -> order.UpdateOrderStatusRequest
+	3,  // 18: order.OrderService.AddDeliveryAddress:input_type -> order.DeliveryAddress
+	3,  // 19: order.OrderService.UpdateDeliveryAddress:input_type -> order.DeliveryAddress
+	7,  // 20: order.OrderService.DeleteDeliveryAddress:input_type -> order.DeleteAddressRequest
+	8,  // 21: order.OrderService.ListDeliveryAddresses:input_type -> order.ListAddressesRequest
+	10, // 22: order.OrderService.SetDeliveryTime:input_type -> order.SetDeliveryTimeRequest
+	11, // 23: order.OrderService.GetAvailableDeliverySlots:input_type -> order.DeliverySlotsRequest
+	0,  // 24: order.OrderService.CreateOrder:output_type -> order.Order
+	0,  // 25: order.OrderService.GetOrder:output_type -> order.Order
+	0,  // 26: order.OrderService.UpdateOrderStatus:output_type -> order.Order
+	3,  // 27: order.OrderService.AddDeliveryAddress:output_type -> order.DeliveryAddress
+	3,  // 28: order.OrderService.UpdateDeliveryAddress:output_type -> order.DeliveryAddress
+	15, // 29: order.OrderService.DeleteDeliveryAddress:output_type -> google.protobuf.Empty
+	9,  // 30: order.OrderService.ListDeliveryAddresses:output_type -> order.ListAddressesResponse
+	0,  // 31: order.OrderService.SetDeliveryTime:output_type -> order.Order
+	13, // 32: order.OrderService.GetAvailableDeliverySlots:output_type -> order.DeliverySlotsResponse
+	24, // [24:33] is the sub-list for method output_type
+	15, // [15:24] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_proto_order_proto_init() }
@@ -990,7 +1123,7 @@ func file_proto_order_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_order_proto_rawDesc), len(file_proto_order_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   13,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
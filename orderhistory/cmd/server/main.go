@@ -3,13 +3,16 @@ package main
 import (
 	"log"
 	"net"
+	"os"
 
 	"meaningfullname/Yurt_Mart/common/config"
 	"meaningfullname/Yurt_Mart/common/database"
+	"meaningfullname/Yurt_Mart/orderhistory/internal/events"
 	"meaningfullname/Yurt_Mart/orderhistory/internal/repository"
 	"meaningfullname/Yurt_Mart/orderhistory/internal/service"
 	"meaningfullname/Yurt_Mart/orderhistory/proto"
 
+	"github.com/nats-io/nats.go"
 	"google.golang.org/grpc"
 )
 
@@ -36,6 +39,22 @@ func main() {
 	}
 	orderService := service.NewOrderHistoryService(repo)
 
+	natsURL := os.Getenv("NATS_URL")
+	if natsURL == "" {
+		natsURL = nats.DefaultURL
+	}
+	nc, err := nats.Connect(natsURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	// The order history is exported and erased with the rest of a user's
+	// data.
+	if err := events.NewUserDataSubscriber(nc, repo).Subscribe(); err != nil {
+		log.Fatalf("Failed to subscribe to user data requests: %v", err)
+	}
+
 	lis, err := net.Listen("tcp", ":8086")
 	if err != nil {
 		log.Fatalf("Failed to listen: %v", err)
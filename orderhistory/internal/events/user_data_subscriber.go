@@ -0,0 +1,36 @@
+package events
+
+import (
+	"context"
+
+	"meaningfullname/Yurt_Mart/common/userdata"
+	"meaningfullname/Yurt_Mart/orderhistory/internal/repository"
+
+	"github.com/nats-io/nats.go"
+)
+
+// ServiceName is how order-history-service names itself to user-service.
+const ServiceName = "order-history-service"
+
+// NewUserDataSubscriber exports and erases the orders of a user when
+// user-service asks for it.
+func NewUserDataSubscriber(nc *nats.Conn, repo repository.OrderRepository) *userdata.Subscriber {
+	return userdata.NewSubscriber(nc, ServiceName, orderData{repo: repo})
+}
+
+// orderData is the orders of users, as exported and erased for user-service.
+type orderData struct {
+	repo repository.OrderRepository
+}
+
+func (d orderData) Export(ctx context.Context, userID string) (interface{}, error) {
+	orders, err := d.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"orders": orders}, nil
+}
+
+func (d orderData) Erase(ctx context.Context, userID string) (int64, error) {
+	return d.repo.DeleteByUserID(ctx, userID)
+}
@@ -19,6 +19,9 @@ type OrderRepository interface {
 	GetByUserID(ctx context.Context, userID string) ([]*model.Order, error)
 	GetRecentByUserID(ctx context.Context, userID string, limit int) ([]*model.Order, error)
 	Delete(ctx context.Context, id string) error
+	// DeleteByUserID removes the user's order history and returns how many
+	// orders it held.
+	DeleteByUserID(ctx context.Context, userID string) (int64, error)
 }
 
 type orderRepository struct {
@@ -106,3 +109,13 @@ func (r *orderRepository) Delete(ctx context.Context, id string) error {
 	_, err := r.collection.DeleteOne(ctx, filter)
 	return err
 }
+
+func (r *orderRepository) DeleteByUserID(ctx context.Context, userID string) (int64, error) {
+	filter := bson.M{"user_id": userID}
+
+	result, err := r.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -1,11 +1,20 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net"
+	"os"
 
 	grpcServer "google.golang.org/grpc"
+	"product-service/internal/config"
 	productGrpc "product-service/internal/grpc"
+	"product-service/internal/handler"
+	"product-service/internal/identity"
+	"product-service/internal/media"
+	"product-service/internal/repository"
+	"product-service/internal/usecase"
 )
 
 func main() {
@@ -14,8 +23,19 @@ func main() {
 		log.Fatalf("Failed to listen: %v", err)
 	}
 
+	cfg := config.Load()
+	repo := repository.NewMongoProductRepository()
+	use := usecase.NewProductUsecase(repo, media.NewLocalStore(cfg.MediaDir))
+
+	// Every replica runs the scheduler loop; the lease lets one of them
+	// apply prices at a time.
+	hostname, _ := os.Hostname()
+	holder := fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	go use.RunPriceScheduler(context.Background(), repository.NewMongoLease(), holder, cfg.PriceSchedulerInterval)
+
 	s := grpcServer.NewServer()
-	productGrpc.RegisterProductServiceServer(s)
+	callers := identity.NewVerifier(identity.NewJWKS(cfg.JWKSURL, cfg.JWKSCacheTTL).Keyfunc)
+	productGrpc.RegisterProductServiceServer(s, handler.NewProductHandler(use, callers, cfg))
 
 	log.Println("Product service is running on port 50051")
 	if err := s.Serve(lis); err != nil {
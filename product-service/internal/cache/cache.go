@@ -37,6 +37,24 @@ func (c *ProductCache) AddProduct(p *domain.Product) {
 	c.productByID[p.ID] = p
 }
 
+// UpdateProduct replaces a cached product in place, adding it if it is not cached yet.
+func (c *ProductCache) UpdateProduct(p *domain.Product) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, found := c.productByID[p.ID]; !found {
+		c.products = append(c.products, p)
+		c.productByID[p.ID] = p
+		return
+	}
+	for i, existing := range c.products {
+		if existing.ID == p.ID {
+			c.products[i] = p
+			break
+		}
+	}
+	c.productByID[p.ID] = p
+}
+
 func (c *ProductCache) GetAll() []*domain.Product {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
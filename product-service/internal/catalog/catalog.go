@@ -0,0 +1,304 @@
+package catalog
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"product-service/internal/domain"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Format int
+
+const (
+	CSV Format = iota
+	JSONL
+)
+
+// CSV columns in the order they are written on export. On import the header
+// row decides the order, and only external_sku and name are mandatory.
+var csvColumns = []string{"external_sku", "name", "description", "category", "price", "quantity", "user_id"}
+
+// Row is a single parsed line of an import file. Err is set when the line
+// could not be turned into a product; the import carries on with the next one.
+// Fields names the product fields the line sets: the CSV columns of the
+// header, or the keys of the JSON object, where "skus" is one of them.
+type Row struct {
+	Line    int
+	Product *domain.Product
+	Fields  []string
+	Err     error
+}
+
+// productFields are the fields an import can set on a product.
+var productFields = map[string]bool{
+	"name":        true,
+	"description": true,
+	"category":    true,
+	"price":       true,
+	"quantity":    true,
+	"user_id":     true,
+	"skus":        true,
+}
+
+type jsonSKU struct {
+	ID       string  `json:"id,omitempty"`
+	Size     string  `json:"size,omitempty"`
+	Weight   float64 `json:"weight,omitempty"`
+	Unit     string  `json:"unit,omitempty"`
+	Price    float64 `json:"price"`
+	Quantity int32   `json:"quantity"`
+	Barcode  string  `json:"barcode,omitempty"`
+}
+
+type jsonProduct struct {
+	ExternalSKU string    `json:"external_sku"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Category    string    `json:"category,omitempty"`
+	Price       float64   `json:"price"`
+	Quantity    int32     `json:"quantity"`
+	UserID      string    `json:"user_id,omitempty"`
+	SKUs        []jsonSKU `json:"skus,omitempty"`
+}
+
+// Read parses r in the given format and calls fn for every data row. Only
+// errors that make the rest of the input unreadable are returned.
+func Read(r io.Reader, format Format, fn func(Row) error) error {
+	switch format {
+	case CSV:
+		return readCSV(r, fn)
+	case JSONL:
+		return readJSONL(r, fn)
+	default:
+		return fmt.Errorf("unsupported catalog format %d", format)
+	}
+}
+
+func readCSV(r io.Reader, fn func(Row) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read csv header: %w", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"external_sku", "name"} {
+		if _, ok := index[required]; !ok {
+			return fmt.Errorf("csv header is missing the %q column", required)
+		}
+	}
+	var fields []string
+	for _, name := range csvColumns {
+		if _, ok := index[name]; ok && productFields[name] {
+			fields = append(fields, name)
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return err
+			}
+			if err := fn(Row{Line: parseErr.Line, Err: parseErr.Err}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(record) != len(header) {
+			if err := fn(Row{Line: line, Err: fmt.Errorf("expected %d fields, got %d", len(header), len(record))}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		product, err := productFromRecord(record, index)
+		if err := fn(Row{Line: line, Product: product, Fields: fields, Err: err}); err != nil {
+			return err
+		}
+	}
+}
+
+func productFromRecord(record []string, index map[string]int) (*domain.Product, error) {
+	field := func(name string) string {
+		if i, ok := index[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	product := &domain.Product{
+		ExternalSKU: field("external_sku"),
+		Name:        field("name"),
+		Description: field("description"),
+		Category:    field("category"),
+		UserID:      field("user_id"),
+	}
+
+	if v := field("price"); v != "" {
+		price, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return product, fmt.Errorf("invalid price %q", v)
+		}
+		product.Price = price
+	}
+	if v := field("quantity"); v != "" {
+		quantity, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return product, fmt.Errorf("invalid quantity %q", v)
+		}
+		product.Quantity = int32(quantity)
+	}
+
+	return product, nil
+}
+
+func readJSONL(r io.Reader, fn func(Row) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var jp jsonProduct
+		var keys map[string]json.RawMessage
+		err := json.Unmarshal([]byte(text), &jp)
+		if err == nil {
+			err = json.Unmarshal([]byte(text), &keys)
+		}
+		if err != nil {
+			if err := fn(Row{Line: line, Err: fmt.Errorf("invalid json: %w", err)}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var fields []string
+		for key := range keys {
+			if productFields[key] {
+				fields = append(fields, key)
+			}
+		}
+		sort.Strings(fields)
+		if err := fn(Row{Line: line, Product: jp.toDomain(), Fields: fields}); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (jp *jsonProduct) toDomain() *domain.Product {
+	skus := make([]domain.SKU, 0, len(jp.SKUs))
+	for _, s := range jp.SKUs {
+		skus = append(skus, domain.SKU{
+			ID:       s.ID,
+			Size:     s.Size,
+			Weight:   s.Weight,
+			Unit:     s.Unit,
+			Price:    s.Price,
+			Quantity: s.Quantity,
+			Barcode:  s.Barcode,
+		})
+	}
+
+	return &domain.Product{
+		ExternalSKU: jp.ExternalSKU,
+		Name:        jp.Name,
+		Description: jp.Description,
+		Category:    jp.Category,
+		Price:       jp.Price,
+		Quantity:    jp.Quantity,
+		UserID:      jp.UserID,
+		SKUs:        skus,
+	}
+}
+
+func fromDomain(p *domain.Product) jsonProduct {
+	skus := make([]jsonSKU, 0, len(p.SKUs))
+	for _, s := range p.SKUs {
+		skus = append(skus, jsonSKU{
+			ID:       s.ID,
+			Size:     s.Size,
+			Weight:   s.Weight,
+			Unit:     s.Unit,
+			Price:    s.Price,
+			Quantity: s.Quantity,
+			Barcode:  s.Barcode,
+		})
+	}
+
+	return jsonProduct{
+		ExternalSKU: p.ExternalSKU,
+		Name:        p.Name,
+		Description: p.Description,
+		Category:    p.Category,
+		Price:       p.Price,
+		Quantity:    p.Quantity,
+		UserID:      p.UserID,
+		SKUs:        skus,
+	}
+}
+
+// Write serialises products to w. CSV output has a header row and no SKU
+// variants; use JSONL for a lossless export.
+func Write(w io.Writer, format Format, products []*domain.Product) error {
+	switch format {
+	case CSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(csvColumns); err != nil {
+			return err
+		}
+		for _, p := range products {
+			record := []string{
+				p.ExternalSKU,
+				p.Name,
+				p.Description,
+				p.Category,
+				strconv.FormatFloat(p.Price, 'f', -1, 64),
+				strconv.FormatInt(int64(p.Quantity), 10),
+				p.UserID,
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	case JSONL:
+		encoder := json.NewEncoder(w)
+		for _, p := range products {
+			if err := encoder.Encode(fromDomain(p)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported catalog format %d", format)
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+type Config struct {
+	// InternalToken is the shared secret other services send in the
+	// x-service-token metadata to call internal RPCs such as ReserveStock.
+	// Those RPCs are refused while it is empty.
+	InternalToken string
+	// MediaDir is where the local blob store keeps uploaded images.
+	MediaDir string
+	// PriceSchedulerInterval is how often scheduled prices are activated
+	// and expired.
+	PriceSchedulerInterval time.Duration
+	// JWKSURL is where user-service publishes the keys access tokens are
+	// signed with; callers are read from verified tokens only.
+	JWKSURL      string
+	JWKSCacheTTL time.Duration
+}
+
+func Load() *Config {
+	return &Config{
+		InternalToken: getEnv("INTERNAL_SERVICE_TOKEN", ""),
+		MediaDir:      getEnv("MEDIA_DIR", "./media"),
+
+		PriceSchedulerInterval: getEnvAsDuration("PRICE_SCHEDULER_INTERVAL", time.Minute),
+		JWKSURL:                getEnv("JWKS_URL", "http://localhost:8081/.well-known/jwks.json"),
+		JWKSCacheTTL:           getEnvAsDuration("JWKS_CACHE_TTL", 10*time.Minute),
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+type PriceScheduleKind string
+
+const (
+	// PriceSale temporarily lowers the price between StartsAt and EndsAt.
+	PriceSale PriceScheduleKind = "sale"
+	// PriceRegular permanently replaces the list price from StartsAt on.
+	PriceRegular PriceScheduleKind = "regular"
+)
+
+var (
+	ErrPriceScheduleNotFound = errors.New("price schedule not found")
+	ErrPricingChanged        = errors.New("the product's pricing changed in the meantime")
+)
+
+type PriceSchedule struct {
+	ID       string            `bson:"id"`
+	Kind     PriceScheduleKind `bson:"kind"`
+	Price    float64           `bson:"price"`
+	StartsAt time.Time         `bson:"starts_at"`
+	EndsAt   time.Time         `bson:"ends_at,omitempty"`
+}
+
+func (s *PriceSchedule) Validate() error {
+	if s.Price <= 0 {
+		return errors.New("scheduled price must be positive")
+	}
+	switch s.Kind {
+	case PriceSale:
+		if s.EndsAt.IsZero() || !s.EndsAt.After(s.StartsAt) {
+			return errors.New("a sale needs an end time after its start time")
+		}
+	case PriceRegular:
+		if !s.EndsAt.IsZero() {
+			return errors.New("a regular price change has no end time")
+		}
+	default:
+		return errors.New("unknown price schedule kind")
+	}
+	return nil
+}
+
+func (s *PriceSchedule) activeAt(at time.Time) bool {
+	if at.Before(s.StartsAt) {
+		return false
+	}
+	return s.EndsAt.IsZero() || at.Before(s.EndsAt)
+}
+
+// ResolvePrice returns the list price and the price a customer pays at the
+// given instant. The list price is the latest regular price change that has
+// started; the effective price is the lowest active sale below it, if any.
+func (p *Product) ResolvePrice(at time.Time) (list, effective float64) {
+	list = p.Price
+	var listFrom time.Time
+	for _, s := range p.PriceSchedules {
+		if s.Kind == PriceRegular && s.activeAt(at) && !s.StartsAt.Before(listFrom) {
+			list, listFrom = s.Price, s.StartsAt
+		}
+	}
+
+	effective = list
+	for _, s := range p.PriceSchedules {
+		if s.Kind == PriceSale && s.activeAt(at) && s.Price < effective {
+			effective = s.Price
+		}
+	}
+	return list, effective
+}
+
+// ApplyPriceSchedules folds started regular price changes into Price and
+// drops expired sales, as of now. It reports whether anything changed.
+func (p *Product) ApplyPriceSchedules(now time.Time) bool {
+	list, _ := p.ResolvePrice(now)
+	changed := list != p.Price
+	p.Price = list
+
+	pending := make([]PriceSchedule, 0, len(p.PriceSchedules))
+	for _, s := range p.PriceSchedules {
+		switch {
+		case s.Kind == PriceRegular && !now.Before(s.StartsAt):
+			changed = true
+		case s.Kind == PriceSale && !s.EndsAt.After(now):
+			changed = true
+		default:
+			pending = append(pending, s)
+		}
+	}
+	p.PriceSchedules = pending
+	return changed
+}
+
+// PriceChangedEvent is published on product.price_changed whenever the price
+// a customer pays for a product changes.
+type PriceChangedEvent struct {
+	ProductID string    `json:"product_id"`
+	OldPrice  float64   `json:"old_price"`
+	NewPrice  float64   `json:"new_price"`
+	ListPrice float64   `json:"list_price"`
+	ChangedAt time.Time `json:"changed_at"`
+}
@@ -1,5 +1,18 @@
 package domain
 
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrProductNotFound   = errors.New("product not found")
+	ErrSKUNotFound       = errors.New("sku not found")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrImageNotFound     = errors.New("image not found")
+	ErrImagesChanged     = errors.New("the product's images changed in the meantime")
+)
+
 type Product struct {
 	ID          string  `bson:"_id,omitempty"`
 	Name        string  `bson:"name"`
@@ -8,4 +21,183 @@ type Product struct {
 	Price       float64 `bson:"price"`
 	Quantity    int32   `bson:"quantity"`
 	UserID      string  `bson:"user_id"`
+	SKUs        []SKU   `bson:"skus"`
+	ExternalSKU string  `bson:"external_sku,omitempty"`
+	Images      []Image `bson:"images"`
+
+	// CurrentPrice is the effective price last applied by the price
+	// scheduler; it is what product.price_changed events compare against.
+	CurrentPrice   float64         `bson:"current_price"`
+	PriceSchedules []PriceSchedule `bson:"price_schedules"`
+	// PricingVersion goes up with every write of the price, current price
+	// or schedules, so the scheduler only stores prices computed from the
+	// latest ones.
+	PricingVersion int64 `bson:"pricing_version"`
+}
+
+// SKU is a sellable variant of a product, e.g. "milk 1L" vs "milk 2L".
+type SKU struct {
+	ID       string  `bson:"id"`
+	Size     string  `bson:"size"`
+	Weight   float64 `bson:"weight"`
+	Unit     string  `bson:"unit"`
+	Price    float64 `bson:"price"`
+	Quantity int32   `bson:"quantity"`
+	Barcode  string  `bson:"barcode"`
+}
+
+// Image points at an uploaded picture in the blob store. Thumbnails maps a
+// size name ("small", "medium", ...) to the blob key of that rendition.
+type Image struct {
+	ID          string            `bson:"id"`
+	Key         string            `bson:"key"`
+	ContentType string            `bson:"content_type"`
+	Width       int32             `bson:"width"`
+	Height      int32             `bson:"height"`
+	Thumbnails  map[string]string `bson:"thumbnails"`
+}
+
+func (p *Product) FindSKU(id string) (*SKU, bool) {
+	for i := range p.SKUs {
+		if p.SKUs[i].ID == id {
+			return &p.SKUs[i], true
+		}
+	}
+	return nil, false
+}
+
+func (p *Product) FindImage(id string) (*Image, bool) {
+	for i := range p.Images {
+		if p.Images[i].ID == id {
+			return &p.Images[i], true
+		}
+	}
+	return nil, false
+}
+
+// Validate checks the fields a product must have before it is stored.
+func (p *Product) Validate() error {
+	if p.Name == "" {
+		return errors.New("name is required")
+	}
+	if p.Price < 0 {
+		return fmt.Errorf("price must not be negative, got %v", p.Price)
+	}
+	if p.Quantity < 0 {
+		return fmt.Errorf("quantity must not be negative, got %d", p.Quantity)
+	}
+	for _, s := range p.SKUs {
+		if s.Price < 0 || s.Quantity < 0 {
+			return fmt.Errorf("sku %q has a negative price or quantity", s.ID)
+		}
+	}
+	return nil
+}
+
+// ApplyImport copies the fields an import row sets from imported onto p.
+// The owner is not one of them; the import decides it. SKUs are merged
+// with MergeSKUs rather than replaced.
+func (p *Product) ApplyImport(imported *Product, fields []string) {
+	for _, field := range fields {
+		switch field {
+		case "name":
+			p.Name = imported.Name
+		case "description":
+			p.Description = imported.Description
+		case "category":
+			p.Category = imported.Category
+		case "price":
+			p.Price = imported.Price
+		case "quantity":
+			p.Quantity = imported.Quantity
+		case "skus":
+			p.MergeSKUs(imported.SKUs)
+		}
+	}
+}
+
+// MergeSKUs updates the SKUs of p from imported ones, matched by ID, then
+// barcode, then size, so a re-imported SKU keeps the ID carts and orders
+// refer to. Unmatched imported SKUs are added; SKUs the import does not
+// mention are kept.
+func (p *Product) MergeSKUs(imported []SKU) {
+	merged := append([]SKU(nil), p.SKUs...)
+	for _, sku := range imported {
+		i := matchSKU(merged, sku)
+		if i < 0 {
+			merged = append(merged, sku)
+			continue
+		}
+		sku.ID = merged[i].ID
+		merged[i] = sku
+	}
+	p.SKUs = merged
+}
+
+func matchSKU(skus []SKU, sku SKU) int {
+	for _, same := range []func(existing SKU) bool{
+		func(existing SKU) bool { return sku.ID != "" && existing.ID == sku.ID },
+		func(existing SKU) bool { return sku.Barcode != "" && existing.Barcode == sku.Barcode },
+		func(existing SKU) bool { return sku.Size != "" && existing.Size == sku.Size },
+	} {
+		for i := range skus {
+			if same(skus[i]) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// ImportReport summarises a bulk catalog import.
+type ImportReport struct {
+	Total   int32
+	Created int32
+	Updated int32
+	Failed  int32
+	DryRun  bool
+	Errors  []ImportRowError
+}
+
+type ImportRowError struct {
+	Row         int
+	ExternalSKU string
+	Message     string
+}
+
+var ErrForbidden = errors.New("only the owning seller or an admin may modify this product")
+
+const (
+	RoleAdmin  = "admin"
+	RoleSeller = "seller"
+)
+
+// Caller is the authenticated user behind a request.
+type Caller struct {
+	UserID string
+	Roles  []string
+}
+
+func (c Caller) IsAdmin() bool {
+	return c.hasRole(RoleAdmin)
+}
+
+// CanSell reports whether c may create or import products, which only
+// sellers and admins may.
+func (c Caller) CanSell() bool {
+	return c.hasRole(RoleSeller) || c.hasRole(RoleAdmin)
+}
+
+func (c Caller) hasRole(want string) bool {
+	for _, role := range c.Roles {
+		if role == want {
+			return true
+		}
+	}
+	return false
+}
+
+// CanBeModifiedBy reports whether c owns the product or is an admin.
+func (p *Product) CanBeModifiedBy(c Caller) bool {
+	return c.IsAdmin() || (c.UserID != "" && c.UserID == p.UserID)
 }
@@ -0,0 +1,41 @@
+package domain
+
+// RestockedEvent is published on product.restocked when a product or one of
+// its SKUs goes from out of stock back into stock. SKUID is empty for the
+// product-level quantity.
+type RestockedEvent struct {
+	ProductID string `json:"product_id"`
+	SKUID     string `json:"sku_id,omitempty"`
+	Quantity  int32  `json:"quantity"`
+}
+
+// Restocks lists what was out of stock in before and is in stock in after.
+// SKUs are matched by ID, or by size for SKUs that were given a new ID.
+func Restocks(before, after *Product) []RestockedEvent {
+	var events []RestockedEvent
+	if before.Quantity <= 0 && after.Quantity > 0 {
+		events = append(events, RestockedEvent{ProductID: after.ID, Quantity: after.Quantity})
+	}
+	for _, sku := range after.SKUs {
+		if sku.Quantity <= 0 {
+			continue
+		}
+		old, found := before.FindSKU(sku.ID)
+		if !found {
+			old, found = before.findSKUBySize(sku.Size)
+		}
+		if found && old.Quantity <= 0 {
+			events = append(events, RestockedEvent{ProductID: after.ID, SKUID: sku.ID, Quantity: sku.Quantity})
+		}
+	}
+	return events
+}
+
+func (p *Product) findSKUBySize(size string) (*SKU, bool) {
+	for i := range p.SKUs {
+		if p.SKUs[i].Size == size {
+			return &p.SKUs[i], true
+		}
+	}
+	return nil, false
+}
@@ -6,6 +6,6 @@ import (
 	"product-service/internal/handler"
 )
 
-func RegisterProductServiceServer(s *grpc.Server) {
-	pb.RegisterProductServiceServer(s, handler.NewProductHandler())
+func RegisterProductServiceServer(s *grpc.Server, h *handler.ProductHandler) {
+	pb.RegisterProductServiceServer(s, h)
 }
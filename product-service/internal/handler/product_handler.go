@@ -1,25 +1,49 @@
 package handler
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"errors"
+	"io"
 	pb "product-service/client-service/proto/productpb"
+	"product-service/internal/catalog"
+	"product-service/internal/config"
 	"product-service/internal/domain"
-	"product-service/internal/repository"
+	"product-service/internal/identity"
+	"product-service/internal/media"
 	"product-service/internal/usecase"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type ProductHandler struct {
 	pb.UnimplementedProductServiceServer
-	usecase usecase.ProductUsecase
+	usecase       usecase.ProductUsecase
+	callers       *identity.Verifier
+	internalToken string
 }
 
-func NewProductHandler() *ProductHandler {
-	repo := repository.NewMongoProductRepository()
-	use := usecase.NewProductUsecase(repo)
-	return &ProductHandler{usecase: use}
+func NewProductHandler(use usecase.ProductUsecase, callers *identity.Verifier, cfg *config.Config) *ProductHandler {
+	return &ProductHandler{usecase: use, callers: callers, internalToken: cfg.InternalToken}
 }
 
 func (h *ProductHandler) CreateProduct(ctx context.Context, req *pb.CreateProductRequest) (*pb.CreateProductResponse, error) {
+	caller, ok := h.callers.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+	if !caller.CanSell() {
+		return nil, status.Error(codes.PermissionDenied, "only sellers and admins may create products")
+	}
+	if req.Product == nil {
+		return nil, status.Error(codes.InvalidArgument, "product is required")
+	}
+
 	product := &domain.Product{
 		Name:        req.Product.Name,
 		Description: req.Product.Description,
@@ -27,7 +51,14 @@ func (h *ProductHandler) CreateProduct(ctx context.Context, req *pb.CreateProduc
 		Price:       req.Product.Price,
 		Quantity:    req.Product.Quantity,
 		UserID:      req.Product.UserId,
+		SKUs:        toDomainSKUs(req.Product.Skus),
+		ExternalSKU: req.Product.ExternalSku,
+	}
+	// Sellers always create products for themselves; admins may act for a seller.
+	if !caller.IsAdmin() || product.UserID == "" {
+		product.UserID = caller.UserID
 	}
+
 	id, err := h.usecase.Create(product)
 	if err != nil {
 		return nil, err
@@ -38,19 +69,11 @@ func (h *ProductHandler) CreateProduct(ctx context.Context, req *pb.CreateProduc
 func (h *ProductHandler) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.GetProductResponse, error) {
 	product, err := h.usecase.GetByID(req.Id)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
 	return &pb.GetProductResponse{
-		Product: &pb.Product{
-			Id:          product.ID,
-			Name:        product.Name,
-			Description: product.Description,
-			Category:    product.Category,
-			Price:       product.Price,
-			Quantity:    product.Quantity,
-			UserId:      product.UserID,
-		},
+		Product: toPbProduct(product),
 	}, nil
 }
 
@@ -62,21 +85,17 @@ func (h *ProductHandler) GetProductsByCategory(ctx context.Context, req *pb.GetP
 
 	var pbProducts []*pb.Product
 	for _, p := range products {
-		pbProducts = append(pbProducts, &pb.Product{
-			Id:          p.ID,
-			Name:        p.Name,
-			Description: p.Description,
-			Category:    p.Category,
-			Price:       p.Price,
-			Quantity:    p.Quantity,
-			UserId:      p.UserID,
-		})
+		pbProducts = append(pbProducts, toPbProduct(p))
 	}
 
 	return &pb.GetProductsByCategoryResponse{Products: pbProducts}, nil
 }
 
 func (h *ProductHandler) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
+	if err := h.authorize(ctx, req.Id); err != nil {
+		return nil, err
+	}
+
 	err := h.usecase.Delete(req.Id)
 	if err != nil {
 		return nil, err
@@ -92,16 +111,489 @@ func (h *ProductHandler) ListProducts(ctx context.Context, req *pb.ListProductsR
 
 	var pbProducts []*pb.Product
 	for _, p := range products {
-		pbProducts = append(pbProducts, &pb.Product{
-			Id:          p.ID,
-			Name:        p.Name,
-			Description: p.Description,
-			Category:    p.Category,
-			Price:       p.Price,
-			Quantity:    p.Quantity,
-			UserId:      p.UserID,
-		})
+		pbProducts = append(pbProducts, toPbProduct(p))
 	}
 
 	return &pb.ListProductsResponse{Products: pbProducts}, nil
 }
+
+func (h *ProductHandler) ListProductsBySeller(ctx context.Context, req *pb.ListProductsBySellerRequest) (*pb.ListProductsBySellerResponse, error) {
+	sellerID := req.SellerId
+	if sellerID == "" {
+		caller, ok := h.callers.FromContext(ctx)
+		if !ok {
+			return nil, status.Error(codes.InvalidArgument, "seller_id is required")
+		}
+		sellerID = caller.UserID
+	}
+
+	products, err := h.usecase.ListBySeller(sellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var pbProducts []*pb.Product
+	for _, p := range products {
+		pbProducts = append(pbProducts, toPbProduct(p))
+	}
+
+	return &pb.ListProductsBySellerResponse{Products: pbProducts}, nil
+}
+
+func (h *ProductHandler) GetSKU(ctx context.Context, req *pb.GetSKURequest) (*pb.GetSKUResponse, error) {
+	if req.SkuId == "" {
+		return nil, status.Error(codes.InvalidArgument, "sku_id is required")
+	}
+
+	product, sku, err := h.usecase.GetBySKU(req.SkuId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.GetSKUResponse{
+		Product: toPbProduct(product),
+		Sku:     toPbSKU(sku),
+	}, nil
+}
+
+func (h *ProductHandler) ReserveStock(ctx context.Context, req *pb.ReserveStockRequest) (*pb.ReserveStockResponse, error) {
+	if !identity.IsInternal(ctx, h.internalToken) {
+		return nil, status.Error(codes.PermissionDenied, "stock reservations are only open to internal services")
+	}
+	if req.SkuId == "" || req.Quantity <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "sku_id and a positive quantity are required")
+	}
+
+	sku, err := h.usecase.ReserveStock(req.SkuId, req.Quantity)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.ReserveStockResponse{SkuId: sku.ID, Remaining: sku.Quantity}, nil
+}
+
+func (h *ProductHandler) ReleaseStock(ctx context.Context, req *pb.ReleaseStockRequest) (*pb.ReleaseStockResponse, error) {
+	if !identity.IsInternal(ctx, h.internalToken) {
+		return nil, status.Error(codes.PermissionDenied, "stock reservations are only open to internal services")
+	}
+	if req.SkuId == "" || req.Quantity <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "sku_id and a positive quantity are required")
+	}
+
+	sku, err := h.usecase.ReleaseStock(req.SkuId, req.Quantity)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &pb.ReleaseStockResponse{SkuId: sku.ID, Remaining: sku.Quantity}, nil
+}
+
+// exportChunkSize caps the payload of a single ExportProducts message.
+const exportChunkSize = 32 * 1024
+
+func (h *ProductHandler) ImportProducts(stream grpc.ClientStreamingServer[pb.ImportProductsRequest, pb.ImportProductsResponse]) error {
+	caller, ok := h.callers.FromContext(stream.Context())
+	if !ok {
+		return status.Error(codes.Unauthenticated, "authentication required")
+	}
+	if !caller.CanSell() {
+		return status.Error(codes.PermissionDenied, "only sellers and admins may import products")
+	}
+
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "empty import stream")
+	}
+	if err != nil {
+		return err
+	}
+
+	// Feed the incoming chunks to the parser through a pipe so the file is
+	// never held in memory as a whole.
+	pr, pw := io.Pipe()
+	go func() {
+		chunk := first.Chunk
+		for {
+			if len(chunk) > 0 {
+				if _, err := pw.Write(chunk); err != nil {
+					return
+				}
+			}
+			req, err := stream.Recv()
+			if err == io.EOF {
+				pw.Close()
+				return
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			chunk = req.Chunk
+		}
+	}()
+
+	report, err := h.usecase.Import(pr, toCatalogFormat(first.Format), first.DryRun, caller)
+	pr.Close()
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	rowErrors := make([]*pb.ImportRowError, 0, len(report.Errors))
+	for _, e := range report.Errors {
+		rowErrors = append(rowErrors, &pb.ImportRowError{
+			Row:         int32(e.Row),
+			ExternalSku: e.ExternalSKU,
+			Message:     e.Message,
+		})
+	}
+
+	return stream.SendAndClose(&pb.ImportProductsResponse{
+		Total:   report.Total,
+		Created: report.Created,
+		Updated: report.Updated,
+		Failed:  report.Failed,
+		DryRun:  report.DryRun,
+		Errors:  rowErrors,
+	})
+}
+
+func (h *ProductHandler) ExportProducts(req *pb.ExportProductsRequest, stream grpc.ServerStreamingServer[pb.ExportProductsResponse]) error {
+	w := bufio.NewWriterSize(&chunkWriter{stream: stream}, exportChunkSize)
+	if err := h.usecase.Export(w, toCatalogFormat(req.Format), req.Category); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// chunkWriter turns writes into ExportProductsResponse messages.
+type chunkWriter struct {
+	stream grpc.ServerStreamingServer[pb.ExportProductsResponse]
+}
+
+func (c *chunkWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), exportChunkSize)
+		chunk := make([]byte, n)
+		copy(chunk, p[:n])
+		if err := c.stream.Send(&pb.ExportProductsResponse{Chunk: chunk}); err != nil {
+			return written, err
+		}
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// maxImageSize limits a single upload; the whole image is decoded in memory.
+const maxImageSize = 10 << 20
+
+func (h *ProductHandler) UploadProductImage(stream grpc.ClientStreamingServer[pb.UploadProductImageRequest, pb.UploadProductImageResponse]) error {
+	// The first message names the product; the caller is authorized for it
+	// before any more of the image is read.
+	req, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "product_id and image data are required")
+	}
+	if err != nil {
+		return err
+	}
+	productID := req.ProductId
+	if productID == "" {
+		return status.Error(codes.InvalidArgument, "product_id and image data are required")
+	}
+	if err := h.authorize(stream.Context(), productID); err != nil {
+		return err
+	}
+
+	var data bytes.Buffer
+	for {
+		if data.Len()+len(req.Chunk) > maxImageSize {
+			return status.Errorf(codes.InvalidArgument, "image is larger than %d bytes", maxImageSize)
+		}
+		data.Write(req.Chunk)
+
+		req, err = stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if data.Len() == 0 {
+		return status.Error(codes.InvalidArgument, "product_id and image data are required")
+	}
+
+	image, err := h.usecase.UploadImage(productID, data.Bytes())
+	if err != nil {
+		return toStatusError(err)
+	}
+
+	product, err := h.usecase.GetByID(productID)
+	if err != nil {
+		return err
+	}
+	position := 0
+	for i := range product.Images {
+		if product.Images[i].ID == image.ID {
+			position = i
+		}
+	}
+
+	return stream.SendAndClose(&pb.UploadProductImageResponse{Image: toPbImage(productID, image, position)})
+}
+
+func (h *ProductHandler) GetProductImage(req *pb.GetProductImageRequest, stream grpc.ServerStreamingServer[pb.GetProductImageResponse]) error {
+	r, contentType, err := h.usecase.OpenImage(req.ProductId, req.ImageId, req.Size)
+	if err != nil {
+		return toStatusError(err)
+	}
+	defer r.Close()
+
+	buf := make([]byte, exportChunkSize)
+	first := true
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			resp := &pb.GetProductImageResponse{Chunk: append([]byte(nil), buf[:n]...)}
+			if first {
+				resp.ContentType = contentType
+				first = false
+			}
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (h *ProductHandler) DeleteProductImage(ctx context.Context, req *pb.DeleteProductImageRequest) (*pb.DeleteProductImageResponse, error) {
+	if err := h.authorize(ctx, req.ProductId); err != nil {
+		return nil, err
+	}
+
+	images, err := h.usecase.DeleteImage(req.ProductId, req.ImageId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.DeleteProductImageResponse{Images: toPbImages(req.ProductId, images)}, nil
+}
+
+func (h *ProductHandler) ReorderProductImages(ctx context.Context, req *pb.ReorderProductImagesRequest) (*pb.ReorderProductImagesResponse, error) {
+	if err := h.authorize(ctx, req.ProductId); err != nil {
+		return nil, err
+	}
+
+	images, err := h.usecase.ReorderImages(req.ProductId, req.ImageIds)
+	if err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) || errors.Is(err, domain.ErrImagesChanged) {
+			return nil, toStatusError(err)
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &pb.ReorderProductImagesResponse{Images: toPbImages(req.ProductId, images)}, nil
+}
+
+func (h *ProductHandler) SchedulePrice(ctx context.Context, req *pb.SchedulePriceRequest) (*pb.SchedulePriceResponse, error) {
+	if req.ProductId == "" || req.Schedule == nil {
+		return nil, status.Error(codes.InvalidArgument, "product_id and schedule are required")
+	}
+	if err := h.authorize(ctx, req.ProductId); err != nil {
+		return nil, err
+	}
+
+	schedule := domain.PriceSchedule{
+		Kind:     domain.PriceSale,
+		Price:    req.Schedule.Price,
+		StartsAt: req.Schedule.StartsAt.AsTime(),
+	}
+	if req.Schedule.Kind == pb.PriceScheduleKind_PRICE_SCHEDULE_KIND_REGULAR {
+		schedule.Kind = domain.PriceRegular
+	}
+	if req.Schedule.StartsAt == nil {
+		schedule.StartsAt = time.Now()
+	}
+	if req.Schedule.EndsAt != nil {
+		schedule.EndsAt = req.Schedule.EndsAt.AsTime()
+	}
+	if err := schedule.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	product, err := h.usecase.SchedulePrice(req.ProductId, schedule)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.SchedulePriceResponse{Product: toPbProduct(product)}, nil
+}
+
+func (h *ProductHandler) CancelPriceSchedule(ctx context.Context, req *pb.CancelPriceScheduleRequest) (*pb.CancelPriceScheduleResponse, error) {
+	if err := h.authorize(ctx, req.ProductId); err != nil {
+		return nil, err
+	}
+
+	product, err := h.usecase.CancelPriceSchedule(req.ProductId, req.ScheduleId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.CancelPriceScheduleResponse{Product: toPbProduct(product)}, nil
+}
+
+func toCatalogFormat(f pb.CatalogFormat) catalog.Format {
+	if f == pb.CatalogFormat_CATALOG_FORMAT_JSONL {
+		return catalog.JSONL
+	}
+	return catalog.CSV
+}
+
+// authorize lets the request through only when the caller owns the product
+// or is an admin.
+func (h *ProductHandler) authorize(ctx context.Context, productID string) error {
+	caller, ok := h.callers.FromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	product, err := h.usecase.GetByID(productID)
+	if err != nil {
+		return toStatusError(domain.ErrProductNotFound)
+	}
+	if !product.CanBeModifiedBy(caller) {
+		return toStatusError(domain.ErrForbidden)
+	}
+	return nil
+}
+
+func toStatusError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrSKUNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrInsufficientStock):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrProductNotFound),
+		errors.Is(err, domain.ErrImageNotFound),
+		errors.Is(err, domain.ErrPriceScheduleNotFound),
+		errors.Is(err, media.ErrBlobNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, domain.ErrImagesChanged):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, media.ErrUnsupportedImage),
+		errors.Is(err, media.ErrImageTooLarge):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return err
+	}
+}
+
+func toPbProduct(p *domain.Product) *pb.Product {
+	skus := make([]*pb.SKU, 0, len(p.SKUs))
+	for i := range p.SKUs {
+		skus = append(skus, toPbSKU(&p.SKUs[i]))
+	}
+
+	schedules := make([]*pb.PriceSchedule, 0, len(p.PriceSchedules))
+	for _, s := range p.PriceSchedules {
+		schedule := &pb.PriceSchedule{
+			Id:       s.ID,
+			Kind:     pb.PriceScheduleKind_PRICE_SCHEDULE_KIND_SALE,
+			Price:    s.Price,
+			StartsAt: timestamppb.New(s.StartsAt),
+		}
+		if s.Kind == domain.PriceRegular {
+			schedule.Kind = pb.PriceScheduleKind_PRICE_SCHEDULE_KIND_REGULAR
+		}
+		if !s.EndsAt.IsZero() {
+			schedule.EndsAt = timestamppb.New(s.EndsAt)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	// Price is the list price; EffectivePrice includes any running sale.
+	list, effective := p.ResolvePrice(time.Now())
+
+	return &pb.Product{
+		Id:             p.ID,
+		Name:           p.Name,
+		Description:    p.Description,
+		Category:       p.Category,
+		Price:          list,
+		Quantity:       p.Quantity,
+		UserId:         p.UserID,
+		Skus:           skus,
+		ExternalSku:    p.ExternalSKU,
+		Images:         toPbImages(p.ID, p.Images),
+		EffectivePrice: effective,
+		PriceSchedules: schedules,
+	}
+}
+
+// imageURL is the gateway route that serves an image; see api-gateway/cmd.
+func imageURL(productID, imageID, size string) string {
+	url := "/product/" + productID + "/images/" + imageID
+	if size != "" {
+		url += "?size=" + size
+	}
+	return url
+}
+
+func toPbImage(productID string, img *domain.Image, position int) *pb.Image {
+	thumbnails := make(map[string]string, len(img.Thumbnails))
+	for size := range img.Thumbnails {
+		thumbnails[size] = imageURL(productID, img.ID, size)
+	}
+
+	return &pb.Image{
+		Id:            img.ID,
+		Url:           imageURL(productID, img.ID, ""),
+		ContentType:   img.ContentType,
+		Width:         img.Width,
+		Height:        img.Height,
+		Position:      int32(position),
+		ThumbnailUrls: thumbnails,
+	}
+}
+
+func toPbImages(productID string, images []domain.Image) []*pb.Image {
+	result := make([]*pb.Image, 0, len(images))
+	for i := range images {
+		result = append(result, toPbImage(productID, &images[i], i))
+	}
+	return result
+}
+
+func toPbSKU(s *domain.SKU) *pb.SKU {
+	return &pb.SKU{
+		Id:       s.ID,
+		Size:     s.Size,
+		Weight:   s.Weight,
+		Unit:     s.Unit,
+		Price:    s.Price,
+		Quantity: s.Quantity,
+		Barcode:  s.Barcode,
+	}
+}
+
+func toDomainSKUs(skus []*pb.SKU) []domain.SKU {
+	result := make([]domain.SKU, 0, len(skus))
+	for _, s := range skus {
+		result = append(result, domain.SKU{
+			ID:       s.Id,
+			Size:     s.Size,
+			Weight:   s.Weight,
+			Unit:     s.Unit,
+			Price:    s.Price,
+			Quantity: s.Quantity,
+			Barcode:  s.Barcode,
+		})
+	}
+	return result
+}
@@ -0,0 +1,216 @@
+package handler_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"testing"
+	"time"
+
+	pb "product-service/client-service/proto/productpb"
+	"product-service/internal/config"
+	"product-service/internal/domain"
+	"product-service/internal/handler"
+	"product-service/internal/identity"
+	"product-service/internal/usecase"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeUsecase serves a single product owned by seller1 and records the
+// writes that reach it.
+type fakeUsecase struct {
+	usecase.ProductUsecase
+	deleted   []string
+	scheduled []string
+	created   []*domain.Product
+}
+
+func (f *fakeUsecase) Create(p *domain.Product) (string, error) {
+	f.created = append(f.created, p)
+	return "p2", nil
+}
+
+func (f *fakeUsecase) GetByID(id string) (*domain.Product, error) {
+	if id != "p1" {
+		return nil, domain.ErrProductNotFound
+	}
+	return &domain.Product{ID: "p1", Name: "Milk", Price: 1.2, UserID: "seller1"}, nil
+}
+
+func (f *fakeUsecase) Delete(id string) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func (f *fakeUsecase) SchedulePrice(productID string, schedule domain.PriceSchedule) (*domain.Product, error) {
+	f.scheduled = append(f.scheduled, productID)
+	return f.GetByID(productID)
+}
+
+func newHandler(t *testing.T) (*handler.ProductHandler, *fakeUsecase, func(userID string, roles ...string) context.Context) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	callers := identity.NewVerifier(func(*jwt.Token) (interface{}, error) { return pub, nil })
+	use := &fakeUsecase{}
+
+	withToken := func(userID string, roles ...string) context.Context {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+			"user_id": userID,
+			"roles":   roles,
+			"exp":     time.Now().Add(time.Minute).Unix(),
+		}).SignedString(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return metadata.NewIncomingContext(context.Background(),
+			metadata.Pairs(identity.AuthorizationKey, "Bearer "+token))
+	}
+	return handler.NewProductHandler(use, callers, &config.Config{}), use, withToken
+}
+
+func TestDeleteProduct_Ownership(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID string
+		roles  []string
+		code   codes.Code
+	}{
+		{"owner", "seller1", nil, codes.OK},
+		{"non-owner", "seller2", nil, codes.PermissionDenied},
+		{"admin", "admin1", []string{domain.RoleAdmin}, codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, use, withToken := newHandler(t)
+
+			_, err := h.DeleteProduct(withToken(tt.userID, tt.roles...), &pb.DeleteProductRequest{Id: "p1"})
+
+			assert.Equal(t, tt.code, status.Code(err))
+			if tt.code == codes.OK {
+				assert.Equal(t, []string{"p1"}, use.deleted)
+			} else {
+				assert.Empty(t, use.deleted)
+			}
+		})
+	}
+}
+
+func TestSchedulePrice_Ownership(t *testing.T) {
+	req := &pb.SchedulePriceRequest{
+		ProductId: "p1",
+		Schedule:  &pb.PriceSchedule{Kind: pb.PriceScheduleKind_PRICE_SCHEDULE_KIND_REGULAR, Price: 1.5},
+	}
+	tests := []struct {
+		name   string
+		userID string
+		roles  []string
+		code   codes.Code
+	}{
+		{"owner", "seller1", nil, codes.OK},
+		{"non-owner", "seller2", nil, codes.PermissionDenied},
+		{"admin", "admin1", []string{domain.RoleAdmin}, codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, use, withToken := newHandler(t)
+
+			_, err := h.SchedulePrice(withToken(tt.userID, tt.roles...), req)
+
+			assert.Equal(t, tt.code, status.Code(err))
+			assert.Equal(t, tt.code == codes.OK, len(use.scheduled) == 1)
+		})
+	}
+}
+
+func TestCreateProduct_RequiresSellerRole(t *testing.T) {
+	req := &pb.CreateProductRequest{Product: &pb.Product{Name: "Bread", Price: 2}}
+	tests := []struct {
+		name  string
+		roles []string
+		code  codes.Code
+	}{
+		{"customer", []string{"customer"}, codes.PermissionDenied},
+		{"no roles", nil, codes.PermissionDenied},
+		{"seller", []string{domain.RoleSeller}, codes.OK},
+		{"admin", []string{domain.RoleAdmin}, codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, use, withToken := newHandler(t)
+
+			_, err := h.CreateProduct(withToken("user1", tt.roles...), req)
+
+			assert.Equal(t, tt.code, status.Code(err))
+			assert.Equal(t, tt.code == codes.OK, len(use.created) == 1)
+		})
+	}
+}
+
+func TestDeleteProduct_IgnoresForgedIdentity(t *testing.T) {
+	h, use, _ := newHandler(t)
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs("x-user-id", "seller1", "x-user-role", domain.RoleAdmin))
+
+	_, err := h.DeleteProduct(ctx, &pb.DeleteProductRequest{Id: "p1"})
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Empty(t, use.deleted)
+}
+
+func TestDeleteProduct_RejectsTokenFromOtherKey(t *testing.T) {
+	h, use, _ := newHandler(t)
+	_, _, withOtherToken := newHandler(t)
+
+	_, err := h.DeleteProduct(withOtherToken("seller1"), &pb.DeleteProductRequest{Id: "p1"})
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Empty(t, use.deleted)
+}
+
+func TestGetProduct_NotFound(t *testing.T) {
+	h, _, _ := newHandler(t)
+
+	_, err := h.GetProduct(context.Background(), &pb.GetProductRequest{Id: "deleted"})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+// uploadStream sends an image upload for p1 in chunks and counts how many
+// of them the handler read.
+type uploadStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	chunks int
+	read   int
+}
+
+func (s *uploadStream) Context() context.Context { return s.ctx }
+
+func (s *uploadStream) Recv() (*pb.UploadProductImageRequest, error) {
+	if s.read == s.chunks {
+		return nil, io.EOF
+	}
+	s.read++
+	return &pb.UploadProductImageRequest{ProductId: "p1", Chunk: make([]byte, 1024)}, nil
+}
+
+func (s *uploadStream) SendAndClose(*pb.UploadProductImageResponse) error { return nil }
+
+func TestUploadProductImage_AuthorizesBeforeReadingChunks(t *testing.T) {
+	h, _, withToken := newHandler(t)
+	stream := &uploadStream{ctx: withToken("seller2"), chunks: 100}
+
+	err := h.UploadProductImage(stream)
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, 1, stream.read)
+}
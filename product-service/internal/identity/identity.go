@@ -0,0 +1,79 @@
+package identity
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"product-service/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys of incoming calls. The api-gateway passes on the caller's
+// access token as is; other services send the shared internal token.
+const (
+	AuthorizationKey = "authorization"
+	// ServiceTokenKey carries the shared secret of internal callers.
+	ServiceTokenKey = "x-service-token"
+)
+
+// SigningMethods are the algorithms user-service signs access tokens with.
+var SigningMethods = []string{"EdDSA", "RS256"}
+
+type claims struct {
+	UserID string   `json:"user_id"`
+	Roles  []string `json:"roles"`
+	jwt.RegisteredClaims
+}
+
+// Verifier reads the caller from the access token of a request, so a
+// client cannot claim to be someone else.
+type Verifier struct {
+	keyfunc jwt.Keyfunc
+}
+
+// NewVerifier checks token signatures with the keys keyfunc returns,
+// usually JWKS.Keyfunc.
+func NewVerifier(keyfunc jwt.Keyfunc) *Verifier {
+	return &Verifier{keyfunc: keyfunc}
+}
+
+// FromContext reads the caller from the bearer token in the incoming gRPC
+// metadata. ok is false when there is no token or it is not valid.
+func (v *Verifier) FromContext(ctx context.Context) (caller domain.Caller, ok bool) {
+	md, found := metadata.FromIncomingContext(ctx)
+	if !found {
+		return domain.Caller{}, false
+	}
+	tokenString, found := strings.CutPrefix(first(md.Get(AuthorizationKey)), "Bearer ")
+	if !found {
+		return domain.Caller{}, false
+	}
+
+	var c claims
+	token, err := jwt.ParseWithClaims(tokenString, &c, v.keyfunc, jwt.WithValidMethods(SigningMethods))
+	if err != nil || !token.Valid {
+		return domain.Caller{}, false
+	}
+	return domain.Caller{UserID: c.UserID, Roles: c.Roles}, c.UserID != ""
+}
+
+// IsInternal reports whether the request comes from another service, i.e.
+// carries token in ServiceTokenKey. It is false for an empty token.
+func IsInternal(ctx context.Context, token string) bool {
+	md, found := metadata.FromIncomingContext(ctx)
+	if !found || token == "" {
+		return false
+	}
+	sent := first(md.Get(ServiceTokenKey))
+	return subtle.ConstantTimeCompare([]byte(sent), []byte(token)) == 1
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
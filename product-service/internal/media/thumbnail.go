@@ -0,0 +1,126 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+)
+
+// ThumbnailSizes maps a size name to the longest edge of the rendition.
+var ThumbnailSizes = map[string]int{
+	"small":  150,
+	"medium": 400,
+	"large":  800,
+}
+
+const ThumbnailContentType = "image/jpeg"
+
+// MaxPixels bounds the width × height of an upload. A few kilobytes of
+// compressed data can declare an image that takes gigabytes once decoded.
+const MaxPixels = 40_000_000
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image type, expected jpeg, png or gif")
+	ErrImageTooLarge    = fmt.Errorf("image has more than %d pixels", MaxPixels)
+)
+
+// DetectImageType sniffs the content type of data and rejects anything that
+// is not an image format we can decode.
+func DetectImageType(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+		return contentType, nil
+	default:
+		return "", ErrUnsupportedImage
+	}
+}
+
+// Decode parses an uploaded jpeg, png or gif. Its dimensions are checked
+// against MaxPixels before any pixel is decoded.
+func Decode(data []byte) (image.Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if int64(config.Width)*int64(config.Height) > MaxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	return img, nil
+}
+
+// Thumbnail scales img so its longest edge is at most maxEdge pixels and
+// encodes it as JPEG. Images that are already small enough are not enlarged.
+func Thumbnail(img image.Image, maxEdge int) ([]byte, error) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w > maxEdge || h > maxEdge {
+		if w >= h {
+			h = max(1, h*maxEdge/w)
+			w = maxEdge
+		} else {
+			w = max(1, w*maxEdge/h)
+			h = maxEdge
+		}
+	}
+
+	// JPEG has no alpha channel, so transparent areas are flattened onto white.
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	scaled := resize(img, w, h)
+	draw.Draw(dst, dst.Bounds(), scaled, image.Point{}, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resize downsamples src to w×h by averaging the source pixels that fall
+// into each destination pixel.
+func resize(src image.Image, w, h int) *image.NRGBA {
+	bounds := src.Bounds()
+	sw, sh := bounds.Dx(), bounds.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0 := bounds.Min.Y + y*sh/h
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*sh/h)
+		for x := 0; x < w; x++ {
+			x0 := bounds.Min.X + x*sw/w
+			x1 := max(x0+1, bounds.Min.X+(x+1)*sw/w)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBA64Model.Convert(src.At(sx, sy)).(color.NRGBA64)
+					r += uint64(c.R)
+					g += uint64(c.G)
+					b += uint64(c.B)
+					a += uint64(c.A)
+					n++
+				}
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
@@ -41,3 +41,47 @@ func (p *Publisher) PublishProductDeleted(id string) {
 	_ = p.conn.Publish("product.deleted", data)
 	log.Println("📤 Published product.deleted for ID:", id)
 }
+
+// PublishProductsCreated sends a whole batch of new products as one
+// product.created message whose payload is a JSON array.
+func (p *Publisher) PublishProductsCreated(products []*domain.Product) {
+	p.publishBatch("product.created", products)
+}
+
+// PublishProductsUpdated is the product.updated counterpart of PublishProductsCreated.
+func (p *Publisher) PublishProductsUpdated(products []*domain.Product) {
+	p.publishBatch("product.updated", products)
+}
+
+func (p *Publisher) PublishPriceChanged(event domain.PriceChangedEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Println("❌ Failed to marshal price change:", err)
+		return
+	}
+	_ = p.conn.Publish("product.price_changed", data)
+	log.Printf("📤 Published product.price_changed for ID %s: %.2f -> %.2f", event.ProductID, event.OldPrice, event.NewPrice)
+}
+
+func (p *Publisher) PublishRestocked(event domain.RestockedEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Println("❌ Failed to marshal restock:", err)
+		return
+	}
+	_ = p.conn.Publish("product.restocked", data)
+	log.Printf("📤 Published product.restocked for ID %s (SKU %q): %d in stock", event.ProductID, event.SKUID, event.Quantity)
+}
+
+func (p *Publisher) publishBatch(subject string, products []*domain.Product) {
+	if len(products) == 0 {
+		return
+	}
+	data, err := json.Marshal(products)
+	if err != nil {
+		log.Println("❌ Failed to marshal product batch:", err)
+		return
+	}
+	_ = p.conn.Publish(subject, data)
+	log.Printf("📤 Published %s batch with %d products", subject, len(products))
+}
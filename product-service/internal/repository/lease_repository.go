@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"product-service/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Lease lets one replica at a time do a job, such as running the price
+// scheduler. A lease that is not renewed expires, so another replica takes
+// over when its holder dies.
+type Lease interface {
+	// Acquire takes the named lease for holder, or renews it if holder has
+	// it already, until ttl from now. It reports whether holder has the
+	// lease.
+	Acquire(name, holder string, ttl time.Duration) (bool, error)
+}
+
+type mongoLease struct {
+	collection *mongo.Collection
+}
+
+func NewMongoLease() Lease {
+	client := database.ConnectMongo("mongodb://localhost:27017")
+	collection := client.Database("onlinesupermarket").Collection("leases")
+	return &mongoLease{collection: collection}
+}
+
+func (m *mongoLease) Acquire(name, holder string, ttl time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	filter := bson.M{"_id": name, "$or": bson.A{
+		bson.M{"holder": holder},
+		bson.M{"expires_at": bson.M{"$lte": now}},
+	}}
+	update := bson.M{"$set": bson.M{"holder": holder, "expires_at": now.Add(ttl)}}
+
+	// When another holder has a live lease the filter misses and the upsert
+	// collides with its document.
+	_, err := m.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
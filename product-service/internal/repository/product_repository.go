@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"log"
 	"product-service/database"
 	"product-service/internal/domain"
@@ -10,15 +11,32 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type ProductRepository interface {
 	Create(product *domain.Product) (string, error)
 	GetProductsByCategory(category string) ([]*domain.Product, error)
+	GetBySeller(sellerID string) ([]*domain.Product, error)
 	GetByID(id string) (*domain.Product, error)
+	GetBySKU(skuID string) (*domain.Product, error)
+	GetByExternalSKU(externalSKU string) (*domain.Product, error)
+	UpdateCatalog(product *domain.Product, fields []string) error
 
 	Delete(id string) error
 	List() ([]*domain.Product, error)
+
+	ReserveStock(skuID string, quantity int32) (*domain.Product, error)
+	ReleaseStock(skuID string, quantity int32) (*domain.Product, error)
+
+	AddImage(productID string, image domain.Image) (*domain.Product, error)
+	RemoveImage(productID, imageID string) (*domain.Product, error)
+	SetImageOrder(productID string, images []domain.Image) (*domain.Product, error)
+
+	AddPriceSchedule(productID string, schedule domain.PriceSchedule) (*domain.Product, error)
+	RemovePriceSchedule(productID, scheduleID string) (*domain.Product, error)
+	UpdatePricing(product *domain.Product) error
+	ListWithPendingPrices() ([]*domain.Product, error)
 }
 
 type mongoRepo struct {
@@ -35,6 +53,8 @@ func (m *mongoRepo) Create(product *domain.Product) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	assignSKUIDs(product)
+
 	res, err := m.collection.InsertOne(ctx, product)
 	if err != nil {
 		return "", err
@@ -52,20 +72,114 @@ func (m *mongoRepo) GetByID(id string) (*domain.Product, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	// No product can have an ID that is not an ObjectID.
 	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, err
+		return nil, domain.ErrProductNotFound
 	}
 
 	var product domain.Product
 	err = m.collection.FindOne(ctx, bson.M{"_id": oid}).Decode(&product)
 	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+func (m *mongoRepo) GetBySKU(skuID string) (*domain.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var product domain.Product
+	err := m.collection.FindOne(ctx, bson.M{"skus.id": skuID}).Decode(&product)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrSKUNotFound
+		}
 		return nil, err
 	}
 
 	return &product, nil
 }
 
+func (m *mongoRepo) GetByExternalSKU(externalSKU string) (*domain.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var product domain.Product
+	err := m.collection.FindOne(ctx, bson.M{"external_sku": externalSKU}).Decode(&product)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// UpdateCatalog stores the given catalog fields of product (see
+// catalog.Row), leaving the rest of the document as it is.
+func (m *mongoRepo) UpdateCatalog(product *domain.Product, fields []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	oid, err := primitive.ObjectIDFromHex(product.ID)
+	if err != nil {
+		return err
+	}
+
+	set := bson.M{}
+	for _, field := range fields {
+		switch field {
+		case "name":
+			set["name"] = product.Name
+		case "description":
+			set["description"] = product.Description
+		case "category":
+			set["category"] = product.Category
+		case "price":
+			set["price"] = product.Price
+		case "quantity":
+			set["quantity"] = product.Quantity
+		case "skus":
+			assignSKUIDs(product)
+			set["skus"] = product.SKUs
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	update := bson.M{"$set": set}
+	if _, ok := set["price"]; ok {
+		update["$inc"] = bson.M{"pricing_version": 1}
+	}
+
+	res, err := m.collection.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrProductNotFound
+	}
+	if _, ok := update["$inc"]; ok {
+		product.PricingVersion++
+	}
+	return nil
+}
+
+func assignSKUIDs(product *domain.Product) {
+	for i := range product.SKUs {
+		if product.SKUs[i].ID == "" {
+			product.SKUs[i].ID = primitive.NewObjectID().Hex()
+		}
+	}
+}
+
 func (m *mongoRepo) GetProductsByCategory(category string) ([]*domain.Product, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -88,6 +202,27 @@ func (m *mongoRepo) GetProductsByCategory(category string) ([]*domain.Product, e
 	return products, nil
 }
 
+func (m *mongoRepo) GetBySeller(sellerID string) ([]*domain.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := m.collection.Find(ctx, bson.M{"user_id": sellerID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var products []*domain.Product
+	for cursor.Next(ctx) {
+		var p domain.Product
+		if err := cursor.Decode(&p); err == nil {
+			products = append(products, &p)
+		}
+	}
+
+	return products, nil
+}
+
 func (m *mongoRepo) Delete(id string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -123,3 +258,196 @@ func (m *mongoRepo) List() ([]*domain.Product, error) {
 
 	return products, nil
 }
+
+// ReserveStock atomically decrements the stock of a SKU, failing when fewer
+// than quantity units are left. The updated product is returned.
+func (m *mongoRepo) ReserveStock(skuID string, quantity int32) (*domain.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"skus": bson.M{"$elemMatch": bson.M{"id": skuID, "quantity": bson.M{"$gte": quantity}}}}
+	update := bson.M{"$inc": bson.M{"skus.$.quantity": -quantity}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var product domain.Product
+	err := m.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&product)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if _, lookupErr := m.GetBySKU(skuID); lookupErr != nil {
+				return nil, lookupErr
+			}
+			return nil, domain.ErrInsufficientStock
+		}
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+func (m *mongoRepo) ReleaseStock(skuID string, quantity int32) (*domain.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"skus.id": skuID}
+	update := bson.M{"$inc": bson.M{"skus.$.quantity": quantity}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var product domain.Product
+	err := m.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&product)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrSKUNotFound
+		}
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// AddImage appends image to the end of the product's image list.
+func (m *mongoRepo) AddImage(productID string, image domain.Image) (*domain.Product, error) {
+	return m.updateImages(productID, bson.M{"$push": bson.M{"images": image}})
+}
+
+func (m *mongoRepo) RemoveImage(productID, imageID string) (*domain.Product, error) {
+	filter := bson.M{"images.id": imageID}
+	update := bson.M{"$pull": bson.M{"images": bson.M{"id": imageID}}}
+	return m.findOneAndUpdate(productID, filter, update, domain.ErrImageNotFound)
+}
+
+// SetImageOrder stores images, the product's images in a new order. It fails
+// with ErrImagesChanged when an image was added or removed in the meantime,
+// so a concurrent upload is never dropped.
+func (m *mongoRepo) SetImageOrder(productID string, images []domain.Image) (*domain.Product, error) {
+	ids := make([]string, 0, len(images))
+	for _, image := range images {
+		ids = append(ids, image.ID)
+	}
+	filter := bson.M{"images": bson.M{"$size": len(images)}}
+	if len(ids) > 0 {
+		filter["images.id"] = bson.M{"$all": ids}
+	}
+
+	product, err := m.findOneAndUpdate(productID, filter, bson.M{"$set": bson.M{"images": images}}, domain.ErrImagesChanged)
+	if errors.Is(err, domain.ErrImagesChanged) {
+		if _, lookupErr := m.GetByID(productID); lookupErr != nil {
+			return nil, domain.ErrProductNotFound
+		}
+	}
+	return product, err
+}
+
+func (m *mongoRepo) updateImages(productID string, update bson.M) (*domain.Product, error) {
+	return m.findOneAndUpdate(productID, bson.M{}, update, domain.ErrProductNotFound)
+}
+
+func (m *mongoRepo) AddPriceSchedule(productID string, schedule domain.PriceSchedule) (*domain.Product, error) {
+	update := bson.M{
+		"$push": bson.M{"price_schedules": schedule},
+		"$inc":  bson.M{"pricing_version": 1},
+	}
+	return m.findOneAndUpdate(productID, bson.M{}, update, domain.ErrProductNotFound)
+}
+
+func (m *mongoRepo) RemovePriceSchedule(productID, scheduleID string) (*domain.Product, error) {
+	filter := bson.M{"price_schedules.id": scheduleID}
+	update := bson.M{
+		"$pull": bson.M{"price_schedules": bson.M{"id": scheduleID}},
+		"$inc":  bson.M{"pricing_version": 1},
+	}
+	return m.findOneAndUpdate(productID, filter, update, domain.ErrPriceScheduleNotFound)
+}
+
+// UpdatePricing stores the list price, current price and remaining schedules
+// computed by the price scheduler. It fails with ErrPricingChanged unless the
+// stored pricing is still at product.PricingVersion, and bumps the version.
+func (m *mongoRepo) UpdatePricing(product *domain.Product) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	oid, err := primitive.ObjectIDFromHex(product.ID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": oid, "pricing_version": product.PricingVersion}
+	if product.PricingVersion == 0 {
+		// Also matches products stored before the version existed.
+		filter["pricing_version"] = bson.M{"$in": bson.A{0, nil}}
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"price":           product.Price,
+			"current_price":   product.CurrentPrice,
+			"price_schedules": product.PriceSchedules,
+		},
+		"$inc": bson.M{"pricing_version": 1},
+	}
+	res, err := m.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		if _, err := m.GetByID(product.ID); err != nil {
+			return domain.ErrProductNotFound
+		}
+		return domain.ErrPricingChanged
+	}
+	product.PricingVersion++
+	return nil
+}
+
+// ListWithPendingPrices returns the products the price scheduler has to look
+// at: those with schedules and those whose current price is out of date.
+func (m *mongoRepo) ListWithPendingPrices() ([]*domain.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"price_schedules.0": bson.M{"$exists": true}},
+		bson.M{"$expr": bson.M{"$ne": bson.A{"$current_price", "$price"}}},
+	}}
+	cursor, err := m.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var products []*domain.Product
+	for cursor.Next(ctx) {
+		var p domain.Product
+		if err := cursor.Decode(&p); err != nil {
+			log.Println("decode error:", err)
+			continue
+		}
+		products = append(products, &p)
+	}
+
+	return products, nil
+}
+
+// findOneAndUpdate applies update to the product with the given ID that also
+// matches filter, returning notFound when no such product exists.
+func (m *mongoRepo) findOneAndUpdate(productID string, filter, update bson.M, notFound error) (*domain.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	oid, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, err
+	}
+	filter["_id"] = oid
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var product domain.Product
+	err = m.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&product)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, notFound
+		}
+		return nil, err
+	}
+
+	return &product, nil
+}
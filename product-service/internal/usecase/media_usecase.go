@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"product-service/internal/domain"
+	"product-service/internal/media"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func imageKey(productID, imageID, size string) string {
+	return fmt.Sprintf("products/%s/%s/%s", productID, imageID, size)
+}
+
+func (u *productUsecase) UploadImage(productID string, data []byte) (*domain.Image, error) {
+	if _, err := u.GetByID(productID); err != nil {
+		return nil, domain.ErrProductNotFound
+	}
+
+	contentType, err := media.DetectImageType(data)
+	if err != nil {
+		return nil, err
+	}
+	img, err := media.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	bounds := img.Bounds()
+	image := domain.Image{
+		ID:          primitive.NewObjectID().Hex(),
+		ContentType: contentType,
+		Width:       int32(bounds.Dx()),
+		Height:      int32(bounds.Dy()),
+		Thumbnails:  make(map[string]string, len(media.ThumbnailSizes)),
+	}
+
+	image.Key = imageKey(productID, image.ID, "original")
+	if err := u.media.Put(ctx, image.Key, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	for size, edge := range media.ThumbnailSizes {
+		thumb, err := media.Thumbnail(img, edge)
+		if err != nil {
+			u.deleteBlobs(image)
+			return nil, err
+		}
+		key := imageKey(productID, image.ID, size)
+		if err := u.media.Put(ctx, key, bytes.NewReader(thumb)); err != nil {
+			u.deleteBlobs(image)
+			return nil, err
+		}
+		image.Thumbnails[size] = key
+	}
+
+	product, err := u.repo.AddImage(productID, image)
+	if err != nil {
+		u.deleteBlobs(image)
+		return nil, err
+	}
+	u.cache.UpdateProduct(product)
+	log.Printf("🖼️ Image %s added to product %s", image.ID, productID)
+
+	return &image, nil
+}
+
+// OpenImage returns the original image, or one of its thumbnails when size
+// is set, together with its content type. The caller closes the reader.
+func (u *productUsecase) OpenImage(productID, imageID, size string) (io.ReadCloser, string, error) {
+	product, err := u.GetByID(productID)
+	if err != nil {
+		return nil, "", domain.ErrProductNotFound
+	}
+	image, ok := product.FindImage(imageID)
+	if !ok {
+		return nil, "", domain.ErrImageNotFound
+	}
+
+	key, contentType := image.Key, image.ContentType
+	if size != "" && size != "original" {
+		thumbKey, ok := image.Thumbnails[size]
+		if !ok {
+			return nil, "", fmt.Errorf("%w: unknown size %q", domain.ErrImageNotFound, size)
+		}
+		key, contentType = thumbKey, media.ThumbnailContentType
+	}
+
+	r, err := u.media.Get(context.Background(), key)
+	if err != nil {
+		return nil, "", err
+	}
+	return r, contentType, nil
+}
+
+func (u *productUsecase) DeleteImage(productID, imageID string) ([]domain.Image, error) {
+	product, err := u.repo.GetByID(productID)
+	if err != nil {
+		return nil, domain.ErrProductNotFound
+	}
+	image, ok := product.FindImage(imageID)
+	if !ok {
+		return nil, domain.ErrImageNotFound
+	}
+	removed := *image
+
+	updated, err := u.repo.RemoveImage(productID, imageID)
+	if err != nil {
+		return nil, err
+	}
+	u.cache.UpdateProduct(updated)
+	u.deleteBlobs(removed)
+	log.Printf("🗑️ Image %s removed from product %s", imageID, productID)
+
+	return updated.Images, nil
+}
+
+// ReorderImages puts the product's images in the order of imageIDs, which
+// must name every image exactly once.
+func (u *productUsecase) ReorderImages(productID string, imageIDs []string) ([]domain.Image, error) {
+	product, err := u.repo.GetByID(productID)
+	if err != nil {
+		return nil, domain.ErrProductNotFound
+	}
+	if len(imageIDs) != len(product.Images) {
+		return nil, errors.New("image_ids must list every image of the product exactly once")
+	}
+
+	images := make([]domain.Image, 0, len(imageIDs))
+	seen := make(map[string]bool, len(imageIDs))
+	for _, id := range imageIDs {
+		image, ok := product.FindImage(id)
+		if !ok || seen[id] {
+			return nil, errors.New("image_ids must list every image of the product exactly once")
+		}
+		seen[id] = true
+		images = append(images, *image)
+	}
+
+	updated, err := u.repo.SetImageOrder(productID, images)
+	if err != nil {
+		return nil, err
+	}
+	u.cache.UpdateProduct(updated)
+
+	return updated.Images, nil
+}
+
+// deleteBlobs removes the original and all thumbnails of an image. Failures
+// are only logged: a dangling blob is harmless, a failed request is not.
+func (u *productUsecase) deleteBlobs(image domain.Image) {
+	ctx := context.Background()
+	keys := []string{image.Key}
+	for _, key := range image.Thumbnails {
+		keys = append(keys, key)
+	}
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := u.media.Delete(ctx, key); err != nil {
+			log.Println("❌ Failed to delete blob:", key, err)
+		}
+	}
+}
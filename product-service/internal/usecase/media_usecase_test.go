@@ -0,0 +1,86 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"product-service/internal/domain"
+	"product-service/internal/media"
+	"product-service/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestUploadImage(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRepo.On("List").Return([]*domain.Product{{ID: "p1", Name: "Milk"}}, nil)
+	var stored domain.Image
+	mockRepo.On("AddImage", "p1", mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(domain.Image) }).
+		Return(&domain.Product{ID: "p1", Name: "Milk"}, nil)
+
+	store := media.NewLocalStore(t.TempDir())
+	use := usecase.NewProductUsecase(mockRepo, store)
+	img, err := use.UploadImage("p1", encodePNG(t, 600, 300))
+
+	assert.NoError(t, err)
+	assert.Equal(t, stored, *img)
+	assert.Equal(t, "image/png", img.ContentType)
+	assert.Equal(t, int32(600), img.Width)
+	assert.Equal(t, int32(300), img.Height)
+	assert.Len(t, img.Thumbnails, len(media.ThumbnailSizes))
+
+	// Thumbnails keep the aspect ratio and are never enlarged.
+	for size, want := range map[string]image.Point{
+		"small": {150, 75},
+		"large": {600, 300},
+	} {
+		r, err := store.Get(context.Background(), img.Thumbnails[size])
+		if !assert.NoError(t, err, size) {
+			continue
+		}
+		thumb, err := jpeg.Decode(r)
+		r.Close()
+		assert.NoError(t, err, size)
+		assert.Equal(t, want, thumb.Bounds().Size(), size)
+	}
+}
+
+func TestUploadImage_TooManyPixels(t *testing.T) {
+	// A valid PNG whose header claims 100000×100000 pixels.
+	data := encodePNG(t, 1, 1)
+	binary.BigEndian.PutUint32(data[16:20], 100000)
+	binary.BigEndian.PutUint32(data[20:24], 100000)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+
+	mockRepo := new(MockRepo)
+	mockRepo.On("List").Return([]*domain.Product{{ID: "p1", Name: "Milk"}}, nil)
+
+	use := usecase.NewProductUsecase(mockRepo, media.NewLocalStore(t.TempDir()))
+	_, err := use.UploadImage("p1", data)
+
+	assert.ErrorIs(t, err, media.ErrImageTooLarge)
+	mockRepo.AssertNotCalled(t, "AddImage", mock.Anything, mock.Anything)
+}
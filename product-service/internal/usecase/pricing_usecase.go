@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"product-service/internal/domain"
+	"product-service/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// priceSchedulerLease names the lease that keeps the price scheduler to a
+// single replica.
+const priceSchedulerLease = "price-scheduler"
+
+func (u *productUsecase) SchedulePrice(productID string, schedule domain.PriceSchedule) (*domain.Product, error) {
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+	schedule.ID = primitive.NewObjectID().Hex()
+
+	product, err := u.repo.AddPriceSchedule(productID, schedule)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("🏷️ Scheduled %s price %.2f for product %s from %s", schedule.Kind, schedule.Price, productID, schedule.StartsAt.Format(time.RFC3339))
+
+	// A schedule that has already started takes effect right away instead of
+	// waiting for the next scheduler tick. If the pricing changed meanwhile,
+	// the scheduler applies it on its next run.
+	if err := u.applyPricing(product, time.Now()); err != nil && !errors.Is(err, domain.ErrPricingChanged) {
+		return nil, err
+	}
+	return product, nil
+}
+
+func (u *productUsecase) CancelPriceSchedule(productID, scheduleID string) (*domain.Product, error) {
+	product, err := u.repo.RemovePriceSchedule(productID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("🏷️ Cancelled price schedule %s of product %s", scheduleID, productID)
+
+	if err := u.applyPricing(product, time.Now()); err != nil && !errors.Is(err, domain.ErrPricingChanged) {
+		return nil, err
+	}
+	return product, nil
+}
+
+// ApplyPriceSchedules is one run of the price scheduler.
+func (u *productUsecase) ApplyPriceSchedules(now time.Time) error {
+	products, err := u.repo.ListWithPendingPrices()
+	if err != nil {
+		return err
+	}
+	for _, p := range products {
+		err := u.applyPricing(p, now)
+		switch {
+		case errors.Is(err, domain.ErrPricingChanged):
+			log.Println("🏷️ Pricing of product changed during the run, left for the next one:", p.ID)
+		case err != nil:
+			log.Println("❌ Failed to apply price schedules for product:", p.ID, err)
+		}
+	}
+	return nil
+}
+
+// applyPricing brings the stored prices of p up to date and publishes
+// product.price_changed when the effective price moved. Nothing is stored or
+// published when the stored pricing is no longer the one p was read with.
+func (u *productUsecase) applyPricing(p *domain.Product, now time.Time) error {
+	oldPrice := p.CurrentPrice
+	schedulesChanged := p.ApplyPriceSchedules(now)
+	list, effective := p.ResolvePrice(now)
+
+	if !schedulesChanged && effective == oldPrice {
+		u.cache.UpdateProduct(p)
+		return nil
+	}
+
+	p.CurrentPrice = effective
+	if err := u.repo.UpdatePricing(p); err != nil {
+		return err
+	}
+	u.cache.UpdateProduct(p)
+
+	// Products stored before price schedules existed have no current price
+	// yet; recording it for the first time is not a price change.
+	if oldPrice != 0 && effective != oldPrice {
+		u.publisher.PublishPriceChanged(domain.PriceChangedEvent{
+			ProductID: p.ID,
+			OldPrice:  oldPrice,
+			NewPrice:  effective,
+			ListPrice: list,
+			ChangedAt: now,
+		})
+	}
+	return nil
+}
+
+// RunPriceScheduler applies price schedules every interval until ctx is
+// done, on runs where holder has the scheduler lease. With several replicas
+// only one of them changes prices and publishes the events.
+func (u *productUsecase) RunPriceScheduler(ctx context.Context, lease repository.Lease, holder string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			// The lease outlives two ticks, so a missed renewal does not
+			// hand the scheduler over.
+			held, err := lease.Acquire(priceSchedulerLease, holder, 2*interval)
+			if err != nil {
+				log.Println("❌ Failed to acquire the price scheduler lease:", err)
+				continue
+			}
+			if !held {
+				continue
+			}
+			if err := u.ApplyPriceSchedules(now); err != nil {
+				log.Println("❌ Price scheduler run failed:", err)
+			}
+		}
+	}
+}
@@ -1,30 +1,55 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	natsPublisher "product-service/internal/nats"
 	"time"
 
 	"product-service/internal/cache"
+	"product-service/internal/catalog"
 	"product-service/internal/domain"
+	"product-service/internal/media"
 	"product-service/internal/repository"
 )
 
 type ProductUsecase interface {
 	Create(product *domain.Product) (string, error)
 	GetProductsByCategory(category string) ([]*domain.Product, error)
+	ListBySeller(sellerID string) ([]*domain.Product, error)
 	GetByID(id string) (*domain.Product, error)
+	GetBySKU(skuID string) (*domain.Product, *domain.SKU, error)
 	Delete(id string) error
 	List() ([]*domain.Product, error)
+	ReserveStock(skuID string, quantity int32) (*domain.SKU, error)
+	ReleaseStock(skuID string, quantity int32) (*domain.SKU, error)
+	Import(r io.Reader, format catalog.Format, dryRun bool, caller domain.Caller) (*domain.ImportReport, error)
+	Export(w io.Writer, format catalog.Format, category string) error
+	UploadImage(productID string, data []byte) (*domain.Image, error)
+	OpenImage(productID, imageID, size string) (io.ReadCloser, string, error)
+	DeleteImage(productID, imageID string) ([]domain.Image, error)
+	ReorderImages(productID string, imageIDs []string) ([]domain.Image, error)
+	SchedulePrice(productID string, schedule domain.PriceSchedule) (*domain.Product, error)
+	CancelPriceSchedule(productID, scheduleID string) (*domain.Product, error)
+	ApplyPriceSchedules(now time.Time) error
+	RunPriceScheduler(ctx context.Context, lease repository.Lease, holder string, interval time.Duration)
 }
 
+// importBatchSize is how many created/updated products are grouped into a
+// single NATS event during an import.
+const importBatchSize = 100
+
 type productUsecase struct {
 	repo      repository.ProductRepository
 	cache     *cache.ProductCache
 	publisher *natsPublisher.Publisher
+	media     media.BlobStore
 }
 
-func NewProductUsecase(repo repository.ProductRepository) ProductUsecase {
+func NewProductUsecase(repo repository.ProductRepository, store media.BlobStore) ProductUsecase {
 	c := cache.NewProductCache()
 
 	products, err := repo.List()
@@ -53,10 +78,12 @@ func NewProductUsecase(repo repository.ProductRepository) ProductUsecase {
 		repo:      repo,
 		cache:     c,
 		publisher: p,
+		media:     store,
 	}
 }
 
 func (u *productUsecase) Create(product *domain.Product) (string, error) {
+	product.CurrentPrice = product.Price
 	id, err := u.repo.Create(product)
 	if err != nil {
 		log.Println("❌ Failed to create product:", err)
@@ -74,10 +101,16 @@ func (u *productUsecase) Create(product *domain.Product) (string, error) {
 
 func (u *productUsecase) Delete(id string) error {
 	log.Println("🗑️ Deleting product with ID:", id)
+	product, lookupErr := u.repo.GetByID(id)
 	err := u.repo.Delete(id)
 	if err != nil {
 		return err
 	}
+	if lookupErr == nil {
+		for _, image := range product.Images {
+			u.deleteBlobs(image)
+		}
+	}
 
 	u.publisher.PublishProductDeleted(id)
 
@@ -88,6 +121,10 @@ func (u *productUsecase) GetProductsByCategory(category string) ([]*domain.Produ
 	return u.repo.GetProductsByCategory(category)
 }
 
+func (u *productUsecase) ListBySeller(sellerID string) ([]*domain.Product, error) {
+	return u.repo.GetBySeller(sellerID)
+}
+
 func (u *productUsecase) List() ([]*domain.Product, error) {
 	log.Println("📦 Returning products from cache...")
 	return u.cache.GetAll(), nil
@@ -101,3 +138,188 @@ func (u *productUsecase) GetByID(id string) (*domain.Product, error) {
 	log.Println("🔎 Product not found in cache, fetching from DB:", id)
 	return u.repo.GetByID(id)
 }
+
+func (u *productUsecase) GetBySKU(skuID string) (*domain.Product, *domain.SKU, error) {
+	product, err := u.repo.GetBySKU(skuID)
+	if err != nil {
+		return nil, nil, err
+	}
+	sku, ok := product.FindSKU(skuID)
+	if !ok {
+		return nil, nil, domain.ErrSKUNotFound
+	}
+	return product, sku, nil
+}
+
+func (u *productUsecase) ReserveStock(skuID string, quantity int32) (*domain.SKU, error) {
+	if quantity <= 0 {
+		return nil, errors.New("quantity must be positive")
+	}
+	product, err := u.repo.ReserveStock(skuID, quantity)
+	if err != nil {
+		log.Println("❌ Failed to reserve stock for SKU:", skuID, err)
+		return nil, err
+	}
+	u.cache.UpdateProduct(product)
+	log.Printf("📦 Reserved %d of SKU %s", quantity, skuID)
+
+	sku, ok := product.FindSKU(skuID)
+	if !ok {
+		return nil, domain.ErrSKUNotFound
+	}
+	return sku, nil
+}
+
+func (u *productUsecase) ReleaseStock(skuID string, quantity int32) (*domain.SKU, error) {
+	if quantity <= 0 {
+		return nil, errors.New("quantity must be positive")
+	}
+	product, err := u.repo.ReleaseStock(skuID, quantity)
+	if err != nil {
+		log.Println("❌ Failed to release stock for SKU:", skuID, err)
+		return nil, err
+	}
+	u.cache.UpdateProduct(product)
+	log.Printf("📦 Released %d of SKU %s", quantity, skuID)
+
+	sku, ok := product.FindSKU(skuID)
+	if !ok {
+		return nil, domain.ErrSKUNotFound
+	}
+	if sku.Quantity-quantity <= 0 {
+		u.publisher.PublishRestocked(domain.RestockedEvent{ProductID: product.ID, SKUID: sku.ID, Quantity: sku.Quantity})
+	}
+	return sku, nil
+}
+
+// Import upserts every valid row of the catalog file by its external SKU.
+// Invalid rows are reported and skipped. In dry-run mode nothing is written
+// and no events are published, but the report shows what would happen.
+// New products belong to the caller; only admins may set user_id per row or
+// update products of other sellers. Updates never change a product's owner.
+func (u *productUsecase) Import(r io.Reader, format catalog.Format, dryRun bool, caller domain.Caller) (*domain.ImportReport, error) {
+	report := &domain.ImportReport{DryRun: dryRun}
+	seen := make(map[string]bool)
+
+	var created, updated []*domain.Product
+	flush := func() {
+		u.publisher.PublishProductsCreated(created)
+		u.publisher.PublishProductsUpdated(updated)
+		created, updated = nil, nil
+	}
+
+	fail := func(row catalog.Row, err error) {
+		report.Failed++
+		rowErr := domain.ImportRowError{Row: row.Line, Message: err.Error()}
+		if row.Product != nil {
+			rowErr.ExternalSKU = row.Product.ExternalSKU
+		}
+		report.Errors = append(report.Errors, rowErr)
+	}
+
+	err := catalog.Read(r, format, func(row catalog.Row) error {
+		report.Total++
+		if row.Err != nil {
+			fail(row, row.Err)
+			return nil
+		}
+
+		product := row.Product
+		if product.ExternalSKU == "" {
+			fail(row, errors.New("external_sku is required"))
+			return nil
+		}
+		if err := product.Validate(); err != nil {
+			fail(row, err)
+			return nil
+		}
+
+		existing, err := u.repo.GetByExternalSKU(product.ExternalSKU)
+		if err != nil && !errors.Is(err, domain.ErrProductNotFound) {
+			fail(row, err)
+			return nil
+		}
+		if existing != nil && !existing.CanBeModifiedBy(caller) {
+			fail(row, domain.ErrForbidden)
+			return nil
+		}
+		isNew := existing == nil && !seen[product.ExternalSKU]
+		seen[product.ExternalSKU] = true
+
+		if dryRun {
+			if isNew {
+				report.Created++
+			} else {
+				report.Updated++
+			}
+			return nil
+		}
+
+		if existing == nil {
+			// Only an admin may import products on behalf of a seller.
+			if !caller.IsAdmin() || product.UserID == "" {
+				product.UserID = caller.UserID
+			}
+			product.CurrentPrice = product.Price
+			id, err := u.repo.Create(product)
+			if err != nil {
+				fail(row, err)
+				return nil
+			}
+			product.ID = id
+			u.cache.AddProduct(product)
+			report.Created++
+			created = append(created, product)
+		} else {
+			// Only the fields in the file change. Stock, images and prices
+			// set through other RPCs survive a re-import, SKUs keep their
+			// IDs and the product keeps its owner, even when an admin
+			// re-imports it.
+			merged := *existing
+			merged.ApplyImport(product, row.Fields)
+			if err := u.repo.UpdateCatalog(&merged, row.Fields); err != nil {
+				fail(row, err)
+				return nil
+			}
+			u.cache.UpdateProduct(&merged)
+			report.Updated++
+			updated = append(updated, &merged)
+			for _, restock := range domain.Restocks(existing, &merged) {
+				u.publisher.PublishRestocked(restock)
+			}
+		}
+
+		if len(created)+len(updated) >= importBatchSize {
+			flush()
+		}
+		return nil
+	})
+
+	if !dryRun {
+		flush()
+	}
+	if err != nil {
+		log.Println("❌ Catalog import aborted:", err)
+		return report, fmt.Errorf("import aborted after %d rows: %w", report.Total, err)
+	}
+
+	log.Printf("📥 Catalog import finished: %d rows, %d created, %d updated, %d failed (dry run: %v)",
+		report.Total, report.Created, report.Updated, report.Failed, dryRun)
+	return report, nil
+}
+
+func (u *productUsecase) Export(w io.Writer, format catalog.Format, category string) error {
+	var products []*domain.Product
+	var err error
+	if category != "" {
+		products, err = u.repo.GetProductsByCategory(category)
+	} else {
+		products, err = u.repo.List()
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("📤 Exporting %d products", len(products))
+	return catalog.Write(w, format, products)
+}
@@ -1,9 +1,14 @@
 package usecase_test
 
 import (
+	"context"
+	"strings"
 	"testing"
+	"time"
 
+	"product-service/internal/catalog"
 	"product-service/internal/domain"
+	"product-service/internal/media"
 	"product-service/internal/usecase"
 
 	"github.com/stretchr/testify/assert"
@@ -24,11 +29,78 @@ func (m *MockRepo) GetProductsByCategory(category string) ([]*domain.Product, er
 	return args.Get(0).([]*domain.Product), args.Error(1)
 }
 
+func (m *MockRepo) GetBySeller(sellerID string) ([]*domain.Product, error) {
+	args := m.Called(sellerID)
+	return args.Get(0).([]*domain.Product), args.Error(1)
+}
+
 func (m *MockRepo) GetByID(id string) (*domain.Product, error) {
 	args := m.Called(id)
 	return args.Get(0).(*domain.Product), args.Error(1)
 }
 
+func (m *MockRepo) GetBySKU(skuID string) (*domain.Product, error) {
+	args := m.Called(skuID)
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockRepo) GetByExternalSKU(externalSKU string) (*domain.Product, error) {
+	args := m.Called(externalSKU)
+	p, _ := args.Get(0).(*domain.Product)
+	return p, args.Error(1)
+}
+
+func (m *MockRepo) UpdateCatalog(product *domain.Product, fields []string) error {
+	args := m.Called(product, fields)
+	return args.Error(0)
+}
+
+func (m *MockRepo) ReserveStock(skuID string, quantity int32) (*domain.Product, error) {
+	args := m.Called(skuID, quantity)
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockRepo) ReleaseStock(skuID string, quantity int32) (*domain.Product, error) {
+	args := m.Called(skuID, quantity)
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockRepo) AddImage(productID string, image domain.Image) (*domain.Product, error) {
+	args := m.Called(productID, image)
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockRepo) RemoveImage(productID, imageID string) (*domain.Product, error) {
+	args := m.Called(productID, imageID)
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockRepo) SetImageOrder(productID string, images []domain.Image) (*domain.Product, error) {
+	args := m.Called(productID, images)
+	p, _ := args.Get(0).(*domain.Product)
+	return p, args.Error(1)
+}
+
+func (m *MockRepo) AddPriceSchedule(productID string, schedule domain.PriceSchedule) (*domain.Product, error) {
+	args := m.Called(productID, schedule)
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockRepo) RemovePriceSchedule(productID, scheduleID string) (*domain.Product, error) {
+	args := m.Called(productID, scheduleID)
+	return args.Get(0).(*domain.Product), args.Error(1)
+}
+
+func (m *MockRepo) UpdatePricing(product *domain.Product) error {
+	args := m.Called(product)
+	return args.Error(0)
+}
+
+func (m *MockRepo) ListWithPendingPrices() ([]*domain.Product, error) {
+	args := m.Called()
+	return args.Get(0).([]*domain.Product), args.Error(1)
+}
+
 func (m *MockRepo) Delete(id string) error {
 	args := m.Called(id)
 	return args.Error(0)
@@ -52,7 +124,7 @@ func TestCreateProduct(t *testing.T) {
 	mockRepo.On("List").Return([]*domain.Product{}, nil) // для инициализации кэша
 	mockRepo.On("Create", product).Return("abc123", nil)
 
-	use := usecase.NewProductUsecase(mockRepo)
+	use := usecase.NewProductUsecase(mockRepo, media.NewLocalStore(t.TempDir()))
 	id, err := use.Create(product)
 
 	assert.NoError(t, err)
@@ -63,9 +135,10 @@ func TestCreateProduct(t *testing.T) {
 func TestDeleteProduct(t *testing.T) {
 	mockRepo := new(MockRepo)
 	mockRepo.On("List").Return([]*domain.Product{}, nil)
+	mockRepo.On("GetByID", "del123").Return(&domain.Product{ID: "del123"}, nil)
 	mockRepo.On("Delete", "del123").Return(nil)
 
-	use := usecase.NewProductUsecase(mockRepo)
+	use := usecase.NewProductUsecase(mockRepo, media.NewLocalStore(t.TempDir()))
 	err := use.Delete("del123")
 
 	assert.NoError(t, err)
@@ -78,7 +151,7 @@ func TestGetByID_FromRepo(t *testing.T) {
 	mockRepo.On("List").Return([]*domain.Product{}, nil)
 	mockRepo.On("GetByID", "id123").Return(expected, nil)
 
-	use := usecase.NewProductUsecase(mockRepo)
+	use := usecase.NewProductUsecase(mockRepo, media.NewLocalStore(t.TempDir()))
 	product, err := use.GetByID("id123")
 
 	assert.NoError(t, err)
@@ -93,9 +166,324 @@ func TestListProducts(t *testing.T) {
 	}
 	mockRepo.On("List").Return(expected, nil)
 
-	use := usecase.NewProductUsecase(mockRepo)
+	use := usecase.NewProductUsecase(mockRepo, media.NewLocalStore(t.TempDir()))
 	result, err := use.List()
 
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 }
+
+func TestReserveStock(t *testing.T) {
+	mockRepo := new(MockRepo)
+	updated := &domain.Product{
+		ID:   "p1",
+		Name: "Milk",
+		SKUs: []domain.SKU{
+			{ID: "sku-1l", Size: "1L", Price: 1.2, Quantity: 8},
+			{ID: "sku-2l", Size: "2L", Price: 2.1, Quantity: 5},
+		},
+	}
+	mockRepo.On("List").Return([]*domain.Product{}, nil)
+	mockRepo.On("ReserveStock", "sku-1l", int32(2)).Return(updated, nil)
+
+	use := usecase.NewProductUsecase(mockRepo, media.NewLocalStore(t.TempDir()))
+	sku, err := use.ReserveStock("sku-1l", 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1L", sku.Size)
+	assert.Equal(t, int32(8), sku.Quantity)
+
+	_, err = use.ReserveStock("sku-1l", 0)
+	assert.Error(t, err)
+	mockRepo.AssertNumberOfCalls(t, "ReserveStock", 1)
+}
+
+func TestImportProducts_DryRun(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockRepo.On("List").Return([]*domain.Product{}, nil)
+	mockRepo.On("GetByExternalSKU", "MILK-1").Return(&domain.Product{ID: "p1", ExternalSKU: "MILK-1", UserID: "seller1"}, nil)
+	mockRepo.On("GetByExternalSKU", "BREAD-1").Return(nil, domain.ErrProductNotFound)
+
+	csv := "external_sku,name,category,price,quantity\n" +
+		"MILK-1,Milk,dairy,1.20,10\n" +
+		"BREAD-1,Bread,bakery,0.80,25\n" +
+		"EGGS-1,Eggs,dairy,abc,5\n" +
+		",No SKU,misc,1,1\n"
+
+	use := usecase.NewProductUsecase(mockRepo, media.NewLocalStore(t.TempDir()))
+	report, err := use.Import(strings.NewReader(csv), catalog.CSV, true, domain.Caller{UserID: "seller1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), report.Total)
+	assert.Equal(t, int32(1), report.Created)
+	assert.Equal(t, int32(1), report.Updated)
+	assert.Equal(t, int32(2), report.Failed)
+	assert.Equal(t, 4, report.Errors[0].Row)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	mockRepo.AssertNotCalled(t, "UpdateCatalog", mock.Anything, mock.Anything)
+}
+
+func TestImportProducts_UpdatesExisting(t *testing.T) {
+	existing := func() *domain.Product {
+		return &domain.Product{
+			ID:           "p1",
+			ExternalSKU:  "MILK-1",
+			Name:         "Milk",
+			Category:     "dairy",
+			Price:        1.20,
+			CurrentPrice: 1.20,
+			Quantity:     40,
+			UserID:       "seller1",
+			SKUs: []domain.SKU{
+				{ID: "sku-1l", Size: "1L", Price: 1.20, Quantity: 30},
+				{ID: "sku-2l", Size: "2L", Price: 2.10, Quantity: 0},
+			},
+			Images: []domain.Image{{ID: "img1"}},
+		}
+	}
+	caller := domain.Caller{UserID: "seller1"}
+
+	t.Run("csv keeps skus and unlisted columns", func(t *testing.T) {
+		var stored *domain.Product
+		mockRepo := new(MockRepo)
+		mockRepo.On("List").Return([]*domain.Product{}, nil)
+		mockRepo.On("GetByExternalSKU", "MILK-1").Return(existing(), nil)
+		mockRepo.On("UpdateCatalog", mock.Anything, []string{"name", "price"}).
+			Run(func(args mock.Arguments) { stored = args.Get(0).(*domain.Product) }).
+			Return(nil)
+
+		use := usecase.NewProductUsecase(mockRepo, media.NewLocalStore(t.TempDir()))
+		report, err := use.Import(strings.NewReader("external_sku,name,price\nMILK-1,Whole Milk,1.35\n"), catalog.CSV, false, caller)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int32(1), report.Updated)
+		assert.Equal(t, "Whole Milk", stored.Name)
+		assert.Equal(t, 1.35, stored.Price)
+		assert.Equal(t, "dairy", stored.Category)
+		assert.Equal(t, int32(40), stored.Quantity)
+		assert.Equal(t, existing().SKUs, stored.SKUs)
+		assert.Equal(t, existing().Images, stored.Images)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("jsonl merges skus", func(t *testing.T) {
+		var stored *domain.Product
+		mockRepo := new(MockRepo)
+		mockRepo.On("List").Return([]*domain.Product{}, nil)
+		mockRepo.On("GetByExternalSKU", "MILK-1").Return(existing(), nil)
+		mockRepo.On("UpdateCatalog", mock.Anything, []string{"name", "skus"}).
+			Run(func(args mock.Arguments) { stored = args.Get(0).(*domain.Product) }).
+			Return(nil)
+
+		jsonl := `{"external_sku":"MILK-1","name":"Milk","skus":[{"size":"2L","price":2.2,"quantity":12},{"size":"5L","price":4.9,"quantity":3}]}` + "\n"
+		use := usecase.NewProductUsecase(mockRepo, media.NewLocalStore(t.TempDir()))
+		report, err := use.Import(strings.NewReader(jsonl), catalog.JSONL, false, caller)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int32(1), report.Updated)
+		assert.Equal(t, int32(40), stored.Quantity)
+		assert.Equal(t, []domain.SKU{
+			{ID: "sku-1l", Size: "1L", Price: 1.20, Quantity: 30},
+			{ID: "sku-2l", Size: "2L", Price: 2.2, Quantity: 12},
+			{Size: "5L", Price: 4.9, Quantity: 3},
+		}, stored.SKUs)
+	})
+}
+
+func TestImportProducts_Ownership(t *testing.T) {
+	csv := "external_sku,name,user_id\nMILK-1,Whole Milk,seller2\n"
+	tests := []struct {
+		name    string
+		caller  domain.Caller
+		updated bool
+	}{
+		{"owner", domain.Caller{UserID: "seller1"}, true},
+		{"non-owner", domain.Caller{UserID: "seller2"}, false},
+		{"admin", domain.Caller{UserID: "admin1", Roles: []string{domain.RoleAdmin}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored *domain.Product
+			mockRepo := new(MockRepo)
+			mockRepo.On("List").Return([]*domain.Product{}, nil)
+			mockRepo.On("GetByExternalSKU", "MILK-1").
+				Return(&domain.Product{ID: "p1", ExternalSKU: "MILK-1", Name: "Milk", UserID: "seller1"}, nil)
+			mockRepo.On("UpdateCatalog", mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) { stored = args.Get(0).(*domain.Product) }).
+				Return(nil)
+
+			use := usecase.NewProductUsecase(mockRepo, media.NewLocalStore(t.TempDir()))
+			report, err := use.Import(strings.NewReader(csv), catalog.CSV, false, tt.caller)
+
+			assert.NoError(t, err)
+			if !tt.updated {
+				assert.Equal(t, int32(1), report.Failed)
+				assert.Equal(t, domain.ErrForbidden.Error(), report.Errors[0].Message)
+				mockRepo.AssertNotCalled(t, "UpdateCatalog", mock.Anything, mock.Anything)
+				return
+			}
+			assert.Equal(t, int32(1), report.Updated)
+			assert.Equal(t, "Whole Milk", stored.Name)
+			assert.Equal(t, "seller1", stored.UserID)
+		})
+	}
+}
+
+func TestImportProducts_NewProductOwner(t *testing.T) {
+	csv := "external_sku,name,price,user_id\nMILK-1,Milk,1.20,seller2\n"
+	tests := []struct {
+		name   string
+		caller domain.Caller
+		owner  string
+	}{
+		{"seller imports for themselves", domain.Caller{UserID: "seller1"}, "seller1"},
+		{"admin imports for a seller", domain.Caller{UserID: "admin1", Roles: []string{domain.RoleAdmin}}, "seller2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created *domain.Product
+			mockRepo := new(MockRepo)
+			mockRepo.On("List").Return([]*domain.Product{}, nil)
+			mockRepo.On("GetByExternalSKU", "MILK-1").Return(nil, domain.ErrProductNotFound)
+			mockRepo.On("Create", mock.Anything).
+				Run(func(args mock.Arguments) { created = args.Get(0).(*domain.Product) }).
+				Return("p1", nil)
+
+			use := usecase.NewProductUsecase(mockRepo, media.NewLocalStore(t.TempDir()))
+			report, err := use.Import(strings.NewReader(csv), catalog.CSV, false, tt.caller)
+
+			assert.NoError(t, err)
+			assert.Equal(t, int32(1), report.Created)
+			assert.Equal(t, tt.owner, created.UserID)
+		})
+	}
+}
+
+func TestReorderImages(t *testing.T) {
+	mockRepo := new(MockRepo)
+	product := &domain.Product{
+		ID:     "p1",
+		Images: []domain.Image{{ID: "a"}, {ID: "b"}, {ID: "c"}},
+	}
+	reordered := []domain.Image{{ID: "c"}, {ID: "a"}, {ID: "b"}}
+	mockRepo.On("List").Return([]*domain.Product{}, nil)
+	mockRepo.On("GetByID", "p1").Return(product, nil)
+	mockRepo.On("SetImageOrder", "p1", reordered).Return(&domain.Product{ID: "p1", Images: reordered}, nil)
+
+	use := usecase.NewProductUsecase(mockRepo, media.NewLocalStore(t.TempDir()))
+	images, err := use.ReorderImages("p1", []string{"c", "a", "b"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, reordered, images)
+
+	_, err = use.ReorderImages("p1", []string{"c", "c", "b"})
+	assert.Error(t, err)
+	mockRepo.AssertNumberOfCalls(t, "SetImageOrder", 1)
+}
+
+func TestApplyPriceSchedules(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	product := &domain.Product{
+		ID:           "p1",
+		Price:        2.0,
+		CurrentPrice: 2.0,
+		PriceSchedules: []domain.PriceSchedule{
+			{ID: "sale", Kind: domain.PriceSale, Price: 1.5, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)},
+			{ID: "raise", Kind: domain.PriceRegular, Price: 2.5, StartsAt: now.Add(24 * time.Hour)},
+		},
+	}
+	mockRepo := new(MockRepo)
+	mockRepo.On("List").Return([]*domain.Product{}, nil)
+	mockRepo.On("ListWithPendingPrices").Return([]*domain.Product{product}, nil)
+	mockRepo.On("UpdatePricing", product).Return(nil)
+
+	use := usecase.NewProductUsecase(mockRepo, media.NewLocalStore(t.TempDir()))
+	err := use.ApplyPriceSchedules(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, product.CurrentPrice)
+	assert.Equal(t, 2.0, product.Price)
+	assert.Len(t, product.PriceSchedules, 2)
+
+	// After the sale ends and the regular price change starts only the new
+	// list price is left.
+	err = use.ApplyPriceSchedules(now.Add(25 * time.Hour))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2.5, product.Price)
+	assert.Equal(t, 2.5, product.CurrentPrice)
+	assert.Empty(t, product.PriceSchedules)
+	mockRepo.AssertNumberOfCalls(t, "UpdatePricing", 2)
+}
+
+func TestApplyPriceSchedules_PricingChanged(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	product := &domain.Product{
+		ID:           "p1",
+		Price:        2.0,
+		CurrentPrice: 2.0,
+		PriceSchedules: []domain.PriceSchedule{
+			{ID: "sale", Kind: domain.PriceSale, Price: 1.5, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)},
+		},
+	}
+	mockRepo := new(MockRepo)
+	mockRepo.On("List").Return([]*domain.Product{{ID: "p1", Price: 2.0, CurrentPrice: 2.0}}, nil)
+	mockRepo.On("ListWithPendingPrices").Return([]*domain.Product{product}, nil)
+	mockRepo.On("UpdatePricing", product).Return(domain.ErrPricingChanged)
+
+	use := usecase.NewProductUsecase(mockRepo, media.NewLocalStore(t.TempDir()))
+	err := use.ApplyPriceSchedules(now)
+
+	// The run goes on, and the cache keeps the price that is stored.
+	assert.NoError(t, err)
+	cached, err := use.GetByID("p1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, cached.CurrentPrice)
+}
+
+type fakeLease struct {
+	held bool
+}
+
+func (f *fakeLease) Acquire(name, holder string, ttl time.Duration) (bool, error) {
+	return f.held, nil
+}
+
+func TestRunPriceScheduler(t *testing.T) {
+	for _, held := range []bool{false, true} {
+		mockRepo := new(MockRepo)
+		mockRepo.On("List").Return([]*domain.Product{}, nil)
+		mockRepo.On("ListWithPendingPrices").Return([]*domain.Product{}, nil)
+
+		use := usecase.NewProductUsecase(mockRepo, media.NewLocalStore(t.TempDir()))
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		use.RunPriceScheduler(ctx, &fakeLease{held: held}, "replica-1", 10*time.Millisecond)
+		cancel()
+
+		if held {
+			mockRepo.AssertCalled(t, "ListWithPendingPrices")
+		} else {
+			mockRepo.AssertNotCalled(t, "ListWithPendingPrices")
+		}
+	}
+}
+
+func TestRestocks(t *testing.T) {
+	before := &domain.Product{ID: "p1", Quantity: 0, SKUs: []domain.SKU{
+		{ID: "s1", Size: "1L", Quantity: 0},
+		{ID: "s2", Size: "2L", Quantity: 0},
+		{ID: "s3", Size: "5L", Quantity: 4},
+	}}
+	// Re-imported SKUs come back with new IDs.
+	after := &domain.Product{ID: "p1", Quantity: 7, SKUs: []domain.SKU{
+		{ID: "s1", Size: "1L", Quantity: 3},
+		{ID: "n2", Size: "2L", Quantity: 0},
+		{ID: "n3", Size: "5L", Quantity: 9},
+		{ID: "n4", Size: "10L", Quantity: 1},
+	}}
+
+	assert.Equal(t, []domain.RestockedEvent{
+		{ProductID: "p1", Quantity: 7},
+		{ProductID: "p1", SKUID: "s1", Quantity: 3},
+	}, domain.Restocks(before, after))
+}
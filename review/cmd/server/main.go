@@ -7,10 +7,12 @@ import (
 	"os/signal"
 	"syscall"
 
+	"meaningfullname/Yurt_Mart/review/internal/events"
 	"meaningfullname/Yurt_Mart/review/internal/repository"
 	"meaningfullname/Yurt_Mart/review/internal/service"
 	"meaningfullname/Yurt_Mart/review/proto"
 
+	"github.com/nats-io/nats.go"
 	"google.golang.org/grpc"
 )
 
@@ -23,6 +25,21 @@ func main() {
 	repo := repository.NewReviewRepository()
 	reviewService := service.NewReviewService(repo)
 
+	natsURL := os.Getenv("NATS_URL")
+	if natsURL == "" {
+		natsURL = nats.DefaultURL
+	}
+	nc, err := nats.Connect(natsURL)
+	if err != nil {
+		log.Fatalf("failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	// Reviews are exported and erased with the rest of a user's data.
+	if err := events.NewUserDataSubscriber(nc, repo).Subscribe(); err != nil {
+		log.Fatalf("failed to subscribe to user data requests: %v", err)
+	}
+
 	srv := grpc.NewServer()
 	proto.RegisterReviewServiceServer(srv, reviewService)
 
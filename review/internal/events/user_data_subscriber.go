@@ -0,0 +1,36 @@
+package events
+
+import (
+	"context"
+
+	"meaningfullname/Yurt_Mart/common/userdata"
+	"meaningfullname/Yurt_Mart/review/internal/repository"
+
+	"github.com/nats-io/nats.go"
+)
+
+// ServiceName is how review-service names itself to user-service.
+const ServiceName = "review-service"
+
+// NewUserDataSubscriber exports and erases the reviews of a user when
+// user-service asks for it.
+func NewUserDataSubscriber(nc *nats.Conn, repo repository.ReviewRepository) *userdata.Subscriber {
+	return userdata.NewSubscriber(nc, ServiceName, reviewData{repo: repo})
+}
+
+// reviewData is the reviews of users, as exported and erased for user-service.
+type reviewData struct {
+	repo repository.ReviewRepository
+}
+
+func (d reviewData) Export(ctx context.Context, userID string) (interface{}, error) {
+	reviews, err := d.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"reviews": reviews}, nil
+}
+
+func (d reviewData) Erase(ctx context.Context, userID string) (int64, error) {
+	return d.repo.DeleteByUserID(ctx, userID)
+}
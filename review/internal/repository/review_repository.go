@@ -18,6 +18,9 @@ type ReviewRepository interface {
 	Update(ctx context.Context, review *model.Review) error
 	Delete(ctx context.Context, id string) error
 	GetAverageRating(ctx context.Context, productID string) (float64, error)
+	// DeleteByUserID removes every review of the user for good, including
+	// soft-deleted ones, and returns how many there were.
+	DeleteByUserID(ctx context.Context, userID string) (int64, error)
 }
 
 type reviewRepository struct {
@@ -70,6 +73,11 @@ func (r *reviewRepository) Delete(ctx context.Context, id string) error {
 	return r.db.WithContext(ctx).Delete(&model.Review{}, "id = ?", id).Error
 }
 
+func (r *reviewRepository) DeleteByUserID(ctx context.Context, userID string) (int64, error) {
+	result := r.db.WithContext(ctx).Unscoped().Where("user_id = ?", userID).Delete(&model.Review{})
+	return result.RowsAffected, result.Error
+}
+
 func (r *reviewRepository) GetAverageRating(ctx context.Context, productID string) (float64, error) {
 	var avg float64
 	err := r.db.WithContext(ctx).Model(&model.Review{}).
@@ -1,16 +1,26 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net"
+	"net/http"
+	"os"
+	"time"
 
 	"shopping-cart-service/database"
 	"shopping-cart-service/events"
+	"shopping-cart-service/internal/cache"
+	"shopping-cart-service/internal/catalog"
 	"shopping-cart-service/internal/handler"
+	"shopping-cart-service/internal/model"
+	"shopping-cart-service/internal/notification"
 	"shopping-cart-service/internal/repository"
 	"shopping-cart-service/internal/service"
 	pb "shopping-cart-service/shopping-cart-service/proto/cartpb"
 
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
 )
 
@@ -19,13 +29,105 @@ func main() {
 	defer client.Disconnect(nil)
 
 	events.InitNATS("nats://localhost:4222")
-	events.SubscribeToCartEvents()
 
 	db := client.Database("shop")
 	repo := repository.NewCartRepository(db)
-	svc := service.NewCartService(repo)
+	if err := repo.MigrateLegacyItems(context.Background()); err != nil {
+		log.Fatalf("Failed to migrate legacy carts: %v", err)
+	}
+
+	productConn, err := grpc.Dial("localhost:50051", grpc.WithInsecure())
+	if err != nil {
+		log.Fatalf("Failed to connect to product-service: %v", err)
+	}
+	defer productConn.Close()
+
+	userAddr := os.Getenv("USER_SERVICE_ADDR")
+	if userAddr == "" {
+		userAddr = "localhost:50053"
+	}
+	userConn, err := grpc.Dial(userAddr, grpc.WithInsecure())
+	if err != nil {
+		log.Fatalf("Failed to connect to user-service: %v", err)
+	}
+	defer userConn.Close()
+
+	smtpConfig := notification.SMTPConfigFromEnv()
+	if !smtpConfig.Enabled() {
+		log.Println("[NOTIFY] SMTP_HOST is not set, cart emails are disabled")
+	}
+	notifier := notification.NewConsumer(notification.NewGRPCUserDirectory(userConn), notification.NewSMTPMailer(smtpConfig))
+	events.SubscribeToCartItemAdded(notifier.HandleCartItemAdded)
+
+	promotions := repository.NewPromotionRepository(db)
+	svc := service.NewCartService(repo, catalog.NewGRPCProductCatalog(productConn), newCartCache(), promotions)
+	events.RespondToCartInfoRequests(func(cartID string) (events.CartInfo, error) {
+		cart, err := svc.GetPricedCart(context.Background(), cartID)
+		if err != nil {
+			return events.CartInfo{}, err
+		}
+		return events.NewCartInfo(cart), nil
+	})
+	events.RespondToPromotionRedeemRequests(
+		func(req events.PromotionRedeemRequest) (events.CartInfo, error) {
+			cart, err := svc.RedeemPromotions(context.Background(), req.CartID, req.OrderID)
+			if err != nil {
+				return events.CartInfo{}, err
+			}
+			return events.NewCartInfo(cart), nil
+		},
+		func(req events.PromotionRedeemRequest) {
+			if err := svc.ReleasePromotions(context.Background(), req.OrderID); err != nil {
+				log.Printf("[PRICING] Could not release promotions of order %s: %v", req.OrderID, err)
+			}
+		},
+	)
+	events.RespondToCartValidateRequests(func(cartID string) (events.CartValidation, error) {
+		issues, err := svc.ValidateCart(context.Background(), cartID, false)
+		if err != nil {
+			return events.CartValidation{}, err
+		}
+		result := events.CartValidation{CartID: cartID, Valid: !model.HasUnresolvedIssues(issues)}
+		for _, issue := range issues {
+			result.Issues = append(result.Issues, events.CartValidationIssue{
+				ProductID: issue.ProductID,
+				SKUID:     issue.SKUID,
+				Kind:      issue.Kind.String(),
+			})
+		}
+		return result, nil
+	})
+	go svc.RunAbandonedCartScheduler(
+		envDuration("ABANDONED_CART_CHECK_INTERVAL", 15*time.Minute),
+		envDuration("ABANDONED_CART_AFTER", 24*time.Hour),
+		publishAbandonedCart,
+	)
 	h := handler.NewCartHandler(svc)
 
+	wishlistRepo := repository.NewWishlistRepository(db)
+	wishlists := service.NewWishlistService(wishlistRepo, svc)
+	events.SubscribeToRestocks(func(restock events.ProductRestocked) {
+		notifyBackInStock(wishlists, restock)
+	})
+
+	userData := service.NewUserDataService(svc, wishlistRepo, promotions)
+	events.RespondToUserExportRequests(func(ctx context.Context, userID string) (interface{}, error) {
+		return userData.Export(ctx, userID)
+	})
+	events.SubscribeToUserDeleted(userData.Erase)
+
+	metricsPort := os.Getenv("METRICS_PORT")
+	if metricsPort == "" {
+		metricsPort = "9092"
+	}
+	go func() {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/metrics", promhttp.Handler())
+		if err := http.ListenAndServe(":"+metricsPort, metricsMux); err != nil {
+			log.Printf("Failed to start metrics server: %v", err)
+		}
+	}()
+
 	lis, err := net.Listen("tcp", ":50052")
 	if err != nil {
 		log.Fatalf("Failed to listen: %v", err)
@@ -33,9 +135,95 @@ func main() {
 
 	s := grpc.NewServer()
 	pb.RegisterCartServiceServer(s, h)
+	pb.RegisterWishlistServiceServer(s, handler.NewWishlistHandler(wishlists, h))
+	pb.RegisterPromotionServiceServer(s, handler.NewPromotionHandler(service.NewPromotionService(promotions)))
 
 	log.Println("Shopping Cart Service is running on :50052")
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("Failed to serve: %v", err)
 	}
 }
+
+// newCartCache uses Redis behind the in-memory cache when Redis is reachable
+// and falls back to the in-memory cache alone otherwise, as before.
+func newCartCache() cache.CartCache {
+	l1 := cache.NewMemoryCache(envDuration("CART_CACHE_L1_TTL", 30*time.Second))
+
+	addr := os.Getenv("REDIS_ADDRESS")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Printf("[CACHE] Redis at %s unavailable, using in-memory cache only: %v", addr, err)
+		client.Close()
+		return cache.NewInstrumented(l1, cache.LayerL1)
+	}
+
+	log.Printf("[CACHE] Using Redis at %s", addr)
+	l2 := cache.NewRedisCache(client, envDuration("CART_CACHE_TTL", 10*time.Minute))
+	return cache.NewLayeredCache(context.Background(), l1, l2)
+}
+
+// notifyBackInStock publishes one wishlist.item.back_in_stock event per user
+// who has the restocked product on a list.
+func notifyBackInStock(wishlists *service.WishlistService, restock events.ProductRestocked) {
+	lists, err := wishlists.ListsWaitingFor(context.Background(), restock.ProductID, restock.SKUID)
+	if err != nil {
+		log.Printf("[WISHLIST] Could not look up lists for product_id=%s: %v", restock.ProductID, err)
+		return
+	}
+
+	byUser := make(map[string]*events.BackInStockEvent)
+	var order []string
+	for _, list := range lists {
+		event, found := byUser[list.UserID]
+		if !found {
+			event = &events.BackInStockEvent{
+				UserID:    list.UserID,
+				ProductID: restock.ProductID,
+				SKUID:     restock.SKUID,
+				Quantity:  restock.Quantity,
+			}
+			byUser[list.UserID] = event
+			order = append(order, list.UserID)
+		}
+		event.Lists = append(event.Lists, events.WishlistRef{ID: list.ID, Name: list.Name})
+	}
+	for _, userID := range order {
+		events.PublishBackInStock(*byUser[userID])
+	}
+}
+
+func publishAbandonedCart(c model.AbandonedCart) error {
+	event := events.CartAbandonedEvent{
+		UserID:         c.Cart.UserID,
+		Total:          c.Cart.Total,
+		Currency:       c.Cart.Currency,
+		LastActivityAt: c.LastActivity,
+	}
+	for _, item := range c.Cart.Items {
+		event.Items = append(event.Items, events.CartAbandonedItem{
+			ProductID: item.ProductID,
+			SKUID:     item.SKUID,
+			Name:      item.Name,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			LineTotal: item.LineTotal,
+		})
+	}
+	return events.PublishCartAbandoned(event)
+}
+
+// envDuration reads a duration such as "24h" from the environment.
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if v, ok := os.LookupEnv(key); ok {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+		log.Printf("Ignoring invalid %s=%q, using %s", key, v, fallback)
+	}
+	return fallback
+}
@@ -0,0 +1,78 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"shopping-cart-service/internal/model"
+
+	"github.com/nats-io/nats.go"
+)
+
+// CartUpdatedSubject is captured by the CARTS stream, so consumers can
+// replay it to build their own view of carts.
+const CartUpdatedSubject = "cart.updated"
+
+// CartUpdatedEvent is the whole cart as it is after a change. Versions of a
+// cart go up by one with every change; a consumer that has seen a version
+// can drop any event at or below it.
+type CartUpdatedEvent struct {
+	CartID    string            `json:"cart_id"`
+	Version   int64             `json:"version"`
+	Change    model.CartChange  `json:"change"`
+	IsGuest   bool              `json:"is_guest"`
+	Items     []CartUpdatedItem `json:"items"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+type CartUpdatedItem struct {
+	ProductID  string    `json:"product_id"`
+	SKUID      string    `json:"sku_id,omitempty"`
+	Quantity   int32     `json:"quantity"`
+	AddedPrice float64   `json:"added_price,omitempty"`
+	AddedAt    time.Time `json:"added_at"`
+}
+
+func NewCartUpdatedEvent(cart *model.Cart, change model.CartChange) CartUpdatedEvent {
+	event := CartUpdatedEvent{
+		CartID:    cart.ID,
+		Version:   cart.Version,
+		Change:    change,
+		IsGuest:   model.IsGuestCart(cart.ID),
+		Items:     make([]CartUpdatedItem, 0, len(cart.Items)),
+		UpdatedAt: cart.UpdatedAt,
+	}
+	for _, item := range cart.Items {
+		event.Items = append(event.Items, CartUpdatedItem{
+			ProductID:  item.ProductID,
+			SKUID:      item.SKUID,
+			Quantity:   item.Quantity,
+			AddedPrice: item.AddedPrice,
+			AddedAt:    item.AddedAt,
+		})
+	}
+	return event
+}
+
+// PublishCartUpdated publishes event on cart.updated and waits for the
+// stream to store it. The message ID is the cart ID and version, so a
+// retried publish is stored once.
+func PublishCartUpdated(event CartUpdatedEvent) error {
+	if jetStream == nil {
+		return fmt.Errorf("jetstream not initialized")
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msgID := fmt.Sprintf("%s:%d", event.CartID, event.Version)
+	if _, err := jetStream.Publish(CartUpdatedSubject, data, nats.MsgId(msgID)); err != nil {
+		return err
+	}
+	log.Printf("[NATS] Published cart.updated: cart_id=%s, version=%d, change=%s", event.CartID, event.Version, event.Change)
+	return nil
+}
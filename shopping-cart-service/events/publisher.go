@@ -1,15 +1,32 @@
 package events
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"time"
 
+	"shopping-cart-service/internal/model"
+
 	"github.com/nats-io/nats.go"
 )
 
-var natsConn *nats.Conn
+// CARTS stream limits. The stream holds cart contents, which are personal
+// data and of no use to consumers after a day. Its subjects are not per
+// user, so after an erasure the user's cart events are not purged but age
+// out within cartsStreamMaxAge.
+const (
+	cartsStreamMaxAge   = 24 * time.Hour
+	cartsStreamMaxBytes = 256 << 20
+)
+
+var (
+	natsConn *nats.Conn
+	// jetStream is nil when the server has no JetStream; cart.updated is
+	// then not published.
+	jetStream nats.JetStreamContext
+)
 
 func InitNATS(url string) {
 	var err error
@@ -18,12 +35,40 @@ func InitNATS(url string) {
 		log.Fatalf("[NATS] Failed to connect: %v", err)
 	}
 	log.Println("[NATS] Connected")
+
+	js, err := natsConn.JetStream()
+	if err != nil {
+		log.Printf("[NATS] JetStream unavailable, cart.updated is not published: %v", err)
+		return
+	}
+	// Same stream as order-service declares, so whichever starts first
+	// creates it. A stream created before it had limits gets them here.
+	stream := &nats.StreamConfig{
+		Name:     "CARTS",
+		Subjects: []string{"cart.*"},
+		MaxAge:   cartsStreamMaxAge,
+		MaxBytes: cartsStreamMaxBytes,
+	}
+	_, err = js.AddStream(stream)
+	if err == nats.ErrStreamNameAlreadyInUse {
+		if _, err := js.UpdateStream(stream); err != nil {
+			log.Printf("[NATS] Could not apply the CARTS stream limits: %v", err)
+		}
+		err = nil
+	}
+	if err != nil {
+		log.Printf("[NATS] Could not add CARTS stream, cart.updated is not published: %v", err)
+		return
+	}
+	jetStream = js
 }
 
+// CartItemAddedEvent is published on cart.item.added. UserID is the cart ID;
+// consumers resolve the owner's contact details themselves.
 type CartItemAddedEvent struct {
 	UserID    string `json:"user_id"`
-	Email     string `json:"email"`
 	ProductID string `json:"product_id"`
+	SKUID     string `json:"sku_id"`
 	Quantity  int32  `json:"quantity"`
 }
 
@@ -47,7 +92,8 @@ func PublishCartItemAdded(event CartItemAddedEvent) {
 	}
 }
 
-func SubscribeToCartEvents() {
+// SubscribeToCartItemAdded passes every cart.item.added event to handle.
+func SubscribeToCartItemAdded(handle func(ctx context.Context, event CartItemAddedEvent) error) {
 	if natsConn == nil {
 		log.Println("[NATS] Not initialized")
 		return
@@ -60,16 +106,282 @@ func SubscribeToCartEvents() {
 			return
 		}
 
-		// Используем user_id как email
-		toEmail := event.UserID
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := handle(ctx, event); err != nil {
+			log.Printf("[NATS] cart.item.added handling failed for user_id=%s: %v", event.UserID, err)
+		}
+	})
+	if err != nil {
+		log.Println("Failed to subscribe to cart.item.added:", err)
+	}
+}
+
+// CartInfo is the cart summary order-service reads from cart.info and
+// requests on cart.info.get. The cart ID is either a user ID or a guest cart
+// ID ("guest:<token>").
+type CartInfo struct {
+	CartID     string         `json:"cart_id"`
+	TotalPrice float64        `json:"total_price"`
+	Currency   string         `json:"currency"`
+	IsGuest    bool           `json:"is_guest"`
+	Subtotal   float64        `json:"subtotal"`
+	Discount   float64        `json:"discount"`
+	Tax        float64        `json:"tax"`
+	Discounts  []CartDiscount `json:"discounts,omitempty"`
+	Items      []CartInfoItem `json:"items,omitempty"`
+}
 
-		body := "You add product " + event.ProductID +
-			" (x" + fmt.Sprint(event.Quantity) + ") to card."
+// CartInfoItem is one available line of the cart, which order-service turns
+// into an order item and reserves stock for.
+type CartInfoItem struct {
+	ProductID string  `json:"product_id"`
+	SKUID     string  `json:"sku_id,omitempty"`
+	Name      string  `json:"name"`
+	Quantity  int32   `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	LineTotal float64 `json:"line_total"`
+}
 
-		SendEmailSMTP(toEmail, "🛒 Cart updated", body)
+// CartDiscount is one line of CartInfo's discount breakdown.
+type CartDiscount struct {
+	Source      string  `json:"source"`
+	PromotionID string  `json:"promotion_id,omitempty"`
+	Code        string  `json:"code,omitempty"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
+
+// NewCartInfo summarises a priced cart for order-service.
+func NewCartInfo(cart *model.PricedCart) CartInfo {
+	info := CartInfo{
+		CartID:     cart.UserID,
+		TotalPrice: cart.Total,
+		Currency:   cart.Currency,
+		IsGuest:    model.IsGuestCart(cart.UserID),
+		Subtotal:   cart.Subtotal,
+		Discount:   cart.Discount,
+		Tax:        cart.Tax,
+	}
+	for _, d := range cart.Discounts {
+		info.Discounts = append(info.Discounts, CartDiscount{
+			Source:      d.Source,
+			PromotionID: d.PromotionID,
+			Code:        d.Code,
+			Description: d.Description,
+			Amount:      d.Amount,
+		})
+	}
+	for _, item := range cart.Items {
+		if !item.Available {
+			continue
+		}
+		info.Items = append(info.Items, CartInfoItem{
+			ProductID: item.ProductID,
+			SKUID:     item.SKUID,
+			Name:      item.Name,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			LineTotal: item.LineTotal,
+		})
+	}
+	return info
+}
 
+func PublishCartInfo(info CartInfo) {
+	if natsConn == nil {
+		log.Println("[NATS] Not initialized")
+		return
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		log.Printf("[NATS] Marshal error: %v", err)
+		return
+	}
+
+	if err := natsConn.Publish("cart.info", data); err != nil {
+		log.Printf("[NATS] Publish error: %v", err)
+	}
+}
+
+// RespondToCartInfoRequests answers cart.info.get requests, whose payload is
+// the bare cart ID, with the CartInfo built by lookup.
+func RespondToCartInfoRequests(lookup func(cartID string) (CartInfo, error)) {
+	if natsConn == nil {
+		log.Println("[NATS] Not initialized")
+		return
+	}
+
+	_, err := natsConn.Subscribe("cart.info.get", func(m *nats.Msg) {
+		cartID := string(m.Data)
+		info, err := lookup(cartID)
+		if err != nil {
+			log.Printf("[NATS] cart.info.get failed for cart_id=%s: %v", cartID, err)
+			return
+		}
+
+		data, err := json.Marshal(info)
+		if err != nil {
+			log.Printf("[NATS] Marshal error: %v", err)
+			return
+		}
+		if err := m.Respond(data); err != nil {
+			log.Printf("[NATS] Respond error: %v", err)
+		}
 	})
 	if err != nil {
-		log.Println("Failed to subscribe to cart.item.added:", err)
+		log.Println("Failed to subscribe to cart.info.get:", err)
+	}
+}
+
+// CartValidation answers cart.validation.get requests. order-service refuses
+// checkout unless Valid is set. Error is set when the cart could not be
+// validated at all.
+type CartValidation struct {
+	CartID string                `json:"cart_id"`
+	Valid  bool                  `json:"valid"`
+	Issues []CartValidationIssue `json:"issues,omitempty"`
+	Error  string                `json:"error,omitempty"`
+}
+
+type CartValidationIssue struct {
+	ProductID string `json:"product_id"`
+	SKUID     string `json:"sku_id,omitempty"`
+	Kind      string `json:"kind"`
+}
+
+// RespondToCartValidateRequests answers cart.validation.get requests, whose
+// payload is the bare cart ID, with the CartValidation built by validate, or
+// one carrying the error so the requester does not wait for a timeout.
+func RespondToCartValidateRequests(validate func(cartID string) (CartValidation, error)) {
+	if natsConn == nil {
+		log.Println("[NATS] Not initialized")
+		return
+	}
+
+	_, err := natsConn.Subscribe("cart.validation.get", func(m *nats.Msg) {
+		cartID := string(m.Data)
+		result, err := validate(cartID)
+		if err != nil {
+			log.Printf("[NATS] cart.validation.get failed for cart_id=%s: %v", cartID, err)
+			result = CartValidation{CartID: cartID, Error: err.Error()}
+		}
+
+		data, err := json.Marshal(result)
+		if err != nil {
+			log.Printf("[NATS] Marshal error: %v", err)
+			return
+		}
+		if err := m.Respond(data); err != nil {
+			log.Printf("[NATS] Respond error: %v", err)
+		}
+	})
+	if err != nil {
+		log.Println("Failed to subscribe to cart.validation.get:", err)
+	}
+}
+
+// PromotionRedeemRequest is the payload of cart.promotions.redeem requests,
+// sent by order-service while it places an order, and of
+// cart.promotions.release messages, sent when the order is not placed after
+// all.
+type PromotionRedeemRequest struct {
+	CartID  string `json:"cart_id"`
+	OrderID string `json:"order_id"`
+}
+
+// PromotionRedeemReply carries the cart the order is charged for, or Error
+// when its promotions could not be redeemed.
+type PromotionRedeemReply struct {
+	Cart  *CartInfo `json:"cart,omitempty"`
+	Error string    `json:"error,omitempty"`
+}
+
+// RespondToPromotionRedeemRequests answers cart.promotions.redeem requests
+// with what redeem returns and passes cart.promotions.release messages to
+// release.
+func RespondToPromotionRedeemRequests(redeem func(PromotionRedeemRequest) (CartInfo, error), release func(PromotionRedeemRequest)) {
+	if natsConn == nil {
+		log.Println("[NATS] Not initialized")
+		return
+	}
+
+	_, err := natsConn.Subscribe("cart.promotions.redeem", func(m *nats.Msg) {
+		var req PromotionRedeemRequest
+		if err := json.Unmarshal(m.Data, &req); err != nil {
+			log.Printf("[NATS] Invalid cart.promotions.redeem request: %v", err)
+			return
+		}
+
+		var reply PromotionRedeemReply
+		info, err := redeem(req)
+		if err != nil {
+			log.Printf("[NATS] cart.promotions.redeem failed for cart_id=%s, order_id=%s: %v", req.CartID, req.OrderID, err)
+			reply.Error = err.Error()
+		} else {
+			reply.Cart = &info
+		}
+
+		data, err := json.Marshal(reply)
+		if err != nil {
+			log.Printf("[NATS] Marshal error: %v", err)
+			return
+		}
+		if err := m.Respond(data); err != nil {
+			log.Printf("[NATS] Respond error: %v", err)
+		}
+	})
+	if err != nil {
+		log.Println("Failed to subscribe to cart.promotions.redeem:", err)
+	}
+
+	_, err = natsConn.Subscribe("cart.promotions.release", func(m *nats.Msg) {
+		var req PromotionRedeemRequest
+		if err := json.Unmarshal(m.Data, &req); err != nil {
+			log.Printf("[NATS] Invalid cart.promotions.release message: %v", err)
+			return
+		}
+		release(req)
+	})
+	if err != nil {
+		log.Println("Failed to subscribe to cart.promotions.release:", err)
+	}
+}
+
+// CartAbandonedItem is one line of an abandoned cart as shown in the reminder.
+type CartAbandonedItem struct {
+	ProductID string  `json:"product_id"`
+	SKUID     string  `json:"sku_id,omitempty"`
+	Name      string  `json:"name"`
+	Quantity  int32   `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	LineTotal float64 `json:"line_total"`
+}
+
+// CartAbandonedEvent is published on cart.abandoned for user carts that have
+// not been touched for a while. user-service resolves the user's email.
+type CartAbandonedEvent struct {
+	UserID         string              `json:"user_id"`
+	Items          []CartAbandonedItem `json:"items"`
+	Total          float64             `json:"total"`
+	Currency       string              `json:"currency"`
+	LastActivityAt time.Time           `json:"last_activity_at"`
+}
+
+func PublishCartAbandoned(event CartAbandonedEvent) error {
+	if natsConn == nil {
+		return fmt.Errorf("nats not initialized")
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if err := natsConn.Publish("cart.abandoned", data); err != nil {
+		return err
 	}
+	log.Printf("[NATS] Published cart.abandoned: user_id=%s, items=%d", event.UserID, len(event.Items))
+	return nil
 }
@@ -0,0 +1,123 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// ServiceName is how this service names itself to user-service in data
+// exports and erasure acknowledgements.
+const ServiceName = "shopping-cart-service"
+
+// UserDeletedEvent is what user-service publishes on user.deleted when a
+// user's data is to be erased. It is published again until the erasure is
+// acknowledged on user.erasure.completed.
+type UserDeletedEvent struct {
+	UserID      string    `json:"user_id"`
+	ErasureID   string    `json:"erasure_id"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// ErasureCompletedEvent acknowledges a UserDeletedEvent. Error is set when
+// the erasure failed.
+type ErasureCompletedEvent struct {
+	ErasureID   string    `json:"erasure_id"`
+	UserID      string    `json:"user_id"`
+	Service     string    `json:"service"`
+	CompletedAt time.Time `json:"completed_at"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// UserExportRequest is the payload of user.export.requested requests.
+type UserExportRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// UserExportReply carries this service's data of the user, or Error.
+type UserExportReply struct {
+	Service string          `json:"service"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// SubscribeToUserDeleted passes user.deleted events to erase and
+// acknowledges each with its result.
+func SubscribeToUserDeleted(erase func(ctx context.Context, userID string) error) {
+	if natsConn == nil {
+		log.Println("[NATS] Not initialized")
+		return
+	}
+
+	_, err := natsConn.Subscribe("user.deleted", func(m *nats.Msg) {
+		var event UserDeletedEvent
+		if err := json.Unmarshal(m.Data, &event); err != nil {
+			log.Printf("[NATS] Failed to unmarshal user.deleted: %v", err)
+			return
+		}
+
+		ack := ErasureCompletedEvent{ErasureID: event.ErasureID, UserID: event.UserID, Service: ServiceName}
+		if err := erase(context.Background(), event.UserID); err != nil {
+			log.Printf("[NATS] Erasing user_id=%s failed: %v", event.UserID, err)
+			ack.Error = err.Error()
+		}
+		ack.CompletedAt = time.Now()
+
+		data, err := json.Marshal(ack)
+		if err != nil {
+			log.Printf("[NATS] Marshal error: %v", err)
+			return
+		}
+		if err := natsConn.Publish("user.erasure.completed", data); err != nil {
+			log.Printf("[NATS] Publish error: %v", err)
+		} else {
+			log.Printf("[NATS] Published user.erasure.completed: erasure_id=%s", event.ErasureID)
+		}
+	})
+	if err != nil {
+		log.Println("Failed to subscribe to user.deleted:", err)
+	}
+}
+
+// RespondToUserExportRequests answers user.export.requested requests with
+// the data export returns, encoded as JSON.
+func RespondToUserExportRequests(export func(ctx context.Context, userID string) (interface{}, error)) {
+	if natsConn == nil {
+		log.Println("[NATS] Not initialized")
+		return
+	}
+
+	_, err := natsConn.Subscribe("user.export.requested", func(m *nats.Msg) {
+		var req UserExportRequest
+		if err := json.Unmarshal(m.Data, &req); err != nil {
+			log.Printf("[NATS] Failed to unmarshal user.export.requested: %v", err)
+			return
+		}
+
+		reply := UserExportReply{Service: ServiceName}
+		data, err := export(context.Background(), req.UserID)
+		if err == nil {
+			reply.Data, err = json.Marshal(data)
+		}
+		if err != nil {
+			log.Printf("[NATS] user.export.requested failed for user_id=%s: %v", req.UserID, err)
+			reply.Data = nil
+			reply.Error = err.Error()
+		}
+
+		payload, err := json.Marshal(reply)
+		if err != nil {
+			log.Printf("[NATS] Marshal error: %v", err)
+			return
+		}
+		if err := m.Respond(payload); err != nil {
+			log.Printf("[NATS] Respond error: %v", err)
+		}
+	})
+	if err != nil {
+		log.Println("Failed to subscribe to user.export.requested:", err)
+	}
+}
@@ -0,0 +1,68 @@
+package events
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/nats-io/nats.go"
+)
+
+// ProductRestocked is what product-service publishes on product.restocked.
+type ProductRestocked struct {
+	ProductID string `json:"product_id"`
+	SKUID     string `json:"sku_id,omitempty"`
+	Quantity  int32  `json:"quantity"`
+}
+
+type WishlistRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// BackInStockEvent is published on wishlist.item.back_in_stock once per user
+// for a restocked product, naming every list of the user it is on.
+type BackInStockEvent struct {
+	UserID    string        `json:"user_id"`
+	ProductID string        `json:"product_id"`
+	SKUID     string        `json:"sku_id,omitempty"`
+	Quantity  int32         `json:"quantity"`
+	Lists     []WishlistRef `json:"lists"`
+}
+
+func SubscribeToRestocks(handle func(ProductRestocked)) {
+	if natsConn == nil {
+		log.Println("[NATS] Not initialized")
+		return
+	}
+
+	_, err := natsConn.Subscribe("product.restocked", func(m *nats.Msg) {
+		var event ProductRestocked
+		if err := json.Unmarshal(m.Data, &event); err != nil {
+			log.Printf("[NATS] Failed to unmarshal product.restocked: %v", err)
+			return
+		}
+		handle(event)
+	})
+	if err != nil {
+		log.Println("Failed to subscribe to product.restocked:", err)
+	}
+}
+
+func PublishBackInStock(event BackInStockEvent) {
+	if natsConn == nil {
+		log.Println("[NATS] Not initialized")
+		return
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("[NATS] Marshal error: %v", err)
+		return
+	}
+
+	if err := natsConn.Publish("wishlist.item.back_in_stock", data); err != nil {
+		log.Printf("[NATS] Publish error: %v", err)
+	} else {
+		log.Printf("[NATS] Published wishlist.item.back_in_stock: user_id=%s, product_id=%s", event.UserID, event.ProductID)
+	}
+}
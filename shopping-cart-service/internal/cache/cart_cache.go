@@ -1,54 +1,106 @@
 package cache
 
 import (
-	"log"
+	"context"
 	"sync"
 	"time"
 
 	"shopping-cart-service/internal/model"
 )
 
-type CartCache struct {
+// CartCache caches cart contents by cart ID.
+//
+// Get returns the Version of the cart state it looked at, also on a miss.
+// Passing it back to Set drops the Set when the cart was invalidated in
+// between, so a cart read from the database before a write is never cached
+// after that write.
+//
+// When Invalidate fails, readers may see the old cart until its entry
+// expires, so callers must report the write as failed.
+type CartCache interface {
+	Get(ctx context.Context, cartID string) (items []model.CartItem, version Version, found bool)
+	Set(ctx context.Context, cartID string, version Version, items []model.CartItem)
+	Invalidate(ctx context.Context, cartID string) error
+}
+
+// Version identifies a cart state. local is used by MemoryCache and shared
+// by RedisCache; LayeredCache needs both.
+type Version struct {
+	local  uint64
+	shared int64
+}
+
+type memoryEntry struct {
+	items     []model.CartItem
+	expiresAt time.Time
+}
+
+// MemoryCache is a process-local CartCache. Entries expire after ttl.
+type MemoryCache struct {
 	mu    sync.RWMutex
-	items map[string][]model.CartItem
+	ttl   time.Duration
+	items map[string]memoryEntry
+	// generation grows with every invalidation. It is one counter for all
+	// carts, which makes a racing Set give up more often than it would have
+	// to but keeps nothing around for carts that are gone.
+	generation uint64
 }
 
-func NewCartCache() *CartCache {
-	c := &CartCache{
-		items: make(map[string][]model.CartItem),
+func NewMemoryCache(ttl time.Duration) *MemoryCache {
+	c := &MemoryCache{
+		ttl:   ttl,
+		items: make(map[string]memoryEntry),
 	}
-	// автоматическое обновление каждые 12 часов
-	go func() {
-		for {
-			time.Sleep(12 * time.Hour)
-			c.Clear()
-			log.Println("🌀 Cart cache refreshed after 12 hours.")
-		}
-	}()
+	go c.evictExpired()
 	return c
 }
 
-func (c *CartCache) Get(userID string) ([]model.CartItem, bool) {
+func (c *MemoryCache) Get(_ context.Context, cartID string) ([]model.CartItem, Version, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	items, found := c.items[userID]
-	return items, found
+	version := Version{local: c.generation}
+	entry, found := c.items[cartID]
+	if !found || time.Now().After(entry.expiresAt) {
+		return nil, version, false
+	}
+	return entry.items, version, true
 }
 
-func (c *CartCache) Set(userID string, items []model.CartItem) {
+func (c *MemoryCache) Set(_ context.Context, cartID string, version Version, items []model.CartItem) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.items[userID] = items
+	if version.local != c.generation {
+		return
+	}
+	c.items[cartID] = memoryEntry{items: items, expiresAt: time.Now().Add(c.ttl)}
 }
 
-func (c *CartCache) Invalidate(userID string) {
+func (c *MemoryCache) Invalidate(_ context.Context, cartID string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	delete(c.items, userID)
+	c.generation++
+	delete(c.items, cartID)
+	return nil
 }
 
-func (c *CartCache) Clear() {
+func (c *MemoryCache) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.items = make(map[string][]model.CartItem)
+	c.generation++
+	c.items = make(map[string]memoryEntry)
+}
+
+// evictExpired drops expired entries of carts nobody reads any more.
+func (c *MemoryCache) evictExpired() {
+	ticker := time.NewTicker(c.ttl)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		c.mu.Lock()
+		for cartID, entry := range c.items {
+			if now.After(entry.expiresAt) {
+				delete(c.items, cartID)
+			}
+		}
+		c.mu.Unlock()
+	}
 }
@@ -0,0 +1,44 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"shopping-cart-service/internal/cache"
+	"shopping-cart-service/internal/model"
+)
+
+func TestMemoryCache_SetAfterInvalidateIsDropped(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemoryCache(time.Minute)
+	stale := []model.CartItem{{UserID: "u1", ProductID: "p1", Quantity: 1}}
+
+	_, version, found := c.Get(ctx, "u1")
+	assert.False(t, found)
+
+	// A write lands between reading the database and filling the cache.
+	c.Invalidate(ctx, "u1")
+	c.Set(ctx, "u1", version, stale)
+
+	_, version, found = c.Get(ctx, "u1")
+	assert.False(t, found)
+
+	c.Set(ctx, "u1", version, stale)
+	items, _, found := c.Get(ctx, "u1")
+	assert.True(t, found)
+	assert.Equal(t, stale, items)
+}
+
+func TestMemoryCache_EntriesExpire(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemoryCache(10 * time.Millisecond)
+
+	_, version, _ := c.Get(ctx, "u2")
+	c.Set(ctx, "u2", version, []model.CartItem{{UserID: "u2", ProductID: "p1", Quantity: 1}})
+	time.Sleep(20 * time.Millisecond)
+
+	_, _, found := c.Get(ctx, "u2")
+	assert.False(t, found)
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+
+	"shopping-cart-service/internal/model"
+)
+
+// LayeredCache puts a MemoryCache (L1) in front of a RedisCache (L2). Other
+// replicas' invalidations reach the L1 through Redis pub/sub, so the L1 TTL
+// only bounds how stale a cart can get when such a message is lost.
+type LayeredCache struct {
+	l1 *MemoryCache
+	l2 *RedisCache
+}
+
+// NewLayeredCache wires l2's invalidations into l1 until ctx is done.
+func NewLayeredCache(ctx context.Context, l1 *MemoryCache, l2 *RedisCache) *LayeredCache {
+	c := &LayeredCache{l1: l1, l2: l2}
+	l2.SubscribeInvalidations(ctx, func(cartID string) {
+		l1.Invalidate(ctx, cartID)
+	})
+	return c
+}
+
+func (c *LayeredCache) Get(ctx context.Context, cartID string) ([]model.CartItem, Version, bool) {
+	items, v1, found := c.l1.Get(ctx, cartID)
+	if found {
+		observe(LayerL1, true)
+		return items, v1, true
+	}
+	observe(LayerL1, false)
+
+	items, v2, found := c.l2.Get(ctx, cartID)
+	observe(LayerL2, found)
+	version := Version{local: v1.local, shared: v2.shared}
+	if found {
+		c.l1.Set(ctx, cartID, version, items)
+	}
+	return items, version, found
+}
+
+func (c *LayeredCache) Set(ctx context.Context, cartID string, version Version, items []model.CartItem) {
+	c.l2.Set(ctx, cartID, version, items)
+	c.l1.Set(ctx, cartID, version, items)
+}
+
+func (c *LayeredCache) Invalidate(ctx context.Context, cartID string) error {
+	c.l1.Invalidate(ctx, cartID)
+	return c.l2.Invalidate(ctx, cartID)
+}
@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"shopping-cart-service/internal/model"
+)
+
+const (
+	LayerL1 = "l1"
+	LayerL2 = "l2"
+)
+
+// lookups counts cache lookups by layer and result. The hit ratio of a layer
+// is
+//
+//	rate(cart_cache_lookups_total{result="hit"}[5m])
+//	  / rate(cart_cache_lookups_total[5m])
+var lookups = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cart_cache_lookups_total",
+		Help: "Cart cache lookups by layer and result",
+	},
+	[]string{"layer", "result"},
+)
+
+func observe(layer string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	lookups.WithLabelValues(layer, result).Inc()
+}
+
+// Instrumented counts the hits and misses of a single-layer cache under
+// layer. LayeredCache counts its layers itself and must not be wrapped.
+type Instrumented struct {
+	CartCache
+	layer string
+}
+
+func NewInstrumented(c CartCache, layer string) *Instrumented {
+	return &Instrumented{CartCache: c, layer: layer}
+}
+
+func (c *Instrumented) Get(ctx context.Context, cartID string) ([]model.CartItem, Version, bool) {
+	items, version, found := c.CartCache.Get(ctx, cartID)
+	observe(c.layer, found)
+	return items, version, found
+}
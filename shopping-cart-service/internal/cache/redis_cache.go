@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"shopping-cart-service/internal/model"
+)
+
+const (
+	// keyPrefix carries the version of the cached format. Bump it when
+	// model.CartItem changes so old replicas and new ones do not read each
+	// other's entries.
+	keyPrefix = "cart:v1:"
+
+	// InvalidationChannel is where RedisCache announces invalidated carts.
+	InvalidationChannel = "cart:invalidate"
+
+	// versionTTL must outlive any cart entry so a version counter never
+	// restarts while entries written under its old values still exist.
+	versionTTL = 30 * 24 * time.Hour
+
+	// invalidateAttempts bounds the tries of an invalidation, which are
+	// invalidateBackoff apart, growing with every attempt.
+	invalidateAttempts = 3
+	invalidateBackoff  = 50 * time.Millisecond
+)
+
+// RedisCache is a CartCache shared by all replicas. Every cart has a version
+// counter; entries are stored under the version they were read at, and an
+// invalidation just increments the counter, leaving the old entry to expire.
+type RedisCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRedisCache(client *redis.Client, ttl time.Duration) *RedisCache {
+	return &RedisCache{client: client, ttl: ttl}
+}
+
+func versionKey(cartID string) string {
+	return keyPrefix + "version:" + cartID
+}
+
+func entryKey(cartID string, version int64) string {
+	return fmt.Sprintf("%sitems:%s:%d", keyPrefix, cartID, version)
+}
+
+func (c *RedisCache) Get(ctx context.Context, cartID string) ([]model.CartItem, Version, bool) {
+	current, err := c.client.Get(ctx, versionKey(cartID)).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.Printf("[CACHE] Redis version lookup failed for %s: %v", cartID, err)
+		// Not knowing the version, a Set must not write anything.
+		return nil, Version{shared: -1}, false
+	}
+	version := Version{shared: current}
+
+	data, err := c.client.Get(ctx, entryKey(cartID, current)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("[CACHE] Redis get failed for %s: %v", cartID, err)
+		}
+		return nil, version, false
+	}
+
+	var items []model.CartItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		log.Printf("[CACHE] Dropping unreadable Redis entry for %s: %v", cartID, err)
+		return nil, version, false
+	}
+	return items, version, true
+}
+
+func (c *RedisCache) Set(ctx context.Context, cartID string, version Version, items []model.CartItem) {
+	if version.shared < 0 {
+		return
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		log.Printf("[CACHE] Marshal error: %v", err)
+		return
+	}
+	// If the cart was invalidated since Get, this writes under a version
+	// nobody reads any more and the entry simply expires.
+	if err := c.client.Set(ctx, entryKey(cartID, version.shared), data, c.ttl).Err(); err != nil {
+		log.Printf("[CACHE] Redis set failed for %s: %v", cartID, err)
+	}
+}
+
+// Invalidate retries a failed invalidation a few times before giving up,
+// since every replica would go on serving the old entry until it expires.
+func (c *RedisCache) Invalidate(ctx context.Context, cartID string) error {
+	var err error
+	for attempt := 1; attempt <= invalidateAttempts; attempt++ {
+		pipe := c.client.TxPipeline()
+		pipe.Incr(ctx, versionKey(cartID))
+		pipe.Expire(ctx, versionKey(cartID), versionTTL)
+		pipe.Publish(ctx, InvalidationChannel, cartID)
+		if _, err = pipe.Exec(ctx); err == nil {
+			return nil
+		}
+		log.Printf("[CACHE] Redis invalidate failed for %s, attempt %d of %d: %v", cartID, attempt, invalidateAttempts, err)
+		if attempt < invalidateAttempts {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * invalidateBackoff):
+			}
+		}
+	}
+	return fmt.Errorf("invalidate cart %s: %w", cartID, err)
+}
+
+// SubscribeInvalidations calls fn with the ID of every cart any replica
+// invalidates, until ctx is done.
+func (c *RedisCache) SubscribeInvalidations(ctx context.Context, fn func(cartID string)) {
+	sub := c.client.Subscribe(ctx, InvalidationChannel)
+	go func() {
+		defer sub.Close()
+		ch := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				fn(msg.Payload)
+			}
+		}
+	}()
+}
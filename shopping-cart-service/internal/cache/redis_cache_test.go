@@ -0,0 +1,137 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"shopping-cart-service/internal/cache"
+	"shopping-cart-service/internal/model"
+)
+
+func newRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+func TestRedisCache_SetAfterInvalidateIsDropped(t *testing.T) {
+	ctx := context.Background()
+	_, client := newRedis(t)
+	c := cache.NewRedisCache(client, time.Minute)
+	stale := []model.CartItem{{UserID: "u1", ProductID: "p1", Quantity: 1}}
+
+	_, version, found := c.Get(ctx, "u1")
+	assert.False(t, found)
+
+	assert.NoError(t, c.Invalidate(ctx, "u1"))
+	c.Set(ctx, "u1", version, stale)
+
+	_, version, found = c.Get(ctx, "u1")
+	assert.False(t, found)
+
+	c.Set(ctx, "u1", version, stale)
+	items, _, found := c.Get(ctx, "u1")
+	assert.True(t, found)
+	assert.Equal(t, stale, items)
+}
+
+func TestRedisCache_EntriesExpire(t *testing.T) {
+	ctx := context.Background()
+	server, client := newRedis(t)
+	c := cache.NewRedisCache(client, time.Minute)
+
+	_, version, _ := c.Get(ctx, "u2")
+	c.Set(ctx, "u2", version, []model.CartItem{{UserID: "u2", ProductID: "p1", Quantity: 1}})
+	server.FastForward(2 * time.Minute)
+
+	_, _, found := c.Get(ctx, "u2")
+	assert.False(t, found)
+}
+
+func TestRedisCache_InvalidateFailsWhenRedisIsDown(t *testing.T) {
+	ctx := context.Background()
+	server, client := newRedis(t)
+	c := cache.NewRedisCache(client, time.Minute)
+
+	_, version, _ := c.Get(ctx, "u3")
+	c.Set(ctx, "u3", version, []model.CartItem{{UserID: "u3", ProductID: "p1", Quantity: 1}})
+	server.Close()
+
+	assert.Error(t, c.Invalidate(ctx, "u3"))
+
+	// Without a version a Set must not write anything.
+	_, version, found := c.Get(ctx, "u3")
+	assert.False(t, found)
+	c.Set(ctx, "u3", version, nil)
+}
+
+func TestLayeredCache_FillsL1FromL2(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, client := newRedis(t)
+	l2 := cache.NewRedisCache(client, time.Minute)
+	items := []model.CartItem{{UserID: "u4", ProductID: "p1", Quantity: 2}}
+
+	writer := cache.NewLayeredCache(ctx, cache.NewMemoryCache(time.Minute), l2)
+	_, version, _ := writer.Get(ctx, "u4")
+	writer.Set(ctx, "u4", version, items)
+
+	l1 := cache.NewMemoryCache(time.Minute)
+	reader := cache.NewLayeredCache(ctx, l1, l2)
+	got, _, found := reader.Get(ctx, "u4")
+	assert.True(t, found)
+	assert.Equal(t, items, got)
+
+	got, _, found = l1.Get(ctx, "u4")
+	assert.True(t, found)
+	assert.Equal(t, items, got)
+}
+
+func TestLayeredCache_InvalidationReachesOtherReplicas(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, client := newRedis(t)
+	l2 := cache.NewRedisCache(client, time.Minute)
+	items := []model.CartItem{{UserID: "u5", ProductID: "p1", Quantity: 1}}
+
+	l1 := cache.NewMemoryCache(time.Minute)
+	replicaA := cache.NewLayeredCache(ctx, l1, l2)
+	replicaB := cache.NewLayeredCache(ctx, cache.NewMemoryCache(time.Minute), l2)
+
+	_, version, _ := replicaA.Get(ctx, "u5")
+	replicaA.Set(ctx, "u5", version, items)
+	_, _, found := l1.Get(ctx, "u5")
+	assert.True(t, found)
+
+	// Give the subscriptions time to be set up before publishing.
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, replicaB.Invalidate(ctx, "u5"))
+
+	assert.Eventually(t, func() bool {
+		_, _, found := l1.Get(ctx, "u5")
+		return !found
+	}, time.Second, 10*time.Millisecond)
+	_, _, found = replicaA.Get(ctx, "u5")
+	assert.False(t, found)
+}
+
+func TestLayeredCache_InvalidateReportsRedisErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server, client := newRedis(t)
+	l1 := cache.NewMemoryCache(time.Minute)
+	c := cache.NewLayeredCache(ctx, l1, cache.NewRedisCache(client, time.Minute))
+
+	_, version, _ := c.Get(ctx, "u6")
+	c.Set(ctx, "u6", version, []model.CartItem{{UserID: "u6", ProductID: "p1", Quantity: 1}})
+	server.Close()
+
+	assert.Error(t, c.Invalidate(ctx, "u6"))
+	_, _, found := l1.Get(ctx, "u6")
+	assert.False(t, found)
+}
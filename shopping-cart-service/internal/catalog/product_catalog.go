@@ -0,0 +1,83 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	productpb "shopping-cart-service/client-service/proto/productpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var ErrProductNotFound = errors.New("product not found")
+
+// ProductSnapshot is what the cart needs to know about a product at the
+// moment the cart is priced.
+type ProductSnapshot struct {
+	ProductID string
+	SKUID     string
+	Name      string
+	Category  string
+	ListPrice float64
+	UnitPrice float64
+	Stock     int32
+}
+
+type ProductCatalog interface {
+	GetProduct(ctx context.Context, productID, skuID string) (*ProductSnapshot, error)
+}
+
+type grpcProductCatalog struct {
+	client productpb.ProductServiceClient
+}
+
+func NewGRPCProductCatalog(conn *grpc.ClientConn) ProductCatalog {
+	return &grpcProductCatalog{client: productpb.NewProductServiceClient(conn)}
+}
+
+func (c *grpcProductCatalog) GetProduct(ctx context.Context, productID, skuID string) (*ProductSnapshot, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if skuID != "" {
+		resp, err := c.client.GetSKU(ctx, &productpb.GetSKURequest{SkuId: skuID})
+		if err != nil {
+			return nil, translate(err)
+		}
+		// SKU prices are not scheduled, so list and unit price are the same.
+		return &ProductSnapshot{
+			ProductID: resp.Product.GetId(),
+			SKUID:     skuID,
+			Name:      strings.TrimSpace(resp.Product.GetName() + " " + resp.Sku.GetSize()),
+			Category:  resp.Product.GetCategory(),
+			ListPrice: resp.Sku.GetPrice(),
+			UnitPrice: resp.Sku.GetPrice(),
+			Stock:     resp.Sku.GetQuantity(),
+		}, nil
+	}
+
+	resp, err := c.client.GetProduct(ctx, &productpb.GetProductRequest{Id: productID})
+	if err != nil {
+		return nil, translate(err)
+	}
+	p := resp.Product
+	return &ProductSnapshot{
+		ProductID: p.GetId(),
+		Name:      p.GetName(),
+		Category:  p.GetCategory(),
+		ListPrice: p.GetPrice(),
+		UnitPrice: p.GetEffectivePrice(),
+		Stock:     p.GetQuantity(),
+	}, nil
+}
+
+func translate(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return ErrProductNotFound
+	}
+	return err
+}
@@ -2,10 +2,16 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"shopping-cart-service/events"
+	"shopping-cart-service/internal/catalog"
 	"shopping-cart-service/internal/model"
 	"shopping-cart-service/internal/service"
 	pb "shopping-cart-service/shopping-cart-service/proto/cartpb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type CartHandler struct {
@@ -22,48 +28,190 @@ func (h *CartHandler) AddToCart(ctx context.Context, req *pb.AddToCartRequest) (
 		err := h.service.AddToCart(ctx, model.CartItem{
 			UserID:    req.UserId,
 			ProductID: item.ProductId,
+			SKUID:     item.SkuId,
 			Quantity:  int32(item.Quantity),
 		})
 		if err != nil {
-			return nil, err
+			return nil, toStatusError(err)
 		}
 
 		events.PublishCartItemAdded(events.CartItemAddedEvent{
-			UserID:    req.UserId, // в этом случае здесь будет email
+			UserID:    req.UserId,
 			ProductID: item.ProductId,
+			SKUID:     item.SkuId,
 			Quantity:  item.Quantity,
 		})
 
 	}
 
-	items, _ := h.service.GetCart(ctx, req.UserId)
-	return toCartResponse(items), nil
+	return h.changedCart(ctx, req.UserId)
 }
 
 func (h *CartHandler) GetCart(ctx context.Context, req *pb.GetCartRequest) (*pb.CartResponse, error) {
-	items, err := h.service.GetCart(ctx, req.UserId)
+	cart, err := h.service.GetPricedCart(ctx, req.UserId)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
-	return toCartResponse(items), nil
+	return toCartResponse(cart), nil
 }
 
 func (h *CartHandler) RemoveFromCart(ctx context.Context, req *pb.RemoveFromCartRequest) (*pb.CartResponse, error) {
-	err := h.service.RemoveFromCart(ctx, req.UserId, req.ProductId)
+	err := h.service.RemoveFromCart(ctx, req.UserId, req.ProductId, req.SkuId)
 	if err != nil {
 		return nil, err
 	}
-	items, _ := h.service.GetCart(ctx, req.UserId)
-	return toCartResponse(items), nil
+	return h.changedCart(ctx, req.UserId)
+}
+
+func (h *CartHandler) UpdateCartItem(ctx context.Context, req *pb.UpdateCartItemRequest) (*pb.CartResponse, error) {
+	err := h.service.UpdateCartItem(ctx, req.UserId, req.ProductId, req.SkuId, req.Quantity)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return h.changedCart(ctx, req.UserId)
 }
 
-func toCartResponse(items []model.CartItem) *pb.CartResponse {
+func (h *CartHandler) ClearCart(ctx context.Context, req *pb.ClearCartRequest) (*pb.CartResponse, error) {
+	if err := h.service.ClearCart(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+	return h.changedCart(ctx, req.UserId)
+}
+
+func (h *CartHandler) MergeCart(ctx context.Context, req *pb.MergeCartRequest) (*pb.CartResponse, error) {
+	if req.SourceUserId == "" || req.TargetUserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "source_user_id and target_user_id are required")
+	}
+	if err := h.service.MergeCart(ctx, req.SourceUserId, req.TargetUserId, model.MergeStrategy(req.Strategy)); err != nil {
+		return nil, err
+	}
+	h.changedCart(ctx, req.SourceUserId)
+	return h.changedCart(ctx, req.TargetUserId)
+}
+
+func (h *CartHandler) ValidateCart(ctx context.Context, req *pb.ValidateCartRequest) (*pb.ValidateCartResponse, error) {
+	issues, err := h.service.ValidateCart(ctx, req.UserId, req.AutoFix)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	var cart *pb.CartResponse
+	if req.AutoFix && len(issues) > 0 {
+		cart, err = h.changedCart(ctx, req.UserId)
+	} else {
+		cart, err = h.GetCart(ctx, &pb.GetCartRequest{UserId: req.UserId})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ValidateCartResponse{
+		Valid:  !model.HasUnresolvedIssues(issues),
+		Issues: toPbIssues(issues),
+		Cart:   cart,
+	}, nil
+}
+
+func (h *CartHandler) ApplyCoupon(ctx context.Context, req *pb.ApplyCouponRequest) (*pb.CartResponse, error) {
+	if err := h.service.ApplyCoupon(ctx, req.UserId, req.Code); err != nil {
+		return nil, toStatusError(err)
+	}
+	return h.changedCart(ctx, req.UserId)
+}
+
+func (h *CartHandler) RemoveCoupon(ctx context.Context, req *pb.RemoveCouponRequest) (*pb.CartResponse, error) {
+	if err := h.service.RemoveCoupon(ctx, req.UserId, req.Code); err != nil {
+		return nil, toStatusError(err)
+	}
+	return h.changedCart(ctx, req.UserId)
+}
+
+// changedCart prices the cart after a mutation, publishes its new total on
+// cart.info for order-service and returns it.
+func (h *CartHandler) changedCart(ctx context.Context, userID string) (*pb.CartResponse, error) {
+	cart, err := h.service.GetPricedCart(ctx, userID)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	events.PublishCartInfo(events.NewCartInfo(cart))
+	return toCartResponse(cart), nil
+}
+
+func toStatusError(err error) error {
+	switch {
+	case errors.Is(err, model.ErrInvalidQuantity):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, model.ErrItemNotFound), errors.Is(err, catalog.ErrProductNotFound),
+		errors.Is(err, model.ErrPromotionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, model.ErrCartNotPriceable):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, model.ErrCouponAlreadyApplied):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, model.ErrPromotionInactive), errors.Is(err, model.ErrPromotionExpired),
+		errors.Is(err, model.ErrMinSpendNotMet), errors.Is(err, model.ErrUsageLimitReached),
+		errors.Is(err, model.ErrCouponNotApplicable), errors.Is(err, model.ErrLoginRequired):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return err
+	}
+}
+
+func toCartResponse(cart *model.PricedCart) *pb.CartResponse {
 	var respItems []*pb.CartItem
-	for _, item := range items {
+	for _, item := range cart.Items {
 		respItems = append(respItems, &pb.CartItem{
-			ProductId: item.ProductID,
-			Quantity:  item.Quantity,
+			ProductId:  item.ProductID,
+			SkuId:      item.SKUID,
+			Quantity:   item.Quantity,
+			AddedAt:    timestamppb.New(item.AddedAt),
+			UpdatedAt:  timestamppb.New(item.UpdatedAt),
+			Name:       item.Name,
+			UnitPrice:  item.UnitPrice,
+			ListPrice:  item.ListPrice,
+			LineTotal:  item.LineTotal,
+			Available:  item.Available,
+			AddedPrice: item.AddedPrice,
+			Category:   item.Category,
+		})
+	}
+	resp := &pb.CartResponse{
+		Items:    respItems,
+		Subtotal: cart.Subtotal,
+		Discount: cart.Discount,
+		Tax:      cart.Tax,
+		Total:    cart.Total,
+		Currency: cart.Currency,
+	}
+	for _, d := range cart.Discounts {
+		resp.Discounts = append(resp.Discounts, &pb.DiscountLine{
+			Source:      d.Source,
+			PromotionId: d.PromotionID,
+			Code:        d.Code,
+			Description: d.Description,
+			Amount:      d.Amount,
+		})
+	}
+	for _, c := range cart.Coupons {
+		resp.Coupons = append(resp.Coupons, &pb.AppliedCoupon{Code: c.Code, Applied: c.Applied, Reason: c.Reason})
+	}
+	return resp
+}
+
+func toPbIssues(issues []model.CartIssue) []*pb.CartIssue {
+	result := make([]*pb.CartIssue, 0, len(issues))
+	for _, issue := range issues {
+		result = append(result, &pb.CartIssue{
+			ProductId:         issue.ProductID,
+			SkuId:             issue.SKUID,
+			Kind:              pb.CartIssueKind(issue.Kind),
+			RequestedQuantity: issue.RequestedQuantity,
+			AvailableQuantity: issue.AvailableQuantity,
+			OldPrice:          issue.OldPrice,
+			NewPrice:          issue.NewPrice,
+			Resolved:          issue.Resolved,
 		})
 	}
-	return &pb.CartResponse{Items: respItems}
+	return result
 }
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"shopping-cart-service/internal/model"
+	"shopping-cart-service/internal/service"
+	pb "shopping-cart-service/shopping-cart-service/proto/cartpb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type PromotionHandler struct {
+	pb.UnimplementedPromotionServiceServer
+	service *service.PromotionService
+}
+
+func NewPromotionHandler(s *service.PromotionService) *PromotionHandler {
+	return &PromotionHandler{service: s}
+}
+
+func (h *PromotionHandler) CreatePromotion(ctx context.Context, req *pb.Promotion) (*pb.Promotion, error) {
+	promo := &model.Promotion{
+		Code:           req.Code,
+		Description:    req.Description,
+		Kind:           model.PromotionKind(req.Kind),
+		Percent:        req.Percent,
+		Amount:         req.Amount,
+		ProductID:      req.ProductId,
+		BuyQuantity:    req.BuyQuantity,
+		FreeQuantity:   req.FreeQuantity,
+		Category:       req.Category,
+		MinSpend:       req.MinSpend,
+		MaxUses:        req.MaxUses,
+		MaxUsesPerUser: req.MaxUsesPerUser,
+	}
+	if req.StartsAt != nil {
+		promo.StartsAt = req.StartsAt.AsTime()
+	}
+	if req.EndsAt != nil {
+		promo.EndsAt = req.EndsAt.AsTime()
+	}
+
+	if err := h.service.CreatePromotion(ctx, promo); err != nil {
+		return nil, toPromotionStatusError(err)
+	}
+	return toPbPromotion(promo), nil
+}
+
+func (h *PromotionHandler) GetPromotion(ctx context.Context, req *pb.GetPromotionRequest) (*pb.Promotion, error) {
+	promo, err := h.service.GetPromotion(ctx, req.Id)
+	if err != nil {
+		return nil, toPromotionStatusError(err)
+	}
+	return toPbPromotion(promo), nil
+}
+
+func (h *PromotionHandler) ListPromotions(ctx context.Context, req *pb.ListPromotionsRequest) (*pb.ListPromotionsResponse, error) {
+	promos, err := h.service.ListPromotions(ctx, req.ActiveOnly)
+	if err != nil {
+		return nil, toPromotionStatusError(err)
+	}
+	resp := &pb.ListPromotionsResponse{}
+	for i := range promos {
+		resp.Promotions = append(resp.Promotions, toPbPromotion(&promos[i]))
+	}
+	return resp, nil
+}
+
+func (h *PromotionHandler) DeactivatePromotion(ctx context.Context, req *pb.GetPromotionRequest) (*pb.Promotion, error) {
+	promo, err := h.service.DeactivatePromotion(ctx, req.Id)
+	if err != nil {
+		return nil, toPromotionStatusError(err)
+	}
+	return toPbPromotion(promo), nil
+}
+
+func toPromotionStatusError(err error) error {
+	switch {
+	case errors.Is(err, model.ErrPromotionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, model.ErrInvalidPromotion):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return err
+	}
+}
+
+func toPbPromotion(promo *model.Promotion) *pb.Promotion {
+	resp := &pb.Promotion{
+		Id:             promo.ID,
+		Code:           promo.Code,
+		Description:    promo.Description,
+		Kind:           pb.PromotionKind(promo.Kind),
+		Percent:        promo.Percent,
+		Amount:         promo.Amount,
+		ProductId:      promo.ProductID,
+		BuyQuantity:    promo.BuyQuantity,
+		FreeQuantity:   promo.FreeQuantity,
+		Category:       promo.Category,
+		MinSpend:       promo.MinSpend,
+		MaxUses:        promo.MaxUses,
+		MaxUsesPerUser: promo.MaxUsesPerUser,
+		Uses:           promo.Uses,
+		StartsAt:       timestamppb.New(promo.StartsAt),
+		Active:         promo.Active,
+		CreatedAt:      timestamppb.New(promo.CreatedAt),
+	}
+	if !promo.EndsAt.IsZero() {
+		resp.EndsAt = timestamppb.New(promo.EndsAt)
+	}
+	return resp
+}
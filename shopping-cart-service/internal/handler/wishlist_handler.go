@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"shopping-cart-service/internal/model"
+	"shopping-cart-service/internal/service"
+	pb "shopping-cart-service/shopping-cart-service/proto/cartpb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type WishlistHandler struct {
+	pb.UnimplementedWishlistServiceServer
+	service *service.WishlistService
+	carts   *CartHandler
+}
+
+func NewWishlistHandler(s *service.WishlistService, carts *CartHandler) *WishlistHandler {
+	return &WishlistHandler{service: s, carts: carts}
+}
+
+func (h *WishlistHandler) ListLists(ctx context.Context, req *pb.ListListsRequest) (*pb.ListListsResponse, error) {
+	lists, err := h.service.ListLists(ctx, req.UserId)
+	if err != nil {
+		return nil, toWishlistStatusError(err)
+	}
+	resp := &pb.ListListsResponse{}
+	for i := range lists {
+		resp.Lists = append(resp.Lists, toPbWishlist(&lists[i]))
+	}
+	return resp, nil
+}
+
+func (h *WishlistHandler) CreateList(ctx context.Context, req *pb.CreateListRequest) (*pb.Wishlist, error) {
+	return h.respond(h.service.CreateList(ctx, req.UserId, req.Name))
+}
+
+func (h *WishlistHandler) GetList(ctx context.Context, req *pb.GetListRequest) (*pb.Wishlist, error) {
+	return h.respond(h.service.GetList(ctx, req.UserId, req.ListId))
+}
+
+func (h *WishlistHandler) RenameList(ctx context.Context, req *pb.RenameListRequest) (*pb.Wishlist, error) {
+	return h.respond(h.service.RenameList(ctx, req.UserId, req.ListId, req.Name))
+}
+
+func (h *WishlistHandler) DeleteList(ctx context.Context, req *pb.DeleteListRequest) (*pb.DeleteListResponse, error) {
+	if err := h.service.DeleteList(ctx, req.UserId, req.ListId); err != nil {
+		return nil, toWishlistStatusError(err)
+	}
+	return &pb.DeleteListResponse{Success: true}, nil
+}
+
+func (h *WishlistHandler) AddListItem(ctx context.Context, req *pb.AddListItemRequest) (*pb.Wishlist, error) {
+	return h.respond(h.service.AddItem(ctx, req.UserId, req.ListId, model.WishlistItem{
+		ProductID: req.ProductId,
+		SKUID:     req.SkuId,
+		Quantity:  req.Quantity,
+	}))
+}
+
+func (h *WishlistHandler) RemoveListItem(ctx context.Context, req *pb.RemoveListItemRequest) (*pb.Wishlist, error) {
+	return h.respond(h.service.RemoveItem(ctx, req.UserId, req.ListId, req.ProductId, req.SkuId))
+}
+
+func (h *WishlistHandler) MoveToCart(ctx context.Context, req *pb.MoveListItemRequest) (*pb.MoveListItemResponse, error) {
+	list, err := h.service.MoveToCart(ctx, req.UserId, req.ListId, req.ProductId, req.SkuId)
+	return h.moved(ctx, req.UserId, list, err)
+}
+
+func (h *WishlistHandler) MoveFromCart(ctx context.Context, req *pb.MoveListItemRequest) (*pb.MoveListItemResponse, error) {
+	list, err := h.service.MoveFromCart(ctx, req.UserId, req.ListId, req.ProductId, req.SkuId)
+	return h.moved(ctx, req.UserId, list, err)
+}
+
+func (h *WishlistHandler) ShareList(ctx context.Context, req *pb.ShareListRequest) (*pb.ShareListResponse, error) {
+	token, err := h.service.ShareList(ctx, req.UserId, req.ListId)
+	if err != nil {
+		return nil, toWishlistStatusError(err)
+	}
+	return &pb.ShareListResponse{ShareToken: token}, nil
+}
+
+func (h *WishlistHandler) UnshareList(ctx context.Context, req *pb.ShareListRequest) (*pb.Wishlist, error) {
+	return h.respond(h.service.UnshareList(ctx, req.UserId, req.ListId))
+}
+
+func (h *WishlistHandler) GetSharedList(ctx context.Context, req *pb.GetSharedListRequest) (*pb.Wishlist, error) {
+	list, err := h.service.GetSharedList(ctx, req.ShareToken)
+	if err != nil {
+		return nil, toWishlistStatusError(err)
+	}
+	resp := toPbWishlist(list)
+	resp.UserId = ""
+	resp.ShareToken = ""
+	return resp, nil
+}
+
+func (h *WishlistHandler) respond(list *model.Wishlist, err error) (*pb.Wishlist, error) {
+	if err != nil {
+		return nil, toWishlistStatusError(err)
+	}
+	return toPbWishlist(list), nil
+}
+
+// moved answers a move between a list and the cart with both of them, and
+// publishes the changed cart like every other cart mutation.
+func (h *WishlistHandler) moved(ctx context.Context, userID string, list *model.Wishlist, err error) (*pb.MoveListItemResponse, error) {
+	if err != nil {
+		return nil, toWishlistStatusError(err)
+	}
+	cart, err := h.carts.changedCart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.MoveListItemResponse{List: toPbWishlist(list), Cart: cart}, nil
+}
+
+func toWishlistStatusError(err error) error {
+	switch {
+	case errors.Is(err, model.ErrListNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, model.ErrEmptyListName):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, model.ErrDefaultList):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return toStatusError(err)
+	}
+}
+
+func toPbWishlist(list *model.Wishlist) *pb.Wishlist {
+	items := make([]*pb.WishlistItem, 0, len(list.Items))
+	for _, item := range list.Items {
+		items = append(items, &pb.WishlistItem{
+			ProductId: item.ProductID,
+			SkuId:     item.SKUID,
+			Quantity:  item.Quantity,
+			AddedAt:   timestamppb.New(item.AddedAt),
+		})
+	}
+	return &pb.Wishlist{
+		Id:         list.ID,
+		UserId:     list.UserID,
+		Name:       list.Name,
+		Kind:       pb.ListKind(list.Kind),
+		Items:      items,
+		ShareToken: list.ShareToken,
+		CreatedAt:  timestamppb.New(list.CreatedAt),
+		UpdatedAt:  timestamppb.New(list.UpdatedAt),
+	}
+}
@@ -1,7 +1,235 @@
 package model
 
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// GuestCartPrefix marks cart IDs that belong to anonymous shoppers. The rest
+// of the ID is an opaque token issued by the api-gateway.
+const GuestCartPrefix = "guest:"
+
+// GuestCartTTL is how long a guest cart survives without being touched.
+const GuestCartTTL = 7 * 24 * time.Hour
+
+func IsGuestCart(cartID string) bool {
+	return strings.HasPrefix(cartID, GuestCartPrefix)
+}
+
+type MergeStrategy int
+
+const (
+	MergeSum MergeStrategy = iota
+	MergeMax
+	MergeKeepTarget
+)
+
+var (
+	ErrInvalidQuantity = errors.New("quantity must be greater than zero")
+	ErrItemNotFound    = errors.New("item is not in the cart")
+	// ErrCartConflict means the cart kept changing underneath an update.
+	ErrCartConflict = errors.New("cart was changed concurrently")
+	// ErrCartNotPriceable means a product price could not be looked up, so
+	// no total is given rather than one that leaves the line out.
+	ErrCartNotPriceable = errors.New("cart cannot be priced right now")
+)
+
+// CartItem is one line of a cart. UserID is the cart ID: a user ID, or a
+// guest cart ID for anonymous shoppers.
 type CartItem struct {
-	UserID    string `bson:"user_id"`
-	ProductID string `bson:"product_id"`
-	Quantity  int32  `bson:"quantity"`
+	UserID    string    `bson:"user_id"`
+	ProductID string    `bson:"product_id"`
+	SKUID     string    `bson:"sku_id"`
+	Quantity  int32     `bson:"quantity"`
+	AddedAt   time.Time `bson:"added_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+	// AddedPrice is the unit price the shopper saw when the item was last
+	// added. ValidateCart reports a price change against it.
+	AddedPrice float64 `bson:"added_price,omitempty"`
+}
+
+// Cart is a whole cart, stored as one document. Version goes up by one with
+// every stored change; an update only succeeds against the version it was
+// made from, so concurrent changes cannot overwrite each other.
+type Cart struct {
+	ID        string     `bson:"_id"`
+	Items     []CartItem `bson:"items"`
+	Version   int64      `bson:"version"`
+	CreatedAt time.Time  `bson:"created_at"`
+	UpdatedAt time.Time  `bson:"updated_at"`
+	// ExpiresAt is only set on guest carts; a TTL index removes them.
+	ExpiresAt *time.Time `bson:"expires_at,omitempty"`
+	// RemindedAt is when an abandoned cart reminder last went out for the cart.
+	RemindedAt *time.Time `bson:"reminded_at,omitempty"`
+}
+
+func (c *Cart) indexOf(productID, skuID string) int {
+	for i, item := range c.Items {
+		if item.ProductID == productID && item.SKUID == skuID {
+			return i
+		}
+	}
+	return -1
+}
+
+// Item returns the line holding exactly this product and SKU.
+func (c *Cart) Item(productID, skuID string) (CartItem, bool) {
+	if i := c.indexOf(productID, skuID); i >= 0 {
+		return c.Items[i], true
+	}
+	return CartItem{}, false
+}
+
+// Add adds item's quantity to the line of the same product and SKU, or adds
+// the line. A known AddedPrice replaces the one on the line.
+func (c *Cart) Add(item CartItem, now time.Time) {
+	if i := c.indexOf(item.ProductID, item.SKUID); i >= 0 {
+		line := &c.Items[i]
+		line.Quantity += item.Quantity
+		line.UpdatedAt = now
+		if item.AddedPrice > 0 {
+			line.AddedPrice = item.AddedPrice
+		}
+		return
+	}
+	item.UserID = c.ID
+	item.AddedAt = now
+	item.UpdatedAt = now
+	c.Items = append(c.Items, item)
+}
+
+func (c *Cart) SetQuantity(productID, skuID string, quantity int32, now time.Time) error {
+	i := c.indexOf(productID, skuID)
+	if i < 0 {
+		return ErrItemNotFound
+	}
+	c.Items[i].Quantity = quantity
+	c.Items[i].UpdatedAt = now
+	return nil
+}
+
+// SetAddedPrice makes price the one the shopper has agreed to for an item.
+func (c *Cart) SetAddedPrice(productID, skuID string, price float64) error {
+	i := c.indexOf(productID, skuID)
+	if i < 0 {
+		return ErrItemNotFound
+	}
+	c.Items[i].AddedPrice = price
+	return nil
+}
+
+func (c *Cart) Remove(productID, skuID string) error {
+	i := c.indexOf(productID, skuID)
+	if i < 0 {
+		return ErrItemNotFound
+	}
+	c.Items = append(c.Items[:i], c.Items[i+1:]...)
+	return nil
+}
+
+// Clear empties the cart. The cart itself stays so that its version keeps
+// going up.
+func (c *Cart) Clear() {
+	c.Items = nil
+}
+
+// CartChange says which operation produced a cart version.
+type CartChange string
+
+const (
+	CartItemAdded       CartChange = "item_added"
+	CartItemRemoved     CartChange = "item_removed"
+	CartQuantityUpdated CartChange = "quantity_updated"
+	CartCleared         CartChange = "cleared"
+	// CartMerged is the change to the target cart of a merge; the source
+	// cart is CartCleared.
+	CartMerged    CartChange = "merged"
+	CartValidated CartChange = "validated"
+)
+
+// PricedItem is a cart line enriched with the product data it was priced with.
+type PricedItem struct {
+	CartItem
+	Name      string
+	Category  string
+	ListPrice float64
+	UnitPrice float64
+	LineTotal float64
+	Available bool
+}
+
+// PricedCart is a cart with totals. Subtotal is at list prices, Discount is
+// what running sales and promotions take off, itemised in Discounts, and Tax
+// is charged on the discounted amount.
+type PricedCart struct {
+	UserID    string
+	Items     []PricedItem
+	Subtotal  float64
+	Discount  float64
+	Discounts []DiscountLine
+	Coupons   []AppliedCoupon
+	Tax       float64
+	Total     float64
+	Currency  string
+}
+
+// IdleCart is a cart that has not been changed since LastActivity.
+type IdleCart struct {
+	CartID       string
+	LastActivity time.Time
+}
+
+// AbandonedCart is a priced user cart that has been idle long enough to
+// remind its owner about it.
+type AbandonedCart struct {
+	Cart         *PricedCart
+	LastActivity time.Time
+}
+
+// IssueKind says what is wrong with a cart line.
+type IssueKind int
+
+const (
+	// IssueRemoved means the product or SKU no longer exists.
+	IssueRemoved IssueKind = iota
+	IssueInsufficientStock
+	// IssuePriceChanged means the unit price differs from AddedPrice.
+	IssuePriceChanged
+)
+
+func (k IssueKind) String() string {
+	switch k {
+	case IssueRemoved:
+		return "removed"
+	case IssueInsufficientStock:
+		return "insufficient_stock"
+	case IssuePriceChanged:
+		return "price_changed"
+	default:
+		return "unknown"
+	}
+}
+
+// CartIssue is one problem ValidateCart found. Resolved is set when the
+// problem was fixed in the cart during validation.
+type CartIssue struct {
+	ProductID         string
+	SKUID             string
+	Kind              IssueKind
+	RequestedQuantity int32
+	AvailableQuantity int32
+	OldPrice          float64
+	NewPrice          float64
+	Resolved          bool
+}
+
+// HasUnresolvedIssues reports whether checkout has to be refused.
+func HasUnresolvedIssues(issues []CartIssue) bool {
+	for _, issue := range issues {
+		if !issue.Resolved {
+			return true
+		}
+	}
+	return false
 }
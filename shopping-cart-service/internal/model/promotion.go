@@ -0,0 +1,215 @@
+package model
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+// PromotionKind decides how a promotion computes its discount.
+type PromotionKind int
+
+const (
+	// PromotionPercentage takes Percent off the whole cart.
+	PromotionPercentage PromotionKind = iota
+	// PromotionFixedAmount takes Amount off the whole cart.
+	PromotionFixedAmount
+	// PromotionBuyXGetY makes FreeQuantity of every BuyQuantity+FreeQuantity
+	// units of ProductID free.
+	PromotionBuyXGetY
+	// PromotionCategory takes Percent off every line in Category.
+	PromotionCategory
+)
+
+func (k PromotionKind) String() string {
+	switch k {
+	case PromotionPercentage:
+		return "percentage"
+	case PromotionFixedAmount:
+		return "fixed_amount"
+	case PromotionBuyXGetY:
+		return "buy_x_get_y"
+	case PromotionCategory:
+		return "category"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	ErrPromotionNotFound    = errors.New("promotion not found")
+	ErrInvalidPromotion     = errors.New("invalid promotion")
+	ErrPromotionInactive    = errors.New("promotion is not active")
+	ErrPromotionExpired     = errors.New("promotion has expired")
+	ErrMinSpendNotMet       = errors.New("cart does not reach the minimum spend")
+	ErrUsageLimitReached    = errors.New("promotion usage limit reached")
+	ErrCouponNotApplicable  = errors.New("coupon does not apply to any item in the cart")
+	ErrCouponAlreadyApplied = errors.New("coupon is already applied to the cart")
+	// ErrLoginRequired means the promotion has a per-user limit, which a
+	// guest cart cannot be counted against.
+	ErrLoginRequired = errors.New("promotion is limited per customer, log in to use it")
+)
+
+// Promotion is a discount rule. Promotions with a Code are coupons the
+// shopper applies to the cart; promotions without one apply to every cart
+// they are eligible for.
+//
+// MaxUses and MaxUsesPerUser limit how many orders may redeem the promotion;
+// zero means unlimited. Uses counts the orders that did.
+type Promotion struct {
+	ID          string        `bson:"_id"`
+	Code        string        `bson:"code,omitempty"`
+	Description string        `bson:"description"`
+	Kind        PromotionKind `bson:"kind"`
+
+	Percent      float64 `bson:"percent,omitempty"`
+	Amount       float64 `bson:"amount,omitempty"`
+	ProductID    string  `bson:"product_id,omitempty"`
+	BuyQuantity  int32   `bson:"buy_quantity,omitempty"`
+	FreeQuantity int32   `bson:"free_quantity,omitempty"`
+	Category     string  `bson:"category,omitempty"`
+
+	MinSpend       float64 `bson:"min_spend,omitempty"`
+	MaxUses        int64   `bson:"max_uses,omitempty"`
+	MaxUsesPerUser int64   `bson:"max_uses_per_user,omitempty"`
+	Uses           int64   `bson:"uses"`
+
+	// StartsAt and EndsAt bound when the promotion applies. A zero EndsAt
+	// means it never ends.
+	StartsAt  time.Time `bson:"starts_at"`
+	EndsAt    time.Time `bson:"ends_at,omitempty"`
+	Active    bool      `bson:"active"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// NormalizeCouponCode makes coupon codes case-insensitive.
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (p *Promotion) Automatic() bool {
+	return p.Code == ""
+}
+
+// Validate checks that the rule makes sense before it is stored.
+func (p *Promotion) Validate() error {
+	switch p.Kind {
+	case PromotionPercentage, PromotionCategory:
+		if p.Percent <= 0 || p.Percent > 100 {
+			return ErrInvalidPromotion
+		}
+		if p.Kind == PromotionCategory && p.Category == "" {
+			return ErrInvalidPromotion
+		}
+	case PromotionFixedAmount:
+		if p.Amount <= 0 {
+			return ErrInvalidPromotion
+		}
+	case PromotionBuyXGetY:
+		if p.ProductID == "" || p.BuyQuantity <= 0 || p.FreeQuantity <= 0 {
+			return ErrInvalidPromotion
+		}
+	default:
+		return ErrInvalidPromotion
+	}
+	if p.MinSpend < 0 || p.MaxUses < 0 || p.MaxUsesPerUser < 0 {
+		return ErrInvalidPromotion
+	}
+	if !p.EndsAt.IsZero() && !p.EndsAt.After(p.StartsAt) {
+		return ErrInvalidPromotion
+	}
+	return nil
+}
+
+// CheckAvailable reports why the promotion cannot be used at now, if it
+// cannot. userUses is how many orders of the user already redeemed it.
+func (p *Promotion) CheckAvailable(now time.Time, userUses int64) error {
+	switch {
+	case !p.Active || now.Before(p.StartsAt):
+		return ErrPromotionInactive
+	case !p.EndsAt.IsZero() && !now.Before(p.EndsAt):
+		return ErrPromotionExpired
+	case p.MaxUses > 0 && p.Uses >= p.MaxUses:
+		return ErrUsageLimitReached
+	case p.MaxUsesPerUser > 0 && userUses >= p.MaxUsesPerUser:
+		return ErrUsageLimitReached
+	}
+	return nil
+}
+
+// Discount computes what the promotion takes off the available lines of
+// cart. Minimum spend is checked against what the lines cost after sale
+// prices. The discount never exceeds that amount.
+func (p *Promotion) Discount(cart *PricedCart) (float64, error) {
+	var spend float64
+	for _, item := range cart.Items {
+		if item.Available {
+			spend += item.LineTotal
+		}
+	}
+	if spend < p.MinSpend {
+		return 0, ErrMinSpendNotMet
+	}
+
+	var discount float64
+	switch p.Kind {
+	case PromotionPercentage:
+		discount = spend * p.Percent / 100
+	case PromotionFixedAmount:
+		discount = p.Amount
+	case PromotionCategory:
+		for _, item := range cart.Items {
+			if item.Available && strings.EqualFold(item.Category, p.Category) {
+				discount += item.LineTotal * p.Percent / 100
+			}
+		}
+	case PromotionBuyXGetY:
+		for _, item := range cart.Items {
+			if !item.Available || item.ProductID != p.ProductID {
+				continue
+			}
+			free := item.Quantity / (p.BuyQuantity + p.FreeQuantity) * p.FreeQuantity
+			discount += float64(free) * item.UnitPrice
+		}
+	}
+
+	if discount <= 0 {
+		return 0, ErrCouponNotApplicable
+	}
+	return math.Min(discount, spend), nil
+}
+
+// DiscountSource tells sale price reductions from promotions.
+const (
+	DiscountSourceSale      = "sale"
+	DiscountSourcePromotion = "promotion"
+)
+
+// DiscountLine is one entry of a cart's discount breakdown.
+type DiscountLine struct {
+	Source      string
+	PromotionID string
+	Code        string
+	Description string
+	Amount      float64
+}
+
+// AppliedCoupon is a coupon attached to a cart. Reason says why it currently
+// takes nothing off when Applied is false.
+type AppliedCoupon struct {
+	Code    string
+	Applied bool
+	Reason  string
+}
+
+// Redemption records that an order used a promotion. There is at most one
+// per order and promotion.
+type Redemption struct {
+	OrderID     string    `bson:"order_id"`
+	PromotionID string    `bson:"promotion_id"`
+	UserID      string    `bson:"user_id"`
+	Code        string    `bson:"code,omitempty"`
+	Amount      float64   `bson:"amount"`
+	RedeemedAt  time.Time `bson:"redeemed_at"`
+}
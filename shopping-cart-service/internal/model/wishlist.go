@@ -0,0 +1,63 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// ListKind tells the two lists every user has apart from the ones they name
+// themselves.
+type ListKind int
+
+const (
+	ListCustom ListKind = iota
+	ListWishlist
+	ListSaveForLater
+)
+
+var (
+	ErrListNotFound  = errors.New("list not found")
+	ErrDefaultList   = errors.New("default lists cannot be renamed or deleted")
+	ErrEmptyListName = errors.New("list name must not be empty")
+)
+
+// DefaultListName is the name a default list is created with.
+func (k ListKind) DefaultListName() string {
+	switch k {
+	case ListWishlist:
+		return "Wishlist"
+	case ListSaveForLater:
+		return "Saved for later"
+	default:
+		return ""
+	}
+}
+
+// Wishlist is a named list of products a user parks outside the cart. Anyone
+// holding ShareToken can read the list but not change it.
+type Wishlist struct {
+	ID         string         `bson:"_id"`
+	UserID     string         `bson:"user_id"`
+	Name       string         `bson:"name"`
+	Kind       ListKind       `bson:"kind"`
+	Items      []WishlistItem `bson:"items"`
+	ShareToken string         `bson:"share_token,omitempty"`
+	CreatedAt  time.Time      `bson:"created_at"`
+	UpdatedAt  time.Time      `bson:"updated_at"`
+}
+
+type WishlistItem struct {
+	ProductID string    `bson:"product_id"`
+	SKUID     string    `bson:"sku_id"`
+	Quantity  int32     `bson:"quantity"`
+	AddedAt   time.Time `bson:"added_at"`
+}
+
+func (l *Wishlist) FindItem(productID, skuID string) (*WishlistItem, bool) {
+	for i := range l.Items {
+		if l.Items[i].ProductID == productID && l.Items[i].SKUID == skuID {
+			return &l.Items[i], true
+		}
+	}
+	return nil, false
+}
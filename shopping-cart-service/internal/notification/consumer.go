@@ -0,0 +1,54 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"shopping-cart-service/events"
+	"shopping-cart-service/internal/model"
+)
+
+// Consumer turns cart events into emails to the cart's owner. Events only
+// carry the cart ID; the address comes from user-service, so a user's
+// current email and preferences are what count.
+type Consumer struct {
+	users  UserDirectory
+	mailer Mailer
+}
+
+func NewConsumer(users UserDirectory, mailer Mailer) *Consumer {
+	return &Consumer{users: users, mailer: mailer}
+}
+
+// HandleCartItemAdded emails the owner of the cart about the added item,
+// unless the cart belongs to a guest or the owner opted out of cart emails
+// or of email altogether.
+func (c *Consumer) HandleCartItemAdded(ctx context.Context, event events.CartItemAddedEvent) error {
+	if model.IsGuestCart(event.UserID) {
+		return nil
+	}
+
+	user, err := c.users.GetUser(ctx, event.UserID)
+	if err != nil {
+		return fmt.Errorf("resolve user %s: %w", event.UserID, err)
+	}
+	if user.CartUpdateEmailsOptOut || user.EmailMuted {
+		log.Printf("[NOTIFY] User %s opted out of cart emails", event.UserID)
+		return nil
+	}
+	if user.Email == "" {
+		return fmt.Errorf("user %s has no email address", event.UserID)
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nYou added product %s (x%d) to your cart.", user.Name, event.ProductID, event.Quantity)
+	if err := c.mailer.Send(user.Email, "🛒 Cart updated", body); err != nil {
+		if errors.Is(err, ErrMailerDisabled) {
+			return nil
+		}
+		return fmt.Errorf("send cart email to user %s: %w", event.UserID, err)
+	}
+	log.Printf("[NOTIFY] Cart email sent to user %s", event.UserID)
+	return nil
+}
@@ -0,0 +1,51 @@
+package notification_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"shopping-cart-service/events"
+	"shopping-cart-service/internal/notification"
+)
+
+type fakeUsers map[string]*notification.Recipient
+
+func (f fakeUsers) GetUser(_ context.Context, userID string) (*notification.Recipient, error) {
+	if user, ok := f[userID]; ok {
+		return user, nil
+	}
+	return nil, errors.New("not found")
+}
+
+type sentMail struct{ to, subject, body string }
+
+type fakeMailer struct{ sent []sentMail }
+
+func (m *fakeMailer) Send(to, subject, body string) error {
+	m.sent = append(m.sent, sentMail{to, subject, body})
+	return nil
+}
+
+func TestHandleCartItemAdded(t *testing.T) {
+	users := fakeUsers{
+		"u1": {UserID: "u1", Email: "ann@example.com", Name: "Ann"},
+		"u2": {UserID: "u2", Email: "bob@example.com", Name: "Bob", CartUpdateEmailsOptOut: true},
+		"u3": {UserID: "u3", Email: "cem@example.com", Name: "Cem", EmailMuted: true},
+	}
+	mailer := &fakeMailer{}
+	consumer := notification.NewConsumer(users, mailer)
+	ctx := context.Background()
+
+	assert.NoError(t, consumer.HandleCartItemAdded(ctx, events.CartItemAddedEvent{UserID: "u1", ProductID: "milk", Quantity: 2}))
+	assert.NoError(t, consumer.HandleCartItemAdded(ctx, events.CartItemAddedEvent{UserID: "u2", ProductID: "milk", Quantity: 1}))
+	assert.NoError(t, consumer.HandleCartItemAdded(ctx, events.CartItemAddedEvent{UserID: "u3", ProductID: "milk", Quantity: 1}))
+	assert.NoError(t, consumer.HandleCartItemAdded(ctx, events.CartItemAddedEvent{UserID: "guest:abc", ProductID: "milk", Quantity: 1}))
+	assert.Error(t, consumer.HandleCartItemAdded(ctx, events.CartItemAddedEvent{UserID: "ghost", ProductID: "milk", Quantity: 1}))
+
+	if assert.Len(t, mailer.sent, 1) {
+		assert.Equal(t, "ann@example.com", mailer.sent[0].to)
+		assert.Contains(t, mailer.sent[0].body, "milk (x2)")
+	}
+}
@@ -0,0 +1,77 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// SMTPConfig is where cart emails are sent through. It is read from the
+// SMTP_* environment variables, the same ones user-service uses.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	// From defaults to Username.
+	From string
+}
+
+func SMTPConfigFromEnv() SMTPConfig {
+	cfg := SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+	if cfg.Port == "" {
+		cfg.Port = "587"
+	}
+	if cfg.From == "" {
+		cfg.From = cfg.Username
+	}
+	return cfg
+}
+
+// Enabled reports whether enough is configured to send mail.
+func (c SMTPConfig) Enabled() bool {
+	return c.Host != "" && c.From != ""
+}
+
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+var ErrMailerDisabled = errors.New("SMTP is not configured")
+
+type smtpMailer struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPMailer(cfg SMTPConfig) Mailer {
+	return &smtpMailer{cfg: cfg}
+}
+
+func (m *smtpMailer) Send(to, subject, body string) error {
+	if !m.cfg.Enabled() {
+		return ErrMailerDisabled
+	}
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(subject, "\r\n") {
+		return fmt.Errorf("invalid email header")
+	}
+
+	message := []byte("From: " + m.cfg.From + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n\r\n" +
+		body + "\r\n")
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+	return smtp.SendMail(m.cfg.Host+":"+m.cfg.Port, auth, m.cfg.From, []string{to}, message)
+}
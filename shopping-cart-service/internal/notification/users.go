@@ -0,0 +1,63 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	userpb "shopping-cart-service/client-service/proto/userpb"
+
+	"google.golang.org/grpc"
+)
+
+// Recipient is what a cart notification needs to know about a user.
+type Recipient struct {
+	UserID string
+	Email  string
+	Name   string
+	// CartUpdateEmailsOptOut is the user's preference for cart update emails.
+	CartUpdateEmailsOptOut bool
+	// EmailMuted is set when the user turned off email notifications as a
+	// whole.
+	EmailMuted bool
+}
+
+// UserDirectory resolves cart IDs of signed-in users to their owners.
+type UserDirectory interface {
+	GetUser(ctx context.Context, userID string) (*Recipient, error)
+}
+
+type grpcUserDirectory struct {
+	client userpb.UserServiceClient
+}
+
+func NewGRPCUserDirectory(conn *grpc.ClientConn) UserDirectory {
+	return &grpcUserDirectory{client: userpb.NewUserServiceClient(conn)}
+}
+
+func (d *grpcUserDirectory) GetUser(ctx context.Context, userID string) (*Recipient, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	resp, err := d.client.GetUser(ctx, &userpb.GetUserRequest{UserId: userID})
+	if err != nil {
+		return nil, err
+	}
+	profile, err := d.client.GetProfile(ctx, &userpb.GetProfileRequest{UserId: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	recipient := &Recipient{
+		UserID:                 resp.GetUserId(),
+		Email:                  resp.GetEmail(),
+		Name:                   resp.GetName(),
+		CartUpdateEmailsOptOut: resp.GetCartUpdateEmailsOptOut(),
+		EmailMuted:             true,
+	}
+	for _, channel := range profile.GetProfile().GetNotificationChannels() {
+		if channel == "email" {
+			recipient.EmailMuted = false
+		}
+	}
+	return recipient, nil
+}
@@ -4,66 +4,209 @@ import (
 	"context"
 	"log"
 	"shopping-cart-service/internal/model"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type CartRepository struct {
 	collection *mongo.Collection
+	// legacy holds carts stored the old way, one document per item. It is
+	// only read by MigrateLegacyItems.
+	legacy *mongo.Collection
 }
 
 func NewCartRepository(db *mongo.Database) *CartRepository {
-	return &CartRepository{
-		collection: db.Collection("cart"),
+	r := &CartRepository{
+		collection: db.Collection("carts"),
+		legacy:     db.Collection("cart"),
 	}
+	r.ensureIndexes()
+	return r
 }
 
-func (r *CartRepository) AddToCart(ctx context.Context, item model.CartItem) error {
-	log.Printf("[DB] ➕ AddToCart: user_id=%s, product_id=%s, qty=%d", item.UserID, item.ProductID, item.Quantity)
+// ensureIndexes creates the TTL index that lets MongoDB drop expired guest
+// carts. Carts without expires_at are never removed by it.
+func (r *CartRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	filter := bson.M{"user_id": item.UserID, "product_id": item.ProductID}
-	update := bson.M{"$inc": bson.M{"quantity": item.Quantity}}
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		log.Printf("[DB] Failed to create TTL index: %v", err)
+	}
+}
 
-	result, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+func (r *CartRepository) GetCart(ctx context.Context, cartID string) (*model.Cart, error) {
+	log.Printf("[DB] 📥 GetCart: cart_id=%s", cartID)
+
+	var cart model.Cart
+	err := r.collection.FindOne(ctx, bson.M{"_id": cartID}).Decode(&cart)
+	if err == mongo.ErrNoDocuments {
+		return &model.Cart{ID: cartID}, nil
+	}
 	if err != nil {
-		log.Printf("[DB] AddToCart error: %v", err)
-		return err
+		log.Printf("[DB] GetCart error: %v", err)
+		return nil, err
 	}
 
-	log.Printf("[DB] Updated: matched=%d, modified=%d", result.MatchedCount, result.ModifiedCount)
+	log.Printf("[DB] Found %d items at version %d", len(cart.Items), cart.Version)
+	return &cart, nil
+}
+
+func (r *CartRepository) SaveCart(ctx context.Context, cart *model.Cart) error {
+	log.Printf("[DB] 💾 SaveCart: cart_id=%s, version=%d, items=%d", cart.ID, cart.Version+1, len(cart.Items))
+
+	next := *cart
+	next.Version = cart.Version + 1
+
+	if cart.Version == 0 {
+		_, err := r.collection.InsertOne(ctx, next)
+		if mongo.IsDuplicateKeyError(err) {
+			return model.ErrCartConflict
+		}
+		if err != nil {
+			log.Printf("[DB] SaveCart error: %v", err)
+			return err
+		}
+	} else {
+		result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": cart.ID, "version": cart.Version}, next)
+		if err != nil {
+			log.Printf("[DB] SaveCart error: %v", err)
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return model.ErrCartConflict
+		}
+	}
+
+	cart.Version = next.Version
 	return nil
 }
 
-func (r *CartRepository) GetCart(ctx context.Context, userID string) ([]model.CartItem, error) {
-	log.Printf("[DB] 📥 GetCart: user_id=%s", userID)
+// FindIdleCarts returns the user carts with items whose last change is older
+// than idleSince and that have not been reminded about since that change.
+// Guest carts are skipped since there is nobody to remind.
+func (r *CartRepository) FindIdleCarts(ctx context.Context, idleSince time.Time) ([]model.IdleCart, error) {
+	filter := bson.M{
+		"_id":        bson.M{"$not": primitive.Regex{Pattern: "^" + model.GuestCartPrefix}},
+		"items.0":    bson.M{"$exists": true},
+		"updated_at": bson.M{"$lt": idleSince},
+		// A missing reminded_at sorts before any date.
+		"$expr": bson.M{"$lt": bson.A{"$reminded_at", "$updated_at"}},
+	}
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "updated_at": 1})
 
-	filter := bson.M{"user_id": userID}
-	cursor, err := r.collection.Find(ctx, filter)
+	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
-		log.Printf("[DB] GetCart error: %v", err)
+		log.Printf("[DB] FindIdleCarts error: %v", err)
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	var items []model.CartItem
-	if err = cursor.All(ctx, &items); err != nil {
+	var found []model.Cart
+	if err = cursor.All(ctx, &found); err != nil {
 		log.Printf("[DB] Cursor error: %v", err)
 		return nil, err
 	}
 
-	log.Printf("[DB] Found %d items", len(items))
-	return items, nil
+	carts := make([]model.IdleCart, 0, len(found))
+	for _, cart := range found {
+		carts = append(carts, model.IdleCart{CartID: cart.ID, LastActivity: cart.UpdatedAt})
+	}
+	return carts, nil
 }
 
-func (r *CartRepository) RemoveFromCart(ctx context.Context, userID string, productID string) error {
-	log.Printf("[DB] RemoveFromCart: user_id=%s, product_id=%s", userID, productID)
+// DeleteCart removes the whole cart document. Deleting a cart that does not
+// exist is not an error.
+func (r *CartRepository) DeleteCart(ctx context.Context, cartID string) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": cartID}); err != nil {
+		log.Printf("[DB] DeleteCart error: %v", err)
+		return err
+	}
+	return nil
+}
 
-	filter := bson.M{"user_id": userID, "product_id": productID}
-	_, err := r.collection.DeleteOne(ctx, filter)
+// MarkReminded records that a reminder went out for the cart. It leaves
+// updated_at alone so the cart keeps counting as idle.
+func (r *CartRepository) MarkReminded(ctx context.Context, cartID string, at time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": cartID}, bson.M{"$set": bson.M{"reminded_at": at}})
 	if err != nil {
-		log.Printf("[DB] RemoveFromCart error: %v", err)
+		log.Printf("[DB] MarkReminded error: %v", err)
 	}
 	return err
 }
+
+// legacyItem is a cart line as stored before carts became one document.
+type legacyItem struct {
+	model.CartItem `bson:",inline"`
+	ExpiresAt      *time.Time `bson:"expires_at,omitempty"`
+	RemindedAt     *time.Time `bson:"reminded_at,omitempty"`
+}
+
+// MigrateLegacyItems turns the per-item documents of the old cart
+// collection into cart documents at version 1 and removes them. A cart that
+// already exists as a document wins over its legacy items. It is safe to run
+// on every start.
+func (r *CartRepository) MigrateLegacyItems(ctx context.Context) error {
+	cursor, err := r.legacy.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": "$user_id", "items": bson.M{"$push": "$$ROOT"}}}},
+	})
+	if err != nil {
+		log.Printf("[DB] MigrateLegacyItems error: %v", err)
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	migrated := 0
+	for cursor.Next(ctx) {
+		var group struct {
+			CartID string       `bson:"_id"`
+			Items  []legacyItem `bson:"items"`
+		}
+		if err := cursor.Decode(&group); err != nil {
+			return err
+		}
+
+		cart := model.Cart{ID: group.CartID, Version: 1}
+		for _, item := range group.Items {
+			cart.Items = append(cart.Items, item.CartItem)
+			if cart.CreatedAt.IsZero() || item.AddedAt.Before(cart.CreatedAt) {
+				cart.CreatedAt = item.AddedAt
+			}
+			if item.UpdatedAt.After(cart.UpdatedAt) {
+				cart.UpdatedAt = item.UpdatedAt
+			}
+			if item.ExpiresAt != nil && (cart.ExpiresAt == nil || item.ExpiresAt.After(*cart.ExpiresAt)) {
+				cart.ExpiresAt = item.ExpiresAt
+			}
+			if item.RemindedAt != nil && (cart.RemindedAt == nil || item.RemindedAt.After(*cart.RemindedAt)) {
+				cart.RemindedAt = item.RemindedAt
+			}
+		}
+
+		if _, err := r.collection.InsertOne(ctx, cart); err != nil && !mongo.IsDuplicateKeyError(err) {
+			log.Printf("[DB] Could not migrate cart %s: %v", cart.ID, err)
+			return err
+		}
+		if _, err := r.legacy.DeleteMany(ctx, bson.M{"user_id": group.CartID}); err != nil {
+			log.Printf("[DB] Could not remove legacy items of cart %s: %v", cart.ID, err)
+			return err
+		}
+		migrated++
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	if migrated > 0 {
+		log.Printf("[DB] Migrated %d legacy carts", migrated)
+	}
+	return nil
+}
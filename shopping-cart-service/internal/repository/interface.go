@@ -3,10 +3,67 @@ package repository
 import (
 	"context"
 	"shopping-cart-service/internal/model"
+	"time"
 )
 
 type CartRepositoryInterface interface {
-	AddToCart(ctx context.Context, item model.CartItem) error
-	GetCart(ctx context.Context, userID string) ([]model.CartItem, error)
-	RemoveFromCart(ctx context.Context, userID string, productID string) error
+	// GetCart returns the stored cart, or an empty cart at version 0 when
+	// there is none.
+	GetCart(ctx context.Context, cartID string) (*model.Cart, error)
+	// SaveCart stores cart as the version after cart.Version and moves
+	// cart.Version on. It returns model.ErrCartConflict, storing nothing,
+	// when the stored cart is no longer at cart.Version.
+	SaveCart(ctx context.Context, cart *model.Cart) error
+	FindIdleCarts(ctx context.Context, idleSince time.Time) ([]model.IdleCart, error)
+	// MarkReminded records a reminder without changing the cart's version.
+	MarkReminded(ctx context.Context, cartID string, at time.Time) error
+	// DeleteCart removes the cart. Deleting a missing cart is a no-op.
+	DeleteCart(ctx context.Context, cartID string) error
+}
+
+type WishlistRepositoryInterface interface {
+	Create(ctx context.Context, list *model.Wishlist) error
+	GetByID(ctx context.Context, id string) (*model.Wishlist, error)
+	GetByShareToken(ctx context.Context, token string) (*model.Wishlist, error)
+	// GetDefault returns the user's list of the given kind, creating it on
+	// first use.
+	GetDefault(ctx context.Context, userID string, kind model.ListKind) (*model.Wishlist, error)
+	ListByUser(ctx context.Context, userID string) ([]model.Wishlist, error)
+	Rename(ctx context.Context, id string, name string) error
+	Delete(ctx context.Context, id string) error
+	AddItem(ctx context.Context, id string, item model.WishlistItem) error
+	RemoveItem(ctx context.Context, id string, productID string, skuID string) error
+	SetShareToken(ctx context.Context, id string, token string) error
+	// FindByItem returns every list holding exactly this product and SKU; an
+	// empty skuID only matches items added without a SKU.
+	FindByItem(ctx context.Context, productID string, skuID string) ([]model.Wishlist, error)
+	// DeleteByUser removes every list of the user.
+	DeleteByUser(ctx context.Context, userID string) error
+}
+
+type PromotionRepositoryInterface interface {
+	Create(ctx context.Context, promo *model.Promotion) error
+	GetByID(ctx context.Context, id string) (*model.Promotion, error)
+	GetByCode(ctx context.Context, code string) (*model.Promotion, error)
+	List(ctx context.Context, activeOnly bool) ([]model.Promotion, error)
+	// ListAutomatic returns the active promotions without a code that run at
+	// now.
+	ListAutomatic(ctx context.Context, now time.Time) ([]model.Promotion, error)
+	SetActive(ctx context.Context, id string, active bool) error
+	UserUses(ctx context.Context, promotionID string, userID string) (int64, error)
+
+	GetCartCoupons(ctx context.Context, cartID string) ([]string, error)
+	AddCartCoupon(ctx context.Context, cartID string, code string) error
+	RemoveCartCoupon(ctx context.Context, cartID string, code string) error
+	ClearCartCoupons(ctx context.Context, cartID string) error
+
+	// Redeem counts the redemption against the promotion's limits and records
+	// it. It returns model.ErrUsageLimitReached, leaving nothing changed, when
+	// a limit is exhausted. Redeeming the same order twice is a no-op.
+	Redeem(ctx context.Context, promo *model.Promotion, redemption model.Redemption) error
+	// ReleaseOrder undoes every redemption of the order.
+	ReleaseOrder(ctx context.Context, orderID string) error
+	// ForgetUser deletes the user's use counters and removes the user from
+	// their redemptions, which stay with their orders.
+	ForgetUser(ctx context.Context, userID string) error
 }
@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"time"
+
+	"shopping-cart-service/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PromotionRepository stores promotions, the coupons attached to carts and
+// the redemptions of placed orders.
+//
+// A redemption touches three documents: the redemption record, the
+// promotion's global use counter and the user's use counter. Each update is
+// conditional on its limit, and Redeem undoes the earlier ones when a later
+// one fails, so the counters never exceed the limits without needing a
+// replica set for transactions.
+type PromotionRepository struct {
+	promotions  *mongo.Collection
+	coupons     *mongo.Collection
+	usage       *mongo.Collection
+	redemptions *mongo.Collection
+}
+
+type cartCoupons struct {
+	CartID    string     `bson:"_id"`
+	Codes     []string   `bson:"codes"`
+	UpdatedAt time.Time  `bson:"updated_at"`
+	ExpiresAt *time.Time `bson:"expires_at,omitempty"`
+}
+
+func NewPromotionRepository(db *mongo.Database) *PromotionRepository {
+	r := &PromotionRepository{
+		promotions:  db.Collection("promotions"),
+		coupons:     db.Collection("cart_coupons"),
+		usage:       db.Collection("promotion_usage"),
+		redemptions: db.Collection("promotion_redemptions"),
+	}
+	r.ensureIndexes()
+	return r
+}
+
+func (r *PromotionRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.promotions.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().SetUnique(true).SetSparse(true),
+	})
+	if err != nil {
+		log.Printf("[DB] Failed to create promotion indexes: %v", err)
+	}
+
+	// Coupons of guest carts go away with the cart.
+	_, err = r.coupons.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		log.Printf("[DB] Failed to create cart coupon indexes: %v", err)
+	}
+
+	_, err = r.redemptions.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "order_id", Value: 1}, {Key: "promotion_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Printf("[DB] Failed to create redemption indexes: %v", err)
+	}
+}
+
+func (r *PromotionRepository) Create(ctx context.Context, promo *model.Promotion) error {
+	promo.ID = primitive.NewObjectID().Hex()
+	promo.Code = model.NormalizeCouponCode(promo.Code)
+	promo.Uses = 0
+	promo.CreatedAt = time.Now()
+
+	if _, err := r.promotions.InsertOne(ctx, promo); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return model.ErrInvalidPromotion
+		}
+		log.Printf("[DB] Create promotion error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *PromotionRepository) GetByID(ctx context.Context, id string) (*model.Promotion, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *PromotionRepository) GetByCode(ctx context.Context, code string) (*model.Promotion, error) {
+	code = model.NormalizeCouponCode(code)
+	if code == "" {
+		return nil, model.ErrPromotionNotFound
+	}
+	return r.findOne(ctx, bson.M{"code": code})
+}
+
+func (r *PromotionRepository) List(ctx context.Context, activeOnly bool) ([]model.Promotion, error) {
+	filter := bson.M{}
+	if activeOnly {
+		filter["active"] = true
+	}
+	return r.find(ctx, filter)
+}
+
+func (r *PromotionRepository) ListAutomatic(ctx context.Context, now time.Time) ([]model.Promotion, error) {
+	return r.find(ctx, bson.M{
+		"code":      bson.M{"$exists": false},
+		"active":    true,
+		"starts_at": bson.M{"$lte": now},
+		"$or": bson.A{
+			bson.M{"ends_at": bson.M{"$exists": false}},
+			bson.M{"ends_at": bson.M{"$gt": now}},
+		},
+	})
+}
+
+func (r *PromotionRepository) SetActive(ctx context.Context, id string, active bool) error {
+	result, err := r.promotions.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"active": active}})
+	if err != nil {
+		log.Printf("[DB] SetActive promotion error: %v", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return model.ErrPromotionNotFound
+	}
+	return nil
+}
+
+func usageID(promotionID, userID string) string {
+	return promotionID + "/" + userID
+}
+
+func (r *PromotionRepository) UserUses(ctx context.Context, promotionID string, userID string) (int64, error) {
+	var usage struct {
+		Uses int64 `bson:"uses"`
+	}
+	err := r.usage.FindOne(ctx, bson.M{"_id": usageID(promotionID, userID)}).Decode(&usage)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		log.Printf("[DB] UserUses error: %v", err)
+		return 0, err
+	}
+	return usage.Uses, nil
+}
+
+func (r *PromotionRepository) GetCartCoupons(ctx context.Context, cartID string) ([]string, error) {
+	var doc cartCoupons
+	err := r.coupons.FindOne(ctx, bson.M{"_id": cartID}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("[DB] GetCartCoupons error: %v", err)
+		return nil, err
+	}
+	return doc.Codes, nil
+}
+
+func (r *PromotionRepository) AddCartCoupon(ctx context.Context, cartID string, code string) error {
+	now := time.Now()
+	set := bson.M{"updated_at": now}
+	if model.IsGuestCart(cartID) {
+		set["expires_at"] = now.Add(model.GuestCartTTL)
+	}
+	_, err := r.coupons.UpdateOne(ctx, bson.M{"_id": cartID}, bson.M{
+		"$addToSet": bson.M{"codes": code},
+		"$set":      set,
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		log.Printf("[DB] AddCartCoupon error: %v", err)
+	}
+	return err
+}
+
+func (r *PromotionRepository) RemoveCartCoupon(ctx context.Context, cartID string, code string) error {
+	result, err := r.coupons.UpdateOne(ctx, bson.M{"_id": cartID, "codes": code}, bson.M{
+		"$pull": bson.M{"codes": code},
+		"$set":  bson.M{"updated_at": time.Now()},
+	})
+	if err != nil {
+		log.Printf("[DB] RemoveCartCoupon error: %v", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return model.ErrPromotionNotFound
+	}
+	return nil
+}
+
+func (r *PromotionRepository) ClearCartCoupons(ctx context.Context, cartID string) error {
+	if _, err := r.coupons.DeleteOne(ctx, bson.M{"_id": cartID}); err != nil {
+		log.Printf("[DB] ClearCartCoupons error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *PromotionRepository) Redeem(ctx context.Context, promo *model.Promotion, redemption model.Redemption) error {
+	redemption.PromotionID = promo.ID
+	redemption.RedeemedAt = time.Now()
+	if _, err := r.redemptions.InsertOne(ctx, redemption); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		log.Printf("[DB] Insert redemption error: %v", err)
+		return err
+	}
+
+	if err := r.countUse(ctx, promo, redemption.UserID); err != nil {
+		r.deleteRedemption(ctx, redemption.OrderID, promo.ID)
+		return err
+	}
+	log.Printf("[DB] Redeemed promotion %s for order %s", promo.ID, redemption.OrderID)
+	return nil
+}
+
+// countUse increments the global and the per-user use counters, each only
+// while it is below its limit.
+func (r *PromotionRepository) countUse(ctx context.Context, promo *model.Promotion, userID string) error {
+	filter := bson.M{"_id": promo.ID}
+	if promo.MaxUses > 0 {
+		filter["uses"] = bson.M{"$lt": promo.MaxUses}
+	}
+	result, err := r.promotions.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"uses": 1}})
+	if err != nil {
+		log.Printf("[DB] Count promotion use error: %v", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return model.ErrUsageLimitReached
+	}
+
+	filter = bson.M{"_id": usageID(promo.ID, userID)}
+	if promo.MaxUsesPerUser > 0 {
+		filter["uses"] = bson.M{"$lt": promo.MaxUsesPerUser}
+	}
+	// At the limit the filter misses the existing document and the upsert
+	// collides with it on _id.
+	_, err = r.usage.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"uses": 1}}, options.Update().SetUpsert(true))
+	if err == nil {
+		return nil
+	}
+
+	r.promotions.UpdateOne(ctx, bson.M{"_id": promo.ID}, bson.M{"$inc": bson.M{"uses": -1}})
+	if mongo.IsDuplicateKeyError(err) {
+		return model.ErrUsageLimitReached
+	}
+	log.Printf("[DB] Count user promotion use error: %v", err)
+	return err
+}
+
+func (r *PromotionRepository) ReleaseOrder(ctx context.Context, orderID string) error {
+	cursor, err := r.redemptions.Find(ctx, bson.M{"order_id": orderID})
+	if err != nil {
+		log.Printf("[DB] Find redemptions error: %v", err)
+		return err
+	}
+	var redemptions []model.Redemption
+	if err := cursor.All(ctx, &redemptions); err != nil {
+		log.Printf("[DB] Cursor error: %v", err)
+		return err
+	}
+
+	for _, redemption := range redemptions {
+		// Deleting first makes a concurrent release of the same order skip
+		// the counters.
+		if !r.deleteRedemption(ctx, orderID, redemption.PromotionID) {
+			continue
+		}
+		dec := bson.M{"$inc": bson.M{"uses": -1}}
+		if _, err := r.promotions.UpdateOne(ctx, bson.M{"_id": redemption.PromotionID}, dec); err != nil {
+			log.Printf("[DB] Release promotion use error: %v", err)
+		}
+		if _, err := r.usage.UpdateOne(ctx, bson.M{"_id": usageID(redemption.PromotionID, redemption.UserID)}, dec); err != nil {
+			log.Printf("[DB] Release user promotion use error: %v", err)
+		}
+	}
+	log.Printf("[DB] Released %d redemptions of order %s", len(redemptions), orderID)
+	return nil
+}
+
+func (r *PromotionRepository) ForgetUser(ctx context.Context, userID string) error {
+	// Use counters carry the user only in their ID.
+	usage := bson.M{"_id": primitive.Regex{Pattern: "/" + regexp.QuoteMeta(userID) + "$"}}
+	if _, err := r.usage.DeleteMany(ctx, usage); err != nil {
+		log.Printf("[DB] Delete user promotion uses error: %v", err)
+		return err
+	}
+	if _, err := r.redemptions.UpdateMany(ctx, bson.M{"user_id": userID}, bson.M{"$set": bson.M{"user_id": ""}}); err != nil {
+		log.Printf("[DB] Anonymize redemptions error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *PromotionRepository) deleteRedemption(ctx context.Context, orderID, promotionID string) bool {
+	result, err := r.redemptions.DeleteOne(ctx, bson.M{"order_id": orderID, "promotion_id": promotionID})
+	if err != nil {
+		log.Printf("[DB] Delete redemption error: %v", err)
+		return false
+	}
+	return result.DeletedCount > 0
+}
+
+func (r *PromotionRepository) findOne(ctx context.Context, filter bson.M) (*model.Promotion, error) {
+	var promo model.Promotion
+	err := r.promotions.FindOne(ctx, filter).Decode(&promo)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, model.ErrPromotionNotFound
+		}
+		log.Printf("[DB] Find promotion error: %v", err)
+		return nil, err
+	}
+	return &promo, nil
+}
+
+func (r *PromotionRepository) find(ctx context.Context, filter bson.M) ([]model.Promotion, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.promotions.Find(ctx, filter, opts)
+	if err != nil {
+		log.Printf("[DB] Find promotions error: %v", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var promos []model.Promotion
+	if err = cursor.All(ctx, &promos); err != nil {
+		log.Printf("[DB] Cursor error: %v", err)
+		return nil, err
+	}
+	return promos, nil
+}
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"shopping-cart-service/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WishlistRepository struct {
+	collection *mongo.Collection
+}
+
+func NewWishlistRepository(db *mongo.Database) *WishlistRepository {
+	r := &WishlistRepository{
+		collection: db.Collection("wishlists"),
+	}
+	r.ensureIndexes()
+	return r
+}
+
+func (r *WishlistRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		// Only one default list of each kind per user, so that concurrent
+		// GetDefault upserts cannot create two.
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "kind", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"kind": bson.M{"$gt": model.ListCustom}}),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "items.product_id", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "share_token", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+	})
+	if err != nil {
+		log.Printf("[DB] Failed to create wishlist indexes: %v", err)
+	}
+}
+
+func (r *WishlistRepository) Create(ctx context.Context, list *model.Wishlist) error {
+	now := time.Now()
+	list.ID = primitive.NewObjectID().Hex()
+	list.CreatedAt = now
+	list.UpdatedAt = now
+	if list.Items == nil {
+		list.Items = []model.WishlistItem{}
+	}
+
+	if _, err := r.collection.InsertOne(ctx, list); err != nil {
+		log.Printf("[DB] Create wishlist error: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *WishlistRepository) GetByID(ctx context.Context, id string) (*model.Wishlist, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *WishlistRepository) GetByShareToken(ctx context.Context, token string) (*model.Wishlist, error) {
+	if token == "" {
+		return nil, model.ErrListNotFound
+	}
+	return r.findOne(ctx, bson.M{"share_token": token})
+}
+
+func (r *WishlistRepository) GetDefault(ctx context.Context, userID string, kind model.ListKind) (*model.Wishlist, error) {
+	now := time.Now()
+	filter := bson.M{"user_id": userID, "kind": kind}
+	update := bson.M{"$setOnInsert": bson.M{
+		"_id":        primitive.NewObjectID().Hex(),
+		"name":       kind.DefaultListName(),
+		"items":      bson.A{},
+		"created_at": now,
+		"updated_at": now,
+	}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var list model.Wishlist
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&list); err != nil {
+		log.Printf("[DB] GetDefault wishlist error: %v", err)
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (r *WishlistRepository) ListByUser(ctx context.Context, userID string) ([]model.Wishlist, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "kind", Value: -1}, {Key: "created_at", Value: 1}})
+	return r.find(ctx, bson.M{"user_id": userID}, opts)
+}
+
+func (r *WishlistRepository) Rename(ctx context.Context, id string, name string) error {
+	return r.updateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"name": name, "updated_at": time.Now()}})
+}
+
+func (r *WishlistRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		log.Printf("[DB] Delete wishlist error: %v", err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return model.ErrListNotFound
+	}
+	return nil
+}
+
+func (r *WishlistRepository) DeleteByUser(ctx context.Context, userID string) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+		log.Printf("[DB] Delete wishlists error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// AddItem adds the quantity to the item already on the list, or appends the
+// item when it is not.
+func (r *WishlistRepository) AddItem(ctx context.Context, id string, item model.WishlistItem) error {
+	now := time.Now()
+	filter := bson.M{"_id": id, "items": bson.M{"$elemMatch": bson.M{"product_id": item.ProductID, "sku_id": item.SKUID}}}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{
+		"$inc": bson.M{"items.$.quantity": item.Quantity},
+		"$set": bson.M{"updated_at": now},
+	})
+	if err != nil {
+		log.Printf("[DB] AddItem wishlist error: %v", err)
+		return err
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	item.AddedAt = now
+	return r.updateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$push": bson.M{"items": item},
+		"$set":  bson.M{"updated_at": now},
+	})
+}
+
+func (r *WishlistRepository) RemoveItem(ctx context.Context, id string, productID string, skuID string) error {
+	filter := bson.M{"_id": id, "items": bson.M{"$elemMatch": bson.M{"product_id": productID, "sku_id": skuID}}}
+	update := bson.M{
+		"$pull": bson.M{"items": bson.M{"product_id": productID, "sku_id": skuID}},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Printf("[DB] RemoveItem wishlist error: %v", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return model.ErrItemNotFound
+	}
+	return nil
+}
+
+// SetShareToken sets the list's share token; an empty token stops sharing.
+func (r *WishlistRepository) SetShareToken(ctx context.Context, id string, token string) error {
+	update := bson.M{"$set": bson.M{"share_token": token}}
+	if token == "" {
+		update = bson.M{"$unset": bson.M{"share_token": ""}}
+	}
+	return r.updateOne(ctx, bson.M{"_id": id}, update)
+}
+
+func (r *WishlistRepository) FindByItem(ctx context.Context, productID string, skuID string) ([]model.Wishlist, error) {
+	return r.find(ctx, bson.M{"items": bson.M{"$elemMatch": bson.M{"product_id": productID, "sku_id": skuID}}})
+}
+
+func (r *WishlistRepository) findOne(ctx context.Context, filter bson.M) (*model.Wishlist, error) {
+	var list model.Wishlist
+	err := r.collection.FindOne(ctx, filter).Decode(&list)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, model.ErrListNotFound
+		}
+		log.Printf("[DB] Find wishlist error: %v", err)
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (r *WishlistRepository) find(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]model.Wishlist, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts...)
+	if err != nil {
+		log.Printf("[DB] Find wishlists error: %v", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var lists []model.Wishlist
+	if err = cursor.All(ctx, &lists); err != nil {
+		log.Printf("[DB] Cursor error: %v", err)
+		return nil, err
+	}
+	return lists, nil
+}
+
+func (r *WishlistRepository) updateOne(ctx context.Context, filter bson.M, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Printf("[DB] Update wishlist error: %v", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return model.ErrListNotFound
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"shopping-cart-service/internal/model"
+)
+
+// DetectAbandonedCarts passes every user cart that has been idle for at least
+// idleFor to notify, once per idle period. A cart is only marked as reminded
+// when notify succeeds, so failed notifications are retried on the next run.
+func (s *CartService) DetectAbandonedCarts(ctx context.Context, now time.Time, idleFor time.Duration, notify func(model.AbandonedCart) error) error {
+	idle, err := s.repo.FindIdleCarts(ctx, now.Add(-idleFor))
+	if err != nil {
+		return err
+	}
+
+	for _, c := range idle {
+		cart, err := s.GetPricedCart(ctx, c.CartID)
+		if err != nil {
+			log.Printf("[ABANDONED] Could not price cart %s: %v", c.CartID, err)
+			continue
+		}
+		if len(cart.Items) == 0 {
+			continue
+		}
+
+		if err := notify(model.AbandonedCart{Cart: cart, LastActivity: c.LastActivity}); err != nil {
+			log.Printf("[ABANDONED] Could not notify about cart %s: %v", c.CartID, err)
+			continue
+		}
+		if err := s.repo.MarkReminded(ctx, c.CartID, now); err != nil {
+			return err
+		}
+		log.Printf("[ABANDONED] Cart %s idle since %s", c.CartID, c.LastActivity.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// RunAbandonedCartScheduler calls DetectAbandonedCarts every interval. It
+// never returns.
+func (s *CartService) RunAbandonedCartScheduler(interval, idleFor time.Duration, notify func(model.AbandonedCart) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		if err := s.DetectAbandonedCarts(context.Background(), now, idleFor, notify); err != nil {
+			log.Printf("[ABANDONED] Scheduler run failed: %v", err)
+		}
+	}
+}
@@ -2,49 +2,247 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"shopping-cart-service/events"
 	"shopping-cart-service/internal/cache"
+	"shopping-cart-service/internal/catalog"
 	"shopping-cart-service/internal/model"
 	"shopping-cart-service/internal/repository"
+	"time"
 )
 
 type CartService struct {
-	repo  repository.CartRepositoryInterface
-	cache *cache.CartCache
+	repo       repository.CartRepositoryInterface
+	cache      cache.CartCache
+	products   catalog.ProductCatalog
+	promotions repository.PromotionRepositoryInterface
 }
 
-func NewCartService(repo repository.CartRepositoryInterface) *CartService {
-	c := cache.NewCartCache()
-	return &CartService{repo: repo, cache: c}
+// NewCartService creates the service. products and promotions may be nil,
+// which leaves carts unpriced or without promotions.
+func NewCartService(repo repository.CartRepositoryInterface, products catalog.ProductCatalog, c cache.CartCache, promotions repository.PromotionRepositoryInterface) *CartService {
+	return &CartService{repo: repo, cache: c, products: products, promotions: promotions}
 }
 
 func (s *CartService) AddToCart(ctx context.Context, item model.CartItem) error {
-	err := s.repo.AddToCart(ctx, item)
-	if err == nil {
-		log.Printf("[CACHE] Invalidated → user_id: %s", item.UserID)
-		s.cache.Invalidate(item.UserID)
+	if item.Quantity <= 0 {
+		return model.ErrInvalidQuantity
 	}
+	if s.products != nil {
+		snapshot, err := s.products.GetProduct(ctx, item.ProductID, item.SKUID)
+		if err != nil {
+			return err
+		}
+		// A SKU ID that belongs to another product is as unknown as a
+		// missing one.
+		if snapshot.ProductID != item.ProductID {
+			return catalog.ErrProductNotFound
+		}
+		item.AddedPrice = snapshot.UnitPrice
+	}
+	_, err := s.update(ctx, item.UserID, model.CartItemAdded, func(cart *model.Cart, now time.Time) error {
+		cart.Add(item, now)
+		return nil
+	})
 	return err
 }
 
 func (s *CartService) GetCart(ctx context.Context, userID string) ([]model.CartItem, error) {
-	if items, found := s.cache.Get(userID); found {
+	items, version, found := s.cache.Get(ctx, userID)
+	if found {
 		log.Println("[CACHE] Returning cart from cache for user:", userID)
 		return items, nil
 	}
-	items, err := s.repo.GetCart(ctx, userID)
-	if err == nil {
-		s.cache.Set(userID, items)
-		log.Printf("[CACHE] Set → user_id: %s, items_count: %d", userID, len(items))
+	cart, err := s.repo.GetCart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(ctx, userID, version, cart.Items)
+	log.Printf("[CACHE] Set → user_id: %s, items_count: %d", userID, len(cart.Items))
+	return cart.Items, nil
+}
+
+// RemoveFromCart takes an item out of the cart. Removing an item that is not
+// in the cart does nothing.
+func (s *CartService) RemoveFromCart(ctx context.Context, userID, productID, skuID string) error {
+	_, err := s.update(ctx, userID, model.CartItemRemoved, func(cart *model.Cart, _ time.Time) error {
+		if err := cart.Remove(productID, skuID); err != nil {
+			return errCartUnchanged
+		}
+		return nil
+	})
+	return err
+}
+
+// UpdateCartItem sets the quantity of an item to an exact value. Use
+// RemoveFromCart to take an item out of the cart.
+func (s *CartService) UpdateCartItem(ctx context.Context, userID, productID, skuID string, quantity int32) error {
+	if quantity <= 0 {
+		return model.ErrInvalidQuantity
+	}
+	_, err := s.update(ctx, userID, model.CartQuantityUpdated, func(cart *model.Cart, now time.Time) error {
+		return cart.SetQuantity(productID, skuID, quantity, now)
+	})
+	return err
+}
+
+func (s *CartService) ClearCart(ctx context.Context, userID string) error {
+	if err := s.clear(ctx, userID); err != nil {
+		return err
+	}
+	if s.promotions != nil {
+		return s.promotions.ClearCartCoupons(ctx, userID)
 	}
-	return items, err
+	return nil
 }
 
-func (s *CartService) RemoveFromCart(ctx context.Context, userID, productID string) error {
-	err := s.repo.RemoveFromCart(ctx, userID, productID)
-	if err == nil {
-		log.Println(" [CACHE] invalidated after RemoveFromCart for user:", userID)
-		s.cache.Invalidate(userID)
+// DeleteCart removes the cart and its coupons for good, where ClearCart
+// leaves an empty cart behind.
+func (s *CartService) DeleteCart(ctx context.Context, cartID string) error {
+	if err := s.repo.DeleteCart(ctx, cartID); err != nil {
+		return err
+	}
+	if err := s.cache.Invalidate(ctx, cartID); err != nil {
+		return err
 	}
+	if s.promotions != nil {
+		return s.promotions.ClearCartCoupons(ctx, cartID)
+	}
+	return nil
+}
+
+func (s *CartService) clear(ctx context.Context, cartID string) error {
+	_, err := s.update(ctx, cartID, model.CartCleared, func(cart *model.Cart, _ time.Time) error {
+		if len(cart.Items) == 0 {
+			return errCartUnchanged
+		}
+		cart.Clear()
+		return nil
+	})
 	return err
 }
+
+// MergeCart moves all items from the source cart into the target cart and
+// empties the source. strategy decides what happens to a product that is in
+// both carts.
+func (s *CartService) MergeCart(ctx context.Context, sourceUserID, targetUserID string, strategy model.MergeStrategy) error {
+	if sourceUserID == targetUserID {
+		return nil
+	}
+
+	source, err := s.repo.GetCart(ctx, sourceUserID)
+	if err != nil {
+		return err
+	}
+
+	if len(source.Items) > 0 {
+		_, err = s.update(ctx, targetUserID, model.CartMerged, func(cart *model.Cart, now time.Time) error {
+			for _, item := range source.Items {
+				current, found := cart.Item(item.ProductID, item.SKUID)
+				switch {
+				case !found || strategy == model.MergeSum:
+					cart.Add(item, now)
+				case strategy == model.MergeMax && item.Quantity > current.Quantity:
+					if err := cart.SetQuantity(item.ProductID, item.SKUID, item.Quantity, now); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := s.clear(ctx, sourceUserID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.moveCoupons(ctx, sourceUserID, targetUserID); err != nil {
+		return err
+	}
+
+	log.Printf("[CACHE] Merged %d items: %s → %s", len(source.Items), sourceUserID, targetUserID)
+	return nil
+}
+
+// moveCoupons attaches the source cart's coupons to the target cart. Whether
+// they still apply there is decided when the target cart is priced.
+func (s *CartService) moveCoupons(ctx context.Context, sourceUserID, targetUserID string) error {
+	if s.promotions == nil {
+		return nil
+	}
+	codes, err := s.promotions.GetCartCoupons(ctx, sourceUserID)
+	if err != nil {
+		return err
+	}
+	for _, code := range codes {
+		if err := s.promotions.AddCartCoupon(ctx, targetUserID, code); err != nil {
+			return err
+		}
+	}
+	return s.promotions.ClearCartCoupons(ctx, sourceUserID)
+}
+
+// maxUpdateAttempts bounds how often update starts over because another
+// change to the cart got in first.
+const maxUpdateAttempts = 5
+
+// errCartUnchanged is returned by a change that has nothing to do; update
+// then saves and publishes nothing.
+var errCartUnchanged = errors.New("cart unchanged")
+
+// update applies change to the stored cart and saves the result as the next
+// version. When another change was saved in between, it starts over from
+// the cart as that change left it, so no change is lost. An error from change
+// leaves the cart as it was. The saved cart is published on cart.updated.
+func (s *CartService) update(ctx context.Context, cartID string, kind model.CartChange, change func(cart *model.Cart, now time.Time) error) (*model.Cart, error) {
+	for attempt := 1; attempt <= maxUpdateAttempts; attempt++ {
+		cart, err := s.repo.GetCart(ctx, cartID)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		if err := change(cart, now); err != nil {
+			if errors.Is(err, errCartUnchanged) {
+				return cart, nil
+			}
+			return nil, err
+		}
+		if cart.CreatedAt.IsZero() {
+			cart.CreatedAt = now
+		}
+		cart.UpdatedAt = now
+		// User carts do not expire.
+		if model.IsGuestCart(cartID) {
+			expiresAt := now.Add(model.GuestCartTTL)
+			cart.ExpiresAt = &expiresAt
+		}
+
+		err = s.repo.SaveCart(ctx, cart)
+		if errors.Is(err, model.ErrCartConflict) {
+			log.Printf("[DB] Cart %s changed concurrently, attempt %d of %d", cartID, attempt, maxUpdateAttempts)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		invalidateErr := s.cache.Invalidate(ctx, cartID)
+		if err := events.PublishCartUpdated(events.NewCartUpdatedEvent(cart, kind)); err != nil {
+			log.Printf("[NATS] Could not publish cart.updated for cart_id=%s, version=%d: %v", cartID, cart.Version, err)
+		}
+		// The cart is saved, but other readers may still be served the old
+		// one, so the caller must not take the write as done.
+		if invalidateErr != nil {
+			return nil, fmt.Errorf("cart saved but cache not invalidated: %w", invalidateErr)
+		}
+		log.Printf("[CACHE] Invalidated → cart_id: %s", cartID)
+		return cart, nil
+	}
+	return nil, model.ErrCartConflict
+}
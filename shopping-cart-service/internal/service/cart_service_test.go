@@ -2,10 +2,14 @@ package service_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"shopping-cart-service/internal/cache"
+	"shopping-cart-service/internal/catalog"
 	"shopping-cart-service/internal/model"
 	"shopping-cart-service/internal/service"
 )
@@ -14,40 +18,147 @@ type mockRepo struct {
 	mock.Mock
 }
 
-func (m *mockRepo) AddToCart(ctx context.Context, item model.CartItem) error {
-	args := m.Called(ctx, item)
-	return args.Error(0)
+// GetCart hands out a copy, as the database would, so that a change the
+// service makes is only seen through SaveCart.
+func (m *mockRepo) GetCart(ctx context.Context, cartID string) (*model.Cart, error) {
+	args := m.Called(ctx, cartID)
+	cart, _ := args.Get(0).(*model.Cart)
+	if cart == nil {
+		return nil, args.Error(1)
+	}
+	copied := *cart
+	copied.Items = append([]model.CartItem(nil), cart.Items...)
+	return &copied, args.Error(1)
+}
+func (m *mockRepo) SaveCart(ctx context.Context, cart *model.Cart) error {
+	args := m.Called(ctx, cart)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	cart.Version++
+	return nil
 }
-func (m *mockRepo) GetCart(ctx context.Context, userID string) ([]model.CartItem, error) {
-	args := m.Called(ctx, userID)
-	return args.Get(0).([]model.CartItem), args.Error(1)
+func (m *mockRepo) FindIdleCarts(ctx context.Context, idleSince time.Time) ([]model.IdleCart, error) {
+	args := m.Called(ctx, idleSince)
+	return args.Get(0).([]model.IdleCart), args.Error(1)
 }
-func (m *mockRepo) RemoveFromCart(ctx context.Context, userID string, productID string) error {
-	args := m.Called(ctx, userID, productID)
+func (m *mockRepo) MarkReminded(ctx context.Context, cartID string, at time.Time) error {
+	args := m.Called(ctx, cartID, at)
 	return args.Error(0)
 }
+func (m *mockRepo) DeleteCart(ctx context.Context, cartID string) error {
+	return m.Called(ctx, cartID).Error(0)
+}
+
+// cartOf is a stored cart at version 1.
+func cartOf(cartID string, items ...model.CartItem) *model.Cart {
+	return &model.Cart{ID: cartID, Version: 1, Items: items}
+}
+
+// quantities maps "product/sku" to the quantity of every line of the cart.
+func quantities(cart *model.Cart) map[string]int32 {
+	q := make(map[string]int32)
+	for _, item := range cart.Items {
+		q[item.ProductID+"/"+item.SKUID] = item.Quantity
+	}
+	return q
+}
+
+// savedCart matches the cart passed to SaveCart.
+func savedCart(cartID string, version int64, want map[string]int32) interface{} {
+	return mock.MatchedBy(func(cart *model.Cart) bool {
+		if cart.ID != cartID || cart.Version != version || len(cart.Items) != len(want) {
+			return false
+		}
+		for key, quantity := range quantities(cart) {
+			if want[key] != quantity {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+type mockCatalog struct {
+	mock.Mock
+}
+
+func (m *mockCatalog) GetProduct(ctx context.Context, productID, skuID string) (*catalog.ProductSnapshot, error) {
+	args := m.Called(ctx, productID, skuID)
+	snapshot, _ := args.Get(0).(*catalog.ProductSnapshot)
+	return snapshot, args.Error(1)
+}
 
 // === ТЕСТЫ ===
 
 func TestAddToCart(t *testing.T) {
 	repo := new(mockRepo)
-	svc := service.NewCartService(repo)
+	svc := service.NewCartService(repo, nil, cache.NewMemoryCache(time.Minute), nil)
 
-	item := model.CartItem{UserID: "u1", ProductID: "p1", Quantity: 1}
-	repo.On("AddToCart", mock.Anything, item).Return(nil)
+	repo.On("GetCart", mock.Anything, "u1").Return(&model.Cart{ID: "u1"}, nil)
+	repo.On("SaveCart", mock.Anything, savedCart("u1", 0, map[string]int32{"p1/s1": 1})).Return(nil)
 
-	err := svc.AddToCart(context.Background(), item)
+	err := svc.AddToCart(context.Background(), model.CartItem{UserID: "u1", ProductID: "p1", SKUID: "s1", Quantity: 1})
 	assert.NoError(t, err)
 	repo.AssertExpectations(t)
 }
 
+func TestAddToCart_RetriesOnConflict(t *testing.T) {
+	repo := new(mockRepo)
+	svc := service.NewCartService(repo, nil, cache.NewMemoryCache(time.Minute), nil)
+
+	stale := cartOf("u1", model.CartItem{UserID: "u1", ProductID: "p1", Quantity: 1})
+	current := cartOf("u1", model.CartItem{UserID: "u1", ProductID: "p1", Quantity: 2})
+	current.Version = 2
+	repo.On("GetCart", mock.Anything, "u1").Return(stale, nil).Once()
+	repo.On("GetCart", mock.Anything, "u1").Return(current, nil).Once()
+	repo.On("SaveCart", mock.Anything, savedCart("u1", 1, map[string]int32{"p1/": 2})).Return(model.ErrCartConflict).Once()
+	repo.On("SaveCart", mock.Anything, savedCart("u1", 2, map[string]int32{"p1/": 3})).Return(nil).Once()
+
+	err := svc.AddToCart(context.Background(), model.CartItem{UserID: "u1", ProductID: "p1", Quantity: 1})
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestAddToCart_GivesUpAfterRepeatedConflicts(t *testing.T) {
+	repo := new(mockRepo)
+	svc := service.NewCartService(repo, nil, cache.NewMemoryCache(time.Minute), nil)
+
+	repo.On("GetCart", mock.Anything, "u1").Return(cartOf("u1"), nil)
+	repo.On("SaveCart", mock.Anything, mock.Anything).Return(model.ErrCartConflict)
+
+	err := svc.AddToCart(context.Background(), model.CartItem{UserID: "u1", ProductID: "p1", Quantity: 1})
+	assert.ErrorIs(t, err, model.ErrCartConflict)
+	repo.AssertNumberOfCalls(t, "SaveCart", 5)
+}
+
+// brokenCache is a cache whose invalidations fail, like Redis going away.
+type brokenCache struct {
+	*cache.MemoryCache
+}
+
+func (brokenCache) Invalidate(context.Context, string) error {
+	return errors.New("redis: connection refused")
+}
+
+func TestAddToCart_FailsWhenCacheIsNotInvalidated(t *testing.T) {
+	repo := new(mockRepo)
+	svc := service.NewCartService(repo, nil, brokenCache{cache.NewMemoryCache(time.Minute)}, nil)
+
+	repo.On("GetCart", mock.Anything, "u1").Return(&model.Cart{ID: "u1"}, nil)
+	repo.On("SaveCart", mock.Anything, mock.Anything).Return(nil)
+
+	err := svc.AddToCart(context.Background(), model.CartItem{UserID: "u1", ProductID: "p1", Quantity: 1})
+	assert.Error(t, err)
+}
+
 func TestGetCart_CacheMiss(t *testing.T) {
 	repo := new(mockRepo)
-	svc := service.NewCartService(repo)
+	svc := service.NewCartService(repo, nil, cache.NewMemoryCache(time.Minute), nil)
 
 	userID := "u2"
 	expectedItems := []model.CartItem{{UserID: userID, ProductID: "p1", Quantity: 2}}
-	repo.On("GetCart", mock.Anything, userID).Return(expectedItems, nil)
+	repo.On("GetCart", mock.Anything, userID).Return(cartOf(userID, expectedItems...), nil)
 
 	items, err := svc.GetCart(context.Background(), userID)
 	assert.NoError(t, err)
@@ -57,13 +168,232 @@ func TestGetCart_CacheMiss(t *testing.T) {
 
 func TestRemoveFromCart(t *testing.T) {
 	repo := new(mockRepo)
-	svc := service.NewCartService(repo)
+	svc := service.NewCartService(repo, nil, cache.NewMemoryCache(time.Minute), nil)
 
 	userID := "u3"
-	productID := "p2"
-	repo.On("RemoveFromCart", mock.Anything, userID, productID).Return(nil)
+	repo.On("GetCart", mock.Anything, userID).Return(cartOf(userID,
+		model.CartItem{UserID: userID, ProductID: "p1", Quantity: 1},
+		model.CartItem{UserID: userID, ProductID: "p2", SKUID: "s2", Quantity: 1},
+	), nil)
+	repo.On("SaveCart", mock.Anything, savedCart(userID, 1, map[string]int32{"p1/": 1})).Return(nil).Once()
+
+	err := svc.RemoveFromCart(context.Background(), userID, "p2", "s2")
+	assert.NoError(t, err)
+
+	// Removing what is not in the cart changes nothing.
+	err = svc.RemoveFromCart(context.Background(), userID, "p9", "")
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+	repo.AssertNumberOfCalls(t, "SaveCart", 1)
+}
+
+func TestUpdateCartItem_InvalidQuantity(t *testing.T) {
+	repo := new(mockRepo)
+	svc := service.NewCartService(repo, nil, cache.NewMemoryCache(time.Minute), nil)
+
+	err := svc.UpdateCartItem(context.Background(), "u4", "p1", "", 0)
+	assert.ErrorIs(t, err, model.ErrInvalidQuantity)
+
+	err = svc.AddToCart(context.Background(), model.CartItem{UserID: "u4", ProductID: "p1", Quantity: -1})
+	assert.ErrorIs(t, err, model.ErrInvalidQuantity)
+	repo.AssertNotCalled(t, "SaveCart", mock.Anything, mock.Anything)
+}
+
+func TestUpdateCartItem_NotInCart(t *testing.T) {
+	repo := new(mockRepo)
+	svc := service.NewCartService(repo, nil, cache.NewMemoryCache(time.Minute), nil)
+
+	repo.On("GetCart", mock.Anything, "u4").Return(cartOf("u4"), nil)
+
+	err := svc.UpdateCartItem(context.Background(), "u4", "p1", "", 2)
+	assert.ErrorIs(t, err, model.ErrItemNotFound)
+	repo.AssertNotCalled(t, "SaveCart", mock.Anything, mock.Anything)
+}
+
+func TestAddToCart_GuestCartExpiry(t *testing.T) {
+	repo := new(mockRepo)
+	svc := service.NewCartService(repo, nil, cache.NewMemoryCache(time.Minute), nil)
+
+	guest := model.GuestCartPrefix + "xyz"
+	repo.On("GetCart", mock.Anything, guest).Return(&model.Cart{ID: guest}, nil)
+	repo.On("SaveCart", mock.Anything, mock.MatchedBy(func(cart *model.Cart) bool {
+		return cart.ExpiresAt != nil && cart.ExpiresAt.After(time.Now().Add(model.GuestCartTTL-time.Minute))
+	})).Return(nil)
+
+	err := svc.AddToCart(context.Background(), model.CartItem{UserID: guest, ProductID: "p1", Quantity: 1})
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestMergeCart(t *testing.T) {
+	repo := new(mockRepo)
+	svc := service.NewCartService(repo, nil, cache.NewMemoryCache(time.Minute), nil)
+
+	repo.On("GetCart", mock.Anything, "guest").Return(cartOf("guest",
+		model.CartItem{UserID: "guest", ProductID: "p1", Quantity: 2},
+		model.CartItem{UserID: "guest", ProductID: "p2", SKUID: "s1", Quantity: 1},
+	), nil)
+	repo.On("GetCart", mock.Anything, "u5").Return(&model.Cart{ID: "u5"}, nil)
+	repo.On("SaveCart", mock.Anything, savedCart("u5", 0, map[string]int32{"p1/": 2, "p2/s1": 1})).Return(nil)
+	repo.On("SaveCart", mock.Anything, savedCart("guest", 1, map[string]int32{})).Return(nil)
+
+	err := svc.MergeCart(context.Background(), "guest", "u5", model.MergeSum)
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestMergeCart_GuestIntoUserKeepsLargerQuantity(t *testing.T) {
+	repo := new(mockRepo)
+	svc := service.NewCartService(repo, nil, cache.NewMemoryCache(time.Minute), nil)
+
+	guest := model.GuestCartPrefix + "abc"
+	repo.On("GetCart", mock.Anything, guest).Return(cartOf(guest,
+		model.CartItem{UserID: guest, ProductID: "p1", Quantity: 5},
+		model.CartItem{UserID: guest, ProductID: "p2", Quantity: 1},
+		model.CartItem{UserID: guest, ProductID: "p3", Quantity: 2},
+	), nil)
+	repo.On("GetCart", mock.Anything, "u7").Return(cartOf("u7",
+		model.CartItem{UserID: "u7", ProductID: "p1", Quantity: 3},
+		model.CartItem{UserID: "u7", ProductID: "p2", Quantity: 4},
+	), nil)
+	repo.On("SaveCart", mock.Anything, savedCart("u7", 1, map[string]int32{"p1/": 5, "p2/": 4, "p3/": 2})).Return(nil)
+	repo.On("SaveCart", mock.Anything, savedCart(guest, 1, map[string]int32{})).Return(nil)
+
+	err := svc.MergeCart(context.Background(), guest, "u7", model.MergeMax)
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestGetPricedCart(t *testing.T) {
+	repo := new(mockRepo)
+	products := new(mockCatalog)
+	svc := service.NewCartService(repo, products, cache.NewMemoryCache(time.Minute), nil)
+
+	repo.On("GetCart", mock.Anything, "u6").Return(cartOf("u6",
+		model.CartItem{UserID: "u6", ProductID: "milk", Quantity: 2},
+		model.CartItem{UserID: "u6", ProductID: "bread", Quantity: 1},
+		model.CartItem{UserID: "u6", ProductID: "gone", Quantity: 3},
+	), nil)
+	products.On("GetProduct", mock.Anything, "milk", "").
+		Return(&catalog.ProductSnapshot{Name: "Milk", ListPrice: 500, UnitPrice: 400, Stock: 10}, nil)
+	products.On("GetProduct", mock.Anything, "bread", "").
+		Return(&catalog.ProductSnapshot{Name: "Bread", ListPrice: 250, UnitPrice: 250, Stock: 0}, nil)
+	products.On("GetProduct", mock.Anything, "gone", "").Return(nil, catalog.ErrProductNotFound)
+
+	cart, err := svc.GetPricedCart(context.Background(), "u6")
+	assert.NoError(t, err)
+	assert.Len(t, cart.Items, 3)
+	assert.Equal(t, 1250.0, cart.Subtotal)
+	assert.Equal(t, 200.0, cart.Discount)
+	assert.Equal(t, 126.0, cart.Tax)
+	assert.Equal(t, 1176.0, cart.Total)
+	assert.Equal(t, 800.0, cart.Items[0].LineTotal)
+	assert.True(t, cart.Items[0].Available)
+	assert.False(t, cart.Items[1].Available)
+	assert.False(t, cart.Items[2].Available)
+}
+
+func TestGetPricedCart_ProductServiceDown(t *testing.T) {
+	repo := new(mockRepo)
+	products := new(mockCatalog)
+	svc := service.NewCartService(repo, products, cache.NewMemoryCache(time.Minute), nil)
+
+	repo.On("GetCart", mock.Anything, "u7").Return(cartOf("u7",
+		model.CartItem{UserID: "u7", ProductID: "milk", Quantity: 2},
+		model.CartItem{UserID: "u7", ProductID: "bread", Quantity: 1},
+	), nil)
+	products.On("GetProduct", mock.Anything, "milk", "").
+		Return(&catalog.ProductSnapshot{Name: "Milk", ListPrice: 500, UnitPrice: 500, Stock: 10}, nil)
+	products.On("GetProduct", mock.Anything, "bread", "").
+		Return(nil, errors.New("connection refused"))
+
+	cart, err := svc.GetPricedCart(context.Background(), "u7")
+	assert.ErrorIs(t, err, model.ErrCartNotPriceable)
+	assert.Nil(t, cart)
+}
+
+func TestDetectAbandonedCarts(t *testing.T) {
+	repo := new(mockRepo)
+	svc := service.NewCartService(repo, nil, cache.NewMemoryCache(time.Minute), nil)
+
+	now := time.Now()
+	lastActivity := now.Add(-30 * time.Hour)
+	repo.On("FindIdleCarts", mock.Anything, now.Add(-24*time.Hour)).Return([]model.IdleCart{
+		{CartID: "u8", LastActivity: lastActivity},
+		{CartID: "u9", LastActivity: lastActivity},
+		{CartID: "u10", LastActivity: lastActivity},
+	}, nil)
+	repo.On("GetCart", mock.Anything, "u8").Return(cartOf("u8", model.CartItem{UserID: "u8", ProductID: "p1", Quantity: 1}), nil)
+	repo.On("GetCart", mock.Anything, "u9").Return(cartOf("u9"), nil)
+	repo.On("GetCart", mock.Anything, "u10").Return(cartOf("u10", model.CartItem{UserID: "u10", ProductID: "p2", Quantity: 1}), nil)
+	repo.On("MarkReminded", mock.Anything, "u8", now).Return(nil)
+
+	var notified []string
+	err := svc.DetectAbandonedCarts(context.Background(), now, 24*time.Hour, func(c model.AbandonedCart) error {
+		if c.Cart.UserID == "u10" {
+			return errors.New("nats down")
+		}
+		notified = append(notified, c.Cart.UserID)
+		assert.Equal(t, lastActivity, c.LastActivity)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u8"}, notified)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "MarkReminded", mock.Anything, "u10", mock.Anything)
+}
+
+func TestAddToCart_UnknownProduct(t *testing.T) {
+	repo := new(mockRepo)
+	products := new(mockCatalog)
+	svc := service.NewCartService(repo, products, cache.NewMemoryCache(time.Minute), nil)
+
+	products.On("GetProduct", mock.Anything, "nope", "").Return(nil, catalog.ErrProductNotFound)
+	products.On("GetProduct", mock.Anything, "p1", "s9").Return(&catalog.ProductSnapshot{ProductID: "p2", SKUID: "s9"}, nil)
+
+	err := svc.AddToCart(context.Background(), model.CartItem{UserID: "u11", ProductID: "nope", Quantity: 1})
+	assert.ErrorIs(t, err, catalog.ErrProductNotFound)
+	err = svc.AddToCart(context.Background(), model.CartItem{UserID: "u11", ProductID: "p1", SKUID: "s9", Quantity: 1})
+	assert.ErrorIs(t, err, catalog.ErrProductNotFound)
+	repo.AssertNotCalled(t, "SaveCart", mock.Anything, mock.Anything)
+}
+
+func TestValidateCart(t *testing.T) {
+	repo := new(mockRepo)
+	products := new(mockCatalog)
+	svc := service.NewCartService(repo, products, cache.NewMemoryCache(time.Minute), nil)
+
+	repo.On("GetCart", mock.Anything, "u12").Return(cartOf("u12",
+		model.CartItem{UserID: "u12", ProductID: "gone", Quantity: 1, AddedPrice: 100},
+		model.CartItem{UserID: "u12", ProductID: "few", Quantity: 5, AddedPrice: 100},
+		model.CartItem{UserID: "u12", ProductID: "dearer", Quantity: 1, AddedPrice: 100},
+		model.CartItem{UserID: "u12", ProductID: "ok", Quantity: 1, AddedPrice: 100},
+	), nil)
+	products.On("GetProduct", mock.Anything, "gone", "").Return(nil, catalog.ErrProductNotFound)
+	products.On("GetProduct", mock.Anything, "few", "").Return(&catalog.ProductSnapshot{ProductID: "few", UnitPrice: 100, Stock: 2}, nil)
+	products.On("GetProduct", mock.Anything, "dearer", "").Return(&catalog.ProductSnapshot{ProductID: "dearer", UnitPrice: 120, Stock: 9}, nil)
+	products.On("GetProduct", mock.Anything, "ok", "").Return(&catalog.ProductSnapshot{ProductID: "ok", UnitPrice: 100, Stock: 9}, nil)
+
+	issues, err := svc.ValidateCart(context.Background(), "u12", false)
+	assert.NoError(t, err)
+	assert.Len(t, issues, 3)
+	assert.Equal(t, model.IssueRemoved, issues[0].Kind)
+	assert.Equal(t, model.IssueInsufficientStock, issues[1].Kind)
+	assert.Equal(t, int32(2), issues[1].AvailableQuantity)
+	assert.Equal(t, model.IssuePriceChanged, issues[2].Kind)
+	assert.Equal(t, 120.0, issues[2].NewPrice)
+	assert.True(t, model.HasUnresolvedIssues(issues))
+
+	repo.AssertNotCalled(t, "SaveCart", mock.Anything, mock.Anything)
+	repo.On("SaveCart", mock.Anything, mock.MatchedBy(func(cart *model.Cart) bool {
+		dearer, _ := cart.Item("dearer", "")
+		return cart.Version == 1 && dearer.AddedPrice == 120 &&
+			assert.ObjectsAreEqual(map[string]int32{"few/": 2, "dearer/": 1, "ok/": 1}, quantities(cart))
+	})).Return(nil)
 
-	err := svc.RemoveFromCart(context.Background(), userID, productID)
+	issues, err = svc.ValidateCart(context.Background(), "u12", true)
 	assert.NoError(t, err)
+	assert.Len(t, issues, 3)
+	assert.False(t, model.HasUnresolvedIssues(issues))
 	repo.AssertExpectations(t)
 }
@@ -0,0 +1,91 @@
+package service_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	productpb "shopping-cart-service/client-service/proto/productpb"
+	"shopping-cart-service/internal/cache"
+	"shopping-cart-service/internal/catalog"
+	"shopping-cart-service/internal/model"
+	"shopping-cart-service/internal/service"
+)
+
+// fakeProductServer answers like product-service: milk exists and every
+// other product was deleted.
+type fakeProductServer struct {
+	productpb.UnimplementedProductServiceServer
+}
+
+func (fakeProductServer) GetProduct(_ context.Context, req *productpb.GetProductRequest) (*productpb.GetProductResponse, error) {
+	if req.Id != "milk" {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+	return &productpb.GetProductResponse{Product: &productpb.Product{
+		Id: "milk", Name: "Milk", Price: 500, EffectivePrice: 500, Quantity: 10,
+	}}, nil
+}
+
+// grpcCatalog is the real product catalog client talking to
+// fakeProductServer.
+func grpcCatalog(t *testing.T) catalog.ProductCatalog {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	productpb.RegisterProductServiceServer(srv, fakeProductServer{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///products",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return catalog.NewGRPCProductCatalog(conn)
+}
+
+func TestValidateCart_DeletedProductOverGRPC(t *testing.T) {
+	repo := new(mockRepo)
+	svc := service.NewCartService(repo, grpcCatalog(t), cache.NewMemoryCache(time.Minute), nil)
+
+	repo.On("GetCart", mock.Anything, "u30").Return(cartOf("u30",
+		model.CartItem{UserID: "u30", ProductID: "milk", Quantity: 1, AddedPrice: 500},
+		model.CartItem{UserID: "u30", ProductID: "gone", Quantity: 1, AddedPrice: 100},
+	), nil)
+
+	issues, err := svc.ValidateCart(context.Background(), "u30", false)
+	assert.NoError(t, err)
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, model.IssueRemoved, issues[0].Kind)
+		assert.Equal(t, "gone", issues[0].ProductID)
+	}
+}
+
+func TestGetPricedCart_DeletedProductOverGRPC(t *testing.T) {
+	repo := new(mockRepo)
+	svc := service.NewCartService(repo, grpcCatalog(t), cache.NewMemoryCache(time.Minute), nil)
+
+	repo.On("GetCart", mock.Anything, "u31").Return(cartOf("u31",
+		model.CartItem{UserID: "u31", ProductID: "milk", Quantity: 2},
+		model.CartItem{UserID: "u31", ProductID: "gone", Quantity: 1},
+	), nil)
+
+	cart, err := svc.GetPricedCart(context.Background(), "u31")
+	assert.NoError(t, err)
+	if assert.Len(t, cart.Items, 2) {
+		assert.True(t, cart.Items[0].Available)
+		assert.False(t, cart.Items[1].Available)
+	}
+	assert.Equal(t, 1000.0, cart.Subtotal)
+}
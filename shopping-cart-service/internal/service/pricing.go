@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+
+	"shopping-cart-service/internal/catalog"
+	"shopping-cart-service/internal/model"
+)
+
+const (
+	// TaxRate is the VAT charged on the discounted cart amount.
+	TaxRate  = 0.12
+	Currency = "KZT"
+)
+
+// GetPricedCart returns the user's cart with every line priced from
+// product-service and the running promotions and the cart's coupons taken
+// off. Lines whose product can no longer be found are returned as
+// unavailable and left out of the totals. If product-service fails for any
+// other reason the cart is not priced and model.ErrCartNotPriceable is
+// returned.
+func (s *CartService) GetPricedCart(ctx context.Context, userID string) (*model.PricedCart, error) {
+	items, err := s.GetCart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart := &model.PricedCart{UserID: userID, Currency: Currency}
+	for _, item := range items {
+		line := model.PricedItem{CartItem: item}
+		if s.products == nil {
+			cart.Items = append(cart.Items, line)
+			continue
+		}
+
+		snapshot, err := s.products.GetProduct(ctx, item.ProductID, item.SKUID)
+		if errors.Is(err, catalog.ErrProductNotFound) {
+			cart.Items = append(cart.Items, line)
+			continue
+		}
+		if err != nil {
+			log.Printf("[PRICING] Could not price product_id=%s, sku_id=%s: %v", item.ProductID, item.SKUID, err)
+			return nil, fmt.Errorf("%w: product %s: %v", model.ErrCartNotPriceable, item.ProductID, err)
+		}
+
+		qty := float64(item.Quantity)
+		line.Name = snapshot.Name
+		line.Category = snapshot.Category
+		line.ListPrice = snapshot.ListPrice
+		line.UnitPrice = snapshot.UnitPrice
+		line.LineTotal = roundMoney(snapshot.UnitPrice * qty)
+		line.Available = snapshot.Stock >= item.Quantity
+		cart.Items = append(cart.Items, line)
+
+		cart.Subtotal += snapshot.ListPrice * qty
+		if snapshot.UnitPrice < snapshot.ListPrice {
+			cart.Discount += (snapshot.ListPrice - snapshot.UnitPrice) * qty
+		}
+	}
+
+	cart.Subtotal = roundMoney(cart.Subtotal)
+	cart.Discount = roundMoney(cart.Discount)
+	if cart.Discount > 0 {
+		cart.Discounts = append(cart.Discounts, model.DiscountLine{
+			Source:      model.DiscountSourceSale,
+			Description: "Sale prices",
+			Amount:      cart.Discount,
+		})
+	}
+	cart.Discount = roundMoney(cart.Discount + s.applyPromotions(ctx, cart))
+	cart.Tax = roundMoney((cart.Subtotal - cart.Discount) * TaxRate)
+	cart.Total = roundMoney(cart.Subtotal - cart.Discount + cart.Tax)
+	return cart, nil
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"shopping-cart-service/internal/model"
+	"shopping-cart-service/internal/repository"
+)
+
+var errPromotionsNotConfigured = errors.New("promotions are not configured")
+
+// PromotionService manages the promotion rules themselves. Applying them to
+// carts is up to CartService.
+type PromotionService struct {
+	repo repository.PromotionRepositoryInterface
+}
+
+func NewPromotionService(repo repository.PromotionRepositoryInterface) *PromotionService {
+	return &PromotionService{repo: repo}
+}
+
+func (s *PromotionService) CreatePromotion(ctx context.Context, promo *model.Promotion) error {
+	if err := promo.Validate(); err != nil {
+		return err
+	}
+	if promo.StartsAt.IsZero() {
+		promo.StartsAt = time.Now()
+	}
+	promo.Active = true
+	return s.repo.Create(ctx, promo)
+}
+
+func (s *PromotionService) GetPromotion(ctx context.Context, id string) (*model.Promotion, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *PromotionService) ListPromotions(ctx context.Context, activeOnly bool) ([]model.Promotion, error) {
+	return s.repo.List(ctx, activeOnly)
+}
+
+// DeactivatePromotion stops the promotion from applying to carts. Orders
+// that already redeemed it keep their discount.
+func (s *PromotionService) DeactivatePromotion(ctx context.Context, id string) (*model.Promotion, error) {
+	if err := s.repo.SetActive(ctx, id, false); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// ApplyCoupon attaches a coupon to the cart after checking that it currently
+// takes something off it.
+func (s *CartService) ApplyCoupon(ctx context.Context, cartID, code string) error {
+	if s.promotions == nil {
+		return errPromotionsNotConfigured
+	}
+	code = model.NormalizeCouponCode(code)
+	promo, err := s.promotions.GetByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+
+	applied, err := s.promotions.GetCartCoupons(ctx, cartID)
+	if err != nil {
+		return err
+	}
+	for _, c := range applied {
+		if c == code {
+			return model.ErrCouponAlreadyApplied
+		}
+	}
+
+	userUses, err := s.userUses(ctx, promo, cartID)
+	if err != nil {
+		return err
+	}
+	if err := promo.CheckAvailable(time.Now(), userUses); err != nil {
+		return err
+	}
+
+	cart, err := s.GetPricedCart(ctx, cartID)
+	if err != nil {
+		return err
+	}
+	if _, err := promo.Discount(cart); err != nil {
+		return err
+	}
+
+	if err := s.promotions.AddCartCoupon(ctx, cartID, code); err != nil {
+		return err
+	}
+	log.Printf("[PRICING] Applied coupon %s to cart %s", code, cartID)
+	return nil
+}
+
+func (s *CartService) RemoveCoupon(ctx context.Context, cartID, code string) error {
+	if s.promotions == nil {
+		return errPromotionsNotConfigured
+	}
+	return s.promotions.RemoveCartCoupon(ctx, cartID, model.NormalizeCouponCode(code))
+}
+
+// RedeemPromotions prices the cart for the order and records a redemption of
+// every promotion in its discount breakdown. If any promotion has run out of
+// uses in the meantime, nothing is redeemed and model.ErrUsageLimitReached is
+// returned. Redeeming an order again does not count it twice.
+func (s *CartService) RedeemPromotions(ctx context.Context, cartID, orderID string) (*model.PricedCart, error) {
+	cart, err := s.GetPricedCart(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+	if s.promotions == nil {
+		return cart, nil
+	}
+
+	for _, line := range cart.Discounts {
+		if line.Source != model.DiscountSourcePromotion {
+			continue
+		}
+		err := s.redeem(ctx, line, model.Redemption{
+			OrderID: orderID,
+			UserID:  customerOf(cartID),
+			Code:    line.Code,
+			Amount:  line.Amount,
+		})
+		if err != nil {
+			if releaseErr := s.promotions.ReleaseOrder(ctx, orderID); releaseErr != nil {
+				log.Printf("[PRICING] Could not release promotions of order %s: %v", orderID, releaseErr)
+			}
+			return nil, err
+		}
+	}
+	return cart, nil
+}
+
+func (s *CartService) redeem(ctx context.Context, line model.DiscountLine, redemption model.Redemption) error {
+	promo, err := s.promotions.GetByID(ctx, line.PromotionID)
+	if err != nil {
+		return err
+	}
+	return s.promotions.Redeem(ctx, promo, redemption)
+}
+
+// ReleasePromotions gives the uses redeemed by an order that was not placed
+// after all back to the promotions.
+func (s *CartService) ReleasePromotions(ctx context.Context, orderID string) error {
+	if s.promotions == nil {
+		return nil
+	}
+	return s.promotions.ReleaseOrder(ctx, orderID)
+}
+
+// applyPromotions adds the automatic promotions and the cart's coupons to
+// cart.Discounts and returns what they take off in total, never more than
+// the cart costs at sale prices.
+func (s *CartService) applyPromotions(ctx context.Context, cart *model.PricedCart) float64 {
+	if s.promotions == nil {
+		return 0
+	}
+	now := time.Now()
+
+	var promos []model.Promotion
+	automatic, err := s.promotions.ListAutomatic(ctx, now)
+	if err != nil {
+		log.Printf("[PRICING] Could not load promotions: %v", err)
+	}
+	promos = append(promos, automatic...)
+
+	codes, err := s.promotions.GetCartCoupons(ctx, cart.UserID)
+	if err != nil {
+		log.Printf("[PRICING] Could not load coupons of cart %s: %v", cart.UserID, err)
+	}
+	coupons := make(map[string]int)
+	for _, code := range codes {
+		coupons[code] = len(cart.Coupons)
+		cart.Coupons = append(cart.Coupons, model.AppliedCoupon{Code: code})
+		promo, err := s.promotions.GetByCode(ctx, code)
+		if err != nil {
+			cart.Coupons[coupons[code]].Reason = err.Error()
+			continue
+		}
+		promos = append(promos, *promo)
+	}
+
+	var spend float64
+	for _, item := range cart.Items {
+		if item.Available {
+			spend += item.LineTotal
+		}
+	}
+
+	var total float64
+	for i := range promos {
+		promo := &promos[i]
+		amount, err := s.promotionDiscount(ctx, promo, cart, now)
+		if err == nil {
+			amount = roundMoney(amount)
+			if total+amount > spend {
+				amount = roundMoney(spend - total)
+			}
+			if amount <= 0 {
+				err = model.ErrCouponNotApplicable
+			}
+		}
+		if !promo.Automatic() {
+			coupon := &cart.Coupons[coupons[promo.Code]]
+			coupon.Applied = err == nil
+			if err != nil {
+				coupon.Reason = err.Error()
+			}
+		}
+		if err != nil {
+			continue
+		}
+
+		total += amount
+		cart.Discounts = append(cart.Discounts, model.DiscountLine{
+			Source:      model.DiscountSourcePromotion,
+			PromotionID: promo.ID,
+			Code:        promo.Code,
+			Description: promo.Description,
+			Amount:      amount,
+		})
+	}
+	return total
+}
+
+func (s *CartService) promotionDiscount(ctx context.Context, promo *model.Promotion, cart *model.PricedCart, now time.Time) (float64, error) {
+	userUses, err := s.userUses(ctx, promo, cart.UserID)
+	if err != nil {
+		return 0, err
+	}
+	if err := promo.CheckAvailable(now, userUses); err != nil {
+		return 0, err
+	}
+	return promo.Discount(cart)
+}
+
+// userUses counts the orders of the cart's user that redeemed promo. It is
+// only looked up for promotions with a per-user limit, which guest carts
+// cannot use: a new guest cart would start from zero every time.
+func (s *CartService) userUses(ctx context.Context, promo *model.Promotion, cartID string) (int64, error) {
+	if promo.MaxUsesPerUser == 0 {
+		return 0, nil
+	}
+	userID := customerOf(cartID)
+	if userID == "" {
+		return 0, model.ErrLoginRequired
+	}
+	return s.promotions.UserUses(ctx, promo.ID, userID)
+}
+
+// customerOf returns the user whose promotion uses a cart counts for. A
+// user's cart is keyed by their user ID, which the api-gateway takes from
+// the access token; guest carts belong to nobody.
+func customerOf(cartID string) string {
+	if model.IsGuestCart(cartID) {
+		return ""
+	}
+	return cartID
+}
@@ -0,0 +1,232 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"shopping-cart-service/internal/cache"
+	"shopping-cart-service/internal/catalog"
+	"shopping-cart-service/internal/model"
+	"shopping-cart-service/internal/service"
+)
+
+type mockPromotionRepo struct {
+	mock.Mock
+}
+
+func (m *mockPromotionRepo) Create(ctx context.Context, promo *model.Promotion) error {
+	return m.Called(ctx, promo).Error(0)
+}
+func (m *mockPromotionRepo) GetByID(ctx context.Context, id string) (*model.Promotion, error) {
+	args := m.Called(ctx, id)
+	promo, _ := args.Get(0).(*model.Promotion)
+	return promo, args.Error(1)
+}
+func (m *mockPromotionRepo) GetByCode(ctx context.Context, code string) (*model.Promotion, error) {
+	args := m.Called(ctx, code)
+	promo, _ := args.Get(0).(*model.Promotion)
+	return promo, args.Error(1)
+}
+func (m *mockPromotionRepo) List(ctx context.Context, activeOnly bool) ([]model.Promotion, error) {
+	args := m.Called(ctx, activeOnly)
+	return args.Get(0).([]model.Promotion), args.Error(1)
+}
+func (m *mockPromotionRepo) ListAutomatic(ctx context.Context, now time.Time) ([]model.Promotion, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).([]model.Promotion), args.Error(1)
+}
+func (m *mockPromotionRepo) SetActive(ctx context.Context, id string, active bool) error {
+	return m.Called(ctx, id, active).Error(0)
+}
+func (m *mockPromotionRepo) UserUses(ctx context.Context, promotionID string, userID string) (int64, error) {
+	args := m.Called(ctx, promotionID, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *mockPromotionRepo) GetCartCoupons(ctx context.Context, cartID string) ([]string, error) {
+	args := m.Called(ctx, cartID)
+	return args.Get(0).([]string), args.Error(1)
+}
+func (m *mockPromotionRepo) AddCartCoupon(ctx context.Context, cartID string, code string) error {
+	return m.Called(ctx, cartID, code).Error(0)
+}
+func (m *mockPromotionRepo) RemoveCartCoupon(ctx context.Context, cartID string, code string) error {
+	return m.Called(ctx, cartID, code).Error(0)
+}
+func (m *mockPromotionRepo) ClearCartCoupons(ctx context.Context, cartID string) error {
+	return m.Called(ctx, cartID).Error(0)
+}
+func (m *mockPromotionRepo) Redeem(ctx context.Context, promo *model.Promotion, redemption model.Redemption) error {
+	return m.Called(ctx, promo, redemption).Error(0)
+}
+func (m *mockPromotionRepo) ReleaseOrder(ctx context.Context, orderID string) error {
+	return m.Called(ctx, orderID).Error(0)
+}
+func (m *mockPromotionRepo) ForgetUser(ctx context.Context, userID string) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+func pricedCart(items ...model.PricedItem) *model.PricedCart {
+	for i := range items {
+		items[i].Available = true
+		items[i].LineTotal = items[i].UnitPrice * float64(items[i].Quantity)
+	}
+	return &model.PricedCart{Items: items}
+}
+
+func pricedItem(productID, category string, price float64, qty int32) model.PricedItem {
+	return model.PricedItem{
+		CartItem:  model.CartItem{ProductID: productID, Quantity: qty},
+		Category:  category,
+		UnitPrice: price,
+	}
+}
+
+func TestPromotionDiscount(t *testing.T) {
+	cart := pricedCart(
+		pricedItem("milk", "dairy", 400, 5),
+		pricedItem("bread", "bakery", 250, 2),
+	)
+
+	tests := []struct {
+		name  string
+		promo model.Promotion
+		want  float64
+		err   error
+	}{
+		{"percentage", model.Promotion{Kind: model.PromotionPercentage, Percent: 10}, 250, nil},
+		{"fixed amount", model.Promotion{Kind: model.PromotionFixedAmount, Amount: 300}, 300, nil},
+		{"fixed amount capped", model.Promotion{Kind: model.PromotionFixedAmount, Amount: 5000}, 2500, nil},
+		{"buy 2 get 1", model.Promotion{Kind: model.PromotionBuyXGetY, ProductID: "milk", BuyQuantity: 2, FreeQuantity: 1}, 400, nil},
+		{"category", model.Promotion{Kind: model.PromotionCategory, Category: "Bakery", Percent: 50}, 250, nil},
+		{"category not in cart", model.Promotion{Kind: model.PromotionCategory, Category: "meat", Percent: 50}, 0, model.ErrCouponNotApplicable},
+		{"min spend", model.Promotion{Kind: model.PromotionPercentage, Percent: 10, MinSpend: 3000}, 0, model.ErrMinSpendNotMet},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.promo.Discount(cart)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPromotionCheckAvailable(t *testing.T) {
+	now := time.Now()
+	promo := model.Promotion{Active: true, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), MaxUses: 10, Uses: 3, MaxUsesPerUser: 1}
+
+	assert.NoError(t, promo.CheckAvailable(now, 0))
+	assert.ErrorIs(t, promo.CheckAvailable(now, 1), model.ErrUsageLimitReached)
+	assert.ErrorIs(t, promo.CheckAvailable(now.Add(2*time.Hour), 0), model.ErrPromotionExpired)
+	assert.ErrorIs(t, promo.CheckAvailable(now.Add(-2*time.Hour), 0), model.ErrPromotionInactive)
+
+	promo.Uses = 10
+	assert.ErrorIs(t, promo.CheckAvailable(now, 0), model.ErrUsageLimitReached)
+}
+
+func newPromotionCartService(items []model.CartItem, promos *mockPromotionRepo) *service.CartService {
+	repo := new(mockRepo)
+	products := new(mockCatalog)
+	repo.On("GetCart", mock.Anything, "u1").Return(cartOf("u1", items...), nil)
+	products.On("GetProduct", mock.Anything, "milk", "").
+		Return(&catalog.ProductSnapshot{ProductID: "milk", Name: "Milk", Category: "dairy", ListPrice: 500, UnitPrice: 400, Stock: 10}, nil)
+	return service.NewCartService(repo, products, cache.NewMemoryCache(time.Minute), promos)
+}
+
+func TestGetPricedCartWithPromotions(t *testing.T) {
+	promos := new(mockPromotionRepo)
+	svc := newPromotionCartService([]model.CartItem{{UserID: "u1", ProductID: "milk", Quantity: 3}}, promos)
+
+	now := time.Now()
+	promos.On("ListAutomatic", mock.Anything, mock.Anything).Return([]model.Promotion{
+		{ID: "auto", Kind: model.PromotionCategory, Category: "dairy", Percent: 10, Active: true, StartsAt: now.Add(-time.Hour)},
+	}, nil)
+	promos.On("GetCartCoupons", mock.Anything, "u1").Return([]string{"SAVE100", "BIG"}, nil)
+	promos.On("GetByCode", mock.Anything, "SAVE100").Return(&model.Promotion{
+		ID: "p1", Code: "SAVE100", Kind: model.PromotionFixedAmount, Amount: 100, Active: true, StartsAt: now.Add(-time.Hour),
+	}, nil)
+	promos.On("GetByCode", mock.Anything, "BIG").Return(&model.Promotion{
+		ID: "p2", Code: "BIG", Kind: model.PromotionFixedAmount, Amount: 100, MinSpend: 5000, Active: true, StartsAt: now.Add(-time.Hour),
+	}, nil)
+
+	cart, err := svc.GetPricedCart(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1500.0, cart.Subtotal)
+	// 300 sale + 120 category + 100 coupon
+	assert.Equal(t, 520.0, cart.Discount)
+	assert.Equal(t, 117.6, cart.Tax)
+	assert.Equal(t, 1097.6, cart.Total)
+	assert.Len(t, cart.Discounts, 3)
+	assert.Equal(t, model.DiscountSourceSale, cart.Discounts[0].Source)
+	assert.Equal(t, "p1", cart.Discounts[2].PromotionID)
+	assert.Equal(t, []model.AppliedCoupon{
+		{Code: "SAVE100", Applied: true},
+		{Code: "BIG", Reason: model.ErrMinSpendNotMet.Error()},
+	}, cart.Coupons)
+}
+
+func TestApplyCoupon(t *testing.T) {
+	promos := new(mockPromotionRepo)
+	svc := newPromotionCartService([]model.CartItem{{UserID: "u1", ProductID: "milk", Quantity: 1}}, promos)
+
+	now := time.Now()
+	promos.On("ListAutomatic", mock.Anything, mock.Anything).Return([]model.Promotion{}, nil)
+	promos.On("GetCartCoupons", mock.Anything, "u1").Return([]string{"OLD"}, nil)
+	promos.On("GetByCode", mock.Anything, "OLD").Return(&model.Promotion{
+		ID: "p1", Code: "OLD", Kind: model.PromotionFixedAmount, Amount: 10, Active: true, StartsAt: now.Add(-time.Hour),
+	}, nil)
+	promos.On("GetByCode", mock.Anything, "BIG").Return(&model.Promotion{
+		ID: "p2", Code: "BIG", Kind: model.PromotionFixedAmount, Amount: 100, MinSpend: 1000, Active: true, StartsAt: now.Add(-time.Hour),
+	}, nil)
+	promos.On("GetByCode", mock.Anything, "TENOFF").Return(&model.Promotion{
+		ID: "p3", Code: "TENOFF", Kind: model.PromotionPercentage, Percent: 10, MaxUsesPerUser: 1, Active: true, StartsAt: now.Add(-time.Hour),
+	}, nil)
+	promos.On("UserUses", mock.Anything, mock.Anything, "u1").Return(int64(0), nil)
+	promos.On("AddCartCoupon", mock.Anything, "u1", "TENOFF").Return(nil)
+
+	ctx := context.Background()
+	assert.ErrorIs(t, svc.ApplyCoupon(ctx, "u1", "old"), model.ErrCouponAlreadyApplied)
+	assert.ErrorIs(t, svc.ApplyCoupon(ctx, "u1", "big"), model.ErrMinSpendNotMet)
+	assert.NoError(t, svc.ApplyCoupon(ctx, "u1", " tenoff "))
+	promos.AssertNumberOfCalls(t, "AddCartCoupon", 1)
+}
+
+func TestApplyCoupon_GuestCartCannotUsePerUserLimit(t *testing.T) {
+	promos := new(mockPromotionRepo)
+	svc := service.NewCartService(new(mockRepo), nil, cache.NewMemoryCache(time.Minute), promos)
+	guest := model.GuestCartPrefix + "abc"
+
+	now := time.Now()
+	promos.On("GetCartCoupons", mock.Anything, guest).Return([]string(nil), nil)
+	promos.On("GetByCode", mock.Anything, "ONCE").Return(&model.Promotion{
+		ID: "p1", Code: "ONCE", Kind: model.PromotionFixedAmount, Amount: 10, MaxUsesPerUser: 1, Active: true, StartsAt: now.Add(-time.Hour),
+	}, nil)
+
+	err := svc.ApplyCoupon(context.Background(), guest, "once")
+	assert.ErrorIs(t, err, model.ErrLoginRequired)
+	promos.AssertNotCalled(t, "UserUses", mock.Anything, mock.Anything, mock.Anything)
+	promos.AssertNotCalled(t, "AddCartCoupon", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRedeemPromotionsReleasesOnLimit(t *testing.T) {
+	promos := new(mockPromotionRepo)
+	svc := newPromotionCartService([]model.CartItem{{UserID: "u1", ProductID: "milk", Quantity: 3}}, promos)
+
+	now := time.Now()
+	first := model.Promotion{ID: "a1", Kind: model.PromotionPercentage, Percent: 10, Active: true, StartsAt: now.Add(-time.Hour)}
+	second := model.Promotion{ID: "a2", Kind: model.PromotionFixedAmount, Amount: 50, MaxUses: 1, Active: true, StartsAt: now.Add(-time.Hour)}
+	promos.On("ListAutomatic", mock.Anything, mock.Anything).Return([]model.Promotion{first, second}, nil)
+	promos.On("GetCartCoupons", mock.Anything, "u1").Return([]string(nil), nil)
+	promos.On("GetByID", mock.Anything, "a1").Return(&first, nil)
+	promos.On("GetByID", mock.Anything, "a2").Return(&second, nil)
+	promos.On("Redeem", mock.Anything, &first, mock.Anything).Return(nil)
+	promos.On("Redeem", mock.Anything, &second, mock.Anything).Return(model.ErrUsageLimitReached)
+	promos.On("ReleaseOrder", mock.Anything, "o1").Return(nil)
+
+	_, err := svc.RedeemPromotions(context.Background(), "u1", "o1")
+	assert.ErrorIs(t, err, model.ErrUsageLimitReached)
+	promos.AssertCalled(t, "Redeem", mock.Anything, &first, model.Redemption{OrderID: "o1", UserID: "u1", Amount: 120})
+	promos.AssertCalled(t, "ReleaseOrder", mock.Anything, "o1")
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"shopping-cart-service/internal/repository"
+)
+
+// UserDataService exports and erases everything the service stores about a
+// user, for user-service's data export and erasure requests.
+type UserDataService struct {
+	carts      *CartService
+	lists      repository.WishlistRepositoryInterface
+	promotions repository.PromotionRepositoryInterface
+}
+
+func NewUserDataService(carts *CartService, lists repository.WishlistRepositoryInterface, promotions repository.PromotionRepositoryInterface) *UserDataService {
+	return &UserDataService{carts: carts, lists: lists, promotions: promotions}
+}
+
+// UserData is the service's part of a user's data export.
+type UserData struct {
+	Cart      []UserDataItem     `json:"cart"`
+	Coupons   []string           `json:"coupons,omitempty"`
+	Wishlists []UserDataWishlist `json:"wishlists"`
+}
+
+type UserDataItem struct {
+	ProductID string    `json:"product_id"`
+	SKUID     string    `json:"sku_id,omitempty"`
+	Quantity  int32     `json:"quantity"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+type UserDataWishlist struct {
+	Name      string         `json:"name"`
+	Shared    bool           `json:"shared"`
+	Items     []UserDataItem `json:"items"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+func (s *UserDataService) Export(ctx context.Context, userID string) (*UserData, error) {
+	items, err := s.carts.GetCart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	data := &UserData{Cart: []UserDataItem{}, Wishlists: []UserDataWishlist{}}
+	for _, item := range items {
+		data.Cart = append(data.Cart, UserDataItem{
+			ProductID: item.ProductID,
+			SKUID:     item.SKUID,
+			Quantity:  item.Quantity,
+			AddedAt:   item.AddedAt,
+		})
+	}
+
+	if s.promotions != nil {
+		if data.Coupons, err = s.promotions.GetCartCoupons(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	lists, err := s.lists.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range lists {
+		exported := UserDataWishlist{
+			Name:      list.Name,
+			Shared:    list.ShareToken != "",
+			Items:     []UserDataItem{},
+			CreatedAt: list.CreatedAt,
+		}
+		for _, item := range list.Items {
+			exported.Items = append(exported.Items, UserDataItem{
+				ProductID: item.ProductID,
+				SKUID:     item.SKUID,
+				Quantity:  item.Quantity,
+				AddedAt:   item.AddedAt,
+			})
+		}
+		data.Wishlists = append(data.Wishlists, exported)
+	}
+	return data, nil
+}
+
+// Erase deletes the user's cart, coupons and lists, and their promotion use
+// counters. The redemptions of their orders are kept without the user.
+func (s *UserDataService) Erase(ctx context.Context, userID string) error {
+	if err := s.carts.DeleteCart(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.lists.DeleteByUser(ctx, userID); err != nil {
+		return err
+	}
+	if s.promotions != nil {
+		return s.promotions.ForgetUser(ctx, userID)
+	}
+	return nil
+}
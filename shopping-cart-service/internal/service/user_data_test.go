@@ -0,0 +1,58 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"shopping-cart-service/internal/cache"
+	"shopping-cart-service/internal/model"
+	"shopping-cart-service/internal/service"
+)
+
+func TestUserDataExport(t *testing.T) {
+	repo := new(mockRepo)
+	lists := new(mockWishlistRepo)
+	promotions := new(mockPromotionRepo)
+	carts := service.NewCartService(repo, nil, cache.NewMemoryCache(time.Minute), promotions)
+	svc := service.NewUserDataService(carts, lists, promotions)
+
+	repo.On("GetCart", mock.Anything, "u1").Return(cartOf("u1", model.CartItem{UserID: "u1", ProductID: "p1", SKUID: "s1", Quantity: 2}), nil)
+	promotions.On("GetCartCoupons", mock.Anything, "u1").Return([]string{"SAVE10"}, nil)
+	lists.On("ListByUser", mock.Anything, "u1").Return([]model.Wishlist{
+		{ID: "l1", UserID: "u1", Name: "Wishlist", ShareToken: "tok", Items: []model.WishlistItem{{ProductID: "p2", Quantity: 1}}},
+	}, nil)
+
+	data, err := svc.Export(context.Background(), "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, []service.UserDataItem{{ProductID: "p1", SKUID: "s1", Quantity: 2}}, data.Cart)
+	assert.Equal(t, []string{"SAVE10"}, data.Coupons)
+	if assert.Len(t, data.Wishlists, 1) {
+		assert.True(t, data.Wishlists[0].Shared)
+		assert.Equal(t, "p2", data.Wishlists[0].Items[0].ProductID)
+	}
+}
+
+func TestUserDataErase(t *testing.T) {
+	repo := new(mockRepo)
+	lists := new(mockWishlistRepo)
+	promotions := new(mockPromotionRepo)
+	c := cache.NewMemoryCache(time.Minute)
+	carts := service.NewCartService(repo, nil, c, promotions)
+	svc := service.NewUserDataService(carts, lists, promotions)
+
+	c.Set(context.Background(), "u1", cache.Version{}, []model.CartItem{{ProductID: "p1", Quantity: 1}})
+	repo.On("DeleteCart", mock.Anything, "u1").Return(nil)
+	promotions.On("ClearCartCoupons", mock.Anything, "u1").Return(nil)
+	lists.On("DeleteByUser", mock.Anything, "u1").Return(nil)
+	promotions.On("ForgetUser", mock.Anything, "u1").Return(nil)
+
+	assert.NoError(t, svc.Erase(context.Background(), "u1"))
+	repo.AssertExpectations(t)
+	lists.AssertExpectations(t)
+	promotions.AssertExpectations(t)
+	_, _, found := c.Get(context.Background(), "u1")
+	assert.False(t, found, "erased cart still cached")
+}
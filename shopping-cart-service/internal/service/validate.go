@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"shopping-cart-service/internal/catalog"
+	"shopping-cart-service/internal/model"
+)
+
+// ValidateCart checks every line of the cart against product-service and
+// returns what no longer holds: products that are gone, quantities above the
+// stock and prices that changed since the item was added. With autoFix the
+// cart is corrected as well: gone lines are removed, quantities are lowered
+// to the stock and the new prices are accepted.
+func (s *CartService) ValidateCart(ctx context.Context, userID string, autoFix bool) ([]model.CartIssue, error) {
+	if s.products == nil {
+		return nil, errors.New("product catalog is not configured")
+	}
+
+	cart, err := s.repo.GetCart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []model.CartIssue
+	for _, item := range cart.Items {
+		issue := model.CartIssue{
+			ProductID:         item.ProductID,
+			SKUID:             item.SKUID,
+			RequestedQuantity: item.Quantity,
+		}
+
+		snapshot, err := s.products.GetProduct(ctx, item.ProductID, item.SKUID)
+		if errors.Is(err, catalog.ErrProductNotFound) {
+			issue.Kind = model.IssueRemoved
+			issues = append(issues, issue)
+			continue
+		}
+		if err != nil {
+			// Without the product there is nothing to check the line against,
+			// so the cart cannot be called valid.
+			return nil, err
+		}
+
+		if snapshot.Stock < item.Quantity {
+			stockIssue := issue
+			stockIssue.Kind = model.IssueInsufficientStock
+			stockIssue.AvailableQuantity = snapshot.Stock
+			issues = append(issues, stockIssue)
+			if snapshot.Stock == 0 {
+				continue
+			}
+		}
+
+		// Items added before prices were recorded have nothing to compare to.
+		if item.AddedPrice > 0 && snapshot.UnitPrice != item.AddedPrice {
+			priceIssue := issue
+			priceIssue.Kind = model.IssuePriceChanged
+			priceIssue.OldPrice = item.AddedPrice
+			priceIssue.NewPrice = snapshot.UnitPrice
+			issues = append(issues, priceIssue)
+		}
+	}
+
+	if autoFix && len(issues) > 0 {
+		_, err := s.update(ctx, userID, model.CartValidated, func(cart *model.Cart, now time.Time) error {
+			for _, issue := range issues {
+				fix(cart, issue, now)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("[PRICING] Could not fix cart %s: %v", userID, err)
+		} else {
+			for i := range issues {
+				issues[i].Resolved = true
+			}
+		}
+	}
+	return issues, nil
+}
+
+// fix corrects the cart for one issue. The cart may have changed since the
+// issue was found, so a line that is gone or already within the stock is
+// left alone.
+func fix(cart *model.Cart, issue model.CartIssue, now time.Time) {
+	switch issue.Kind {
+	case model.IssueRemoved:
+		_ = cart.Remove(issue.ProductID, issue.SKUID)
+	case model.IssueInsufficientStock:
+		item, found := cart.Item(issue.ProductID, issue.SKUID)
+		switch {
+		case !found || item.Quantity <= issue.AvailableQuantity:
+		case issue.AvailableQuantity > 0:
+			_ = cart.SetQuantity(issue.ProductID, issue.SKUID, issue.AvailableQuantity, now)
+		default:
+			_ = cart.Remove(issue.ProductID, issue.SKUID)
+		}
+	case model.IssuePriceChanged:
+		_ = cart.SetAddedPrice(issue.ProductID, issue.SKUID, issue.NewPrice)
+	}
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"strings"
+
+	"shopping-cart-service/internal/catalog"
+	"shopping-cart-service/internal/model"
+	"shopping-cart-service/internal/repository"
+)
+
+type WishlistService struct {
+	lists repository.WishlistRepositoryInterface
+	carts *CartService
+}
+
+func NewWishlistService(lists repository.WishlistRepositoryInterface, carts *CartService) *WishlistService {
+	return &WishlistService{lists: lists, carts: carts}
+}
+
+// ListLists returns all lists of the user, the default wishlist and
+// save-for-later list first. The default lists are created on first use.
+func (s *WishlistService) ListLists(ctx context.Context, userID string) ([]model.Wishlist, error) {
+	for _, kind := range []model.ListKind{model.ListWishlist, model.ListSaveForLater} {
+		if _, err := s.lists.GetDefault(ctx, userID, kind); err != nil {
+			return nil, err
+		}
+	}
+	return s.lists.ListByUser(ctx, userID)
+}
+
+func (s *WishlistService) CreateList(ctx context.Context, userID, name string) (*model.Wishlist, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, model.ErrEmptyListName
+	}
+	list := &model.Wishlist{UserID: userID, Name: name, Kind: model.ListCustom}
+	if err := s.lists.Create(ctx, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetList returns one of the user's lists. An empty listID means the default
+// wishlist.
+func (s *WishlistService) GetList(ctx context.Context, userID, listID string) (*model.Wishlist, error) {
+	return s.owned(ctx, userID, listID, model.ListWishlist)
+}
+
+func (s *WishlistService) RenameList(ctx context.Context, userID, listID, name string) (*model.Wishlist, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, model.ErrEmptyListName
+	}
+	list, err := s.owned(ctx, userID, listID, model.ListCustom)
+	if err != nil {
+		return nil, err
+	}
+	if list.Kind != model.ListCustom {
+		return nil, model.ErrDefaultList
+	}
+	if err := s.lists.Rename(ctx, list.ID, name); err != nil {
+		return nil, err
+	}
+	list.Name = name
+	return list, nil
+}
+
+func (s *WishlistService) DeleteList(ctx context.Context, userID, listID string) error {
+	list, err := s.owned(ctx, userID, listID, model.ListCustom)
+	if err != nil {
+		return err
+	}
+	if list.Kind != model.ListCustom {
+		return model.ErrDefaultList
+	}
+	return s.lists.Delete(ctx, list.ID)
+}
+
+// AddItem puts a product on a list, the default wishlist when listID is
+// empty. Unknown products are refused like they are for the cart.
+func (s *WishlistService) AddItem(ctx context.Context, userID, listID string, item model.WishlistItem) (*model.Wishlist, error) {
+	if item.Quantity < 0 {
+		return nil, model.ErrInvalidQuantity
+	}
+	if item.Quantity == 0 {
+		item.Quantity = 1
+	}
+	if s.carts.products != nil {
+		snapshot, err := s.carts.products.GetProduct(ctx, item.ProductID, item.SKUID)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot.ProductID != item.ProductID {
+			return nil, catalog.ErrProductNotFound
+		}
+	}
+
+	list, err := s.owned(ctx, userID, listID, model.ListWishlist)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.lists.AddItem(ctx, list.ID, item); err != nil {
+		return nil, err
+	}
+	return s.lists.GetByID(ctx, list.ID)
+}
+
+func (s *WishlistService) RemoveItem(ctx context.Context, userID, listID, productID, skuID string) (*model.Wishlist, error) {
+	list, err := s.owned(ctx, userID, listID, model.ListWishlist)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.lists.RemoveItem(ctx, list.ID, productID, skuID); err != nil {
+		return nil, err
+	}
+	return s.lists.GetByID(ctx, list.ID)
+}
+
+// MoveToCart puts a list item into the user's cart with its list quantity
+// and takes it off the list.
+func (s *WishlistService) MoveToCart(ctx context.Context, userID, listID, productID, skuID string) (*model.Wishlist, error) {
+	list, err := s.owned(ctx, userID, listID, model.ListSaveForLater)
+	if err != nil {
+		return nil, err
+	}
+	item, found := list.FindItem(productID, skuID)
+	if !found {
+		return nil, model.ErrItemNotFound
+	}
+
+	err = s.carts.AddToCart(ctx, model.CartItem{
+		UserID:    userID,
+		ProductID: productID,
+		SKUID:     skuID,
+		Quantity:  item.Quantity,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.lists.RemoveItem(ctx, list.ID, productID, skuID); err != nil {
+		return nil, err
+	}
+	log.Printf("[WISHLIST] Moved product_id=%s, sku_id=%s from list %s to cart of %s", productID, skuID, list.ID, userID)
+	return s.lists.GetByID(ctx, list.ID)
+}
+
+// MoveFromCart parks a cart item on a list, the save-for-later list when
+// listID is empty, and removes it from the cart.
+func (s *WishlistService) MoveFromCart(ctx context.Context, userID, listID, productID, skuID string) (*model.Wishlist, error) {
+	list, err := s.owned(ctx, userID, listID, model.ListSaveForLater)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.carts.GetCart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var quantity int32
+	for _, item := range items {
+		if item.ProductID == productID && item.SKUID == skuID {
+			quantity = item.Quantity
+		}
+	}
+	if quantity == 0 {
+		return nil, model.ErrItemNotFound
+	}
+
+	err = s.lists.AddItem(ctx, list.ID, model.WishlistItem{ProductID: productID, SKUID: skuID, Quantity: quantity})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.carts.RemoveFromCart(ctx, userID, productID, skuID); err != nil {
+		return nil, err
+	}
+	log.Printf("[WISHLIST] Moved product_id=%s, sku_id=%s from cart of %s to list %s", productID, skuID, userID, list.ID)
+	return s.lists.GetByID(ctx, list.ID)
+}
+
+// ShareList returns the list's share token, creating one if the list is not
+// shared yet.
+func (s *WishlistService) ShareList(ctx context.Context, userID, listID string) (string, error) {
+	list, err := s.owned(ctx, userID, listID, model.ListWishlist)
+	if err != nil {
+		return "", err
+	}
+	if list.ShareToken != "" {
+		return list.ShareToken, nil
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+	if err := s.lists.SetShareToken(ctx, list.ID, token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// UnshareList invalidates the list's share token.
+func (s *WishlistService) UnshareList(ctx context.Context, userID, listID string) (*model.Wishlist, error) {
+	list, err := s.owned(ctx, userID, listID, model.ListWishlist)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.lists.SetShareToken(ctx, list.ID, ""); err != nil {
+		return nil, err
+	}
+	list.ShareToken = ""
+	return list, nil
+}
+
+// GetSharedList returns the list a share token points at. The caller must
+// not expose the owner or the token itself.
+func (s *WishlistService) GetSharedList(ctx context.Context, token string) (*model.Wishlist, error) {
+	return s.lists.GetByShareToken(ctx, token)
+}
+
+// ListsWaitingFor returns the lists holding a product (or one SKU of it)
+// that has just come back into stock.
+func (s *WishlistService) ListsWaitingFor(ctx context.Context, productID, skuID string) ([]model.Wishlist, error) {
+	return s.lists.FindByItem(ctx, productID, skuID)
+}
+
+// owned loads a list of the user. An empty listID means the user's default
+// list of fallback kind; for ListCustom there is no such default.
+func (s *WishlistService) owned(ctx context.Context, userID, listID string, fallback model.ListKind) (*model.Wishlist, error) {
+	if listID == "" {
+		if fallback == model.ListCustom {
+			return nil, model.ErrListNotFound
+		}
+		return s.lists.GetDefault(ctx, userID, fallback)
+	}
+
+	list, err := s.lists.GetByID(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	// Someone else's list looks the same as a missing one.
+	if list.UserID != userID {
+		return nil, model.ErrListNotFound
+	}
+	return list, nil
+}
@@ -0,0 +1,106 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"shopping-cart-service/internal/cache"
+	"shopping-cart-service/internal/model"
+	"shopping-cart-service/internal/service"
+)
+
+type mockWishlistRepo struct {
+	mock.Mock
+}
+
+func (m *mockWishlistRepo) Create(ctx context.Context, list *model.Wishlist) error {
+	return m.Called(ctx, list).Error(0)
+}
+func (m *mockWishlistRepo) GetByID(ctx context.Context, id string) (*model.Wishlist, error) {
+	args := m.Called(ctx, id)
+	list, _ := args.Get(0).(*model.Wishlist)
+	return list, args.Error(1)
+}
+func (m *mockWishlistRepo) GetByShareToken(ctx context.Context, token string) (*model.Wishlist, error) {
+	args := m.Called(ctx, token)
+	list, _ := args.Get(0).(*model.Wishlist)
+	return list, args.Error(1)
+}
+func (m *mockWishlistRepo) GetDefault(ctx context.Context, userID string, kind model.ListKind) (*model.Wishlist, error) {
+	args := m.Called(ctx, userID, kind)
+	list, _ := args.Get(0).(*model.Wishlist)
+	return list, args.Error(1)
+}
+func (m *mockWishlistRepo) ListByUser(ctx context.Context, userID string) ([]model.Wishlist, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]model.Wishlist), args.Error(1)
+}
+func (m *mockWishlistRepo) Rename(ctx context.Context, id string, name string) error {
+	return m.Called(ctx, id, name).Error(0)
+}
+func (m *mockWishlistRepo) Delete(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
+}
+func (m *mockWishlistRepo) AddItem(ctx context.Context, id string, item model.WishlistItem) error {
+	return m.Called(ctx, id, item).Error(0)
+}
+func (m *mockWishlistRepo) RemoveItem(ctx context.Context, id string, productID string, skuID string) error {
+	return m.Called(ctx, id, productID, skuID).Error(0)
+}
+func (m *mockWishlistRepo) SetShareToken(ctx context.Context, id string, token string) error {
+	return m.Called(ctx, id, token).Error(0)
+}
+func (m *mockWishlistRepo) FindByItem(ctx context.Context, productID string, skuID string) ([]model.Wishlist, error) {
+	args := m.Called(ctx, productID, skuID)
+	return args.Get(0).([]model.Wishlist), args.Error(1)
+}
+func (m *mockWishlistRepo) DeleteByUser(ctx context.Context, userID string) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+func TestMoveFromCart_DefaultsToSaveForLater(t *testing.T) {
+	repo := new(mockRepo)
+	lists := new(mockWishlistRepo)
+	carts := service.NewCartService(repo, nil, cache.NewMemoryCache(time.Minute), nil)
+	svc := service.NewWishlistService(lists, carts)
+
+	saved := &model.Wishlist{ID: "l1", UserID: "u1", Kind: model.ListSaveForLater}
+	lists.On("GetDefault", mock.Anything, "u1", model.ListSaveForLater).Return(saved, nil)
+	repo.On("GetCart", mock.Anything, "u1").Return(cartOf("u1", model.CartItem{UserID: "u1", ProductID: "p1", Quantity: 3}), nil)
+	lists.On("AddItem", mock.Anything, "l1", model.WishlistItem{ProductID: "p1", Quantity: 3}).Return(nil)
+	repo.On("SaveCart", mock.Anything, savedCart("u1", 1, map[string]int32{})).Return(nil)
+	lists.On("GetByID", mock.Anything, "l1").Return(saved, nil)
+
+	list, err := svc.MoveFromCart(context.Background(), "u1", "", "p1", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "l1", list.ID)
+	repo.AssertExpectations(t)
+	lists.AssertExpectations(t)
+}
+
+func TestWishlist_OtherUsersListIsNotFound(t *testing.T) {
+	lists := new(mockWishlistRepo)
+	svc := service.NewWishlistService(lists, service.NewCartService(new(mockRepo), nil, cache.NewMemoryCache(time.Minute), nil))
+
+	lists.On("GetByID", mock.Anything, "l2").Return(&model.Wishlist{ID: "l2", UserID: "owner"}, nil)
+
+	_, err := svc.GetList(context.Background(), "intruder", "l2")
+	assert.ErrorIs(t, err, model.ErrListNotFound)
+	_, err = svc.ShareList(context.Background(), "intruder", "l2")
+	assert.ErrorIs(t, err, model.ErrListNotFound)
+	lists.AssertNotCalled(t, "SetShareToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteList_DefaultListIsKept(t *testing.T) {
+	lists := new(mockWishlistRepo)
+	svc := service.NewWishlistService(lists, service.NewCartService(new(mockRepo), nil, cache.NewMemoryCache(time.Minute), nil))
+
+	lists.On("GetByID", mock.Anything, "l3").Return(&model.Wishlist{ID: "l3", UserID: "u3", Kind: model.ListWishlist}, nil)
+
+	err := svc.DeleteList(context.Background(), "u3", "l3")
+	assert.ErrorIs(t, err, model.ErrDefaultList)
+	lists.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
@@ -9,6 +9,7 @@ package cartpb
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,10 +22,222 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// How MergeCart resolves a product that is in both carts.
+type MergeStrategy int32
+
+const (
+	MergeStrategy_MERGE_STRATEGY_SUM         MergeStrategy = 0 // add the quantities up
+	MergeStrategy_MERGE_STRATEGY_MAX         MergeStrategy = 1 // keep the larger quantity
+	MergeStrategy_MERGE_STRATEGY_KEEP_TARGET MergeStrategy = 2 // keep the target cart's line untouched
+)
+
+// Enum value maps for MergeStrategy.
+var (
+	MergeStrategy_name = map[int32]string{
+		0: "MERGE_STRATEGY_SUM",
+		1: "MERGE_STRATEGY_MAX",
+		2: "MERGE_STRATEGY_KEEP_TARGET",
+	}
+	MergeStrategy_value = map[string]int32{
+		"MERGE_STRATEGY_SUM":         0,
+		"MERGE_STRATEGY_MAX":         1,
+		"MERGE_STRATEGY_KEEP_TARGET": 2,
+	}
+)
+
+func (x MergeStrategy) Enum() *MergeStrategy {
+	p := new(MergeStrategy)
+	*p = x
+	return p
+}
+
+func (x MergeStrategy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MergeStrategy) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_cart_proto_enumTypes[0].Descriptor()
+}
+
+func (MergeStrategy) Type() protoreflect.EnumType {
+	return &file_proto_cart_proto_enumTypes[0]
+}
+
+func (x MergeStrategy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MergeStrategy.Descriptor instead.
+func (MergeStrategy) EnumDescriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{0}
+}
+
+type CartIssueKind int32
+
+const (
+	CartIssueKind_CART_ISSUE_REMOVED            CartIssueKind = 0 // the product or SKU no longer exists
+	CartIssueKind_CART_ISSUE_INSUFFICIENT_STOCK CartIssueKind = 1
+	CartIssueKind_CART_ISSUE_PRICE_CHANGED      CartIssueKind = 2 // unit price differs from added_price
+)
+
+// Enum value maps for CartIssueKind.
+var (
+	CartIssueKind_name = map[int32]string{
+		0: "CART_ISSUE_REMOVED",
+		1: "CART_ISSUE_INSUFFICIENT_STOCK",
+		2: "CART_ISSUE_PRICE_CHANGED",
+	}
+	CartIssueKind_value = map[string]int32{
+		"CART_ISSUE_REMOVED":            0,
+		"CART_ISSUE_INSUFFICIENT_STOCK": 1,
+		"CART_ISSUE_PRICE_CHANGED":      2,
+	}
+)
+
+func (x CartIssueKind) Enum() *CartIssueKind {
+	p := new(CartIssueKind)
+	*p = x
+	return p
+}
+
+func (x CartIssueKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CartIssueKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_cart_proto_enumTypes[1].Descriptor()
+}
+
+func (CartIssueKind) Type() protoreflect.EnumType {
+	return &file_proto_cart_proto_enumTypes[1]
+}
+
+func (x CartIssueKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CartIssueKind.Descriptor instead.
+func (CartIssueKind) EnumDescriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{1}
+}
+
+type ListKind int32
+
+const (
+	ListKind_LIST_KIND_CUSTOM         ListKind = 0
+	ListKind_LIST_KIND_WISHLIST       ListKind = 1
+	ListKind_LIST_KIND_SAVE_FOR_LATER ListKind = 2
+)
+
+// Enum value maps for ListKind.
+var (
+	ListKind_name = map[int32]string{
+		0: "LIST_KIND_CUSTOM",
+		1: "LIST_KIND_WISHLIST",
+		2: "LIST_KIND_SAVE_FOR_LATER",
+	}
+	ListKind_value = map[string]int32{
+		"LIST_KIND_CUSTOM":         0,
+		"LIST_KIND_WISHLIST":       1,
+		"LIST_KIND_SAVE_FOR_LATER": 2,
+	}
+)
+
+func (x ListKind) Enum() *ListKind {
+	p := new(ListKind)
+	*p = x
+	return p
+}
+
+func (x ListKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ListKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_cart_proto_enumTypes[2].Descriptor()
+}
+
+func (ListKind) Type() protoreflect.EnumType {
+	return &file_proto_cart_proto_enumTypes[2]
+}
+
+func (x ListKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ListKind.Descriptor instead.
+func (ListKind) EnumDescriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{2}
+}
+
+type PromotionKind int32
+
+const (
+	PromotionKind_PROMOTION_KIND_PERCENTAGE   PromotionKind = 0 // percent off the cart
+	PromotionKind_PROMOTION_KIND_FIXED_AMOUNT PromotionKind = 1 // amount off the cart
+	PromotionKind_PROMOTION_KIND_BUY_X_GET_Y  PromotionKind = 2 // free_quantity of every buy_quantity + free_quantity units free
+	PromotionKind_PROMOTION_KIND_CATEGORY     PromotionKind = 3 // percent off the lines of a category
+)
+
+// Enum value maps for PromotionKind.
+var (
+	PromotionKind_name = map[int32]string{
+		0: "PROMOTION_KIND_PERCENTAGE",
+		1: "PROMOTION_KIND_FIXED_AMOUNT",
+		2: "PROMOTION_KIND_BUY_X_GET_Y",
+		3: "PROMOTION_KIND_CATEGORY",
+	}
+	PromotionKind_value = map[string]int32{
+		"PROMOTION_KIND_PERCENTAGE":   0,
+		"PROMOTION_KIND_FIXED_AMOUNT": 1,
+		"PROMOTION_KIND_BUY_X_GET_Y":  2,
+		"PROMOTION_KIND_CATEGORY":     3,
+	}
+)
+
+func (x PromotionKind) Enum() *PromotionKind {
+	p := new(PromotionKind)
+	*p = x
+	return p
+}
+
+func (x PromotionKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PromotionKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_cart_proto_enumTypes[3].Descriptor()
+}
+
+func (PromotionKind) Type() protoreflect.EnumType {
+	return &file_proto_cart_proto_enumTypes[3]
+}
+
+func (x PromotionKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PromotionKind.Descriptor instead.
+func (PromotionKind) EnumDescriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{3}
+}
+
 type CartItem struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
-	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity  int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	SkuId     string                 `protobuf:"bytes,3,opt,name=sku_id,json=skuId,proto3" json:"sku_id,omitempty"`
+	AddedAt   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=added_at,json=addedAt,proto3" json:"added_at,omitempty"`
+	UpdatedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// Filled in from product-service when the cart is returned.
+	Name      string  `protobuf:"bytes,6,opt,name=name,proto3" json:"name,omitempty"`
+	UnitPrice float64 `protobuf:"fixed64,7,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
+	ListPrice float64 `protobuf:"fixed64,8,opt,name=list_price,json=listPrice,proto3" json:"list_price,omitempty"`
+	LineTotal float64 `protobuf:"fixed64,9,opt,name=line_total,json=lineTotal,proto3" json:"line_total,omitempty"`
+	Available bool    `protobuf:"varint,10,opt,name=available,proto3" json:"available,omitempty"`
+	// Unit price when the item was last added; ValidateCart compares against it.
+	AddedPrice    float64 `protobuf:"fixed64,11,opt,name=added_price,json=addedPrice,proto3" json:"added_price,omitempty"`
+	Category      string  `protobuf:"bytes,12,opt,name=category,proto3" json:"category,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -73,6 +286,76 @@ func (x *CartItem) GetQuantity() int32 {
 	return 0
 }
 
+func (x *CartItem) GetSkuId() string {
+	if x != nil {
+		return x.SkuId
+	}
+	return ""
+}
+
+func (x *CartItem) GetAddedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AddedAt
+	}
+	return nil
+}
+
+func (x *CartItem) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *CartItem) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CartItem) GetUnitPrice() float64 {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return 0
+}
+
+func (x *CartItem) GetListPrice() float64 {
+	if x != nil {
+		return x.ListPrice
+	}
+	return 0
+}
+
+func (x *CartItem) GetLineTotal() float64 {
+	if x != nil {
+		return x.LineTotal
+	}
+	return 0
+}
+
+func (x *CartItem) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+func (x *CartItem) GetAddedPrice() float64 {
+	if x != nil {
+		return x.AddedPrice
+	}
+	return 0
+}
+
+func (x *CartItem) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
 type AddToCartRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
@@ -173,6 +456,7 @@ type RemoveFromCartRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	ProductId     string                 `protobuf:"bytes,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	SkuId         string                 `protobuf:"bytes,3,opt,name=sku_id,json=skuId,proto3" json:"sku_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -221,27 +505,38 @@ func (x *RemoveFromCartRequest) GetProductId() string {
 	return ""
 }
 
-type CartResponse struct {
+func (x *RemoveFromCartRequest) GetSkuId() string {
+	if x != nil {
+		return x.SkuId
+	}
+	return ""
+}
+
+// Sets the exact quantity of an item already in the cart.
+type UpdateCartItemRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Items         []*CartItem            `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ProductId     string                 `protobuf:"bytes,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	SkuId         string                 `protobuf:"bytes,3,opt,name=sku_id,json=skuId,proto3" json:"sku_id,omitempty"`
+	Quantity      int32                  `protobuf:"varint,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CartResponse) Reset() {
-	*x = CartResponse{}
+func (x *UpdateCartItemRequest) Reset() {
+	*x = UpdateCartItemRequest{}
 	mi := &file_proto_cart_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CartResponse) String() string {
+func (x *UpdateCartItemRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CartResponse) ProtoMessage() {}
+func (*UpdateCartItemRequest) ProtoMessage() {}
 
-func (x *CartResponse) ProtoReflect() protoreflect.Message {
+func (x *UpdateCartItemRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_cart_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -253,42 +548,2182 @@ func (x *CartResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CartResponse.ProtoReflect.Descriptor instead.
-func (*CartResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use UpdateCartItemRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCartItemRequest) Descriptor() ([]byte, []int) {
 	return file_proto_cart_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *CartResponse) GetItems() []*CartItem {
+func (x *UpdateCartItemRequest) GetUserId() string {
 	if x != nil {
-		return x.Items
+		return x.UserId
 	}
-	return nil
+	return ""
 }
 
-var File_proto_cart_proto protoreflect.FileDescriptor
+func (x *UpdateCartItemRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
 
-const file_proto_cart_proto_rawDesc = "" +
-	"\n" +
-	"\x10proto/cart.proto\x12\x06cartpb\"E\n" +
-	"\bCartItem\x12\x1d\n" +
-	"\n" +
-	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
-	"\bquantity\x18\x02 \x01(\x05R\bquantity\"S\n" +
-	"\x10AddToCartRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\x12&\n" +
-	"\x05items\x18\x02 \x03(\v2\x10.cartpb.CartItemR\x05items\")\n" +
-	"\x0eGetCartRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\"O\n" +
-	"\x15RemoveFromCartRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1d\n" +
-	"\n" +
-	"product_id\x18\x02 \x01(\tR\tproductId\"6\n" +
-	"\fCartResponse\x12&\n" +
-	"\x05items\x18\x01 \x03(\v2\x10.cartpb.CartItemR\x05items2\xca\x01\n" +
-	"\vCartService\x12;\n" +
-	"\tAddToCart\x12\x18.cartpb.AddToCartRequest\x1a\x14.cartpb.CartResponse\x127\n" +
-	"\aGetCart\x12\x16.cartpb.GetCartRequest\x1a\x14.cartpb.CartResponse\x12E\n" +
-	"\x0eRemoveFromCart\x12\x1d.cartpb.RemoveFromCartRequest\x1a\x14.cartpb.CartResponseB$Z\"shopping-cart-service/proto/cartpbb\x06proto3"
+func (x *UpdateCartItemRequest) GetSkuId() string {
+	if x != nil {
+		return x.SkuId
+	}
+	return ""
+}
+
+func (x *UpdateCartItemRequest) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type ClearCartRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClearCartRequest) Reset() {
+	*x = ClearCartRequest{}
+	mi := &file_proto_cart_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearCartRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearCartRequest) ProtoMessage() {}
+
+func (x *ClearCartRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearCartRequest.ProtoReflect.Descriptor instead.
+func (*ClearCartRequest) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ClearCartRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+// Moves every item of the source cart into the target cart. The source cart
+// is emptied. Cart IDs are user IDs or guest cart tokens ("guest:...").
+type MergeCartRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SourceUserId  string                 `protobuf:"bytes,1,opt,name=source_user_id,json=sourceUserId,proto3" json:"source_user_id,omitempty"`
+	TargetUserId  string                 `protobuf:"bytes,2,opt,name=target_user_id,json=targetUserId,proto3" json:"target_user_id,omitempty"`
+	Strategy      MergeStrategy          `protobuf:"varint,3,opt,name=strategy,proto3,enum=cartpb.MergeStrategy" json:"strategy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MergeCartRequest) Reset() {
+	*x = MergeCartRequest{}
+	mi := &file_proto_cart_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MergeCartRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MergeCartRequest) ProtoMessage() {}
+
+func (x *MergeCartRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MergeCartRequest.ProtoReflect.Descriptor instead.
+func (*MergeCartRequest) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MergeCartRequest) GetSourceUserId() string {
+	if x != nil {
+		return x.SourceUserId
+	}
+	return ""
+}
+
+func (x *MergeCartRequest) GetTargetUserId() string {
+	if x != nil {
+		return x.TargetUserId
+	}
+	return ""
+}
+
+func (x *MergeCartRequest) GetStrategy() MergeStrategy {
+	if x != nil {
+		return x.Strategy
+	}
+	return MergeStrategy_MERGE_STRATEGY_SUM
+}
+
+type CartResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Items    []*CartItem            `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Subtotal float64                `protobuf:"fixed64,2,opt,name=subtotal,proto3" json:"subtotal,omitempty"`
+	// Sum of the discounts lines.
+	Discount      float64          `protobuf:"fixed64,3,opt,name=discount,proto3" json:"discount,omitempty"`
+	Tax           float64          `protobuf:"fixed64,4,opt,name=tax,proto3" json:"tax,omitempty"`
+	Total         float64          `protobuf:"fixed64,5,opt,name=total,proto3" json:"total,omitempty"`
+	Currency      string           `protobuf:"bytes,6,opt,name=currency,proto3" json:"currency,omitempty"`
+	Discounts     []*DiscountLine  `protobuf:"bytes,7,rep,name=discounts,proto3" json:"discounts,omitempty"`
+	Coupons       []*AppliedCoupon `protobuf:"bytes,8,rep,name=coupons,proto3" json:"coupons,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CartResponse) Reset() {
+	*x = CartResponse{}
+	mi := &file_proto_cart_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CartResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CartResponse) ProtoMessage() {}
+
+func (x *CartResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CartResponse.ProtoReflect.Descriptor instead.
+func (*CartResponse) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CartResponse) GetItems() []*CartItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *CartResponse) GetSubtotal() float64 {
+	if x != nil {
+		return x.Subtotal
+	}
+	return 0
+}
+
+func (x *CartResponse) GetDiscount() float64 {
+	if x != nil {
+		return x.Discount
+	}
+	return 0
+}
+
+func (x *CartResponse) GetTax() float64 {
+	if x != nil {
+		return x.Tax
+	}
+	return 0
+}
+
+func (x *CartResponse) GetTotal() float64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *CartResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *CartResponse) GetDiscounts() []*DiscountLine {
+	if x != nil {
+		return x.Discounts
+	}
+	return nil
+}
+
+func (x *CartResponse) GetCoupons() []*AppliedCoupon {
+	if x != nil {
+		return x.Coupons
+	}
+	return nil
+}
+
+type DiscountLine struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// "sale" for reduced product prices, "promotion" for promotions.
+	Source        string  `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	PromotionId   string  `protobuf:"bytes,2,opt,name=promotion_id,json=promotionId,proto3" json:"promotion_id,omitempty"`
+	Code          string  `protobuf:"bytes,3,opt,name=code,proto3" json:"code,omitempty"`
+	Description   string  `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Amount        float64 `protobuf:"fixed64,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiscountLine) Reset() {
+	*x = DiscountLine{}
+	mi := &file_proto_cart_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiscountLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiscountLine) ProtoMessage() {}
+
+func (x *DiscountLine) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiscountLine.ProtoReflect.Descriptor instead.
+func (*DiscountLine) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DiscountLine) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *DiscountLine) GetPromotionId() string {
+	if x != nil {
+		return x.PromotionId
+	}
+	return ""
+}
+
+func (x *DiscountLine) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *DiscountLine) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *DiscountLine) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+// A coupon attached to the cart. It stays attached while it does not apply,
+// e.g. below the minimum spend; reason says why.
+type AppliedCoupon struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Applied       bool                   `protobuf:"varint,2,opt,name=applied,proto3" json:"applied,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppliedCoupon) Reset() {
+	*x = AppliedCoupon{}
+	mi := &file_proto_cart_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppliedCoupon) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppliedCoupon) ProtoMessage() {}
+
+func (x *AppliedCoupon) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppliedCoupon.ProtoReflect.Descriptor instead.
+func (*AppliedCoupon) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AppliedCoupon) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *AppliedCoupon) GetApplied() bool {
+	if x != nil {
+		return x.Applied
+	}
+	return false
+}
+
+func (x *AppliedCoupon) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ApplyCouponRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyCouponRequest) Reset() {
+	*x = ApplyCouponRequest{}
+	mi := &file_proto_cart_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyCouponRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyCouponRequest) ProtoMessage() {}
+
+func (x *ApplyCouponRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyCouponRequest.ProtoReflect.Descriptor instead.
+func (*ApplyCouponRequest) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ApplyCouponRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ApplyCouponRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type RemoveCouponRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveCouponRequest) Reset() {
+	*x = RemoveCouponRequest{}
+	mi := &file_proto_cart_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveCouponRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveCouponRequest) ProtoMessage() {}
+
+func (x *RemoveCouponRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveCouponRequest.ProtoReflect.Descriptor instead.
+func (*RemoveCouponRequest) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RemoveCouponRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RemoveCouponRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+// Checks every cart line against product-service. With auto_fix the cart is
+// corrected: gone lines are removed, quantities are lowered to the stock and
+// changed prices are accepted.
+type ValidateCartRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	AutoFix       bool                   `protobuf:"varint,2,opt,name=auto_fix,json=autoFix,proto3" json:"auto_fix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCartRequest) Reset() {
+	*x = ValidateCartRequest{}
+	mi := &file_proto_cart_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCartRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCartRequest) ProtoMessage() {}
+
+func (x *ValidateCartRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCartRequest.ProtoReflect.Descriptor instead.
+func (*ValidateCartRequest) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ValidateCartRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ValidateCartRequest) GetAutoFix() bool {
+	if x != nil {
+		return x.AutoFix
+	}
+	return false
+}
+
+type CartIssue struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	ProductId         string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	SkuId             string                 `protobuf:"bytes,2,opt,name=sku_id,json=skuId,proto3" json:"sku_id,omitempty"`
+	Kind              CartIssueKind          `protobuf:"varint,3,opt,name=kind,proto3,enum=cartpb.CartIssueKind" json:"kind,omitempty"`
+	RequestedQuantity int32                  `protobuf:"varint,4,opt,name=requested_quantity,json=requestedQuantity,proto3" json:"requested_quantity,omitempty"`
+	AvailableQuantity int32                  `protobuf:"varint,5,opt,name=available_quantity,json=availableQuantity,proto3" json:"available_quantity,omitempty"`
+	OldPrice          float64                `protobuf:"fixed64,6,opt,name=old_price,json=oldPrice,proto3" json:"old_price,omitempty"`
+	NewPrice          float64                `protobuf:"fixed64,7,opt,name=new_price,json=newPrice,proto3" json:"new_price,omitempty"`
+	// Set when auto_fix corrected the line.
+	Resolved      bool `protobuf:"varint,8,opt,name=resolved,proto3" json:"resolved,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CartIssue) Reset() {
+	*x = CartIssue{}
+	mi := &file_proto_cart_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CartIssue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CartIssue) ProtoMessage() {}
+
+func (x *CartIssue) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CartIssue.ProtoReflect.Descriptor instead.
+func (*CartIssue) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CartIssue) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *CartIssue) GetSkuId() string {
+	if x != nil {
+		return x.SkuId
+	}
+	return ""
+}
+
+func (x *CartIssue) GetKind() CartIssueKind {
+	if x != nil {
+		return x.Kind
+	}
+	return CartIssueKind_CART_ISSUE_REMOVED
+}
+
+func (x *CartIssue) GetRequestedQuantity() int32 {
+	if x != nil {
+		return x.RequestedQuantity
+	}
+	return 0
+}
+
+func (x *CartIssue) GetAvailableQuantity() int32 {
+	if x != nil {
+		return x.AvailableQuantity
+	}
+	return 0
+}
+
+func (x *CartIssue) GetOldPrice() float64 {
+	if x != nil {
+		return x.OldPrice
+	}
+	return 0
+}
+
+func (x *CartIssue) GetNewPrice() float64 {
+	if x != nil {
+		return x.NewPrice
+	}
+	return 0
+}
+
+func (x *CartIssue) GetResolved() bool {
+	if x != nil {
+		return x.Resolved
+	}
+	return false
+}
+
+type ValidateCartResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// False while any issue is unresolved; checkout is refused then.
+	Valid         bool          `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	Issues        []*CartIssue  `protobuf:"bytes,2,rep,name=issues,proto3" json:"issues,omitempty"`
+	Cart          *CartResponse `protobuf:"bytes,3,opt,name=cart,proto3" json:"cart,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCartResponse) Reset() {
+	*x = ValidateCartResponse{}
+	mi := &file_proto_cart_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCartResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCartResponse) ProtoMessage() {}
+
+func (x *ValidateCartResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCartResponse.ProtoReflect.Descriptor instead.
+func (*ValidateCartResponse) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ValidateCartResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *ValidateCartResponse) GetIssues() []*CartIssue {
+	if x != nil {
+		return x.Issues
+	}
+	return nil
+}
+
+func (x *ValidateCartResponse) GetCart() *CartResponse {
+	if x != nil {
+		return x.Cart
+	}
+	return nil
+}
+
+type WishlistItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	SkuId         string                 `protobuf:"bytes,2,opt,name=sku_id,json=skuId,proto3" json:"sku_id,omitempty"`
+	Quantity      int32                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	AddedAt       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=added_at,json=addedAt,proto3" json:"added_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WishlistItem) Reset() {
+	*x = WishlistItem{}
+	mi := &file_proto_cart_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WishlistItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WishlistItem) ProtoMessage() {}
+
+func (x *WishlistItem) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WishlistItem.ProtoReflect.Descriptor instead.
+func (*WishlistItem) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *WishlistItem) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *WishlistItem) GetSkuId() string {
+	if x != nil {
+		return x.SkuId
+	}
+	return ""
+}
+
+func (x *WishlistItem) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *WishlistItem) GetAddedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AddedAt
+	}
+	return nil
+}
+
+type Wishlist struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// user_id and share_token are left empty in GetSharedList responses.
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Kind          ListKind               `protobuf:"varint,4,opt,name=kind,proto3,enum=cartpb.ListKind" json:"kind,omitempty"`
+	Items         []*WishlistItem        `protobuf:"bytes,5,rep,name=items,proto3" json:"items,omitempty"`
+	ShareToken    string                 `protobuf:"bytes,6,opt,name=share_token,json=shareToken,proto3" json:"share_token,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Wishlist) Reset() {
+	*x = Wishlist{}
+	mi := &file_proto_cart_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Wishlist) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Wishlist) ProtoMessage() {}
+
+func (x *Wishlist) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Wishlist.ProtoReflect.Descriptor instead.
+func (*Wishlist) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Wishlist) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Wishlist) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Wishlist) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Wishlist) GetKind() ListKind {
+	if x != nil {
+		return x.Kind
+	}
+	return ListKind_LIST_KIND_CUSTOM
+}
+
+func (x *Wishlist) GetItems() []*WishlistItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *Wishlist) GetShareToken() string {
+	if x != nil {
+		return x.ShareToken
+	}
+	return ""
+}
+
+func (x *Wishlist) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Wishlist) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ListListsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListListsRequest) Reset() {
+	*x = ListListsRequest{}
+	mi := &file_proto_cart_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListListsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListListsRequest) ProtoMessage() {}
+
+func (x *ListListsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListListsRequest.ProtoReflect.Descriptor instead.
+func (*ListListsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListListsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type ListListsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lists         []*Wishlist            `protobuf:"bytes,1,rep,name=lists,proto3" json:"lists,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListListsResponse) Reset() {
+	*x = ListListsResponse{}
+	mi := &file_proto_cart_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListListsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListListsResponse) ProtoMessage() {}
+
+func (x *ListListsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListListsResponse.ProtoReflect.Descriptor instead.
+func (*ListListsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListListsResponse) GetLists() []*Wishlist {
+	if x != nil {
+		return x.Lists
+	}
+	return nil
+}
+
+type CreateListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateListRequest) Reset() {
+	*x = CreateListRequest{}
+	mi := &file_proto_cart_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateListRequest) ProtoMessage() {}
+
+func (x *CreateListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateListRequest.ProtoReflect.Descriptor instead.
+func (*CreateListRequest) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CreateListRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateListRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// An empty list_id means the default wishlist.
+type GetListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ListId        string                 `protobuf:"bytes,2,opt,name=list_id,json=listId,proto3" json:"list_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetListRequest) Reset() {
+	*x = GetListRequest{}
+	mi := &file_proto_cart_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetListRequest) ProtoMessage() {}
+
+func (x *GetListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetListRequest.ProtoReflect.Descriptor instead.
+func (*GetListRequest) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetListRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetListRequest) GetListId() string {
+	if x != nil {
+		return x.ListId
+	}
+	return ""
+}
+
+type RenameListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ListId        string                 `protobuf:"bytes,2,opt,name=list_id,json=listId,proto3" json:"list_id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameListRequest) Reset() {
+	*x = RenameListRequest{}
+	mi := &file_proto_cart_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameListRequest) ProtoMessage() {}
+
+func (x *RenameListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameListRequest.ProtoReflect.Descriptor instead.
+func (*RenameListRequest) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *RenameListRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RenameListRequest) GetListId() string {
+	if x != nil {
+		return x.ListId
+	}
+	return ""
+}
+
+func (x *RenameListRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DeleteListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ListId        string                 `protobuf:"bytes,2,opt,name=list_id,json=listId,proto3" json:"list_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteListRequest) Reset() {
+	*x = DeleteListRequest{}
+	mi := &file_proto_cart_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteListRequest) ProtoMessage() {}
+
+func (x *DeleteListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteListRequest.ProtoReflect.Descriptor instead.
+func (*DeleteListRequest) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DeleteListRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *DeleteListRequest) GetListId() string {
+	if x != nil {
+		return x.ListId
+	}
+	return ""
+}
+
+type DeleteListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteListResponse) Reset() {
+	*x = DeleteListResponse{}
+	mi := &file_proto_cart_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteListResponse) ProtoMessage() {}
+
+func (x *DeleteListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteListResponse.ProtoReflect.Descriptor instead.
+func (*DeleteListResponse) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *DeleteListResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// An empty list_id means the default wishlist.
+type AddListItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ListId        string                 `protobuf:"bytes,2,opt,name=list_id,json=listId,proto3" json:"list_id,omitempty"`
+	ProductId     string                 `protobuf:"bytes,3,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	SkuId         string                 `protobuf:"bytes,4,opt,name=sku_id,json=skuId,proto3" json:"sku_id,omitempty"`
+	Quantity      int32                  `protobuf:"varint,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddListItemRequest) Reset() {
+	*x = AddListItemRequest{}
+	mi := &file_proto_cart_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddListItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddListItemRequest) ProtoMessage() {}
+
+func (x *AddListItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddListItemRequest.ProtoReflect.Descriptor instead.
+func (*AddListItemRequest) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *AddListItemRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AddListItemRequest) GetListId() string {
+	if x != nil {
+		return x.ListId
+	}
+	return ""
+}
+
+func (x *AddListItemRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *AddListItemRequest) GetSkuId() string {
+	if x != nil {
+		return x.SkuId
+	}
+	return ""
+}
+
+func (x *AddListItemRequest) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type RemoveListItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ListId        string                 `protobuf:"bytes,2,opt,name=list_id,json=listId,proto3" json:"list_id,omitempty"`
+	ProductId     string                 `protobuf:"bytes,3,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	SkuId         string                 `protobuf:"bytes,4,opt,name=sku_id,json=skuId,proto3" json:"sku_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveListItemRequest) Reset() {
+	*x = RemoveListItemRequest{}
+	mi := &file_proto_cart_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveListItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveListItemRequest) ProtoMessage() {}
+
+func (x *RemoveListItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveListItemRequest.ProtoReflect.Descriptor instead.
+func (*RemoveListItemRequest) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *RemoveListItemRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RemoveListItemRequest) GetListId() string {
+	if x != nil {
+		return x.ListId
+	}
+	return ""
+}
+
+func (x *RemoveListItemRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *RemoveListItemRequest) GetSkuId() string {
+	if x != nil {
+		return x.SkuId
+	}
+	return ""
+}
+
+// An empty list_id means the save-for-later list.
+type MoveListItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ListId        string                 `protobuf:"bytes,2,opt,name=list_id,json=listId,proto3" json:"list_id,omitempty"`
+	ProductId     string                 `protobuf:"bytes,3,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	SkuId         string                 `protobuf:"bytes,4,opt,name=sku_id,json=skuId,proto3" json:"sku_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoveListItemRequest) Reset() {
+	*x = MoveListItemRequest{}
+	mi := &file_proto_cart_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoveListItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveListItemRequest) ProtoMessage() {}
+
+func (x *MoveListItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveListItemRequest.ProtoReflect.Descriptor instead.
+func (*MoveListItemRequest) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *MoveListItemRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *MoveListItemRequest) GetListId() string {
+	if x != nil {
+		return x.ListId
+	}
+	return ""
+}
+
+func (x *MoveListItemRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *MoveListItemRequest) GetSkuId() string {
+	if x != nil {
+		return x.SkuId
+	}
+	return ""
+}
+
+type MoveListItemResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	List          *Wishlist              `protobuf:"bytes,1,opt,name=list,proto3" json:"list,omitempty"`
+	Cart          *CartResponse          `protobuf:"bytes,2,opt,name=cart,proto3" json:"cart,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoveListItemResponse) Reset() {
+	*x = MoveListItemResponse{}
+	mi := &file_proto_cart_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoveListItemResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveListItemResponse) ProtoMessage() {}
+
+func (x *MoveListItemResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveListItemResponse.ProtoReflect.Descriptor instead.
+func (*MoveListItemResponse) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *MoveListItemResponse) GetList() *Wishlist {
+	if x != nil {
+		return x.List
+	}
+	return nil
+}
+
+func (x *MoveListItemResponse) GetCart() *CartResponse {
+	if x != nil {
+		return x.Cart
+	}
+	return nil
+}
+
+type ShareListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ListId        string                 `protobuf:"bytes,2,opt,name=list_id,json=listId,proto3" json:"list_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareListRequest) Reset() {
+	*x = ShareListRequest{}
+	mi := &file_proto_cart_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareListRequest) ProtoMessage() {}
+
+func (x *ShareListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareListRequest.ProtoReflect.Descriptor instead.
+func (*ShareListRequest) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ShareListRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ShareListRequest) GetListId() string {
+	if x != nil {
+		return x.ListId
+	}
+	return ""
+}
+
+type ShareListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShareToken    string                 `protobuf:"bytes,1,opt,name=share_token,json=shareToken,proto3" json:"share_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareListResponse) Reset() {
+	*x = ShareListResponse{}
+	mi := &file_proto_cart_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareListResponse) ProtoMessage() {}
+
+func (x *ShareListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareListResponse.ProtoReflect.Descriptor instead.
+func (*ShareListResponse) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ShareListResponse) GetShareToken() string {
+	if x != nil {
+		return x.ShareToken
+	}
+	return ""
+}
+
+type GetSharedListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShareToken    string                 `protobuf:"bytes,1,opt,name=share_token,json=shareToken,proto3" json:"share_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSharedListRequest) Reset() {
+	*x = GetSharedListRequest{}
+	mi := &file_proto_cart_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSharedListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSharedListRequest) ProtoMessage() {}
+
+func (x *GetSharedListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSharedListRequest.ProtoReflect.Descriptor instead.
+func (*GetSharedListRequest) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GetSharedListRequest) GetShareToken() string {
+	if x != nil {
+		return x.ShareToken
+	}
+	return ""
+}
+
+type Promotion struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Empty for promotions that apply automatically.
+	Code         string        `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Description  string        `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Kind         PromotionKind `protobuf:"varint,4,opt,name=kind,proto3,enum=cartpb.PromotionKind" json:"kind,omitempty"`
+	Percent      float64       `protobuf:"fixed64,5,opt,name=percent,proto3" json:"percent,omitempty"`
+	Amount       float64       `protobuf:"fixed64,6,opt,name=amount,proto3" json:"amount,omitempty"`
+	ProductId    string        `protobuf:"bytes,7,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	BuyQuantity  int32         `protobuf:"varint,8,opt,name=buy_quantity,json=buyQuantity,proto3" json:"buy_quantity,omitempty"`
+	FreeQuantity int32         `protobuf:"varint,9,opt,name=free_quantity,json=freeQuantity,proto3" json:"free_quantity,omitempty"`
+	Category     string        `protobuf:"bytes,10,opt,name=category,proto3" json:"category,omitempty"`
+	MinSpend     float64       `protobuf:"fixed64,11,opt,name=min_spend,json=minSpend,proto3" json:"min_spend,omitempty"`
+	// Zero means unlimited.
+	MaxUses        int64                  `protobuf:"varint,12,opt,name=max_uses,json=maxUses,proto3" json:"max_uses,omitempty"`
+	MaxUsesPerUser int64                  `protobuf:"varint,13,opt,name=max_uses_per_user,json=maxUsesPerUser,proto3" json:"max_uses_per_user,omitempty"`
+	Uses           int64                  `protobuf:"varint,14,opt,name=uses,proto3" json:"uses,omitempty"`
+	StartsAt       *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=starts_at,json=startsAt,proto3" json:"starts_at,omitempty"`
+	// Unset means the promotion does not end.
+	EndsAt        *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=ends_at,json=endsAt,proto3" json:"ends_at,omitempty"`
+	Active        bool                   `protobuf:"varint,17,opt,name=active,proto3" json:"active,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,18,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Promotion) Reset() {
+	*x = Promotion{}
+	mi := &file_proto_cart_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Promotion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Promotion) ProtoMessage() {}
+
+func (x *Promotion) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Promotion.ProtoReflect.Descriptor instead.
+func (*Promotion) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *Promotion) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Promotion) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Promotion) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Promotion) GetKind() PromotionKind {
+	if x != nil {
+		return x.Kind
+	}
+	return PromotionKind_PROMOTION_KIND_PERCENTAGE
+}
+
+func (x *Promotion) GetPercent() float64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *Promotion) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Promotion) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *Promotion) GetBuyQuantity() int32 {
+	if x != nil {
+		return x.BuyQuantity
+	}
+	return 0
+}
+
+func (x *Promotion) GetFreeQuantity() int32 {
+	if x != nil {
+		return x.FreeQuantity
+	}
+	return 0
+}
+
+func (x *Promotion) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Promotion) GetMinSpend() float64 {
+	if x != nil {
+		return x.MinSpend
+	}
+	return 0
+}
+
+func (x *Promotion) GetMaxUses() int64 {
+	if x != nil {
+		return x.MaxUses
+	}
+	return 0
+}
+
+func (x *Promotion) GetMaxUsesPerUser() int64 {
+	if x != nil {
+		return x.MaxUsesPerUser
+	}
+	return 0
+}
+
+func (x *Promotion) GetUses() int64 {
+	if x != nil {
+		return x.Uses
+	}
+	return 0
+}
+
+func (x *Promotion) GetStartsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartsAt
+	}
+	return nil
+}
+
+func (x *Promotion) GetEndsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndsAt
+	}
+	return nil
+}
+
+func (x *Promotion) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *Promotion) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type GetPromotionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPromotionRequest) Reset() {
+	*x = GetPromotionRequest{}
+	mi := &file_proto_cart_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPromotionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPromotionRequest) ProtoMessage() {}
+
+func (x *GetPromotionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPromotionRequest.ProtoReflect.Descriptor instead.
+func (*GetPromotionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GetPromotionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListPromotionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ActiveOnly    bool                   `protobuf:"varint,1,opt,name=active_only,json=activeOnly,proto3" json:"active_only,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPromotionsRequest) Reset() {
+	*x = ListPromotionsRequest{}
+	mi := &file_proto_cart_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPromotionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPromotionsRequest) ProtoMessage() {}
+
+func (x *ListPromotionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPromotionsRequest.ProtoReflect.Descriptor instead.
+func (*ListPromotionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ListPromotionsRequest) GetActiveOnly() bool {
+	if x != nil {
+		return x.ActiveOnly
+	}
+	return false
+}
+
+type ListPromotionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Promotions    []*Promotion           `protobuf:"bytes,1,rep,name=promotions,proto3" json:"promotions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPromotionsResponse) Reset() {
+	*x = ListPromotionsResponse{}
+	mi := &file_proto_cart_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPromotionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPromotionsResponse) ProtoMessage() {}
+
+func (x *ListPromotionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_cart_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPromotionsResponse.ProtoReflect.Descriptor instead.
+func (*ListPromotionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_cart_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ListPromotionsResponse) GetPromotions() []*Promotion {
+	if x != nil {
+		return x.Promotions
+	}
+	return nil
+}
+
+var File_proto_cart_proto protoreflect.FileDescriptor
+
+const file_proto_cart_proto_rawDesc = "" +
+	"\n" +
+	"\x10proto/cart.proto\x12\x06cartpb\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9a\x03\n" +
+	"\bCartItem\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12\x15\n" +
+	"\x06sku_id\x18\x03 \x01(\tR\x05skuId\x125\n" +
+	"\badded_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\aaddedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x12\n" +
+	"\x04name\x18\x06 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"unit_price\x18\a \x01(\x01R\tunitPrice\x12\x1d\n" +
+	"\n" +
+	"list_price\x18\b \x01(\x01R\tlistPrice\x12\x1d\n" +
+	"\n" +
+	"line_total\x18\t \x01(\x01R\tlineTotal\x12\x1c\n" +
+	"\tavailable\x18\n" +
+	" \x01(\bR\tavailable\x12\x1f\n" +
+	"\vadded_price\x18\v \x01(\x01R\n" +
+	"addedPrice\x12\x1a\n" +
+	"\bcategory\x18\f \x01(\tR\bcategory\"S\n" +
+	"\x10AddToCartRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12&\n" +
+	"\x05items\x18\x02 \x03(\v2\x10.cartpb.CartItemR\x05items\")\n" +
+	"\x0eGetCartRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"f\n" +
+	"\x15RemoveFromCartRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\tR\tproductId\x12\x15\n" +
+	"\x06sku_id\x18\x03 \x01(\tR\x05skuId\"\x82\x01\n" +
+	"\x15UpdateCartItemRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\tR\tproductId\x12\x15\n" +
+	"\x06sku_id\x18\x03 \x01(\tR\x05skuId\x12\x1a\n" +
+	"\bquantity\x18\x04 \x01(\x05R\bquantity\"+\n" +
+	"\x10ClearCartRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"\x91\x01\n" +
+	"\x10MergeCartRequest\x12$\n" +
+	"\x0esource_user_id\x18\x01 \x01(\tR\fsourceUserId\x12$\n" +
+	"\x0etarget_user_id\x18\x02 \x01(\tR\ftargetUserId\x121\n" +
+	"\bstrategy\x18\x03 \x01(\x0e2\x15.cartpb.MergeStrategyR\bstrategy\"\x97\x02\n" +
+	"\fCartResponse\x12&\n" +
+	"\x05items\x18\x01 \x03(\v2\x10.cartpb.CartItemR\x05items\x12\x1a\n" +
+	"\bsubtotal\x18\x02 \x01(\x01R\bsubtotal\x12\x1a\n" +
+	"\bdiscount\x18\x03 \x01(\x01R\bdiscount\x12\x10\n" +
+	"\x03tax\x18\x04 \x01(\x01R\x03tax\x12\x14\n" +
+	"\x05total\x18\x05 \x01(\x01R\x05total\x12\x1a\n" +
+	"\bcurrency\x18\x06 \x01(\tR\bcurrency\x122\n" +
+	"\tdiscounts\x18\a \x03(\v2\x14.cartpb.DiscountLineR\tdiscounts\x12/\n" +
+	"\acoupons\x18\b \x03(\v2\x15.cartpb.AppliedCouponR\acoupons\"\x97\x01\n" +
+	"\fDiscountLine\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12!\n" +
+	"\fpromotion_id\x18\x02 \x01(\tR\vpromotionId\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\tR\x04code\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\x01R\x06amount\"U\n" +
+	"\rAppliedCoupon\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\aapplied\x18\x02 \x01(\bR\aapplied\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"A\n" +
+	"\x12ApplyCouponRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\"B\n" +
+	"\x13RemoveCouponRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\"I\n" +
+	"\x13ValidateCartRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
+	"\bauto_fix\x18\x02 \x01(\bR\aautoFix\"\xa0\x02\n" +
+	"\tCartIssue\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x15\n" +
+	"\x06sku_id\x18\x02 \x01(\tR\x05skuId\x12)\n" +
+	"\x04kind\x18\x03 \x01(\x0e2\x15.cartpb.CartIssueKindR\x04kind\x12-\n" +
+	"\x12requested_quantity\x18\x04 \x01(\x05R\x11requestedQuantity\x12-\n" +
+	"\x12available_quantity\x18\x05 \x01(\x05R\x11availableQuantity\x12\x1b\n" +
+	"\told_price\x18\x06 \x01(\x01R\boldPrice\x12\x1b\n" +
+	"\tnew_price\x18\a \x01(\x01R\bnewPrice\x12\x1a\n" +
+	"\bresolved\x18\b \x01(\bR\bresolved\"\x81\x01\n" +
+	"\x14ValidateCartResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12)\n" +
+	"\x06issues\x18\x02 \x03(\v2\x11.cartpb.CartIssueR\x06issues\x12(\n" +
+	"\x04cart\x18\x03 \x01(\v2\x14.cartpb.CartResponseR\x04cart\"\x97\x01\n" +
+	"\fWishlistItem\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x15\n" +
+	"\x06sku_id\x18\x02 \x01(\tR\x05skuId\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x05R\bquantity\x125\n" +
+	"\badded_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\aaddedAt\"\xb0\x02\n" +
+	"\bWishlist\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12$\n" +
+	"\x04kind\x18\x04 \x01(\x0e2\x10.cartpb.ListKindR\x04kind\x12*\n" +
+	"\x05items\x18\x05 \x03(\v2\x14.cartpb.WishlistItemR\x05items\x12\x1f\n" +
+	"\vshare_token\x18\x06 \x01(\tR\n" +
+	"shareToken\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"+\n" +
+	"\x10ListListsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\";\n" +
+	"\x11ListListsResponse\x12&\n" +
+	"\x05lists\x18\x01 \x03(\v2\x10.cartpb.WishlistR\x05lists\"@\n" +
+	"\x11CreateListRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"B\n" +
+	"\x0eGetListRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x17\n" +
+	"\alist_id\x18\x02 \x01(\tR\x06listId\"Y\n" +
+	"\x11RenameListRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x17\n" +
+	"\alist_id\x18\x02 \x01(\tR\x06listId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\"E\n" +
+	"\x11DeleteListRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x17\n" +
+	"\alist_id\x18\x02 \x01(\tR\x06listId\".\n" +
+	"\x12DeleteListResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x98\x01\n" +
+	"\x12AddListItemRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x17\n" +
+	"\alist_id\x18\x02 \x01(\tR\x06listId\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x03 \x01(\tR\tproductId\x12\x15\n" +
+	"\x06sku_id\x18\x04 \x01(\tR\x05skuId\x12\x1a\n" +
+	"\bquantity\x18\x05 \x01(\x05R\bquantity\"\x7f\n" +
+	"\x15RemoveListItemRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x17\n" +
+	"\alist_id\x18\x02 \x01(\tR\x06listId\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x03 \x01(\tR\tproductId\x12\x15\n" +
+	"\x06sku_id\x18\x04 \x01(\tR\x05skuId\"}\n" +
+	"\x13MoveListItemRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x17\n" +
+	"\alist_id\x18\x02 \x01(\tR\x06listId\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x03 \x01(\tR\tproductId\x12\x15\n" +
+	"\x06sku_id\x18\x04 \x01(\tR\x05skuId\"f\n" +
+	"\x14MoveListItemResponse\x12$\n" +
+	"\x04list\x18\x01 \x01(\v2\x10.cartpb.WishlistR\x04list\x12(\n" +
+	"\x04cart\x18\x02 \x01(\v2\x14.cartpb.CartResponseR\x04cart\"D\n" +
+	"\x10ShareListRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x17\n" +
+	"\alist_id\x18\x02 \x01(\tR\x06listId\"4\n" +
+	"\x11ShareListResponse\x12\x1f\n" +
+	"\vshare_token\x18\x01 \x01(\tR\n" +
+	"shareToken\"7\n" +
+	"\x14GetSharedListRequest\x12\x1f\n" +
+	"\vshare_token\x18\x01 \x01(\tR\n" +
+	"shareToken\"\xe9\x04\n" +
+	"\tPromotion\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12)\n" +
+	"\x04kind\x18\x04 \x01(\x0e2\x15.cartpb.PromotionKindR\x04kind\x12\x18\n" +
+	"\apercent\x18\x05 \x01(\x01R\apercent\x12\x16\n" +
+	"\x06amount\x18\x06 \x01(\x01R\x06amount\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\a \x01(\tR\tproductId\x12!\n" +
+	"\fbuy_quantity\x18\b \x01(\x05R\vbuyQuantity\x12#\n" +
+	"\rfree_quantity\x18\t \x01(\x05R\ffreeQuantity\x12\x1a\n" +
+	"\bcategory\x18\n" +
+	" \x01(\tR\bcategory\x12\x1b\n" +
+	"\tmin_spend\x18\v \x01(\x01R\bminSpend\x12\x19\n" +
+	"\bmax_uses\x18\f \x01(\x03R\amaxUses\x12)\n" +
+	"\x11max_uses_per_user\x18\r \x01(\x03R\x0emaxUsesPerUser\x12\x12\n" +
+	"\x04uses\x18\x0e \x01(\x03R\x04uses\x127\n" +
+	"\tstarts_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\bstartsAt\x123\n" +
+	"\aends_at\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\x06endsAt\x12\x16\n" +
+	"\x06active\x18\x11 \x01(\bR\x06active\x129\n" +
+	"\n" +
+	"created_at\x18\x12 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"%\n" +
+	"\x13GetPromotionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"8\n" +
+	"\x15ListPromotionsRequest\x12\x1f\n" +
+	"\vactive_only\x18\x01 \x01(\bR\n" +
+	"activeOnly\"K\n" +
+	"\x16ListPromotionsResponse\x121\n" +
+	"\n" +
+	"promotions\x18\x01 \x03(\v2\x11.cartpb.PromotionR\n" +
+	"promotions*_\n" +
+	"\rMergeStrategy\x12\x16\n" +
+	"\x12MERGE_STRATEGY_SUM\x10\x00\x12\x16\n" +
+	"\x12MERGE_STRATEGY_MAX\x10\x01\x12\x1e\n" +
+	"\x1aMERGE_STRATEGY_KEEP_TARGET\x10\x02*h\n" +
+	"\rCartIssueKind\x12\x16\n" +
+	"\x12CART_ISSUE_REMOVED\x10\x00\x12!\n" +
+	"\x1dCART_ISSUE_INSUFFICIENT_STOCK\x10\x01\x12\x1c\n" +
+	"\x18CART_ISSUE_PRICE_CHANGED\x10\x02*V\n" +
+	"\bListKind\x12\x14\n" +
+	"\x10LIST_KIND_CUSTOM\x10\x00\x12\x16\n" +
+	"\x12LIST_KIND_WISHLIST\x10\x01\x12\x1c\n" +
+	"\x18LIST_KIND_SAVE_FOR_LATER\x10\x02*\x8c\x01\n" +
+	"\rPromotionKind\x12\x1d\n" +
+	"\x19PROMOTION_KIND_PERCENTAGE\x10\x00\x12\x1f\n" +
+	"\x1bPROMOTION_KIND_FIXED_AMOUNT\x10\x01\x12\x1e\n" +
+	"\x1aPROMOTION_KIND_BUY_X_GET_Y\x10\x02\x12\x1b\n" +
+	"\x17PROMOTION_KIND_CATEGORY\x10\x032\xda\x04\n" +
+	"\vCartService\x12;\n" +
+	"\tAddToCart\x12\x18.cartpb.AddToCartRequest\x1a\x14.cartpb.CartResponse\x127\n" +
+	"\aGetCart\x12\x16.cartpb.GetCartRequest\x1a\x14.cartpb.CartResponse\x12E\n" +
+	"\x0eRemoveFromCart\x12\x1d.cartpb.RemoveFromCartRequest\x1a\x14.cartpb.CartResponse\x12E\n" +
+	"\x0eUpdateCartItem\x12\x1d.cartpb.UpdateCartItemRequest\x1a\x14.cartpb.CartResponse\x12;\n" +
+	"\tClearCart\x12\x18.cartpb.ClearCartRequest\x1a\x14.cartpb.CartResponse\x12;\n" +
+	"\tMergeCart\x12\x18.cartpb.MergeCartRequest\x1a\x14.cartpb.CartResponse\x12I\n" +
+	"\fValidateCart\x12\x1b.cartpb.ValidateCartRequest\x1a\x1c.cartpb.ValidateCartResponse\x12?\n" +
+	"\vApplyCoupon\x12\x1a.cartpb.ApplyCouponRequest\x1a\x14.cartpb.CartResponse\x12A\n" +
+	"\fRemoveCoupon\x12\x1b.cartpb.RemoveCouponRequest\x1a\x14.cartpb.CartResponse2\xa3\x02\n" +
+	"\x10PromotionService\x127\n" +
+	"\x0fCreatePromotion\x12\x11.cartpb.Promotion\x1a\x11.cartpb.Promotion\x12>\n" +
+	"\fGetPromotion\x12\x1b.cartpb.GetPromotionRequest\x1a\x11.cartpb.Promotion\x12O\n" +
+	"\x0eListPromotions\x12\x1d.cartpb.ListPromotionsRequest\x1a\x1e.cartpb.ListPromotionsResponse\x12E\n" +
+	"\x13DeactivatePromotion\x12\x1b.cartpb.GetPromotionRequest\x1a\x11.cartpb.Promotion2\x95\x06\n" +
+	"\x0fWishlistService\x12@\n" +
+	"\tListLists\x12\x18.cartpb.ListListsRequest\x1a\x19.cartpb.ListListsResponse\x129\n" +
+	"\n" +
+	"CreateList\x12\x19.cartpb.CreateListRequest\x1a\x10.cartpb.Wishlist\x123\n" +
+	"\aGetList\x12\x16.cartpb.GetListRequest\x1a\x10.cartpb.Wishlist\x129\n" +
+	"\n" +
+	"RenameList\x12\x19.cartpb.RenameListRequest\x1a\x10.cartpb.Wishlist\x12C\n" +
+	"\n" +
+	"DeleteList\x12\x19.cartpb.DeleteListRequest\x1a\x1a.cartpb.DeleteListResponse\x12;\n" +
+	"\vAddListItem\x12\x1a.cartpb.AddListItemRequest\x1a\x10.cartpb.Wishlist\x12A\n" +
+	"\x0eRemoveListItem\x12\x1d.cartpb.RemoveListItemRequest\x1a\x10.cartpb.Wishlist\x12G\n" +
+	"\n" +
+	"MoveToCart\x12\x1b.cartpb.MoveListItemRequest\x1a\x1c.cartpb.MoveListItemResponse\x12I\n" +
+	"\fMoveFromCart\x12\x1b.cartpb.MoveListItemRequest\x1a\x1c.cartpb.MoveListItemResponse\x12@\n" +
+	"\tShareList\x12\x18.cartpb.ShareListRequest\x1a\x19.cartpb.ShareListResponse\x129\n" +
+	"\vUnshareList\x12\x18.cartpb.ShareListRequest\x1a\x10.cartpb.Wishlist\x12?\n" +
+	"\rGetSharedList\x12\x1c.cartpb.GetSharedListRequest\x1a\x10.cartpb.WishlistB$Z\"shopping-cart-service/proto/cartpbb\x06proto3"
 
 var (
 	file_proto_cart_proto_rawDescOnce sync.Once
@@ -302,28 +2737,129 @@ func file_proto_cart_proto_rawDescGZIP() []byte {
 	return file_proto_cart_proto_rawDescData
 }
 
-var file_proto_cart_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_proto_cart_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_proto_cart_proto_msgTypes = make([]protoimpl.MessageInfo, 35)
 var file_proto_cart_proto_goTypes = []any{
-	(*CartItem)(nil),              // 0: cartpb.CartItem
-	(*AddToCartRequest)(nil),      // 1: cartpb.AddToCartRequest
-	(*GetCartRequest)(nil),        // 2: cartpb.GetCartRequest
-	(*RemoveFromCartRequest)(nil), // 3: cartpb.RemoveFromCartRequest
-	(*CartResponse)(nil),          // 4: cartpb.CartResponse
+	(MergeStrategy)(0),             // 0: cartpb.MergeStrategy
+	(CartIssueKind)(0),             // 1: cartpb.CartIssueKind
+	(ListKind)(0),                  // 2: cartpb.ListKind
+	(PromotionKind)(0),             // 3: cartpb.PromotionKind
+	(*CartItem)(nil),               // 4: cartpb.CartItem
+	(*AddToCartRequest)(nil),       // 5: cartpb.AddToCartRequest
+	(*GetCartRequest)(nil),         // 6: cartpb.GetCartRequest
+	(*RemoveFromCartRequest)(nil),  // 7: cartpb.RemoveFromCartRequest
+	(*UpdateCartItemRequest)(nil),  // 8: cartpb.UpdateCartItemRequest
+	(*ClearCartRequest)(nil),       // 9: cartpb.ClearCartRequest
+	(*MergeCartRequest)(nil),       // 10: cartpb.MergeCartRequest
+	(*CartResponse)(nil),           // 11: cartpb.CartResponse
+	(*DiscountLine)(nil),           // 12: cartpb.DiscountLine
+	(*AppliedCoupon)(nil),          // 13: cartpb.AppliedCoupon
+	(*ApplyCouponRequest)(nil),     // 14: cartpb.ApplyCouponRequest
+	(*RemoveCouponRequest)(nil),    // 15: cartpb.RemoveCouponRequest
+	(*ValidateCartRequest)(nil),    // 16: cartpb.ValidateCartRequest
+	(*CartIssue)(nil),              // 17: cartpb.CartIssue
+	(*ValidateCartResponse)(nil),   // 18: cartpb.ValidateCartResponse
+	(*WishlistItem)(nil),           // 19: cartpb.WishlistItem
+	(*Wishlist)(nil),               // 20: cartpb.Wishlist
+	(*ListListsRequest)(nil),       // 21: cartpb.ListListsRequest
+	(*ListListsResponse)(nil),      // 22: cartpb.ListListsResponse
+	(*CreateListRequest)(nil),      // 23: cartpb.CreateListRequest
+	(*GetListRequest)(nil),         // 24: cartpb.GetListRequest
+	(*RenameListRequest)(nil),      // 25: cartpb.RenameListRequest
+	(*DeleteListRequest)(nil),      // 26: cartpb.DeleteListRequest
+	(*DeleteListResponse)(nil),     // 27: cartpb.DeleteListResponse
+	(*AddListItemRequest)(nil),     // 28: cartpb.AddListItemRequest
+	(*RemoveListItemRequest)(nil),  // 29: cartpb.RemoveListItemRequest
+	(*MoveListItemRequest)(nil),    // 30: cartpb.MoveListItemRequest
+	(*MoveListItemResponse)(nil),   // 31: cartpb.MoveListItemResponse
+	(*ShareListRequest)(nil),       // 32: cartpb.ShareListRequest
+	(*ShareListResponse)(nil),      // 33: cartpb.ShareListResponse
+	(*GetSharedListRequest)(nil),   // 34: cartpb.GetSharedListRequest
+	(*Promotion)(nil),              // 35: cartpb.Promotion
+	(*GetPromotionRequest)(nil),    // 36: cartpb.GetPromotionRequest
+	(*ListPromotionsRequest)(nil),  // 37: cartpb.ListPromotionsRequest
+	(*ListPromotionsResponse)(nil), // 38: cartpb.ListPromotionsResponse
+	(*timestamppb.Timestamp)(nil),  // 39: google.protobuf.Timestamp
 }
 var file_proto_cart_proto_depIdxs = []int32{
-	0, // 0: cartpb.AddToCartRequest.items:type_name -> cartpb.CartItem
-	0, // 1: cartpb.CartResponse.items:type_name -> cartpb.CartItem
-	1, // 2: cartpb.CartService.AddToCart:input_type -> cartpb.AddToCartRequest
-	2, // 3: cartpb.CartService.GetCart:input_type -> cartpb.GetCartRequest
-	3, // 4: cartpb.CartService.RemoveFromCart:input_type -> cartpb.RemoveFromCartRequest
-	4, // 5: cartpb.CartService.AddToCart:output_type -> cartpb.CartResponse
-	4, // 6: cartpb.CartService.GetCart:output_type -> cartpb.CartResponse
-	4, // 7: cartpb.CartService.RemoveFromCart:output_type -> cartpb.CartResponse
-	5, // [5:8] is the sub-list for method output_type
-	2, // [2:5] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	39, // 0: cartpb.CartItem.added_at:type_name -> google.protobuf.Timestamp
+	39, // 1: cartpb.CartItem.updated_at:type_name -> google.protobuf.Timestamp
+	4,  // 2: cartpb.AddToCartRequest.items:type_name -> cartpb.CartItem
+	0,  // 3: cartpb.MergeCartRequest.strategy:type_name -> cartpb.MergeStrategy
+	4,  // 4: cartpb.CartResponse.items:type_name -> cartpb.CartItem
+	12, // 5: cartpb.CartResponse.discounts:type_name -> cartpb.DiscountLine
+	13, // 6: cartpb.CartResponse.coupons:type_name -> cartpb.AppliedCoupon
+	1,  // 7: cartpb.CartIssue.kind:type_name -> cartpb.CartIssueKind
+	17, // 8: cartpb.ValidateCartResponse.issues:type_name -> cartpb.CartIssue
+	11, // 9: cartpb.ValidateCartResponse.cart:type_name -> cartpb.CartResponse
+	39, // 10: cartpb.WishlistItem.added_at:type_name -> google.protobuf.Timestamp
+	2,  // 11: cartpb.Wishlist.kind:type_name -> cartpb.ListKind
+	19, // 12: cartpb.Wishlist.items:type_name -> cartpb.WishlistItem
+	39, // 13: cartpb.Wishlist.created_at:type_name -> google.protobuf.Timestamp
+	39, // 14: cartpb.Wishlist.updated_at:type_name -> google.protobuf.Timestamp
+	20, // 15: cartpb.ListListsResponse.lists:type_name -> cartpb.Wishlist
+	20, // 16: cartpb.MoveListItemResponse.list:type_name -> cartpb.Wishlist
+	11, // 17: cartpb.MoveListItemResponse.cart:type_name -> cartpb.CartResponse
+	3,  // 18: cartpb.Promotion.kind:type_name -> cartpb.PromotionKind
+	39, // 19: cartpb.Promotion.starts_at:type_name -> google.protobuf.Timestamp
+	39, // 20: cartpb.Promotion.ends_at:type_name -> google.protobuf.Timestamp
+	39, // 21: cartpb.Promotion.created_at:type_name -> google.protobuf.Timestamp
+	35, // 22: cartpb.ListPromotionsResponse.promotions:type_name -> cartpb.Promotion
+	5,  // 23: cartpb.CartService.AddToCart:input_type -> cartpb.AddToCartRequest
+	6,  // 24: cartpb.CartService.GetCart:input_type -> cartpb.GetCartRequest
+	7,  // 25: cartpb.CartService.RemoveFromCart:input_type -> cartpb.RemoveFromCartRequest
+	8,  // 26: cartpb.CartService.UpdateCartItem:input_type -> cartpb.UpdateCartItemRequest
+	9,  // 27: cartpb.CartService.ClearCart:input_type -> cartpb.ClearCartRequest
+	10, // 28: cartpb.CartService.MergeCart:input_type -> cartpb.MergeCartRequest
+	16, // 29: cartpb.CartService.ValidateCart:input_type -> cartpb.ValidateCartRequest
+	14, // 30: cartpb.CartService.ApplyCoupon:input_type -> cartpb.ApplyCouponRequest
+	15, // 31: cartpb.CartService.RemoveCoupon:input_type -> cartpb.RemoveCouponRequest
+	35, // 32: cartpb.PromotionService.CreatePromotion:input_type -> cartpb.Promotion
+	36, // 33: cartpb.PromotionService.GetPromotion:input_type -> cartpb.GetPromotionRequest
+	37, // 34: cartpb.PromotionService.ListPromotions:input_type -> cartpb.ListPromotionsRequest
+	36, // 35: cartpb.PromotionService.DeactivatePromotion:input_type -> cartpb.GetPromotionRequest
+	21, // 36: cartpb.WishlistService.ListLists:input_type -> cartpb.ListListsRequest
+	23, // 37: cartpb.WishlistService.CreateList:input_type -> cartpb.CreateListRequest
+	24, // 38: cartpb.WishlistService.GetList:input_type -> cartpb.GetListRequest
+	25, // 39: cartpb.WishlistService.RenameList:input_type -> cartpb.RenameListRequest
+	26, // 40: cartpb.WishlistService.DeleteList:input_type -> cartpb.DeleteListRequest
+	28, // 41: cartpb.WishlistService.AddListItem:input_type -> cartpb.AddListItemRequest
+	29, // 42: cartpb.WishlistService.RemoveListItem:input_type -> cartpb.RemoveListItemRequest
+	30, // 43: cartpb.WishlistService.MoveToCart:input_type -> cartpb.MoveListItemRequest
+	30, // 44: cartpb.WishlistService.MoveFromCart:input_type -> cartpb.MoveListItemRequest
+	32, // 45: cartpb.WishlistService.ShareList:input_type -> cartpb.ShareListRequest
+	32, // 46: cartpb.WishlistService.UnshareList:input_type -> cartpb.ShareListRequest
+	34, // 47: cartpb.WishlistService.GetSharedList:input_type -> cartpb.GetSharedListRequest
+	11, // 48: cartpb.CartService.AddToCart:output_type -> cartpb.CartResponse
+	11, // 49: cartpb.CartService.GetCart:output_type -> cartpb.CartResponse
+	11, // 50: cartpb.CartService.RemoveFromCart:output_type -> cartpb.CartResponse
+	11, // 51: cartpb.CartService.UpdateCartItem:output_type -> cartpb.CartResponse
+	11, // 52: cartpb.CartService.ClearCart:output_type -> cartpb.CartResponse
+	11, // 53: cartpb.CartService.MergeCart:output_type -> cartpb.CartResponse
+	18, // 54: cartpb.CartService.ValidateCart:output_type -> cartpb.ValidateCartResponse
+	11, // 55: cartpb.CartService.ApplyCoupon:output_type -> cartpb.CartResponse
+	11, // 56: cartpb.CartService.RemoveCoupon:output_type -> cartpb.CartResponse
+	35, // 57: cartpb.PromotionService.CreatePromotion:output_type -> cartpb.Promotion
+	35, // 58: cartpb.PromotionService.GetPromotion:output_type -> cartpb.Promotion
+	38, // 59: cartpb.PromotionService.ListPromotions:output_type -> cartpb.ListPromotionsResponse
+	35, // 60: cartpb.PromotionService.DeactivatePromotion:output_type -> cartpb.Promotion
+	22, // 61: cartpb.WishlistService.ListLists:output_type -> cartpb.ListListsResponse
+	20, // 62: cartpb.WishlistService.CreateList:output_type -> cartpb.Wishlist
+	20, // 63: cartpb.WishlistService.GetList:output_type -> cartpb.Wishlist
+	20, // 64: cartpb.WishlistService.RenameList:output_type -> cartpb.Wishlist
+	27, // 65: cartpb.WishlistService.DeleteList:output_type -> cartpb.DeleteListResponse
+	20, // 66: cartpb.WishlistService.AddListItem:output_type -> cartpb.Wishlist
+	20, // 67: cartpb.WishlistService.RemoveListItem:output_type -> cartpb.Wishlist
+	31, // 68: cartpb.WishlistService.MoveToCart:output_type -> cartpb.MoveListItemResponse
+	31, // 69: cartpb.WishlistService.MoveFromCart:output_type -> cartpb.MoveListItemResponse
+	33, // 70: cartpb.WishlistService.ShareList:output_type -> cartpb.ShareListResponse
+	20, // 71: cartpb.WishlistService.UnshareList:output_type -> cartpb.Wishlist
+	20, // 72: cartpb.WishlistService.GetSharedList:output_type -> cartpb.Wishlist
+	48, // [48:73] is the sub-list for method output_type
+	23, // [23:48] is the sub-list for method input_type
+	23, // [23:23] is the sub-list for extension type_name
+	23, // [23:23] is the sub-list for extension extendee
+	0,  // [0:23] is the sub-list for field type_name
 }
 
 func init() { file_proto_cart_proto_init() }
@@ -336,13 +2872,14 @@ func file_proto_cart_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_cart_proto_rawDesc), len(file_proto_cart_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   5,
+			NumEnums:      4,
+			NumMessages:   35,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   3,
 		},
 		GoTypes:           file_proto_cart_proto_goTypes,
 		DependencyIndexes: file_proto_cart_proto_depIdxs,
+		EnumInfos:         file_proto_cart_proto_enumTypes,
 		MessageInfos:      file_proto_cart_proto_msgTypes,
 	}.Build()
 	File_proto_cart_proto = out.File
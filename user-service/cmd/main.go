@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
+	"time"
+
 	"github.com/go-redis/redis/v8"
 	"github.com/nats-io/nats.go"
 	//"user-service/internal/adapters/cache"
 	"user-service/internal/adapters/handlers"
 	"user-service/internal/adapters/repositories"
+	"user-service/internal/auth"
 	"user-service/internal/config"
+	"user-service/internal/core/domain"
 	"user-service/internal/core/services"
 	"user-service/internal/infrastructure/database"
 	"user-service/internal/infrastructure/email"
@@ -51,16 +56,116 @@ func main() {
 		cfg.SMTPPassword,
 		cfg.SMTPUsername,
 	)
-	userService := services.NewUserService(userRepo)
+	publisher, err := infrastructure_nats.NewPublisher(nc, cfg.UserEventRetention)
+	if err != nil {
+		logger.Fatal("Failed to set up the %s stream: %v", infrastructure_nats.StreamName, err)
+	}
+	userService := services.NewUserService(userRepo, publisher)
+	resetRepo := repositories.NewMongoPasswordResetRepository(mongoClient, cfg.DBName)
+	verificationRepo := repositories.NewMongoEmailVerificationRepository(mongoClient, cfg.DBName)
+	sessionRepo := repositories.NewRedisSessionRepository(redisClient)
+	loginAttempts := repositories.NewRedisLoginAttemptRepository(redisClient)
+	passwordService := services.NewPasswordService(
+		userRepo,
+		resetRepo,
+		emailService,
+		loginAttempts,
+		services.PasswordResetConfig{
+			TokenTTL:    cfg.PasswordResetTTL,
+			MaxPerEmail: cfg.PasswordResetMaxPerEmail,
+			MaxPerIP:    cfg.PasswordResetMaxPerIP,
+			Window:      cfg.PasswordResetWindow,
+		},
+	)
+	go passwordService.Run(context.Background())
+	verificationService := services.NewVerificationService(
+		userRepo,
+		verificationRepo,
+		emailService,
+		cfg.EmailVerificationTTL,
+	)
+
+	keyRing, err := auth.NewKeyRing(
+		repositories.NewMongoSigningKeyRepository(mongoClient, cfg.DBName),
+		auth.KeyRingConfig{
+			Algorithm:        cfg.JWTSigningAlgorithm,
+			RotationInterval: cfg.JWTKeyRotationInterval,
+			PublishAhead:     cfg.JWTKeyPublishAhead,
+			VerifyFor:        cfg.AccessTokenTTL,
+		},
+	)
+	if err != nil {
+		logger.Fatal("Invalid signing key configuration: %v", err)
+	}
+	if err := keyRing.Rotate(context.Background(), time.Now()); err != nil {
+		logger.Fatal("Failed to load signing keys: %v", err)
+	}
+	go keyRing.Run(context.Background(), time.Minute)
+
+	twoFactorService := services.NewTwoFactorService(userRepo, cfg.TwoFactorIssuer)
+	loginGuard := services.NewLoginGuard(
+		loginAttempts,
+		userRepo,
+		publisher,
+		services.LoginGuardConfig{
+			BackoffAfter:       cfg.LoginBackoffAfter,
+			IPBackoffAfter:     cfg.LoginIPBackoffAfter,
+			BackoffBase:        cfg.LoginBackoffBase,
+			MaxBackoff:         cfg.LoginMaxBackoff,
+			MaxAccountFailures: cfg.LoginMaxFailures,
+			MaxIPFailures:      cfg.LoginMaxIPFailures,
+			LockoutDuration:    cfg.LoginLockoutDuration,
+			FailureWindow:      cfg.LoginFailureWindow,
+		},
+	)
+	authService := auth.NewService(
+		keyRing,
+		userService,
+		twoFactorService,
+		loginGuard,
+		sessionRepo,
+		repositories.NewRedisLoginChallengeRepository(redisClient),
+		auth.Config{
+			AccessTTL:       cfg.AccessTokenTTL,
+			RefreshTTL:      cfg.RefreshTokenTTL,
+			ChallengeTTL:    cfg.LoginChallengeTTL,
+			TwoFactorPolicy: domain.NewTwoFactorPolicy(cfg.TwoFactorRequiredRoles...),
+		},
+	)
+
+	roleService := services.NewRoleService(userRepo)
+	if cfg.AdminEmail != "" {
+		if admin, err := userRepo.FindByEmail(context.Background(), cfg.AdminEmail); err != nil {
+			logger.Error("Failed to find admin %s: %v", cfg.AdminEmail, err)
+		} else if _, err := roleService.AssignRole(context.Background(), admin.ID, domain.RoleAdmin); err != nil {
+			logger.Error("Failed to make %s admin: %v", cfg.AdminEmail, err)
+		}
+	}
+
+	privacyService := services.NewPrivacyService(
+		userRepo,
+		sessionRepo,
+		resetRepo,
+		verificationRepo,
+		repositories.NewMongoErasureRepository(mongoClient, cfg.DBName),
+		publisher,
+		infrastructure_nats.NewDataCollector(nc, cfg.DataServices),
+		services.PrivacyConfig{
+			DataServices:  cfg.DataServices,
+			ExportTimeout: cfg.DataExportTimeout,
+			RetryAfter:    cfg.ErasureRetryInterval,
+		},
+	)
+	go privacyService.Run(context.Background(), cfg.ErasureRetryInterval)
 
-	eventHandler := handlers.NewEventHandler(emailService)
+	eventHandler := handlers.NewEventHandler(emailService, userRepo, verificationService, privacyService, cfg.CartReminderCooldown)
 	subscriber := infrastructure_nats.NewSubscriber(nc, eventHandler)
 	if err := subscriber.Subscribe(); err != nil {
 		logger.Fatal("Failed to subscribe to NATS: %v", err)
 	}
 
 	logger.Info("Starting gRPC server on port %d", cfg.GRPCPort)
-	if err := grpc.StartGRPCServer(cfg.GRPCPort, userService); err != nil {
+	if err := grpc.StartGRPCServer(cfg.GRPCPort, cfg.JWKSPort, userService, passwordService, verificationService, roleService, twoFactorService, loginGuard, services.NewProfileService(userRepo), privacyService, authService); err != nil {
 		logger.Fatal("Failed to start gRPC server: %v", err)
 	}
 }
@@ -1,30 +1,86 @@
 package handlers
 
 import (
-	//"context"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	"user-service/internal/core/domain"
 	"user-service/internal/core/ports"
+	"user-service/internal/core/services"
 )
 
 type EventHandler struct {
 	emailService ports.EmailService
+	repo         domain.UserRepository
+	verification services.VerificationService
+	privacy      services.PrivacyService
+	// cartReminderCooldown is the least time between two abandoned cart
+	// reminders to the same user.
+	cartReminderCooldown time.Duration
 }
 
-func NewEventHandler(emailService ports.EmailService) domain.EventHandler {
+func NewEventHandler(emailService ports.EmailService, repo domain.UserRepository, verification services.VerificationService, privacy services.PrivacyService, cartReminderCooldown time.Duration) domain.EventHandler {
 	return &EventHandler{
-		emailService: emailService,
+		emailService:         emailService,
+		repo:                 repo,
+		verification:         verification,
+		privacy:              privacy,
+		cartReminderCooldown: cartReminderCooldown,
 	}
 }
 
-func (h *EventHandler) HandleUserCreated(user *domain.User) error {
-	return h.emailService.SendWelcomeEmail(user.Email, user.Name)
+func (h *EventHandler) HandleUserCreated(event *domain.UserEvent) error {
+	// A failed welcome email must not keep the user from verifying, so
+	// both are sent and their errors reported together.
+	var errs []error
+	if err := h.verification.SendVerification(context.Background(), event.UserID); err != nil {
+		errs = append(errs, fmt.Errorf("verification email for %s: %v", event.UserID, err))
+	}
+	if err := h.emailService.SendWelcomeEmail(event.Email, event.Name); err != nil {
+		errs = append(errs, fmt.Errorf("welcome email for %s: %v", event.UserID, err))
+	}
+	return errors.Join(errs...)
 }
 
-func (h *EventHandler) HandleUserUpdated(user *domain.User) error {
+func (h *EventHandler) HandleUserUpdated(event *domain.UserEvent) error {
 	return nil
 }
 
-func (h *EventHandler) HandleUserDeleted(userID string) error {
-	// Implement if needed
+func (h *EventHandler) HandleUserDeleted(event *domain.UserDeleted) error {
+	return h.privacy.EraseLocalData(context.Background(), event)
+}
+
+func (h *EventHandler) HandleErasureCompleted(event *domain.ErasureCompleted) error {
+	return h.privacy.RecordCompletion(context.Background(), event)
+}
+
+func (h *EventHandler) HandleCartAbandoned(event *domain.CartAbandoned) error {
+	ctx := context.Background()
+	user, err := h.repo.FindByID(ctx, event.UserID)
+	if err != nil {
+		return fmt.Errorf("cart reminder for %s: %v", event.UserID, err)
+	}
+	if user.CartRemindersOptOut || !user.Notifies(domain.ChannelEmail) {
+		return nil
+	}
+
+	// MongoDB keeps milliseconds, so the claim can be matched again below.
+	now := time.Now().Truncate(time.Millisecond)
+	ok, err := h.repo.ClaimCartReminder(ctx, user.ID, now, h.cartReminderCooldown)
+	if err != nil || !ok {
+		return err
+	}
+	if err := h.emailService.SendAbandonedCartEmail(user.Email, user.Name, event); err != nil {
+		if releaseErr := h.repo.ReleaseCartReminder(ctx, user.ID, now); releaseErr != nil {
+			return errors.Join(err, releaseErr)
+		}
+		return err
+	}
 	return nil
 }
+
+func (h *EventHandler) HandleAccountLocked(event *domain.AccountLocked) error {
+	return h.emailService.SendAccountLockedEmail(event.Email, event.Name, event.Until)
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"user-service/internal/core/domain"
+)
+
+type MongoEmailVerificationRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoEmailVerificationRepository(client *mongo.Client, dbName string) *MongoEmailVerificationRepository {
+	r := &MongoEmailVerificationRepository{
+		collection: client.Database(dbName).Collection("email_verifications"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+	})
+	if err != nil {
+		fmt.Printf("Failed to create email verification indexes: %v\n", err)
+	}
+	return r
+}
+
+func (r *MongoEmailVerificationRepository) Create(ctx context.Context, token *domain.EmailVerificationToken) error {
+	if _, err := r.collection.InsertOne(ctx, token); err != nil {
+		return fmt.Errorf("failed to store email verification token: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoEmailVerificationRepository) Consume(ctx context.Context, tokenHash string, now time.Time) (*domain.EmailVerificationToken, error) {
+	filter := bson.M{"_id": tokenHash, "expires_at": bson.M{"$gt": now}}
+
+	var token domain.EmailVerificationToken
+	err := r.collection.FindOneAndDelete(ctx, filter).Decode(&token)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrInvalidVerificationToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume email verification token: %v", err)
+	}
+	return &token, nil
+}
+
+func (r *MongoEmailVerificationRepository) DeleteByUser(ctx context.Context, userID string) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+		return fmt.Errorf("failed to delete email verification tokens: %v", err)
+	}
+	return nil
+}
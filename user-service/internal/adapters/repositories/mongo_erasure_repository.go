@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"user-service/internal/core/domain"
+)
+
+type MongoErasureRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoErasureRepository(client *mongo.Client, dbName string) *MongoErasureRepository {
+	r := &MongoErasureRepository{
+		collection: client.Database(dbName).Collection("erasure_requests"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "completed_at", Value: 1}, {Key: "requested_at", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+	})
+	if err != nil {
+		fmt.Printf("Failed to create erasure request indexes: %v\n", err)
+	}
+	return r
+}
+
+func (r *MongoErasureRepository) Create(ctx context.Context, request *domain.ErasureRequest) error {
+	if _, err := r.collection.InsertOne(ctx, request); err != nil {
+		return fmt.Errorf("failed to store erasure request: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoErasureRepository) Get(ctx context.Context, id string) (*domain.ErasureRequest, error) {
+	var request domain.ErasureRequest
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&request)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrErasureNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find erasure request: %v", err)
+	}
+	return &request, nil
+}
+
+func (r *MongoErasureRepository) RecordCompletion(ctx context.Context, id, service string, at time.Time, errMsg string) (*domain.ErasureRequest, error) {
+	filter := bson.M{"_id": id, "services.name": service}
+	set := bson.M{"services.$.last_error": errMsg}
+	if errMsg == "" {
+		set["services.$.completed_at"] = at
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var request domain.ErasureRequest
+	err := r.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&request)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrErasureNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record erasure completion: %v", err)
+	}
+
+	if request.CompletedAt == nil && len(request.Pending()) == 0 {
+		_, err := r.collection.UpdateOne(ctx,
+			bson.M{"_id": id, "completed_at": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"completed_at": at}},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to complete erasure request: %v", err)
+		}
+		request.CompletedAt = &at
+	}
+	return &request, nil
+}
+
+func (r *MongoErasureRepository) ListPending(ctx context.Context, before time.Time) ([]*domain.ErasureRequest, error) {
+	filter := bson.M{"completed_at": bson.M{"$exists": false}, "requested_at": bson.M{"$lt": before}}
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending erasure requests: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var requests []*domain.ErasureRequest
+	if err := cursor.All(ctx, &requests); err != nil {
+		return nil, fmt.Errorf("failed to decode erasure requests: %v", err)
+	}
+	return requests, nil
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"user-service/internal/core/domain"
+)
+
+type MongoPasswordResetRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoPasswordResetRepository(client *mongo.Client, dbName string) *MongoPasswordResetRepository {
+	r := &MongoPasswordResetRepository{
+		collection: client.Database(dbName).Collection("password_resets"),
+	}
+
+	// Expired tokens are refused anyway; the TTL index only cleans them up.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+	})
+	if err != nil {
+		fmt.Printf("Failed to create password reset indexes: %v\n", err)
+	}
+	return r
+}
+
+func (r *MongoPasswordResetRepository) Create(ctx context.Context, token *domain.PasswordResetToken) error {
+	if _, err := r.collection.InsertOne(ctx, token); err != nil {
+		return fmt.Errorf("failed to store password reset token: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoPasswordResetRepository) Consume(ctx context.Context, tokenHash string, now time.Time) (*domain.PasswordResetToken, error) {
+	filter := bson.M{"_id": tokenHash, "expires_at": bson.M{"$gt": now}}
+
+	var token domain.PasswordResetToken
+	err := r.collection.FindOneAndDelete(ctx, filter).Decode(&token)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrInvalidResetToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume password reset token: %v", err)
+	}
+	return &token, nil
+}
+
+func (r *MongoPasswordResetRepository) DeleteByUser(ctx context.Context, userID string) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+		return fmt.Errorf("failed to delete password reset tokens: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"user-service/internal/core/domain"
+)
+
+// MongoSigningKeyRepository shares the token signing keys between replicas.
+// The collection holds private keys and must not be readable by other
+// services.
+type MongoSigningKeyRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoSigningKeyRepository(client *mongo.Client, dbName string) *MongoSigningKeyRepository {
+	return &MongoSigningKeyRepository{
+		collection: client.Database(dbName).Collection("signing_keys"),
+	}
+}
+
+func (r *MongoSigningKeyRepository) List(ctx context.Context) ([]*domain.SigningKey, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "not_before", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var keys []*domain.SigningKey
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, fmt.Errorf("failed to decode signing keys: %v", err)
+	}
+	return keys, nil
+}
+
+func (r *MongoSigningKeyRepository) Create(ctx context.Context, key *domain.SigningKey) error {
+	_, err := r.collection.InsertOne(ctx, key)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrSigningKeyExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store signing key: %v", err)
+	}
+	return nil
+}
+
+func (r *MongoSigningKeyRepository) Delete(ctx context.Context, id string) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return fmt.Errorf("failed to delete signing key: %v", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -59,7 +60,7 @@ func (r *MongoUserRepository) FindByID(ctx context.Context, id string) (*domain.
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			fmt.Printf("No user found with ID '%s'\n", id)
-			return nil, errors.New("user not found")
+			return nil, domain.ErrUserNotFound
 		}
 		fmt.Printf("Error finding user: %v\n", err)
 		return nil, err
@@ -78,7 +79,7 @@ func (r *MongoUserRepository) FindByEmail(ctx context.Context, email string) (*d
 	err := collection.FindOne(ctx, filter).Decode(&user)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("user not found")
+			return nil, domain.ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -119,12 +120,16 @@ func (r *MongoUserRepository) Update(ctx context.Context, user *domain.User) err
 		fmt.Printf("Email '%s' is available for use\n", user.Email)
 	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"email": user.Email,
-			"name":  user.Name,
-		},
+	set := bson.M{
+		"email": user.Email,
+		"name":  user.Name,
+	}
+	// A new address has to be verified again.
+	if existingUser.Email != user.Email {
+		set["email_verified"] = false
+		user.EmailVerified = false
 	}
+	update := bson.M{"$set": set}
 
 	filter := bson.M{"_id": existingUser.ID}
 
@@ -142,7 +147,7 @@ func (r *MongoUserRepository) Update(ctx context.Context, user *domain.User) err
 
 	if result.MatchedCount == 0 {
 		fmt.Printf("No user found to update with ID: %s\n", existingUser.ID)
-		return errors.New("user not found")
+		return domain.ErrUserNotFound
 	}
 
 	if result.ModifiedCount == 0 {
@@ -220,3 +225,185 @@ func (r *MongoUserRepository) List(ctx context.Context, page, pageSize int) ([]*
 
 	return users, total, nil
 }
+
+func (r *MongoUserRepository) SetCartRemindersOptOut(ctx context.Context, id string, optOut bool) error {
+	collection := r.client.Database(r.database).Collection(r.collection)
+
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"cart_reminders_opt_out": optOut}})
+	if err != nil {
+		return fmt.Errorf("failed to update cart reminder preference: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *MongoUserRepository) SetCartUpdateEmailsOptOut(ctx context.Context, id string, optOut bool) error {
+	collection := r.client.Database(r.database).Collection(r.collection)
+
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"cart_update_emails_opt_out": optOut}})
+	if err != nil {
+		return fmt.Errorf("failed to update cart email preference: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *MongoUserRepository) UpdatePassword(ctx context.Context, id string, hashedPassword string, revokeSessionsAt time.Time) error {
+	collection := r.client.Database(r.database).Collection(r.collection)
+
+	update := bson.M{"$set": bson.M{"password": hashedPassword, "sessions_revoked_at": revokeSessionsAt}}
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *MongoUserRepository) MarkEmailVerified(ctx context.Context, id string, email string) error {
+	collection := r.client.Database(r.database).Collection(r.collection)
+
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": id, "email": email}, bson.M{"$set": bson.M{"email_verified": true}})
+	if err != nil {
+		return fmt.Errorf("failed to verify email: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *MongoUserRepository) AddRoles(ctx context.Context, id string, roles ...string) error {
+	collection := r.client.Database(r.database).Collection(r.collection)
+
+	update := bson.M{"$addToSet": bson.M{"roles": bson.M{"$each": roles}}}
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return fmt.Errorf("failed to add roles: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *MongoUserRepository) RemoveRole(ctx context.Context, id string, role string) error {
+	collection := r.client.Database(r.database).Collection(r.collection)
+
+	// Matching only users with a second role keeps the check and the
+	// removal atomic.
+	filter := bson.M{"_id": id, "roles": role, "roles.1": bson.M{"$exists": true}}
+	result, err := collection.UpdateOne(ctx, filter, bson.M{"$pull": bson.M{"roles": role}})
+	if err != nil {
+		return fmt.Errorf("failed to remove role: %v", err)
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	count, err := collection.CountDocuments(ctx, bson.M{"_id": id, "roles": role})
+	if err != nil {
+		return fmt.Errorf("failed to remove role: %v", err)
+	}
+	if count > 0 {
+		return domain.ErrLastRole
+	}
+	return nil
+}
+
+func (r *MongoUserRepository) SetTwoFactor(ctx context.Context, id string, twoFactor *domain.TwoFactor) error {
+	collection := r.client.Database(r.database).Collection(r.collection)
+
+	update := bson.M{"$set": bson.M{"two_factor": twoFactor}}
+	if twoFactor == nil {
+		update = bson.M{"$unset": bson.M{"two_factor": ""}}
+	}
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return fmt.Errorf("failed to set two-factor authentication: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *MongoUserRepository) UseTOTPStep(ctx context.Context, id string, step int64) (bool, error) {
+	collection := r.client.Database(r.database).Collection(r.collection)
+
+	// Two logins with the same code race here; only one update matches.
+	filter := bson.M{"_id": id, "two_factor.last_used_step": bson.M{"$lt": step}}
+	result, err := collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"two_factor.last_used_step": step}})
+	if err != nil {
+		return false, fmt.Errorf("failed to record two-factor code: %v", err)
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *MongoUserRepository) UseRecoveryCode(ctx context.Context, id string, codeHash string) (bool, error) {
+	collection := r.client.Database(r.database).Collection(r.collection)
+
+	filter := bson.M{"_id": id, "two_factor.recovery_code_hashes": codeHash}
+	update := bson.M{"$pull": bson.M{"two_factor.recovery_code_hashes": codeHash}}
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %v", err)
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *MongoUserRepository) UpdateProfile(ctx context.Context, id string, profile *domain.Profile, consent *domain.ConsentRecord) error {
+	collection := r.client.Database(r.database).Collection(r.collection)
+
+	// The consent record goes in with the profile that changed it.
+	update := bson.M{"$set": bson.M{"profile": profile}}
+	if consent != nil {
+		update["$push"] = bson.M{"consent_history": consent}
+	}
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update profile: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *MongoUserRepository) ClaimCartReminder(ctx context.Context, id string, now time.Time, cooldown time.Duration) (bool, error) {
+	collection := r.client.Database(r.database).Collection(r.collection)
+
+	// Checking and recording in one update keeps two reminders that arrive at
+	// the same time from both being sent.
+	filter := bson.M{
+		"_id":                    id,
+		"cart_reminders_opt_out": bson.M{"$ne": true},
+		"$or": bson.A{
+			bson.M{"last_cart_reminder_at": bson.M{"$exists": false}},
+			bson.M{"last_cart_reminder_at": bson.M{"$lte": now.Add(-cooldown)}},
+		},
+	}
+	result, err := collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"last_cart_reminder_at": now}})
+	if err != nil {
+		return false, fmt.Errorf("failed to record cart reminder: %v", err)
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *MongoUserRepository) ReleaseCartReminder(ctx context.Context, id string, claimedAt time.Time) error {
+	collection := r.client.Database(r.database).Collection(r.collection)
+
+	// Only the claim this caller made is taken back; a later one stays.
+	filter := bson.M{"_id": id, "last_cart_reminder_at": claimedAt}
+	_, err := collection.UpdateOne(ctx, filter, bson.M{"$unset": bson.M{"last_cart_reminder_at": ""}})
+	if err != nil {
+		return fmt.Errorf("failed to release cart reminder: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisLoginAttemptRepository keeps failure counts and blocks in Redis, so
+// that every replica throttles the same logins. Both expire on their own.
+type RedisLoginAttemptRepository struct {
+	client *redis.Client
+}
+
+func NewRedisLoginAttemptRepository(client *redis.Client) *RedisLoginAttemptRepository {
+	return &RedisLoginAttemptRepository{client: client}
+}
+
+func loginFailuresKey(key string) string { return "login_failures:" + key }
+
+func loginBlockedKey(key string) string { return "login_blocked:" + key }
+
+func (r *RedisLoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	var failures *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		failures = pipe.Incr(ctx, loginFailuresKey(key))
+		pipe.Expire(ctx, loginFailuresKey(key), window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(failures.Val()), nil
+}
+
+func (r *RedisLoginAttemptRepository) ResetFailures(ctx context.Context, key string) error {
+	return r.client.Del(ctx, loginFailuresKey(key)).Err()
+}
+
+func (r *RedisLoginAttemptRepository) Block(ctx context.Context, key string, d time.Duration) error {
+	return r.client.Set(ctx, loginBlockedKey(key), 1, d).Err()
+}
+
+func (r *RedisLoginAttemptRepository) BlockedFor(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.client.PTTL(ctx, loginBlockedKey(key)).Result()
+	if err != nil {
+		return 0, err
+	}
+	// PTTL is negative for keys that do not exist.
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (r *RedisLoginAttemptRepository) Unblock(ctx context.Context, key string) error {
+	return r.client.Del(ctx, loginBlockedKey(key)).Err()
+}
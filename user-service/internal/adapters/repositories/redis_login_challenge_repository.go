@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"user-service/internal/core/domain"
+)
+
+// RedisLoginChallengeRepository keeps each login challenge in a hash next to
+// its count of wrong codes, so both expire together.
+type RedisLoginChallengeRepository struct {
+	client *redis.Client
+}
+
+func NewRedisLoginChallengeRepository(client *redis.Client) *RedisLoginChallengeRepository {
+	return &RedisLoginChallengeRepository{client: client}
+}
+
+func loginChallengeKey(hash string) string { return "login_challenge:" + hash }
+
+func (r *RedisLoginChallengeRepository) Create(ctx context.Context, tokenHash string, challenge *domain.LoginChallenge, ttl time.Duration) error {
+	key := loginChallengeKey(tokenHash)
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key,
+			"user_id", challenge.UserID,
+			"email", challenge.Email,
+			"user_agent", challenge.UserAgent,
+			"ip_address", challenge.IPAddress,
+			"failures", 0,
+		)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+func (r *RedisLoginChallengeRepository) Get(ctx context.Context, tokenHash string) (*domain.LoginChallenge, error) {
+	fields, err := r.client.HGetAll(ctx, loginChallengeKey(tokenHash)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if fields["user_id"] == "" {
+		return nil, domain.ErrInvalidLoginChallenge
+	}
+	return &domain.LoginChallenge{
+		UserID:    fields["user_id"],
+		Email:     fields["email"],
+		UserAgent: fields["user_agent"],
+		IPAddress: fields["ip_address"],
+	}, nil
+}
+
+func (r *RedisLoginChallengeRepository) RecordFailure(ctx context.Context, tokenHash string) (int, error) {
+	key := loginChallengeKey(tokenHash)
+	var failures *redis.IntCmd
+	err := r.client.Watch(ctx, func(tx *redis.Tx) error {
+		// Incrementing an expired challenge would recreate it without a TTL.
+		exists, err := tx.Exists(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if exists == 0 {
+			return domain.ErrInvalidLoginChallenge
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			failures = pipe.HIncrBy(ctx, key, "failures", 1)
+			return nil
+		})
+		return err
+	}, key)
+
+	// Codes guessed in parallel for one challenge end it.
+	if err == redis.TxFailedErr {
+		return 0, domain.ErrInvalidLoginChallenge
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int(failures.Val()), nil
+}
+
+func (r *RedisLoginChallengeRepository) Delete(ctx context.Context, tokenHash string) error {
+	deleted, err := r.client.Del(ctx, loginChallengeKey(tokenHash)).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return domain.ErrInvalidLoginChallenge
+	}
+	return nil
+}
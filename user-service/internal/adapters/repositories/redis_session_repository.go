@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"user-service/internal/core/domain"
+)
+
+// RedisSessionRepository keeps sessions in Redis so that every replica sees
+// the same sessions and revocations. Keys expire with their session.
+type RedisSessionRepository struct {
+	client *redis.Client
+}
+
+func NewRedisSessionRepository(client *redis.Client) *RedisSessionRepository {
+	return &RedisSessionRepository{client: client}
+}
+
+func sessionKey(id string) string { return "session:" + id }
+
+func userSessionsKey(userID string) string { return "user_sessions:" + userID }
+
+// usedRefreshKey marks a refresh token hash that was rotated away, which is
+// how reuse is told apart from a token that never existed.
+func usedRefreshKey(hash string) string { return "refresh_used:" + hash }
+
+func (r *RedisSessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(session.ExpiresAt)
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, sessionKey(session.ID), data, ttl)
+		pipe.SAdd(ctx, userSessionsKey(session.UserID), session.ID)
+		// Sessions all last equally long, so the newest one expires last.
+		pipe.Expire(ctx, userSessionsKey(session.UserID), ttl)
+		return nil
+	})
+	return err
+}
+
+func (r *RedisSessionRepository) Get(ctx context.Context, id string) (*domain.Session, error) {
+	return getSession(ctx, r.client, id)
+}
+
+func getSession(ctx context.Context, client redis.Cmdable, id string) (*domain.Session, error) {
+	data, err := client.Get(ctx, sessionKey(id)).Bytes()
+	if err == redis.Nil {
+		return nil, domain.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session domain.Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *RedisSessionRepository) Rotate(ctx context.Context, id, oldHash, newHash string, usedAt time.Time, userAgent, ipAddress string) (*domain.Session, error) {
+	var rotated *domain.Session
+	err := r.client.Watch(ctx, func(tx *redis.Tx) error {
+		session, err := getSession(ctx, tx, id)
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			return domain.ErrInvalidRefreshToken
+		}
+		if err != nil {
+			return err
+		}
+
+		if session.RefreshTokenHash != oldHash {
+			used, err := tx.Exists(ctx, usedRefreshKey(oldHash)).Result()
+			if err != nil {
+				return err
+			}
+			if used == 0 {
+				return domain.ErrInvalidRefreshToken
+			}
+			if _, err := deleteSessions(ctx, tx, session.UserID, id); err != nil {
+				return err
+			}
+			return domain.ErrRefreshTokenReused
+		}
+
+		session.RefreshTokenHash = newHash
+		session.LastUsedAt = usedAt
+		session.UserAgent = userAgent
+		session.IPAddress = ipAddress
+		data, err := json.Marshal(session)
+		if err != nil {
+			return err
+		}
+		ttl := time.Until(session.ExpiresAt)
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, sessionKey(id), data, ttl)
+			pipe.Set(ctx, usedRefreshKey(oldHash), id, ttl)
+			return nil
+		})
+		rotated = session
+		return err
+	}, sessionKey(id))
+
+	// Another refresh with the same token won the race.
+	if err == redis.TxFailedErr {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rotated, nil
+}
+
+func (r *RedisSessionRepository) ListByUser(ctx context.Context, userID string) ([]*domain.Session, error) {
+	ids, err := r.client.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*domain.Session, 0, len(ids))
+	var expired []interface{}
+	for _, id := range ids {
+		session, err := r.Get(ctx, id)
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			expired = append(expired, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if len(expired) > 0 {
+		r.client.SRem(ctx, userSessionsKey(userID), expired...)
+	}
+	return sessions, nil
+}
+
+func (r *RedisSessionRepository) Delete(ctx context.Context, userID, id string) error {
+	session, err := r.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return domain.ErrSessionNotFound
+	}
+	_, err = deleteSessions(ctx, r.client, userID, id)
+	return err
+}
+
+func (r *RedisSessionRepository) DeleteByUser(ctx context.Context, userID, keepID string) (int, error) {
+	ids, err := r.client.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	var revoke []string
+	for _, id := range ids {
+		if id != keepID {
+			revoke = append(revoke, id)
+		}
+	}
+	if len(revoke) == 0 {
+		return 0, nil
+	}
+	return deleteSessions(ctx, r.client, userID, revoke...)
+}
+
+// deleteSessions removes the sessions and returns how many still existed.
+func deleteSessions(ctx context.Context, client redis.Cmdable, userID string, ids ...string) (int, error) {
+	keys := make([]string, len(ids))
+	members := make([]interface{}, len(ids))
+	for i, id := range ids {
+		keys[i] = sessionKey(id)
+		members[i] = id
+	}
+
+	var deleted *redis.IntCmd
+	_, err := client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		deleted = pipe.Del(ctx, keys...)
+		pipe.SRem(ctx, userSessionsKey(userID), members...)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(deleted.Val()), nil
+}
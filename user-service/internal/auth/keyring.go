@@ -0,0 +1,300 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"user-service/internal/core/domain"
+)
+
+// Algorithms access tokens can be signed with.
+const (
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmRS256 = "RS256"
+)
+
+var ErrUnknownSigningKey = errors.New("unknown signing key")
+
+type KeyRingConfig struct {
+	// Algorithm is used for new keys. Existing keys keep theirs, so a
+	// change takes effect with the next rotation.
+	Algorithm string
+	// RotationInterval is how long each key signs.
+	RotationInterval time.Duration
+	// PublishAhead is how long a key is in the JWKS before it signs. It has
+	// to be longer than verifiers cache the JWKS.
+	PublishAhead time.Duration
+	// VerifyFor is how long a key stays in the JWKS after it stopped
+	// signing. It has to cover the access token lifetime.
+	VerifyFor time.Duration
+}
+
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	public    crypto.PublicKey
+	notBefore time.Time
+}
+
+// KeyRing holds the keys access tokens are signed and verified with. The
+// keys live in a SigningKeyRepository so that all replicas sign with the same
+// key and publish the same JWKS; Rotate creates new keys on schedule and
+// drops retired ones.
+type KeyRing struct {
+	repo   domain.SigningKeyRepository
+	config KeyRingConfig
+
+	mu sync.RWMutex
+	// keys is sorted by notBefore, oldest first.
+	keys []*signingKey
+}
+
+func NewKeyRing(repo domain.SigningKeyRepository, config KeyRingConfig) (*KeyRing, error) {
+	switch config.Algorithm {
+	case AlgorithmEdDSA, AlgorithmRS256:
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", config.Algorithm)
+	}
+	return &KeyRing{repo: repo, config: config}, nil
+}
+
+// Run calls Rotate every interval until ctx is done.
+func (k *KeyRing) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := k.Rotate(ctx, now); err != nil {
+				log.Printf("Failed to rotate signing keys: %v", err)
+			}
+		}
+	}
+}
+
+// Rotate loads the stored keys, creates the next key once it is due to be
+// published and deletes keys that no token can still be signed with.
+func (k *KeyRing) Rotate(ctx context.Context, now time.Time) error {
+	stored, err := k.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	if notBefore, due := k.nextKeyDue(stored, now); due {
+		key, err := newStoredKey(k.config.Algorithm, notBefore, now)
+		if err != nil {
+			return err
+		}
+		// Replicas derive the same ID for the same slot, so only one of
+		// them creates the key.
+		if err := k.repo.Create(ctx, key); err != nil && !errors.Is(err, domain.ErrSigningKeyExists) {
+			return err
+		}
+		if stored, err = k.repo.List(ctx); err != nil {
+			return err
+		}
+	}
+
+	keys := make([]*signingKey, 0, len(stored))
+	for i, key := range stored {
+		if i+1 < len(stored) && !now.Before(stored[i+1].NotBefore.Add(k.config.VerifyFor)) {
+			if err := k.repo.Delete(ctx, key.ID); err != nil {
+				log.Printf("Failed to delete retired signing key %s: %v", key.ID, err)
+			}
+			continue
+		}
+		parsed, err := parseStoredKey(key)
+		if err != nil {
+			return fmt.Errorf("signing key %s: %w", key.ID, err)
+		}
+		keys = append(keys, parsed)
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.mu.Unlock()
+	return nil
+}
+
+// nextKeyDue returns when the next key starts signing and whether it has
+// to be created now.
+func (k *KeyRing) nextKeyDue(stored []*domain.SigningKey, now time.Time) (time.Time, bool) {
+	if len(stored) == 0 {
+		// Nothing can be verified yet, so there is nothing to wait for.
+		return now.Truncate(time.Second), true
+	}
+
+	next := stored[len(stored)-1].NotBefore.Add(k.config.RotationInterval)
+	if now.Before(next.Add(-k.config.PublishAhead)) {
+		return time.Time{}, false
+	}
+	// After a long outage the slot is in the past; the new key still has
+	// to be published ahead.
+	if next.Before(now) {
+		next = now.Add(k.config.PublishAhead).Truncate(time.Second)
+	}
+	return next, true
+}
+
+func (k *KeyRing) current(now time.Time) (*signingKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	for i := len(k.keys) - 1; i >= 0; i-- {
+		if !k.keys[i].notBefore.After(now) {
+			return k.keys[i], nil
+		}
+	}
+	return nil, errors.New("no signing key is active")
+}
+
+// Sign signs the claims with the current key and names it in the kid header.
+func (k *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	key, err := k.current(time.Now())
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.private)
+}
+
+// Keyfunc returns the public key named by the token's kid header.
+func (k *KeyRing) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for _, key := range k.keys {
+		if key.id == kid {
+			if token.Method.Alg() != key.method.Alg() {
+				return nil, fmt.Errorf("key %s is not for %s", kid, token.Method.Alg())
+			}
+			return key.public, nil
+		}
+	}
+	return nil, ErrUnknownSigningKey
+}
+
+// Methods lists the algorithms Keyfunc can return keys for.
+func Methods() []string {
+	return []string{AlgorithmEdDSA, AlgorithmRS256}
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// ServeHTTP serves the public keys as a JWKS (RFC 7517), including keys that
+// do not sign yet or any more.
+func (k *KeyRing) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	k.mu.RLock()
+	keys := make([]jwk, 0, len(k.keys))
+	for _, key := range k.keys {
+		entry := jwk{Use: "sig", Alg: key.method.Alg(), Kid: key.id}
+		switch public := key.public.(type) {
+		case ed25519.PublicKey:
+			entry.Kty = "OKP"
+			entry.Crv = "Ed25519"
+			entry.X = base64.RawURLEncoding.EncodeToString(public)
+		case *rsa.PublicKey:
+			entry.Kty = "RSA"
+			entry.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			entry.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		}
+		keys = append(keys, entry)
+	}
+	k.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(map[string][]jwk{"keys": keys})
+}
+
+func generateKey(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case AlgorithmEdDSA:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		return private, err
+	case AlgorithmRS256:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+}
+
+func newStoredKey(algorithm string, notBefore, now time.Time) (*domain.SigningKey, error) {
+	private, err := generateKey(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.SigningKey{
+		ID:         strings.ToLower(algorithm) + "-" + notBefore.UTC().Format("20060102T150405Z"),
+		Algorithm:  algorithm,
+		PrivateKey: der,
+		NotBefore:  notBefore,
+		CreatedAt:  now,
+	}, nil
+}
+
+func parseStoredKey(key *domain.SigningKey) (*signingKey, error) {
+	parsed, err := x509.ParsePKCS8PrivateKey(key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("not a signing key")
+	}
+
+	var method jwt.SigningMethod
+	switch key.Algorithm {
+	case AlgorithmEdDSA:
+		_, ok = private.(ed25519.PrivateKey)
+		method = jwt.SigningMethodEdDSA
+	case AlgorithmRS256:
+		_, ok = private.(*rsa.PrivateKey)
+		method = jwt.SigningMethodRS256
+	}
+	if method == nil || !ok {
+		return nil, fmt.Errorf("key does not match algorithm %q", key.Algorithm)
+	}
+
+	return &signingKey{
+		id:        key.ID,
+		method:    method,
+		private:   private,
+		public:    private.Public(),
+		notBefore: key.NotBefore,
+	}, nil
+}
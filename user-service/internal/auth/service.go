@@ -2,30 +2,58 @@ package auth
 
 import (
 	"context"
-	"sync"
-	//"errors"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"user-service/internal/core/domain"
 	"user-service/internal/core/services"
+	"user-service/internal/core/utils"
 	pb "user-service/proto/auth"
 )
 
+// maxChallengeFailures is how many wrong codes end a login challenge.
+const maxChallengeFailures = 5
+
+type Config struct {
+	// AccessTTL is the lifetime of access tokens and RefreshTTL the one of
+	// sessions.
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	// ChallengeTTL is how long a two-factor code can be entered after the
+	// password.
+	ChallengeTTL    time.Duration
+	TwoFactorPolicy domain.TwoFactorPolicy
+}
+
 type Service struct {
 	pb.UnimplementedAuthServiceServer
-	jwtSecret   []byte
+	keys        *KeyRing
 	userService services.UserService
-	blacklist   map[string]time.Time
-	mu          sync.RWMutex
+	twoFactor   services.TwoFactorService
+	loginGuard  services.LoginGuard
+	sessions    domain.SessionRepository
+	challenges  domain.LoginChallengeRepository
+	config      Config
 }
 
-func NewService(jwtSecret string, userService services.UserService) *Service {
+func NewService(keys *KeyRing, userService services.UserService, twoFactor services.TwoFactorService, loginGuard services.LoginGuard, sessions domain.SessionRepository, challenges domain.LoginChallengeRepository, config Config) *Service {
 	return &Service{
-		jwtSecret:   []byte(jwtSecret),
+		keys:        keys,
 		userService: userService,
-		blacklist:   make(map[string]time.Time),
+		twoFactor:   twoFactor,
+		loginGuard:  loginGuard,
+		sessions:    sessions,
+		challenges:  challenges,
+		config:      config,
 	}
 }
 
@@ -34,46 +62,255 @@ func (s *Service) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginRes
 		return nil, status.Error(codes.InvalidArgument, "email and password are required")
 	}
 
+	var blocked *domain.LoginBlockedError
+	if err := s.loginGuard.Check(ctx, req.Email, req.IpAddress); errors.As(err, &blocked) {
+		return nil, status.Error(codes.ResourceExhausted, blocked.Error())
+	} else if err != nil {
+		return nil, status.Error(codes.Internal, "failed to check login attempts")
+	}
+
+	// Unknown emails count as failures just like wrong passwords.
 	user, err := s.userService.GetByEmail(ctx, req.Email)
 	if err != nil {
+		user = nil
+	}
+	if user == nil || !s.userService.VerifyPassword(user.Password, req.Password) {
+		if err := s.loginGuard.RecordFailure(ctx, req.Email, user, req.IpAddress, req.UserAgent); err != nil {
+			log.Printf("Failed to record failed login: %v", err)
+		}
 		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
 	}
 
-	if !s.userService.VerifyPassword(user.Password, req.Password) {
-		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+	// The account's failures are only forgotten once every login step
+	// passed, so a password alone cannot reset the count of wrong codes.
+	if user.TwoFactorEnabled() {
+		challengeToken, err := utils.NewToken()
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to generate token")
+		}
+		challenge := &domain.LoginChallenge{
+			UserID:    user.ID,
+			Email:     req.Email,
+			UserAgent: req.UserAgent,
+			IPAddress: req.IpAddress,
+		}
+		if err := s.challenges.Create(ctx, utils.HashToken(challengeToken), challenge, s.config.ChallengeTTL); err != nil {
+			return nil, status.Error(codes.Internal, "failed to create login challenge")
+		}
+		return &pb.LoginResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challengeToken,
+		}, nil
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
-	})
+	s.recordSuccess(ctx, req.Email)
+	return s.startSession(ctx, user, req.UserAgent, req.IpAddress)
+}
+
+func (s *Service) recordSuccess(ctx context.Context, email string) {
+	if err := s.loginGuard.RecordSuccess(ctx, email); err != nil {
+		log.Printf("Failed to reset failed logins: %v", err)
+	}
+}
+
+// VerifyTwoFactor completes a login that Login answered with a challenge.
+func (s *Service) VerifyTwoFactor(ctx context.Context, req *pb.VerifyTwoFactorRequest) (*pb.LoginResponse, error) {
+	if req.ChallengeToken == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "challenge token and code are required")
+	}
+
+	hash := utils.HashToken(req.ChallengeToken)
+	challenge, err := s.challenges.Get(ctx, hash)
+	if errors.Is(err, domain.ErrInvalidLoginChallenge) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to load login challenge")
+	}
+	user, err := s.userService.GetByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, domain.ErrInvalidLoginChallenge.Error())
+	}
+
+	userAgent, ipAddress := req.UserAgent, req.IpAddress
+	if userAgent == "" && ipAddress == "" {
+		userAgent, ipAddress = challenge.UserAgent, challenge.IPAddress
+	}
+
+	// Wrong codes are failed logins, so each new challenge only gets the
+	// tries the account has left.
+	var blocked *domain.LoginBlockedError
+	if err := s.loginGuard.Check(ctx, challenge.Email, ipAddress); errors.As(err, &blocked) {
+		return nil, status.Error(codes.ResourceExhausted, blocked.Error())
+	} else if err != nil {
+		return nil, status.Error(codes.Internal, "failed to check login attempts")
+	}
+
+	err = s.twoFactor.Verify(ctx, user, req.Code)
+	switch {
+	case errors.Is(err, domain.ErrInvalidTwoFactorCode):
+		if err := s.loginGuard.RecordFailure(ctx, challenge.Email, user, ipAddress, userAgent); err != nil {
+			log.Printf("Failed to record failed login: %v", err)
+		}
+		failures, err := s.challenges.RecordFailure(ctx, hash)
+		if err != nil || failures >= maxChallengeFailures {
+			s.challenges.Delete(ctx, hash)
+			return nil, status.Error(codes.Unauthenticated, "too many invalid codes, log in again")
+		}
+		return nil, status.Error(codes.Unauthenticated, domain.ErrInvalidTwoFactorCode.Error())
+	case errors.Is(err, domain.ErrTwoFactorNotEnrolled):
+		// Two-factor authentication was turned off since the password was
+		// checked; the challenge no longer applies.
+		s.challenges.Delete(ctx, hash)
+		return nil, status.Error(codes.Unauthenticated, domain.ErrInvalidLoginChallenge.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, "failed to verify code")
+	}
+
+	// Only one request can use up the challenge.
+	err = s.challenges.Delete(ctx, hash)
+	if errors.Is(err, domain.ErrInvalidLoginChallenge) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to complete login challenge")
+	}
+
+	s.recordSuccess(ctx, challenge.Email)
+	return s.startSession(ctx, user, userAgent, ipAddress)
+}
+
+// startSession creates a session for a user who passed every login step and
+// issues its tokens.
+func (s *Service) startSession(ctx context.Context, user *domain.User, userAgent, ipAddress string) (*pb.LoginResponse, error) {
+	now := time.Now()
+	session := &domain.Session{
+		ID:         uuid.New().String(),
+		UserID:     user.ID,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.config.RefreshTTL),
+	}
+	refreshToken, err := newRefreshToken(session.ID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to generate token")
+	}
+	session.RefreshTokenHash = utils.HashToken(refreshToken)
+	if err := s.sessions.Create(ctx, session); err != nil {
+		return nil, status.Error(codes.Internal, "failed to create session")
+	}
 
-	tokenString, err := token.SignedString(s.jwtSecret)
+	tokenString, err := s.accessToken(user, session.ID, now)
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to generate token")
 	}
 
 	return &pb.LoginResponse{
-		Token:  tokenString,
-		UserId: user.ID,
-		Email:  user.Email,
+		Token:                  tokenString,
+		UserId:                 user.ID,
+		Email:                  user.Email,
+		EmailVerified:          user.EmailVerified,
+		RefreshToken:           refreshToken,
+		ExpiresIn:              int64(s.config.AccessTTL.Seconds()),
+		SessionId:              session.ID,
+		TwoFactorSetupRequired: s.config.TwoFactorPolicy.SetupRequired(user),
 	}, nil
 }
 
+// Refresh rotates the refresh token of a session and issues a new access
+// token for it.
+func (s *Service) Refresh(ctx context.Context, req *pb.RefreshRequest) (*pb.RefreshResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "refresh token is required")
+	}
+
+	sessionID, _, ok := strings.Cut(req.RefreshToken, ".")
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, domain.ErrInvalidRefreshToken.Error())
+	}
+	session, err := s.sessions.Get(ctx, sessionID)
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		return nil, status.Error(codes.Unauthenticated, domain.ErrInvalidRefreshToken.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to load session")
+	}
+	user, err := s.userService.GetByID(ctx, session.UserID)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, domain.ErrInvalidRefreshToken.Error())
+	}
+	if revokedByUser(user, session) {
+		s.sessions.Delete(ctx, user.ID, session.ID)
+		return nil, status.Error(codes.Unauthenticated, "session has been revoked")
+	}
+
+	refreshToken, err := newRefreshToken(session.ID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to generate token")
+	}
+	now := time.Now()
+	_, err = s.sessions.Rotate(ctx, session.ID, utils.HashToken(req.RefreshToken), utils.HashToken(refreshToken), now, req.UserAgent, req.IpAddress)
+	switch {
+	case errors.Is(err, domain.ErrRefreshTokenReused):
+		return nil, status.Error(codes.Unauthenticated, "refresh token reuse detected, session has been revoked")
+	case errors.Is(err, domain.ErrInvalidRefreshToken):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, "failed to rotate refresh token")
+	}
+
+	tokenString, err := s.accessToken(user, session.ID, now)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to generate token")
+	}
+
+	return &pb.RefreshResponse{
+		Token:        tokenString,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.config.AccessTTL.Seconds()),
+		SessionId:    session.ID,
+	}, nil
+}
+
+// newRefreshToken starts with the session ID so Refresh can find the session
+// without storing the token.
+func newRefreshToken(sessionID string) (string, error) {
+	secret, err := utils.NewToken()
+	if err != nil {
+		return "", err
+	}
+	return sessionID + "." + secret, nil
+}
+
+func (s *Service) accessToken(user *domain.User, sessionID string, now time.Time) (string, error) {
+	return s.keys.Sign(jwt.MapClaims{
+		"user_id": user.ID,
+		"email":   user.Email,
+		// Only hints for clients; ValidateToken reports the current state.
+		"email_verified": user.EmailVerified,
+		"roles":          user.RoleList(),
+		"permissions":    user.Permissions(s.config.TwoFactorPolicy),
+		"sid":            sessionID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(s.config.AccessTTL).Unix(),
+	})
+}
+
+// revokedByUser reports whether the user revoked all their sessions, e.g. by
+// changing their password, after the session started.
+func revokedByUser(user *domain.User, session *domain.Session) bool {
+	return user.SessionsRevokedAt != nil && !session.CreatedAt.After(*user.SessionsRevokedAt)
+}
+
+// Logout ends the session of the token. Expired tokens can still log out.
 func (s *Service) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
 	if req.Token == "" {
 		return nil, status.Error(codes.InvalidArgument, "token is required")
 	}
 
-	// Parse the token to get expiration time
-	token, err := jwt.Parse(req.Token, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, status.Error(codes.InvalidArgument, "invalid token signing method")
-		}
-		return s.jwtSecret, nil
-	})
-
+	token, err := jwt.Parse(req.Token, s.keys.Keyfunc, jwt.WithValidMethods(Methods()), jwt.WithoutClaimsValidation())
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, "invalid token")
 	}
@@ -82,38 +319,149 @@ func (s *Service) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.Logout
 	if !ok {
 		return nil, status.Error(codes.InvalidArgument, "invalid token claims")
 	}
-
-	// Add token to blacklist with its expiration time
-	exp, ok := claims["exp"].(float64)
-	if !ok {
-		return nil, status.Error(codes.InvalidArgument, "invalid token expiration")
+	userID, _ := claims["user_id"].(string)
+	sessionID, _ := claims["sid"].(string)
+	if userID == "" || sessionID == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid token claims")
 	}
 
-	s.mu.Lock()
-	s.blacklist[req.Token] = time.Unix(int64(exp), 0)
-	s.mu.Unlock()
+	err = s.sessions.Delete(ctx, userID, sessionID)
+	if err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+		return nil, status.Error(codes.Internal, "failed to end session")
+	}
 
 	return &pb.LogoutResponse{
 		Success: true,
 	}, nil
 }
 
-// Helper method to check if a token is blacklisted
-func (s *Service) isTokenBlacklisted(token string) bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	if exp, exists := s.blacklist[token]; exists {
-		if time.Now().After(exp) {
-			// Token has expired, remove from blacklist
-			s.mu.RUnlock()
-			s.mu.Lock()
-			delete(s.blacklist, token)
-			s.mu.Unlock()
-			s.mu.RLock()
-			return false
+// JWKS serves the public keys tokens can be verified with.
+func (s *Service) JWKS() http.Handler {
+	return s.keys
+}
+
+// ValidateToken checks the signature and expiry of a token, that its session
+// was not logged out or revoked and that the user's sessions were not
+// revoked since it was issued. It is the revocation check the gateway runs
+// on every request.
+func (s *Service) ValidateToken(ctx context.Context, req *pb.ValidateTokenRequest) (*pb.ValidateTokenResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	invalid := func(reason string) (*pb.ValidateTokenResponse, error) {
+		return &pb.ValidateTokenResponse{Valid: false, Error: reason}, nil
+	}
+
+	token, err := jwt.Parse(req.Token, s.keys.Keyfunc, jwt.WithValidMethods(Methods()))
+	if err != nil {
+		return invalid("invalid token")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return invalid("invalid token claims")
+	}
+	userID, _ := claims["user_id"].(string)
+	sessionID, _ := claims["sid"].(string)
+	if userID == "" || sessionID == "" {
+		return invalid("invalid token claims")
+	}
+
+	session, err := s.sessions.Get(ctx, sessionID)
+	if errors.Is(err, domain.ErrSessionNotFound) || (err == nil && session.UserID != userID) {
+		return invalid("session has ended")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to load session")
+	}
+
+	user, err := s.userService.GetByID(ctx, userID)
+	if err != nil {
+		return invalid("user not found")
+	}
+	if user.SessionsRevokedAt != nil {
+		// iat has second precision, so a token from the second of the
+		// revocation counts as revoked.
+		iat, _ := claims["iat"].(float64)
+		if int64(iat) <= user.SessionsRevokedAt.Unix() {
+			return invalid("token has been revoked")
+		}
+	}
+
+	return &pb.ValidateTokenResponse{
+		Valid:         true,
+		UserId:        userID,
+		EmailVerified: user.EmailVerified,
+		SessionId:     sessionID,
+		Roles:         user.RoleList(),
+		Permissions:   user.Permissions(s.config.TwoFactorPolicy),
+	}, nil
+}
+
+// ListSessions returns the user's active sessions, most recently used first.
+func (s *Service) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	user, err := s.userService.GetByID(ctx, req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	sessions, err := s.sessions.ListByUser(ctx, req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list sessions")
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+
+	resp := &pb.ListSessionsResponse{}
+	for _, session := range sessions {
+		if revokedByUser(user, session) {
+			continue
 		}
-		return true
+		resp.Sessions = append(resp.Sessions, &pb.Session{
+			SessionId:  session.ID,
+			UserAgent:  session.UserAgent,
+			IpAddress:  session.IPAddress,
+			CreatedAt:  timestamppb.New(session.CreatedAt),
+			LastUsedAt: timestamppb.New(session.LastUsedAt),
+			ExpiresAt:  timestamppb.New(session.ExpiresAt),
+		})
 	}
-	return false
+	return resp, nil
+}
+
+func (s *Service) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	if req.UserId == "" || req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id and session_id are required")
+	}
+
+	err := s.sessions.Delete(ctx, req.UserId, req.SessionId)
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to revoke session")
+	}
+
+	return &pb.RevokeSessionResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *Service) RevokeAllSessions(ctx context.Context, req *pb.RevokeAllSessionsRequest) (*pb.RevokeAllSessionsResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	revoked, err := s.sessions.DeleteByUser(ctx, req.UserId, req.KeepSessionId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to revoke sessions")
+	}
+
+	return &pb.RevokeAllSessionsResponse{
+		Revoked: int32(revoked),
+	}, nil
 }
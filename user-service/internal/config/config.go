@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -16,6 +18,68 @@ type Config struct {
 	SMTPUsername string
 	SMTPPassword string
 	GRPCPort     int
+	// JWKSPort serves the keys tokens are verified with, apart from the
+	// metrics on 9090.
+	JWKSPort int
+	// CartReminderCooldown limits abandoned cart emails per user.
+	CartReminderCooldown time.Duration
+	// PasswordResetTTL is how long a password reset token can be used.
+	// At most PasswordResetMaxPerEmail resets per email and
+	// PasswordResetMaxPerIP per IP address are requested within
+	// PasswordResetWindow.
+	PasswordResetTTL         time.Duration
+	PasswordResetMaxPerEmail int
+	PasswordResetMaxPerIP    int
+	PasswordResetWindow      time.Duration
+	// EmailVerificationTTL is how long an email verification token can be
+	// used.
+	EmailVerificationTTL time.Duration
+	// AccessTokenTTL is the lifetime of access tokens and RefreshTokenTTL
+	// the one of a login session.
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// JWTSigningAlgorithm is EdDSA or RS256. Signing keys are rotated every
+	// JWTKeyRotationInterval and published JWTKeyPublishAhead before use.
+	JWTSigningAlgorithm    string
+	JWTKeyRotationInterval time.Duration
+	JWTKeyPublishAhead     time.Duration
+	// AdminEmail, if set, names a user who is made admin on start, so the
+	// first admin can hand out roles.
+	AdminEmail string
+	// TwoFactorRequiredRoles grant their permissions only to users with
+	// two-factor authentication. TwoFactorIssuer names the account in
+	// authenticator apps.
+	TwoFactorRequiredRoles []string
+	TwoFactorIssuer        string
+	// LoginChallengeTTL is how long the two-factor code can be entered after
+	// the password.
+	LoginChallengeTTL time.Duration
+	// After LoginBackoffAfter failed logins to an account in a row, the next
+	// attempt waits LoginBackoffBase, doubling up to LoginMaxBackoff; IP
+	// addresses back off after LoginIPBackoffAfter. LoginMaxFailures locks the account for LoginLockoutDuration; an IP
+	// address is blocked as long after LoginMaxIPFailures.
+	LoginBackoffAfter    int
+	LoginIPBackoffAfter  int
+	LoginBackoffBase     time.Duration
+	LoginMaxBackoff      time.Duration
+	LoginMaxFailures     int
+	LoginMaxIPFailures   int
+	LoginLockoutDuration time.Duration
+	// LoginFailureWindow is how long failed logins are remembered.
+	LoginFailureWindow time.Duration
+	// UserEventRetention is how long the USERS stream keeps events, which
+	// carry emails and names. Its subjects are not per user, so an erased
+	// user's events cannot be purged and only age out; keep it short. It
+	// only has to outlast consumer downtime, as user.deleted is published
+	// again every ErasureRetryInterval until acknowledged.
+	UserEventRetention time.Duration
+	// DataServices are the other services holding user data. They must
+	// answer data export requests within DataExportTimeout and acknowledge
+	// erasures, which are published again every ErasureRetryInterval until
+	// they do.
+	DataServices         []string
+	DataExportTimeout    time.Duration
+	ErasureRetryInterval time.Duration
 }
 
 func LoadConfig() *Config {
@@ -30,6 +94,40 @@ func LoadConfig() *Config {
 		SMTPUsername: getEnv("SMTP_USERNAME", "placeholer@internet.ru"),
 		SMTPPassword: getEnv("SMTP_PASSWORD", "placeholer"),
 		GRPCPort:     getEnvAsInt("GRPC_PORT", 50053),
+		JWKSPort:     getEnvAsInt("JWKS_PORT", 8081),
+
+		CartReminderCooldown: getEnvAsDuration("CART_REMINDER_COOLDOWN", 72*time.Hour),
+		PasswordResetTTL:     getEnvAsDuration("PASSWORD_RESET_TTL", time.Hour),
+		EmailVerificationTTL: getEnvAsDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+		AccessTokenTTL:       getEnvAsDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL:      getEnvAsDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+		PasswordResetMaxPerEmail: getEnvAsInt("PASSWORD_RESET_MAX_PER_EMAIL", 3),
+		PasswordResetMaxPerIP:    getEnvAsInt("PASSWORD_RESET_MAX_PER_IP", 20),
+		PasswordResetWindow:      getEnvAsDuration("PASSWORD_RESET_WINDOW", time.Hour),
+
+		JWTSigningAlgorithm:    getEnv("JWT_SIGNING_ALGORITHM", "EdDSA"),
+		JWTKeyRotationInterval: getEnvAsDuration("JWT_KEY_ROTATION_INTERVAL", 30*24*time.Hour),
+		JWTKeyPublishAhead:     getEnvAsDuration("JWT_KEY_PUBLISH_AHEAD", time.Hour),
+		AdminEmail:             getEnv("ADMIN_EMAIL", ""),
+
+		TwoFactorRequiredRoles: getEnvAsList("TWO_FACTOR_REQUIRED_ROLES", []string{"admin"}),
+		TwoFactorIssuer:        getEnv("TWO_FACTOR_ISSUER", "Yurt Mart"),
+		LoginChallengeTTL:      getEnvAsDuration("LOGIN_CHALLENGE_TTL", 5*time.Minute),
+
+		LoginBackoffAfter:    getEnvAsInt("LOGIN_BACKOFF_AFTER", 3),
+		LoginIPBackoffAfter:  getEnvAsInt("LOGIN_IP_BACKOFF_AFTER", 10),
+		LoginBackoffBase:     getEnvAsDuration("LOGIN_BACKOFF_BASE", time.Second),
+		LoginMaxBackoff:      getEnvAsDuration("LOGIN_MAX_BACKOFF", 5*time.Minute),
+		LoginMaxFailures:     getEnvAsInt("LOGIN_MAX_FAILURES", 10),
+		LoginMaxIPFailures:   getEnvAsInt("LOGIN_MAX_IP_FAILURES", 50),
+		LoginLockoutDuration: getEnvAsDuration("LOGIN_LOCKOUT_DURATION", 30*time.Minute),
+		LoginFailureWindow:   getEnvAsDuration("LOGIN_FAILURE_WINDOW", time.Hour),
+
+		UserEventRetention:   getEnvAsDuration("USER_EVENT_RETENTION", 24*time.Hour),
+		DataServices:         getEnvAsList("USER_DATA_SERVICES", []string{"shopping-cart-service", "order-service", "review-service", "order-history-service"}),
+		DataExportTimeout:    getEnvAsDuration("DATA_EXPORT_TIMEOUT", 5*time.Second),
+		ErasureRetryInterval: getEnvAsDuration("ERASURE_RETRY_INTERVAL", 10*time.Minute),
 	}
 }
 
@@ -47,3 +145,25 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(getEnv(key, "")); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsList reads a comma-separated list. Set but empty means none.
+func getEnvAsList(key string, defaultValue []string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+type CartItem struct {
+	ProductID string  `json:"product_id"`
+	SKUID     string  `json:"sku_id,omitempty"`
+	Name      string  `json:"name"`
+	Quantity  int32   `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	LineTotal float64 `json:"line_total"`
+}
+
+// CartAbandoned is the payload of cart.abandoned. It carries the user ID
+// only; the email address is looked up here.
+type CartAbandoned struct {
+	UserID         string     `json:"user_id"`
+	Items          []CartItem `json:"items"`
+	Total          float64    `json:"total"`
+	Currency       string     `json:"currency"`
+	LastActivityAt time.Time  `json:"last_activity_at"`
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrInvalidVerificationToken = errors.New("invalid or expired email verification token")
+
+// EmailVerificationToken proves ownership of Email when its token comes
+// back. Only the token's hash is stored.
+type EmailVerificationToken struct {
+	TokenHash string    `bson:"_id"`
+	UserID    string    `bson:"user_id"`
+	Email     string    `bson:"email"`
+	CreatedAt time.Time `bson:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+type EmailVerificationRepository interface {
+	Create(ctx context.Context, token *EmailVerificationToken) error
+	// Consume removes and returns the token with this hash if it has not
+	// expired at now. It returns ErrInvalidVerificationToken otherwise.
+	Consume(ctx context.Context, tokenHash string, now time.Time) (*EmailVerificationToken, error)
+	DeleteByUser(ctx context.Context, userID string) error
+}
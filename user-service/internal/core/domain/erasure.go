@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrErasureNotFound = errors.New("erasure request not found")
+
+// ServiceName is how user-service names itself in erasure acknowledgements
+// and data exports.
+const ServiceName = "user-service"
+
+const (
+	// ErasureCompletedEvent is published by every service once it has
+	// deleted or anonymized its data of a user after UserDeletedEvent.
+	ErasureCompletedEvent = "user.erasure.completed"
+	// UserExportRequestedEvent is a request: every service replies with a
+	// ServiceExport of its data of the user.
+	UserExportRequestedEvent = "user.export.requested"
+)
+
+// UserDeleted is the payload of UserDeletedEvent. Services must handle it
+// more than once without harm, as it is published again until they
+// acknowledge it.
+type UserDeleted struct {
+	UserID      string    `json:"user_id"`
+	ErasureID   string    `json:"erasure_id"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// ErasureCompleted is the payload of ErasureCompletedEvent. A non-empty
+// Error means the service failed and will try again on the next
+// UserDeletedEvent.
+type ErasureCompleted struct {
+	ErasureID   string    `json:"erasure_id"`
+	UserID      string    `json:"user_id"`
+	Service     string    `json:"service"`
+	CompletedAt time.Time `json:"completed_at"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// UserExportRequested is the payload of UserExportRequestedEvent.
+type UserExportRequested struct {
+	UserID string `json:"user_id"`
+}
+
+// ServiceExport is a service's reply to UserExportRequestedEvent.
+type ServiceExport struct {
+	Service string          `json:"service"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// ErasureRequest tracks the erasure of a user's data across services. It is
+// kept after the user is gone as proof the erasure happened, so it holds
+// nothing but the user's ID.
+type ErasureRequest struct {
+	ID          string           `bson:"_id"`
+	UserID      string           `bson:"user_id"`
+	RequestedAt time.Time        `bson:"requested_at"`
+	Services    []ErasureService `bson:"services"`
+	// CompletedAt is set once every service has acknowledged.
+	CompletedAt *time.Time `bson:"completed_at,omitempty"`
+}
+
+// ErasureService is one service's progress on an ErasureRequest.
+type ErasureService struct {
+	Name        string     `bson:"name"`
+	CompletedAt *time.Time `bson:"completed_at,omitempty"`
+	// LastError is the error the service last reported, if any.
+	LastError string `bson:"last_error,omitempty"`
+}
+
+func NewErasureRequest(id, userID string, services []string, now time.Time) *ErasureRequest {
+	request := &ErasureRequest{ID: id, UserID: userID, RequestedAt: now}
+	for _, name := range services {
+		request.Services = append(request.Services, ErasureService{Name: name})
+	}
+	return request
+}
+
+// Pending returns the services that have not acknowledged yet.
+func (r *ErasureRequest) Pending() []string {
+	var pending []string
+	for _, service := range r.Services {
+		if service.CompletedAt == nil {
+			pending = append(pending, service.Name)
+		}
+	}
+	return pending
+}
+
+type ErasureRepository interface {
+	Create(ctx context.Context, request *ErasureRequest) error
+	Get(ctx context.Context, id string) (*ErasureRequest, error)
+	// RecordCompletion marks service done with the request, or records its
+	// error if errMsg is not empty, and returns the updated request. The
+	// request's CompletedAt is set once no service is left pending. It
+	// returns ErrErasureNotFound if there is no such request or service is
+	// not part of it.
+	RecordCompletion(ctx context.Context, id, service string, at time.Time, errMsg string) (*ErasureRequest, error)
+	// ListPending returns the requests not completed that were made
+	// before.
+	ListPending(ctx context.Context, before time.Time) ([]*ErasureRequest, error)
+}
@@ -1,13 +1,48 @@
 package domain
 
+import "time"
+
 const (
 	UserCreatedEvent = "user.created"
 	UserUpdatedEvent = "user.updated"
 	UserDeletedEvent = "user.deleted"
+	// LoginFailedEvent and AccountLockedEvent report failed logins, e.g. for
+	// security monitoring.
+	LoginFailedEvent   = "user.login_failed"
+	AccountLockedEvent = "user.locked"
+
+	// CartAbandonedEvent is published by the shopping cart service.
+	CartAbandonedEvent = "cart.abandoned"
 )
 
+// UserEvent is the payload of UserCreatedEvent and UserUpdatedEvent. It
+// carries what other services may know about a user, so never the password
+// hash or two-factor secrets.
+type UserEvent struct {
+	UserID        string    `json:"user_id"`
+	Email         string    `json:"email"`
+	Name          string    `json:"name"`
+	EmailVerified bool      `json:"email_verified"`
+	Roles         []string  `json:"roles"`
+	At            time.Time `json:"at"`
+}
+
+func NewUserEvent(user *User, at time.Time) *UserEvent {
+	return &UserEvent{
+		UserID:        user.ID,
+		Email:         user.Email,
+		Name:          user.Name,
+		EmailVerified: user.EmailVerified,
+		Roles:         user.RoleList(),
+		At:            at,
+	}
+}
+
 type EventHandler interface {
-	HandleUserCreated(user *User) error
-	HandleUserUpdated(user *User) error
-	HandleUserDeleted(userID string) error
+	HandleUserCreated(event *UserEvent) error
+	HandleUserUpdated(event *UserEvent) error
+	HandleUserDeleted(event *UserDeleted) error
+	HandleErasureCompleted(event *ErasureCompleted) error
+	HandleCartAbandoned(event *CartAbandoned) error
+	HandleAccountLocked(event *AccountLocked) error
 }
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// LoginBlockedError is returned for a login attempted while failed logins
+// are backed off or the account is locked.
+type LoginBlockedError struct {
+	// Locked is set for an account lockout, which only ends early when an
+	// admin unlocks the account.
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	retryAfter := e.RetryAfter.Round(time.Second)
+	if retryAfter < time.Second {
+		retryAfter = time.Second
+	}
+	if e.Locked {
+		return fmt.Sprintf("account is locked after too many failed logins, try again in %s", retryAfter)
+	}
+	return fmt.Sprintf("too many failed logins, try again in %s", retryAfter)
+}
+
+// LoginFailed is published for every login with a wrong password or an
+// unknown email. UserID is empty for unknown emails.
+type LoginFailed struct {
+	UserID    string    `json:"user_id,omitempty"`
+	Email     string    `json:"email"`
+	IPAddress string    `json:"ip_address,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	Failures  int       `json:"failures"`
+	At        time.Time `json:"at"`
+}
+
+// AccountLocked is published when failed logins lock an account.
+type AccountLocked struct {
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	IPAddress string    `json:"ip_address,omitempty"`
+	Failures  int       `json:"failures"`
+	Until     time.Time `json:"until"`
+}
+
+// LoginAttemptRepository counts failed logins and blocks further ones, both
+// by key, e.g. an account or an IP address.
+type LoginAttemptRepository interface {
+	// RecordFailure counts a failure and returns the failures so far. The
+	// count is forgotten after window without failures.
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	ResetFailures(ctx context.Context, key string) error
+	Block(ctx context.Context, key string, d time.Duration) error
+	// BlockedFor returns how much longer the key is blocked, or zero.
+	BlockedFor(ctx context.Context, key string) (time.Duration, error)
+	Unblock(ctx context.Context, key string) error
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
+// PasswordResetToken lets the holder of the emailed token set a new password
+// once. Only the token's hash is stored.
+type PasswordResetToken struct {
+	TokenHash string    `bson:"_id"`
+	UserID    string    `bson:"user_id"`
+	CreatedAt time.Time `bson:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+type PasswordResetRepository interface {
+	Create(ctx context.Context, token *PasswordResetToken) error
+	// Consume removes and returns the token with this hash if it has not
+	// expired at now, so that it cannot be used twice. It returns
+	// ErrInvalidResetToken otherwise.
+	Consume(ctx context.Context, tokenHash string, now time.Time) (*PasswordResetToken, error)
+	DeleteByUser(ctx context.Context, userID string) error
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidProfile is wrapped by the errors that say which profile field
+// is wrong.
+var ErrInvalidProfile = errors.New("invalid profile")
+
+// Notification channels.
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelPush  = "push"
+)
+
+// Channels lists every notification channel, in the order they are shown.
+var Channels = []string{ChannelEmail, ChannelSMS, ChannelPush}
+
+// Dietary preferences product recommendations and filters can use.
+var DietaryPreferences = []string{
+	"vegetarian",
+	"vegan",
+	"halal",
+	"kosher",
+	"gluten_free",
+	"lactose_free",
+	"nut_free",
+}
+
+// Locales emails and the storefront are translated to, and currencies
+// prices can be shown in. The first of each is the default.
+var (
+	Locales    = []string{"ru", "kk", "en"}
+	Currencies = []string{"KZT", "USD", "EUR", "RUB"}
+)
+
+// ConsentMarketing is consent to marketing emails and messages.
+const ConsentMarketing = "marketing"
+
+type Profile struct {
+	// Phone is in E.164 format, e.g. +77011234567.
+	Phone string `bson:"phone,omitempty"`
+	// Locale is a language from Locales, optionally with a region, e.g.
+	// kk-KZ. Empty means the default.
+	Locale string `bson:"locale,omitempty"`
+	// Currency is a code from Currencies. Empty means the default.
+	Currency           string   `bson:"currency,omitempty"`
+	DietaryPreferences []string `bson:"dietary_preferences,omitempty"`
+	// MutedChannels are the notification channels the user turned off, so
+	// users who never chose get notified everywhere they can be.
+	MutedChannels    []string `bson:"muted_channels,omitempty"`
+	MarketingConsent bool     `bson:"marketing_consent"`
+}
+
+// ConsentRecord is one grant or withdrawal of consent. Records are only
+// ever added, so they show what the user agreed to at any time.
+type ConsentRecord struct {
+	Purpose   string    `bson:"purpose"`
+	Granted   bool      `bson:"granted"`
+	At        time.Time `bson:"at"`
+	IPAddress string    `bson:"ip_address,omitempty"`
+	UserAgent string    `bson:"user_agent,omitempty"`
+}
+
+// NotificationChannels returns the channels the user can be notified on:
+// those not muted, and SMS only with a phone number.
+func (u *User) NotificationChannels() []string {
+	var channels []string
+	for _, channel := range Channels {
+		if channel == ChannelSMS && u.Profile.Phone == "" {
+			continue
+		}
+		muted := false
+		for _, m := range u.Profile.MutedChannels {
+			muted = muted || m == channel
+		}
+		if !muted {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
+
+// Notifies reports whether the user can be notified on the channel.
+func (u *User) Notifies(channel string) bool {
+	for _, c := range u.NotificationChannels() {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
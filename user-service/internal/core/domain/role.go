@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+)
+
+var (
+	ErrUnknownRole = errors.New("unknown role")
+	ErrLastRole    = errors.New("a user must keep at least one role")
+)
+
+const (
+	RoleCustomer = "customer"
+	RoleSeller   = "seller"
+	RoleCourier  = "courier"
+	RoleSupport  = "support"
+	RoleAdmin    = "admin"
+)
+
+// Permissions are what routes check; roles are only a way to hand them out.
+const (
+	PermissionOrdersCreate     = "orders:create"
+	PermissionOrdersReadAny    = "orders:read_any"
+	PermissionProductsWrite    = "products:write"
+	PermissionDeliveriesUpdate = "deliveries:update"
+	PermissionPromotionsManage = "promotions:manage"
+	PermissionUsersRead        = "users:read"
+	PermissionUsersManageRoles = "users:manage_roles"
+	PermissionUsersUnlock      = "users:unlock"
+)
+
+var rolePermissions = map[string][]string{
+	RoleCustomer: {PermissionOrdersCreate},
+	RoleSeller:   {PermissionProductsWrite},
+	RoleCourier:  {PermissionDeliveriesUpdate},
+	RoleSupport:  {PermissionOrdersReadAny, PermissionUsersRead, PermissionUsersUnlock},
+	RoleAdmin: {
+		PermissionOrdersCreate,
+		PermissionOrdersReadAny,
+		PermissionProductsWrite,
+		PermissionDeliveriesUpdate,
+		PermissionPromotionsManage,
+		PermissionUsersRead,
+		PermissionUsersManageRoles,
+		PermissionUsersUnlock,
+	},
+}
+
+func IsRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RoleList returns the user's roles. Users stored before roles existed are
+// customers.
+func (u *User) RoleList() []string {
+	if len(u.Roles) == 0 {
+		return []string{RoleCustomer}
+	}
+	return u.Roles
+}
+
+// Permissions returns what the user's roles grant, sorted. Roles the policy
+// requires two-factor authentication for grant nothing until it is enabled.
+func (u *User) Permissions(policy TwoFactorPolicy) []string {
+	seen := map[string]bool{}
+	var permissions []string
+	for _, role := range u.RoleList() {
+		if policy[role] && !u.TwoFactorEnabled() {
+			continue
+		}
+		for _, permission := range rolePermissions[role] {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	sort.Strings(permissions)
+	return permissions
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReused means a refresh token was used after it had
+	// been rotated, so it may have been stolen.
+	ErrRefreshTokenReused = errors.New("refresh token was already used")
+)
+
+// Session is a login on one device. It lives until ExpiresAt unless it is
+// revoked, and is kept going by rotating its refresh token. Only the hash of
+// the current refresh token is stored.
+type Session struct {
+	ID               string
+	UserID           string
+	RefreshTokenHash string
+	UserAgent        string
+	IPAddress        string
+	CreatedAt        time.Time
+	LastUsedAt       time.Time
+	ExpiresAt        time.Time
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *Session) error
+	// Get returns ErrSessionNotFound for sessions that expired or were
+	// revoked.
+	Get(ctx context.Context, id string) (*Session, error)
+	// Rotate replaces the session's refresh token hash oldHash with newHash
+	// and records the client the refresh came from. A hash that was rotated
+	// away before is reuse: the session is revoked and
+	// ErrRefreshTokenReused returned. Any other mismatch returns
+	// ErrInvalidRefreshToken.
+	Rotate(ctx context.Context, id, oldHash, newHash string, usedAt time.Time, userAgent, ipAddress string) (*Session, error)
+	ListByUser(ctx context.Context, userID string) ([]*Session, error)
+	// Delete revokes one session of the user.
+	Delete(ctx context.Context, userID, id string) error
+	// DeleteByUser revokes every session of the user except keepID and
+	// returns how many it revoked.
+	DeleteByUser(ctx context.Context, userID, keepID string) (int, error)
+}
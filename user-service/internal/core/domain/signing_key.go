@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrSigningKeyExists = errors.New("signing key already exists")
+
+// SigningKey is a key access tokens are signed with. It signs from NotBefore
+// until the next key's NotBefore. PrivateKey is PKCS #8 DER.
+type SigningKey struct {
+	ID         string    `bson:"_id"`
+	Algorithm  string    `bson:"algorithm"`
+	PrivateKey []byte    `bson:"private_key"`
+	NotBefore  time.Time `bson:"not_before"`
+	CreatedAt  time.Time `bson:"created_at"`
+}
+
+type SigningKeyRepository interface {
+	// List returns every key, oldest NotBefore first.
+	List(ctx context.Context) ([]*SigningKey, error)
+	// Create returns ErrSigningKeyExists if a key with the same ID exists,
+	// e.g. because another replica rotated first.
+	Create(ctx context.Context, key *SigningKey) error
+	Delete(ctx context.Context, id string) error
+}
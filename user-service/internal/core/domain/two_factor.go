@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrTwoFactorNotEnrolled    = errors.New("two-factor authentication is not enrolled")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrInvalidLoginChallenge   = errors.New("invalid or expired login challenge")
+)
+
+// TwoFactor is a user's TOTP setup. It only guards logins once Enabled,
+// which confirming a first code sets.
+type TwoFactor struct {
+	// Secret is needed to check codes, so it is stored as is.
+	Secret  string `bson:"secret"`
+	Enabled bool   `bson:"enabled"`
+	// RecoveryCodeHashes are the unused recovery codes, hashed.
+	RecoveryCodeHashes []string `bson:"recovery_code_hashes,omitempty"`
+	// LastUsedStep is the TOTP time step of the last accepted code. A code
+	// is only accepted for a later step, so it cannot be replayed.
+	LastUsedStep int64      `bson:"last_used_step"`
+	EnabledAt    *time.Time `bson:"enabled_at,omitempty"`
+}
+
+func (u *User) TwoFactorEnabled() bool {
+	return u.TwoFactor != nil && u.TwoFactor.Enabled
+}
+
+// TwoFactorPolicy is the set of roles whose permissions are only granted to
+// users with two-factor authentication enabled.
+type TwoFactorPolicy map[string]bool
+
+// NewTwoFactorPolicy requires two-factor authentication for the roles.
+func NewTwoFactorPolicy(roles ...string) TwoFactorPolicy {
+	policy := TwoFactorPolicy{}
+	for _, role := range roles {
+		policy[role] = true
+	}
+	return policy
+}
+
+// SetupRequired reports whether the user has a role that needs two-factor
+// authentication without having it enabled.
+func (p TwoFactorPolicy) SetupRequired(u *User) bool {
+	if u.TwoFactorEnabled() {
+		return false
+	}
+	for _, role := range u.RoleList() {
+		if p[role] {
+			return true
+		}
+	}
+	return false
+}
+
+// LoginChallenge is the first step of a login with two-factor
+// authentication: the password was right and a code is still owed.
+type LoginChallenge struct {
+	UserID string
+	// Email is the one the password was entered for, so wrong codes count
+	// as failed logins to the same account.
+	Email     string
+	UserAgent string
+	IPAddress string
+}
+
+type LoginChallengeRepository interface {
+	Create(ctx context.Context, tokenHash string, challenge *LoginChallenge, ttl time.Duration) error
+	Get(ctx context.Context, tokenHash string) (*LoginChallenge, error)
+	// RecordFailure counts a wrong code and returns how many there were.
+	RecordFailure(ctx context.Context, tokenHash string) (int, error)
+	// Delete returns ErrInvalidLoginChallenge if the challenge was already
+	// gone, so only one caller can complete it.
+	Delete(ctx context.Context, tokenHash string) error
+}
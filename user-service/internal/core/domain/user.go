@@ -2,13 +2,35 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"time"
 )
 
+var ErrUserNotFound = errors.New("user not found")
+
 type User struct {
 	ID       string `bson:"_id"`
 	Email    string `bson:"email"`
 	Name     string `bson:"name"`
 	Password string `bson:"password"`
+	// EmailVerified is set once the user proved they own Email. Changing
+	// the email clears it.
+	EmailVerified bool `bson:"email_verified"`
+	// Roles decide what the user may do; see RoleList.
+	Roles []string `bson:"roles,omitempty"`
+	// TwoFactor is nil until the user enrolls.
+	TwoFactor *TwoFactor `bson:"two_factor,omitempty"`
+	Profile   Profile    `bson:"profile"`
+	// ConsentHistory records every change of consent, oldest first.
+	ConsentHistory []ConsentRecord `bson:"consent_history,omitempty"`
+	// CartRemindersOptOut stops abandoned cart reminder emails.
+	CartRemindersOptOut bool       `bson:"cart_reminders_opt_out"`
+	LastCartReminderAt  *time.Time `bson:"last_cart_reminder_at,omitempty"`
+	// CartUpdateEmailsOptOut stops the emails sent when the cart changes.
+	CartUpdateEmailsOptOut bool `bson:"cart_update_emails_opt_out"`
+	// SessionsRevokedAt invalidates every token issued up to then. It is
+	// set when the password changes.
+	SessionsRevokedAt *time.Time `bson:"sessions_revoked_at,omitempty"`
 }
 
 type UserRepository interface {
@@ -18,4 +40,34 @@ type UserRepository interface {
 	Update(ctx context.Context, user *User) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context, page, pageSize int) ([]*User, int64, error)
+	SetCartRemindersOptOut(ctx context.Context, id string, optOut bool) error
+	SetCartUpdateEmailsOptOut(ctx context.Context, id string, optOut bool) error
+	// UpdatePassword stores a new password hash and revokes the sessions
+	// issued before revokeSessionsAt.
+	UpdatePassword(ctx context.Context, id string, hashedPassword string, revokeSessionsAt time.Time) error
+	// MarkEmailVerified verifies the user's email if it is still email.
+	MarkEmailVerified(ctx context.Context, id string, email string) error
+	// AddRoles gives the user the roles they do not have yet.
+	AddRoles(ctx context.Context, id string, roles ...string) error
+	// RemoveRole takes the role away unless it is the user's only one, in
+	// which case it returns ErrLastRole.
+	RemoveRole(ctx context.Context, id string, role string) error
+	// SetTwoFactor replaces the user's two-factor setup; nil removes it.
+	SetTwoFactor(ctx context.Context, id string, twoFactor *TwoFactor) error
+	// UseTOTPStep records a code accepted for step and reports false if a
+	// code for that step or a later one was already used.
+	UseTOTPStep(ctx context.Context, id string, step int64) (bool, error)
+	// UseRecoveryCode removes the recovery code and reports false if the
+	// user does not have it.
+	UseRecoveryCode(ctx context.Context, id string, codeHash string) (bool, error)
+	// UpdateProfile replaces the user's profile and appends consent to the
+	// consent history unless it is nil.
+	UpdateProfile(ctx context.Context, id string, profile *Profile, consent *ConsentRecord) error
+	// ClaimCartReminder records a reminder sent at now unless the user opted
+	// out or already got one within cooldown. It reports whether the caller
+	// may send the reminder.
+	ClaimCartReminder(ctx context.Context, id string, now time.Time, cooldown time.Duration) (bool, error)
+	// ReleaseCartReminder takes back the claim made at claimedAt when the
+	// reminder could not be sent, so the next abandoned cart event retries.
+	ReleaseCartReminder(ctx context.Context, id string, claimedAt time.Time) error
 }
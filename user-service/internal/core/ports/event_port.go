@@ -0,0 +1,24 @@
+package ports
+
+import (
+	"context"
+
+	"user-service/internal/core/domain"
+)
+
+// UserEventPublisher announces changes to users, to other services as well
+// as to this service's own event handlers.
+type UserEventPublisher interface {
+	PublishUserCreated(user *domain.User) error
+	PublishUserUpdated(user *domain.User) error
+	PublishUserDeleted(event *domain.UserDeleted) error
+	PublishLoginFailed(event *domain.LoginFailed) error
+	PublishAccountLocked(event *domain.AccountLocked) error
+}
+
+// UserDataCollector asks the other services for their data of a user.
+type UserDataCollector interface {
+	// CollectUserData returns the exports of the services that answered
+	// before ctx is done, and the names of those that did not.
+	CollectUserData(ctx context.Context, userID string) ([]domain.ServiceExport, []string, error)
+}
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 	"user-service/internal/core/domain"
 )
 
@@ -23,4 +24,7 @@ type UserService interface {
 type EmailService interface {
 	SendWelcomeEmail(email, name string) error
 	SendPasswordResetEmail(email, token string) error
+	SendVerificationEmail(email, name, token string) error
+	SendAbandonedCartEmail(email, name string, cart *domain.CartAbandoned) error
+	SendAccountLockedEmail(email, name string, until time.Time) error
 }
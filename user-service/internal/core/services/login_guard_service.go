@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"user-service/internal/core/domain"
+	"user-service/internal/core/ports"
+)
+
+type LoginGuardConfig struct {
+	// After BackoffAfter failures in a row, each further one blocks the next
+	// attempt for BackoffBase, doubling up to MaxBackoff. IP addresses are
+	// shared more often, so they get IPBackoffAfter.
+	BackoffAfter   int
+	IPBackoffAfter int
+	BackoffBase    time.Duration
+	MaxBackoff     time.Duration
+	// MaxAccountFailures locks the account and MaxIPFailures blocks the IP
+	// address for LockoutDuration.
+	MaxAccountFailures int
+	MaxIPFailures      int
+	LockoutDuration    time.Duration
+	// FailureWindow is how long failures are counted after the last one.
+	FailureWindow time.Duration
+}
+
+// LoginGuard throttles password guessing, per account and per IP address.
+type LoginGuard interface {
+	// Check returns a *domain.LoginBlockedError if logging in to the email
+	// or from the IP address is blocked right now.
+	Check(ctx context.Context, email, ipAddress string) error
+	// RecordFailure counts a failed login. user is nil for unknown emails,
+	// which are throttled the same way so that they cannot be told apart.
+	RecordFailure(ctx context.Context, email string, user *domain.User, ipAddress, userAgent string) error
+	// RecordSuccess forgets the account's failures. Those of the IP address
+	// stay, or logging in to one account would allow more guesses at others.
+	RecordSuccess(ctx context.Context, email string) error
+	// Unlock ends a lockout and the backoff of the user's account.
+	Unlock(ctx context.Context, userID string) error
+}
+
+type loginGuard struct {
+	attempts  domain.LoginAttemptRepository
+	users     domain.UserRepository
+	publisher ports.UserEventPublisher
+	config    LoginGuardConfig
+}
+
+func NewLoginGuard(attempts domain.LoginAttemptRepository, users domain.UserRepository, publisher ports.UserEventPublisher, config LoginGuardConfig) LoginGuard {
+	return &loginGuard{
+		attempts:  attempts,
+		users:     users,
+		publisher: publisher,
+		config:    config,
+	}
+}
+
+// Accounts are counted by email, so unknown emails count too.
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func lockKey(email string) string {
+	return "locked:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ipAddress string) string { return "ip:" + ipAddress }
+
+func (g *loginGuard) Check(ctx context.Context, email, ipAddress string) error {
+	locked, err := g.attempts.BlockedFor(ctx, lockKey(email))
+	if err != nil {
+		return err
+	}
+	if locked > 0 {
+		return &domain.LoginBlockedError{Locked: true, RetryAfter: locked}
+	}
+
+	keys := []string{accountKey(email)}
+	if ipAddress != "" {
+		keys = append(keys, ipKey(ipAddress))
+	}
+	for _, key := range keys {
+		blocked, err := g.attempts.BlockedFor(ctx, key)
+		if err != nil {
+			return err
+		}
+		if blocked > 0 {
+			return &domain.LoginBlockedError{RetryAfter: blocked}
+		}
+	}
+	return nil
+}
+
+func (g *loginGuard) RecordFailure(ctx context.Context, email string, user *domain.User, ipAddress, userAgent string) error {
+	now := time.Now()
+	failures, err := g.attempts.RecordFailure(ctx, accountKey(email), g.config.FailureWindow)
+	if err != nil {
+		return err
+	}
+
+	event := &domain.LoginFailed{
+		Email:     email,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Failures:  failures,
+		At:        now,
+	}
+	if user != nil {
+		event.UserID = user.ID
+	}
+	if err := g.publisher.PublishLoginFailed(event); err != nil {
+		log.Printf("Failed to publish %s: %v", domain.LoginFailedEvent, err)
+	}
+
+	switch {
+	case failures >= g.config.MaxAccountFailures:
+		if err := g.lock(ctx, email, user, ipAddress, failures, now); err != nil {
+			return err
+		}
+	case failures >= g.config.BackoffAfter:
+		if err := g.attempts.Block(ctx, accountKey(email), g.backoff(failures-g.config.BackoffAfter)); err != nil {
+			return err
+		}
+	}
+
+	if ipAddress == "" {
+		return nil
+	}
+	ipFailures, err := g.attempts.RecordFailure(ctx, ipKey(ipAddress), g.config.FailureWindow)
+	if err != nil {
+		return err
+	}
+	switch {
+	case ipFailures >= g.config.MaxIPFailures:
+		return g.attempts.Block(ctx, ipKey(ipAddress), g.config.LockoutDuration)
+	case ipFailures >= g.config.IPBackoffAfter:
+		return g.attempts.Block(ctx, ipKey(ipAddress), g.backoff(ipFailures-g.config.IPBackoffAfter))
+	}
+	return nil
+}
+
+// lock locks the account and starts counting afresh for when it ends.
+func (g *loginGuard) lock(ctx context.Context, email string, user *domain.User, ipAddress string, failures int, now time.Time) error {
+	if err := g.attempts.Block(ctx, lockKey(email), g.config.LockoutDuration); err != nil {
+		return err
+	}
+	if err := g.attempts.ResetFailures(ctx, accountKey(email)); err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	// The event is also what emails the user.
+	err := g.publisher.PublishAccountLocked(&domain.AccountLocked{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Name:      user.Name,
+		IPAddress: ipAddress,
+		Failures:  failures,
+		Until:     now.Add(g.config.LockoutDuration),
+	})
+	if err != nil {
+		log.Printf("Failed to publish %s for user %s: %v", domain.AccountLockedEvent, user.ID, err)
+	}
+	return nil
+}
+
+// backoff is the block after the given number of failures past the backoff
+// threshold.
+func (g *loginGuard) backoff(excess int) time.Duration {
+	d := g.config.BackoffBase
+	for i := 0; i < excess && d < g.config.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > g.config.MaxBackoff {
+		d = g.config.MaxBackoff
+	}
+	return d
+}
+
+func (g *loginGuard) RecordSuccess(ctx context.Context, email string) error {
+	return g.attempts.ResetFailures(ctx, accountKey(email))
+}
+
+func (g *loginGuard) Unlock(ctx context.Context, userID string) error {
+	user, err := g.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := g.attempts.Unblock(ctx, lockKey(user.Email)); err != nil {
+		return err
+	}
+	if err := g.attempts.Unblock(ctx, accountKey(user.Email)); err != nil {
+		return err
+	}
+	return g.attempts.ResetFailures(ctx, accountKey(user.Email))
+}
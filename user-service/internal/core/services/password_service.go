@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"user-service/internal/core/domain"
+	"user-service/internal/core/ports"
+	"user-service/internal/core/utils"
+)
+
+const MinPasswordLength = 8
+
+var (
+	ErrWeakPassword    = errors.New("password must be at least 8 characters long")
+	ErrInvalidPassword = errors.New("current password is incorrect")
+	// ErrTooManyResetRequests is returned for every email, registered or
+	// not, once it or the IP address asked for too many resets.
+	ErrTooManyResetRequests = errors.New("too many password reset requests, try again later")
+)
+
+// resetQueueSize bounds the reset requests waiting for Run.
+const resetQueueSize = 100
+
+type PasswordResetConfig struct {
+	// TokenTTL is how long a reset token can be used.
+	TokenTTL time.Duration
+	// MaxPerEmail and MaxPerIP limit the reset requests for one email and
+	// from one IP address within Window.
+	MaxPerEmail int
+	MaxPerIP    int
+	Window      time.Duration
+}
+
+type PasswordService interface {
+	// RequestPasswordReset queues emailing a reset token to the user with
+	// this email. Looking the user up is left to Run, so the call takes as
+	// long and returns the same whether or not such a user exists.
+	RequestPasswordReset(ctx context.Context, email, ipAddress string) error
+	// Run handles the queued reset requests one at a time until ctx is
+	// done.
+	Run(ctx context.Context)
+	// ConfirmPasswordReset sets a new password with a token from
+	// RequestPasswordReset and revokes every session of the user.
+	ConfirmPasswordReset(ctx context.Context, token, newPassword string) error
+	// ChangePassword sets a new password for a user who knows the current
+	// one and revokes every session of the user.
+	ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error
+}
+
+type passwordService struct {
+	users  domain.UserRepository
+	resets domain.PasswordResetRepository
+	emails ports.EmailService
+	// attempts counts reset requests per email and IP address.
+	attempts domain.LoginAttemptRepository
+	config   PasswordResetConfig
+	queue    chan string
+}
+
+func NewPasswordService(users domain.UserRepository, resets domain.PasswordResetRepository, emails ports.EmailService, attempts domain.LoginAttemptRepository, config PasswordResetConfig) PasswordService {
+	return &passwordService{
+		users:    users,
+		resets:   resets,
+		emails:   emails,
+		attempts: attempts,
+		config:   config,
+		queue:    make(chan string, resetQueueSize),
+	}
+}
+
+func resetEmailKey(email string) string {
+	return "reset:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func resetIPKey(ipAddress string) string { return "reset-ip:" + ipAddress }
+
+func (s *passwordService) RequestPasswordReset(ctx context.Context, email, ipAddress string) error {
+	if err := s.limit(ctx, resetEmailKey(email), s.config.MaxPerEmail); err != nil {
+		return err
+	}
+	if ipAddress != "" {
+		if err := s.limit(ctx, resetIPKey(ipAddress), s.config.MaxPerIP); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case s.queue <- email:
+		return nil
+	default:
+		return errors.New("password reset queue is full")
+	}
+}
+
+// limit counts a reset request under key and refuses it past max requests
+// within the window.
+func (s *passwordService) limit(ctx context.Context, key string, max int) error {
+	requests, err := s.attempts.RecordFailure(ctx, key, s.config.Window)
+	if err != nil {
+		return err
+	}
+	if requests > max {
+		return ErrTooManyResetRequests
+	}
+	return nil
+}
+
+func (s *passwordService) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case email := <-s.queue:
+			if err := s.sendReset(ctx, email); err != nil {
+				log.Printf("Failed to handle password reset request: %v", err)
+			}
+		}
+	}
+}
+
+func (s *passwordService) sendReset(ctx context.Context, email string) error {
+	user, err := s.users.FindByEmail(ctx, email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := utils.NewToken()
+	if err != nil {
+		return err
+	}
+
+	// Only the latest token works.
+	if err := s.resets.DeleteByUser(ctx, user.ID); err != nil {
+		return err
+	}
+	now := time.Now()
+	err = s.resets.Create(ctx, &domain.PasswordResetToken{
+		TokenHash: utils.HashToken(token),
+		UserID:    user.ID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.config.TokenTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.emails.SendPasswordResetEmail(user.Email, token); err != nil {
+		return fmt.Errorf("send password reset email to user %s: %v", user.ID, err)
+	}
+	return nil
+}
+
+func (s *passwordService) ConfirmPasswordReset(ctx context.Context, token, newPassword string) error {
+	if len(newPassword) < MinPasswordLength {
+		return ErrWeakPassword
+	}
+
+	reset, err := s.resets.Consume(ctx, utils.HashToken(token), time.Now())
+	if err != nil {
+		return err
+	}
+	return s.setPassword(ctx, reset.UserID, newPassword)
+}
+
+func (s *passwordService) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	if len(newPassword) < MinPasswordLength {
+		return ErrWeakPassword
+	}
+
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !utils.VerifyPassword(user.Password, currentPassword) {
+		return ErrInvalidPassword
+	}
+	return s.setPassword(ctx, userID, newPassword)
+}
+
+func (s *passwordService) setPassword(ctx context.Context, userID, newPassword string) error {
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.users.UpdatePassword(ctx, userID, hashedPassword, time.Now()); err != nil {
+		return err
+	}
+	// Tokens requested before the change must not undo it.
+	return s.resets.DeleteByUser(ctx, userID)
+}
@@ -0,0 +1,316 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"user-service/internal/core/domain"
+	"user-service/internal/core/ports"
+	"user-service/internal/core/utils"
+)
+
+type PrivacyConfig struct {
+	// DataServices are the other services holding user data; erasures wait
+	// for each of them to acknowledge.
+	DataServices []string
+	// ExportTimeout is how long an export waits for the other services.
+	ExportTimeout time.Duration
+	// RetryAfter is how long an erasure waits for acknowledgements before
+	// it is published again.
+	RetryAfter time.Duration
+}
+
+// DataExport is everything the services store about a user.
+type DataExport struct {
+	UserID     string                     `json:"user_id"`
+	ExportedAt time.Time                  `json:"exported_at"`
+	Services   map[string]json.RawMessage `json:"services"`
+	// Missing names the services that failed or did not answer in time.
+	Missing []string `json:"missing,omitempty"`
+}
+
+// PrivacyService exports and erases users' data across the services.
+type PrivacyService interface {
+	ExportData(ctx context.Context, userID string) (*DataExport, error)
+	// EraseMyData erases the data of a user who confirmed with their
+	// password.
+	EraseMyData(ctx context.Context, userID, password string) (*domain.ErasureRequest, error)
+	// Erase records an erasure request and publishes UserDeletedEvent, so
+	// that every service, this one included, erases the user's data.
+	Erase(ctx context.Context, userID string) (*domain.ErasureRequest, error)
+	GetErasure(ctx context.Context, id string) (*domain.ErasureRequest, error)
+	// EraseLocalData deletes what this service stores about the user and
+	// records that it is done. It is safe to call again.
+	EraseLocalData(ctx context.Context, event *domain.UserDeleted) error
+	// RecordCompletion records another service's acknowledgement.
+	RecordCompletion(ctx context.Context, event *domain.ErasureCompleted) error
+	// RetryPending publishes UserDeletedEvent again for the erasures that
+	// are still pending RetryAfter after they were requested.
+	RetryPending(ctx context.Context, now time.Time) error
+	// Run calls RetryPending every interval until ctx is done.
+	Run(ctx context.Context, interval time.Duration)
+}
+
+type privacyService struct {
+	users         domain.UserRepository
+	sessions      domain.SessionRepository
+	resets        domain.PasswordResetRepository
+	verifications domain.EmailVerificationRepository
+	erasures      domain.ErasureRepository
+	publisher     ports.UserEventPublisher
+	collector     ports.UserDataCollector
+	config        PrivacyConfig
+}
+
+func NewPrivacyService(
+	users domain.UserRepository,
+	sessions domain.SessionRepository,
+	resets domain.PasswordResetRepository,
+	verifications domain.EmailVerificationRepository,
+	erasures domain.ErasureRepository,
+	publisher ports.UserEventPublisher,
+	collector ports.UserDataCollector,
+	config PrivacyConfig,
+) PrivacyService {
+	return &privacyService{
+		users:         users,
+		sessions:      sessions,
+		resets:        resets,
+		verifications: verifications,
+		erasures:      erasures,
+		publisher:     publisher,
+		collector:     collector,
+		config:        config,
+	}
+}
+
+// exportedUser is this service's part of a DataExport. Password hashes and
+// two-factor secrets are left out.
+type exportedUser struct {
+	ID                     string            `json:"id"`
+	Email                  string            `json:"email"`
+	Name                   string            `json:"name"`
+	EmailVerified          bool              `json:"email_verified"`
+	Roles                  []string          `json:"roles"`
+	TwoFactorEnabled       bool              `json:"two_factor_enabled"`
+	Profile                exportedProfile   `json:"profile"`
+	ConsentHistory         []exportedConsent `json:"consent_history"`
+	CartRemindersOptOut    bool              `json:"cart_reminders_opt_out"`
+	CartUpdateEmailsOptOut bool              `json:"cart_update_emails_opt_out"`
+	Sessions               []exportedSession `json:"sessions"`
+}
+
+type exportedProfile struct {
+	Phone              string   `json:"phone,omitempty"`
+	Locale             string   `json:"locale,omitempty"`
+	Currency           string   `json:"currency,omitempty"`
+	DietaryPreferences []string `json:"dietary_preferences,omitempty"`
+	MutedChannels      []string `json:"muted_channels,omitempty"`
+	MarketingConsent   bool     `json:"marketing_consent"`
+}
+
+type exportedConsent struct {
+	Purpose   string    `json:"purpose"`
+	Granted   bool      `json:"granted"`
+	At        time.Time `json:"at"`
+	IPAddress string    `json:"ip_address,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+}
+
+type exportedSession struct {
+	UserAgent  string    `json:"user_agent,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+func (s *privacyService) ExportData(ctx context.Context, userID string) (*DataExport, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+	sessions, err := s.sessions.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	local, err := json.Marshal(toExportedUser(user, sessions))
+	if err != nil {
+		return nil, err
+	}
+
+	collectCtx, cancel := context.WithTimeout(ctx, s.config.ExportTimeout)
+	defer cancel()
+	exports, missing, err := s.collector.CollectUserData(collectCtx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &DataExport{
+		UserID:     userID,
+		ExportedAt: time.Now(),
+		Services:   map[string]json.RawMessage{domain.ServiceName: local},
+		Missing:    missing,
+	}
+	for _, e := range exports {
+		if e.Error != "" {
+			log.Printf("%s failed to export user %s: %s", e.Service, userID, e.Error)
+			export.Missing = append(export.Missing, e.Service)
+			continue
+		}
+		export.Services[e.Service] = e.Data
+	}
+	return export, nil
+}
+
+func toExportedUser(user *domain.User, sessions []*domain.Session) exportedUser {
+	exported := exportedUser{
+		ID:               user.ID,
+		Email:            user.Email,
+		Name:             user.Name,
+		EmailVerified:    user.EmailVerified,
+		Roles:            user.RoleList(),
+		TwoFactorEnabled: user.TwoFactorEnabled(),
+		Profile: exportedProfile{
+			Phone:              user.Profile.Phone,
+			Locale:             user.Profile.Locale,
+			Currency:           user.Profile.Currency,
+			DietaryPreferences: user.Profile.DietaryPreferences,
+			MutedChannels:      user.Profile.MutedChannels,
+			MarketingConsent:   user.Profile.MarketingConsent,
+		},
+		CartRemindersOptOut:    user.CartRemindersOptOut,
+		CartUpdateEmailsOptOut: user.CartUpdateEmailsOptOut,
+	}
+	for _, record := range user.ConsentHistory {
+		exported.ConsentHistory = append(exported.ConsentHistory, exportedConsent(record))
+	}
+	for _, session := range sessions {
+		exported.Sessions = append(exported.Sessions, exportedSession{
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+		})
+	}
+	return exported
+}
+
+func (s *privacyService) EraseMyData(ctx context.Context, userID, password string) (*domain.ErasureRequest, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+	if !utils.VerifyPassword(user.Password, password) {
+		return nil, ErrInvalidPassword
+	}
+	return s.Erase(ctx, userID)
+}
+
+func (s *privacyService) Erase(ctx context.Context, userID string) (*domain.ErasureRequest, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	services := append([]string{domain.ServiceName}, s.config.DataServices...)
+	request := domain.NewErasureRequest(uuid.New().String(), userID, services, time.Now())
+	if err := s.erasures.Create(ctx, request); err != nil {
+		return nil, err
+	}
+
+	// The request is stored, so RetryPending publishes it if this fails.
+	if err := s.publisher.PublishUserDeleted(toUserDeleted(request)); err != nil {
+		log.Printf("Failed to publish %s for erasure %s: %v", domain.UserDeletedEvent, request.ID, err)
+	}
+	return request, nil
+}
+
+func toUserDeleted(request *domain.ErasureRequest) *domain.UserDeleted {
+	return &domain.UserDeleted{
+		UserID:      request.UserID,
+		ErasureID:   request.ID,
+		RequestedAt: request.RequestedAt,
+	}
+}
+
+func (s *privacyService) GetErasure(ctx context.Context, id string) (*domain.ErasureRequest, error) {
+	return s.erasures.Get(ctx, id)
+}
+
+func (s *privacyService) EraseLocalData(ctx context.Context, event *domain.UserDeleted) error {
+	erase := func() error {
+		if _, err := s.sessions.DeleteByUser(ctx, event.UserID, ""); err != nil {
+			return err
+		}
+		if err := s.resets.DeleteByUser(ctx, event.UserID); err != nil {
+			return err
+		}
+		if err := s.verifications.DeleteByUser(ctx, event.UserID); err != nil {
+			return err
+		}
+		return s.users.Delete(ctx, event.UserID)
+	}
+
+	errMsg := ""
+	err := erase()
+	if err != nil {
+		errMsg = err.Error()
+	}
+	if _, recordErr := s.erasures.RecordCompletion(ctx, event.ErasureID, domain.ServiceName, time.Now(), errMsg); recordErr != nil {
+		log.Printf("Failed to record erasure %s: %v", event.ErasureID, recordErr)
+	}
+	return err
+}
+
+func (s *privacyService) RecordCompletion(ctx context.Context, event *domain.ErasureCompleted) error {
+	request, err := s.erasures.RecordCompletion(ctx, event.ErasureID, event.Service, event.CompletedAt, event.Error)
+	if err != nil {
+		return err
+	}
+	if event.Error != "" {
+		log.Printf("%s failed to erase user %s: %s", event.Service, request.UserID, event.Error)
+	}
+	return nil
+}
+
+func (s *privacyService) RetryPending(ctx context.Context, now time.Time) error {
+	requests, err := s.erasures.ListPending(ctx, now.Add(-s.config.RetryAfter))
+	if err != nil {
+		return err
+	}
+	for _, request := range requests {
+		log.Printf("Erasure %s still waits for %v, publishing it again", request.ID, request.Pending())
+		if err := s.publisher.PublishUserDeleted(toUserDeleted(request)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *privacyService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.RetryPending(ctx, now); err != nil {
+				log.Printf("Failed to retry pending erasures: %v", err)
+			}
+		}
+	}
+}
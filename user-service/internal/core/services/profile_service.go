@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"user-service/internal/core/domain"
+)
+
+// ConsentSource is where a consent change came from, kept with the record.
+type ConsentSource struct {
+	IPAddress string
+	UserAgent string
+}
+
+type ProfileService interface {
+	GetProfile(ctx context.Context, userID string) (*domain.User, error)
+	// UpdateProfile validates and replaces the user's profile and returns
+	// the updated user. A change of marketing consent is added to the
+	// consent history.
+	UpdateProfile(ctx context.Context, userID string, profile domain.Profile, source ConsentSource) (*domain.User, error)
+}
+
+type profileService struct {
+	users domain.UserRepository
+}
+
+func NewProfileService(users domain.UserRepository) ProfileService {
+	return &profileService{users: users}
+}
+
+func (s *profileService) GetProfile(ctx context.Context, userID string) (*domain.User, error) {
+	return s.users.FindByID(ctx, userID)
+}
+
+func (s *profileService) UpdateProfile(ctx context.Context, userID string, profile domain.Profile, source ConsentSource) (*domain.User, error) {
+	if err := normalizeProfile(&profile); err != nil {
+		return nil, err
+	}
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var consent *domain.ConsentRecord
+	if profile.MarketingConsent != user.Profile.MarketingConsent {
+		consent = &domain.ConsentRecord{
+			Purpose:   domain.ConsentMarketing,
+			Granted:   profile.MarketingConsent,
+			At:        time.Now(),
+			IPAddress: source.IPAddress,
+			UserAgent: source.UserAgent,
+		}
+	}
+	if err := s.users.UpdateProfile(ctx, userID, &profile, consent); err != nil {
+		return nil, err
+	}
+	return s.users.FindByID(ctx, userID)
+}
+
+var (
+	phonePattern  = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+	localePattern = regexp.MustCompile(`^([a-z]{2})(-[A-Z]{2})?$`)
+)
+
+// normalizeProfile validates the profile and brings it into the stored
+// form: canonical case, and lists sorted without duplicates.
+func normalizeProfile(profile *domain.Profile) error {
+	profile.Phone = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(profile.Phone)
+	if profile.Phone != "" && !phonePattern.MatchString(profile.Phone) {
+		return fmt.Errorf("%w: phone must be in international format, e.g. +77011234567", domain.ErrInvalidProfile)
+	}
+
+	if profile.Locale != "" {
+		language, region, _ := strings.Cut(profile.Locale, "-")
+		profile.Locale = strings.ToLower(language)
+		if region != "" {
+			profile.Locale += "-" + strings.ToUpper(region)
+		}
+		match := localePattern.FindStringSubmatch(profile.Locale)
+		if match == nil || !oneOf(domain.Locales, match[1]) {
+			return fmt.Errorf("%w: locale must be one of %s", domain.ErrInvalidProfile, strings.Join(domain.Locales, ", "))
+		}
+	}
+
+	profile.Currency = strings.ToUpper(profile.Currency)
+	if profile.Currency != "" && !oneOf(domain.Currencies, profile.Currency) {
+		return fmt.Errorf("%w: currency must be one of %s", domain.ErrInvalidProfile, strings.Join(domain.Currencies, ", "))
+	}
+
+	var err error
+	if profile.DietaryPreferences, err = normalizeList(profile.DietaryPreferences, domain.DietaryPreferences, "dietary preference"); err != nil {
+		return err
+	}
+	if profile.MutedChannels, err = normalizeList(profile.MutedChannels, domain.Channels, "notification channel"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func normalizeList(values, allowed []string, what string) ([]string, error) {
+	seen := map[string]bool{}
+	var list []string
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if !oneOf(allowed, value) {
+			return nil, fmt.Errorf("%w: unknown %s %q", domain.ErrInvalidProfile, what, value)
+		}
+		if !seen[value] {
+			seen[value] = true
+			list = append(list, value)
+		}
+	}
+	sort.Strings(list)
+	return list, nil
+}
+
+func oneOf(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+
+	"user-service/internal/core/domain"
+)
+
+type RoleService interface {
+	// AssignRole gives the user a role and returns the updated user.
+	AssignRole(ctx context.Context, userID, role string) (*domain.User, error)
+	// RevokeRole takes a role away and returns the updated user. The last
+	// role of a user cannot be revoked.
+	RevokeRole(ctx context.Context, userID, role string) (*domain.User, error)
+}
+
+type roleService struct {
+	users domain.UserRepository
+}
+
+func NewRoleService(users domain.UserRepository) RoleService {
+	return &roleService{users: users}
+}
+
+func (s *roleService) AssignRole(ctx context.Context, userID, role string) (*domain.User, error) {
+	if !domain.IsRole(role) {
+		return nil, domain.ErrUnknownRole
+	}
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Storing the implicit customer role keeps it when another is added.
+	roles := []string{role}
+	if len(user.Roles) == 0 {
+		roles = append(roles, domain.RoleCustomer)
+	}
+	if err := s.users.AddRoles(ctx, userID, roles...); err != nil {
+		return nil, err
+	}
+	return s.users.FindByID(ctx, userID)
+}
+
+func (s *roleService) RevokeRole(ctx context.Context, userID, role string) (*domain.User, error) {
+	if !domain.IsRole(role) {
+		return nil, domain.ErrUnknownRole
+	}
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(user.Roles) == 0 && role == domain.RoleCustomer {
+		return nil, domain.ErrLastRole
+	}
+
+	if err := s.users.RemoveRole(ctx, userID, role); err != nil {
+		return nil, err
+	}
+	return s.users.FindByID(ctx, userID)
+}
@@ -0,0 +1,240 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"user-service/internal/auth"
+	"user-service/internal/core/domain"
+	"user-service/internal/core/services"
+	"user-service/internal/core/utils"
+	pb "user-service/proto/auth"
+)
+
+// fakeSessions keeps sessions in memory with the same rotation rules as the
+// Redis repository.
+type fakeSessions struct {
+	sessions map[string]*domain.Session
+	used     map[string]bool
+}
+
+func (f *fakeSessions) Create(_ context.Context, session *domain.Session) error {
+	copied := *session
+	f.sessions[session.ID] = &copied
+	return nil
+}
+
+func (f *fakeSessions) Get(_ context.Context, id string) (*domain.Session, error) {
+	session, ok := f.sessions[id]
+	if !ok {
+		return nil, domain.ErrSessionNotFound
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (f *fakeSessions) Rotate(_ context.Context, id, oldHash, newHash string, usedAt time.Time, userAgent, ipAddress string) (*domain.Session, error) {
+	session, ok := f.sessions[id]
+	if !ok {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	if session.RefreshTokenHash != oldHash {
+		if !f.used[oldHash] {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		delete(f.sessions, id)
+		return nil, domain.ErrRefreshTokenReused
+	}
+	f.used[oldHash] = true
+	session.RefreshTokenHash = newHash
+	session.LastUsedAt = usedAt
+	session.UserAgent = userAgent
+	session.IPAddress = ipAddress
+	copied := *session
+	return &copied, nil
+}
+
+func (f *fakeSessions) ListByUser(_ context.Context, userID string) ([]*domain.Session, error) {
+	var sessions []*domain.Session
+	for _, session := range f.sessions {
+		if session.UserID == userID {
+			copied := *session
+			sessions = append(sessions, &copied)
+		}
+	}
+	return sessions, nil
+}
+
+func (f *fakeSessions) Delete(_ context.Context, userID, id string) error {
+	session, ok := f.sessions[id]
+	if !ok || session.UserID != userID {
+		return domain.ErrSessionNotFound
+	}
+	delete(f.sessions, id)
+	return nil
+}
+
+func (f *fakeSessions) DeleteByUser(_ context.Context, userID, keepID string) (int, error) {
+	revoked := 0
+	for id, session := range f.sessions {
+		if session.UserID == userID && id != keepID {
+			delete(f.sessions, id)
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+// fakeUserService implements the parts of services.UserService the auth
+// service uses.
+type fakeUserService struct {
+	services.UserService
+	users *fakeUsers
+}
+
+func (f *fakeUserService) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	return f.users.FindByID(ctx, id)
+}
+
+func (f *fakeUserService) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return f.users.FindByEmail(ctx, email)
+}
+
+func (f *fakeUserService) VerifyPassword(hashedPassword, password string) bool {
+	return utils.VerifyPassword(hashedPassword, password)
+}
+
+func newAuthService(t *testing.T) (*auth.Service, *fakeUsers, *fakeSessions) {
+	return newAuthServiceWithGuard(t, newFakeAttempts(), testLoginGuardConfig)
+}
+
+func newAuthServiceWithGuard(t *testing.T, attempts *fakeAttempts, guard services.LoginGuardConfig) (*auth.Service, *fakeUsers, *fakeSessions) {
+	hash, err := utils.HashPassword("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &fakeUsers{users: map[string]*domain.User{
+		"u1": {ID: "u1", Email: "ann@example.com", Password: hash},
+	}}
+	sessions := &fakeSessions{sessions: map[string]*domain.Session{}, used: map[string]bool{}}
+	svc := auth.NewService(
+		newKeyRing(t, &fakeSigningKeys{}, time.Now()),
+		&fakeUserService{users: users},
+		services.NewTwoFactorService(users, "Yurt Mart"),
+		services.NewLoginGuard(attempts, users, &fakePublisher{}, guard),
+		sessions,
+		fakeChallenges{},
+		auth.Config{
+			AccessTTL:       time.Minute,
+			RefreshTTL:      time.Hour,
+			ChallengeTTL:    time.Minute,
+			TwoFactorPolicy: domain.NewTwoFactorPolicy(domain.RoleAdmin),
+		},
+	)
+	return svc, users, sessions
+}
+
+func login(t *testing.T, svc *auth.Service, userAgent string) *pb.LoginResponse {
+	resp, err := svc.Login(context.Background(), &pb.LoginRequest{
+		Email:     "ann@example.com",
+		Password:  "password",
+		UserAgent: userAgent,
+	})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	return resp
+}
+
+func isValid(t *testing.T, svc *auth.Service, token string) bool {
+	resp, err := svc.ValidateToken(context.Background(), &pb.ValidateTokenRequest{Token: token})
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	return resp.Valid
+}
+
+func TestRefresh_RotatesAndDetectsReuse(t *testing.T) {
+	svc, _, sessions := newAuthService(t)
+	ctx := context.Background()
+
+	first := login(t, svc, "phone")
+	if !isValid(t, svc, first.Token) {
+		t.Fatal("a fresh access token is not valid")
+	}
+
+	refreshed, err := svc.Refresh(ctx, &pb.RefreshRequest{RefreshToken: first.RefreshToken, UserAgent: "phone"})
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if refreshed.RefreshToken == first.RefreshToken || refreshed.SessionId != first.SessionId {
+		t.Error("expected a new refresh token for the same session")
+	}
+
+	_, err = svc.Refresh(ctx, &pb.RefreshRequest{RefreshToken: first.RefreshToken})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected a reused refresh token to be refused, got %v", err)
+	}
+	if _, ok := sessions.sessions[first.SessionId]; ok {
+		t.Error("the session survived refresh token reuse")
+	}
+	if isValid(t, svc, refreshed.Token) {
+		t.Error("an access token of the revoked session is still valid")
+	}
+	if _, err := svc.Refresh(ctx, &pb.RefreshRequest{RefreshToken: refreshed.RefreshToken}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected the latest refresh token to die with its session, got %v", err)
+	}
+}
+
+func TestSessions_ListAndRevoke(t *testing.T) {
+	svc, _, _ := newAuthService(t)
+	ctx := context.Background()
+
+	phone := login(t, svc, "phone")
+	laptop := login(t, svc, "laptop")
+	tablet := login(t, svc, "tablet")
+
+	list, err := svc.ListSessions(ctx, &pb.ListSessionsRequest{UserId: "u1"})
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(list.Sessions) != 3 {
+		t.Fatalf("expected 3 sessions, got %d", len(list.Sessions))
+	}
+
+	if _, err := svc.Logout(ctx, &pb.LogoutRequest{Token: phone.Token}); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if isValid(t, svc, phone.Token) {
+		t.Error("a logged out token is still valid")
+	}
+	if _, err := svc.RevokeSession(ctx, &pb.RevokeSessionRequest{UserId: "someone-else", SessionId: laptop.SessionId}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected another user's session to be out of reach, got %v", err)
+	}
+
+	revoked, err := svc.RevokeAllSessions(ctx, &pb.RevokeAllSessionsRequest{UserId: "u1", KeepSessionId: tablet.SessionId})
+	if err != nil {
+		t.Fatalf("RevokeAllSessions: %v", err)
+	}
+	if revoked.Revoked != 1 {
+		t.Errorf("expected 1 revoked session, got %d", revoked.Revoked)
+	}
+	if isValid(t, svc, laptop.Token) || !isValid(t, svc, tablet.Token) {
+		t.Error("RevokeAllSessions did not keep exactly the kept session")
+	}
+}
+
+func TestRefresh_AfterPasswordChange(t *testing.T) {
+	svc, users, _ := newAuthService(t)
+
+	resp := login(t, svc, "phone")
+	revokedAt := time.Now()
+	users.users["u1"].SessionsRevokedAt = &revokedAt
+
+	if _, err := svc.Refresh(context.Background(), &pb.RefreshRequest{RefreshToken: resp.RefreshToken}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected sessions from before the password change to be refused, got %v", err)
+	}
+}
@@ -1,7 +1,9 @@
 package tests
 
 import (
+	"strings"
 	"testing"
+	"user-service/internal/core/domain"
 	"user-service/internal/infrastructure/email"
 )
 
@@ -24,3 +26,24 @@ func TestSMTPSender(t *testing.T) {
 		t.Errorf("Failed to send password reset email: %v", err)
 	}
 }
+
+func TestRenderAbandonedCartEmail(t *testing.T) {
+	body, err := email.RenderAbandonedCartEmail("Test User", &domain.CartAbandoned{
+		UserID: "u1",
+		Items: []domain.CartItem{
+			{ProductID: "p1", Name: "Milk", Quantity: 2, UnitPrice: 400, LineTotal: 800},
+			{ProductID: "p2", Name: "Bread", Quantity: 1, UnitPrice: 250, LineTotal: 250},
+		},
+		Total:    1176,
+		Currency: "KZT",
+	})
+	if err != nil {
+		t.Fatalf("Failed to render email: %v", err)
+	}
+
+	for _, want := range []string{"Hello Test User", "Milk x2: 800.00 KZT", "Bread x1: 250.00 KZT", "Total: 1176.00 KZT"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Email body is missing %q:\n%s", want, body)
+		}
+	}
+}
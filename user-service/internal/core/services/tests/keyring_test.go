@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"user-service/internal/auth"
+	"user-service/internal/core/domain"
+)
+
+type fakeSigningKeys struct {
+	keys []*domain.SigningKey
+}
+
+func (f *fakeSigningKeys) List(_ context.Context) ([]*domain.SigningKey, error) {
+	sort.Slice(f.keys, func(i, j int) bool { return f.keys[i].NotBefore.Before(f.keys[j].NotBefore) })
+	return append([]*domain.SigningKey(nil), f.keys...), nil
+}
+
+func (f *fakeSigningKeys) Create(_ context.Context, key *domain.SigningKey) error {
+	for _, existing := range f.keys {
+		if existing.ID == key.ID {
+			return domain.ErrSigningKeyExists
+		}
+	}
+	f.keys = append(f.keys, key)
+	return nil
+}
+
+func (f *fakeSigningKeys) Delete(_ context.Context, id string) error {
+	for i, key := range f.keys {
+		if key.ID == id {
+			f.keys = append(f.keys[:i], f.keys[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+var testKeyRingConfig = auth.KeyRingConfig{
+	Algorithm:        auth.AlgorithmEdDSA,
+	RotationInterval: 24 * time.Hour,
+	PublishAhead:     time.Hour,
+	VerifyFor:        15 * time.Minute,
+}
+
+func newKeyRing(t *testing.T, repo *fakeSigningKeys, now time.Time) *auth.KeyRing {
+	ring, err := auth.NewKeyRing(repo, testKeyRingConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ring.Rotate(context.Background(), now); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	return ring
+}
+
+func publishedKids(t *testing.T, ring *auth.KeyRing) []string {
+	rec := httptest.NewRecorder()
+	ring.ServeHTTP(rec, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &jwks); err != nil {
+		t.Fatalf("JWKS is not JSON: %v", err)
+	}
+	var kids []string
+	for _, key := range jwks.Keys {
+		if key.Kty != "OKP" || key.X == "" {
+			t.Errorf("key %s is not a complete Ed25519 JWK", key.Kid)
+		}
+		kids = append(kids, key.Kid)
+	}
+	return kids
+}
+
+func TestKeyRing_SignAndVerify(t *testing.T) {
+	ring := newKeyRing(t, &fakeSigningKeys{}, time.Now())
+
+	signed, err := ring.Sign(jwt.MapClaims{"user_id": "u1"})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	token, err := jwt.Parse(signed, ring.Keyfunc, jwt.WithValidMethods(auth.Methods()))
+	if err != nil || !token.Valid {
+		t.Fatalf("a signed token does not verify: %v", err)
+	}
+	if token.Header["kid"] != publishedKids(t, ring)[0] {
+		t.Error("the token's kid is not in the JWKS")
+	}
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": "u1"})
+	forged.Header["kid"] = token.Header["kid"]
+	forgedString, _ := forged.SignedString([]byte("guessed"))
+	if _, err := jwt.Parse(forgedString, ring.Keyfunc, jwt.WithValidMethods(auth.Methods())); err == nil {
+		t.Error("an HS256 token was accepted")
+	}
+}
+
+func TestKeyRing_Rotation(t *testing.T) {
+	repo := &fakeSigningKeys{}
+	// Sign uses the real clock, so the first key is placed just before its
+	// rotation.
+	now := time.Now()
+	start := now.Add(-(23*time.Hour + time.Minute))
+	ring := newKeyRing(t, repo, start)
+	first := publishedKids(t, ring)
+
+	// A second replica sharing the repository creates no key of its own.
+	newKeyRing(t, repo, start.Add(time.Minute))
+	if len(repo.keys) != 1 {
+		t.Fatalf("expected 1 key, got %d", len(repo.keys))
+	}
+
+	ctx := context.Background()
+	// An hour before the rotation the next key is published but not used.
+	if err := ring.Rotate(ctx, now); err != nil {
+		t.Fatal(err)
+	}
+	if kids := publishedKids(t, ring); len(kids) != 2 {
+		t.Fatalf("expected the next key to be published ahead, got %v", kids)
+	}
+	token, _ := jwt.Parse(mustSign(t, ring), ring.Keyfunc)
+	if token.Header["kid"] != first[0] {
+		t.Error("the next key signs before its time")
+	}
+
+	// Once the new key has signed for longer than a token lives, the old
+	// key is gone.
+	if err := ring.Rotate(ctx, now.Add(time.Hour+16*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	kids := publishedKids(t, ring)
+	if len(kids) != 1 || kids[0] == first[0] {
+		t.Errorf("expected only the new key to be left, got %v", kids)
+	}
+}
+
+func mustSign(t *testing.T, ring *auth.KeyRing) string {
+	signed, err := ring.Sign(jwt.MapClaims{"user_id": "u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
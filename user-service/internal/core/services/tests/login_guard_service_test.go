@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"user-service/internal/core/domain"
+	"user-service/internal/core/services"
+	pb "user-service/proto/auth"
+)
+
+// fakeAttempts counts failures and blocks in memory. Blocks expire on a
+// clock the test can move.
+type fakeAttempts struct {
+	failures map[string]int
+	blocked  map[string]time.Time
+	now      time.Time
+}
+
+func newFakeAttempts() *fakeAttempts {
+	return &fakeAttempts{failures: map[string]int{}, blocked: map[string]time.Time{}, now: time.Now()}
+}
+
+func (f *fakeAttempts) RecordFailure(_ context.Context, key string, _ time.Duration) (int, error) {
+	f.failures[key]++
+	return f.failures[key], nil
+}
+
+func (f *fakeAttempts) ResetFailures(_ context.Context, key string) error {
+	delete(f.failures, key)
+	return nil
+}
+
+func (f *fakeAttempts) Block(_ context.Context, key string, d time.Duration) error {
+	f.blocked[key] = f.now.Add(d)
+	return nil
+}
+
+func (f *fakeAttempts) BlockedFor(_ context.Context, key string) (time.Duration, error) {
+	if until, ok := f.blocked[key]; ok && until.After(f.now) {
+		return until.Sub(f.now), nil
+	}
+	return 0, nil
+}
+
+func (f *fakeAttempts) Unblock(_ context.Context, key string) error {
+	delete(f.blocked, key)
+	return nil
+}
+
+// waitOut moves the clock past every block that is not a lockout.
+func (f *fakeAttempts) waitOut(d time.Duration) {
+	f.now = f.now.Add(d)
+}
+
+type fakePublisher struct {
+	loginFailures []*domain.LoginFailed
+	lockouts      []*domain.AccountLocked
+	deletions     []*domain.UserDeleted
+	created       []*domain.User
+	updated       []*domain.User
+}
+
+func (f *fakePublisher) PublishLoginFailed(event *domain.LoginFailed) error {
+	f.loginFailures = append(f.loginFailures, event)
+	return nil
+}
+
+func (f *fakePublisher) PublishAccountLocked(event *domain.AccountLocked) error {
+	f.lockouts = append(f.lockouts, event)
+	return nil
+}
+
+var testLoginGuardConfig = services.LoginGuardConfig{
+	BackoffAfter:       3,
+	IPBackoffAfter:     10,
+	BackoffBase:        time.Second,
+	MaxBackoff:         time.Minute,
+	MaxAccountFailures: 5,
+	MaxIPFailures:      20,
+	LockoutDuration:    30 * time.Minute,
+	FailureWindow:      time.Hour,
+}
+
+func newLoginGuard() (services.LoginGuard, *fakeAttempts, *fakePublisher, *fakeUsers) {
+	users := &fakeUsers{users: map[string]*domain.User{
+		"u1": {ID: "u1", Email: "ann@example.com", Name: "Ann"},
+	}}
+	attempts := newFakeAttempts()
+	publisher := &fakePublisher{}
+	return services.NewLoginGuard(attempts, users, publisher, testLoginGuardConfig), attempts, publisher, users
+}
+
+func TestLoginBackoff(t *testing.T) {
+	ctx := context.Background()
+	guard, attempts, publisher, users := newLoginGuard()
+	user := users.users["u1"]
+
+	var backoffs []time.Duration
+	for i := 0; i < 4; i++ {
+		if err := guard.Check(ctx, "ann@example.com", "10.0.0.1"); err != nil {
+			t.Fatalf("attempt %d: Check: %v", i+1, err)
+		}
+		guard.RecordFailure(ctx, "ann@example.com", user, "10.0.0.1", "curl")
+
+		var blocked *domain.LoginBlockedError
+		if err := guard.Check(ctx, "ANN@example.com", "10.0.0.2"); errors.As(err, &blocked) {
+			backoffs = append(backoffs, blocked.RetryAfter)
+		}
+		attempts.waitOut(time.Minute)
+	}
+
+	if len(backoffs) != 2 || backoffs[0] != time.Second || backoffs[1] != 2*time.Second {
+		t.Fatalf("backoffs = %v, want [1s 2s] from the third failure on", backoffs)
+	}
+	if len(publisher.loginFailures) != 4 || publisher.loginFailures[3].Failures != 4 || publisher.loginFailures[3].UserID != "u1" {
+		t.Fatalf("login_failed events = %+v", publisher.loginFailures)
+	}
+
+	guard.RecordSuccess(ctx, "ann@example.com")
+	guard.RecordFailure(ctx, "ann@example.com", user, "10.0.0.1", "curl")
+	if err := guard.Check(ctx, "ann@example.com", "10.0.0.2"); err != nil {
+		t.Fatalf("failures before a successful login still count: %v", err)
+	}
+}
+
+func TestAccountLockout(t *testing.T) {
+	ctx := context.Background()
+	guard, attempts, publisher, users := newLoginGuard()
+
+	for i := 0; i < 5; i++ {
+		guard.RecordFailure(ctx, "ann@example.com", users.users["u1"], "10.0.0.1", "curl")
+		attempts.waitOut(2 * time.Minute)
+	}
+
+	var blocked *domain.LoginBlockedError
+	if err := guard.Check(ctx, "ann@example.com", ""); !errors.As(err, &blocked) || !blocked.Locked {
+		t.Fatalf("Check after too many failures: err = %v", err)
+	}
+	if len(publisher.lockouts) != 1 || publisher.lockouts[0].Email != "ann@example.com" {
+		t.Fatalf("user.locked events = %+v", publisher.lockouts)
+	}
+
+	if err := guard.Unlock(ctx, "u1"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if err := guard.Check(ctx, "ann@example.com", ""); err != nil {
+		t.Fatalf("Check after Unlock: %v", err)
+	}
+	if err := guard.Unlock(ctx, "missing"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("Unlock of an unknown user: err = %v", err)
+	}
+}
+
+func TestUnknownEmailsAreThrottledAlike(t *testing.T) {
+	ctx := context.Background()
+	guard, attempts, publisher, _ := newLoginGuard()
+
+	for i := 0; i < 5; i++ {
+		guard.RecordFailure(ctx, "nobody@example.com", nil, "10.0.0.1", "curl")
+		attempts.waitOut(2 * time.Minute)
+	}
+
+	var blocked *domain.LoginBlockedError
+	if err := guard.Check(ctx, "nobody@example.com", ""); !errors.As(err, &blocked) || !blocked.Locked {
+		t.Fatalf("unknown email is not locked like an account: err = %v", err)
+	}
+	if len(publisher.lockouts) != 0 {
+		t.Fatal("user.locked published for an unknown email")
+	}
+}
+
+func TestIPBlock(t *testing.T) {
+	ctx := context.Background()
+	guard, attempts, _, _ := newLoginGuard()
+
+	// Spreading guesses over many accounts avoids their lockouts but not the
+	// one of the address.
+	for i := 0; i < 20; i++ {
+		guard.RecordFailure(ctx, string(rune('a'+i))+"@example.com", nil, "10.0.0.1", "curl")
+		attempts.waitOut(2 * time.Minute)
+	}
+	if err := guard.Check(ctx, "ann@example.com", "10.0.0.1"); err == nil {
+		t.Fatal("address with too many failures is not blocked")
+	}
+	if err := guard.Check(ctx, "ann@example.com", "10.0.0.2"); err != nil {
+		t.Fatalf("other address is blocked: %v", err)
+	}
+}
+
+func TestLoginIsThrottled(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newAuthService(t)
+
+	for i := 0; i < 3; i++ {
+		_, err := svc.Login(ctx, &pb.LoginRequest{Email: "ann@example.com", Password: "wrong"})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("attempt %d: err = %v", i+1, err)
+		}
+	}
+
+	// The right password does not help during the backoff.
+	_, err := svc.Login(ctx, &pb.LoginRequest{Email: "ann@example.com", Password: "password"})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("login during backoff: err = %v", err)
+	}
+}
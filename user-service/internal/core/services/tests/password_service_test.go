@@ -0,0 +1,255 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"user-service/internal/core/domain"
+	"user-service/internal/core/services"
+	"user-service/internal/core/utils"
+)
+
+// fakeUsers implements the parts of domain.UserRepository the password and
+// verification services use.
+type fakeUsers struct {
+	domain.UserRepository
+	users map[string]*domain.User
+}
+
+func (f *fakeUsers) FindByID(_ context.Context, id string) (*domain.User, error) {
+	if user, ok := f.users[id]; ok {
+		return user, nil
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (f *fakeUsers) FindByEmail(_ context.Context, email string) (*domain.User, error) {
+	for _, user := range f.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (f *fakeUsers) UpdatePassword(_ context.Context, id string, hashedPassword string, revokeSessionsAt time.Time) error {
+	user, ok := f.users[id]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	user.Password = hashedPassword
+	user.SessionsRevokedAt = &revokeSessionsAt
+	return nil
+}
+
+func (f *fakeUsers) MarkEmailVerified(_ context.Context, id, email string) error {
+	user, ok := f.users[id]
+	if !ok || user.Email != email {
+		return domain.ErrUserNotFound
+	}
+	user.EmailVerified = true
+	return nil
+}
+
+type fakeResets map[string]*domain.PasswordResetToken
+
+func (f fakeResets) Create(_ context.Context, token *domain.PasswordResetToken) error {
+	f[token.TokenHash] = token
+	return nil
+}
+
+func (f fakeResets) Consume(_ context.Context, tokenHash string, now time.Time) (*domain.PasswordResetToken, error) {
+	token, ok := f[tokenHash]
+	if !ok || !token.ExpiresAt.After(now) {
+		return nil, domain.ErrInvalidResetToken
+	}
+	delete(f, tokenHash)
+	return token, nil
+}
+
+func (f fakeResets) DeleteByUser(_ context.Context, userID string) error {
+	for hash, token := range f {
+		if token.UserID == userID {
+			delete(f, hash)
+		}
+	}
+	return nil
+}
+
+type fakeEmails struct {
+	resetTokens        chan string
+	verificationTokens chan string
+}
+
+func (f *fakeEmails) SendWelcomeEmail(email, name string) error { return nil }
+func (f *fakeEmails) SendPasswordResetEmail(email, token string) error {
+	f.resetTokens <- token
+	return nil
+}
+func (f *fakeEmails) SendVerificationEmail(email, name, token string) error {
+	f.verificationTokens <- token
+	return nil
+}
+func (f *fakeEmails) SendAbandonedCartEmail(email, name string, cart *domain.CartAbandoned) error {
+	return nil
+}
+func (f *fakeEmails) SendAccountLockedEmail(email, name string, until time.Time) error { return nil }
+
+func newPasswordService(t *testing.T, ttl time.Duration) (services.PasswordService, *fakeUsers, fakeResets, *fakeEmails) {
+	hash, err := utils.HashPassword("old-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &fakeUsers{users: map[string]*domain.User{
+		"u1": {ID: "u1", Email: "ann@example.com", Password: hash},
+	}}
+	resets := fakeResets{}
+	emails := &fakeEmails{resetTokens: make(chan string, 4), verificationTokens: make(chan string, 4)}
+	svc := services.NewPasswordService(users, resets, emails, newFakeAttempts(), services.PasswordResetConfig{
+		TokenTTL:    ttl,
+		MaxPerEmail: 3,
+		MaxPerIP:    5,
+		Window:      time.Hour,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go svc.Run(ctx)
+	return svc, users, resets, emails
+}
+
+func receiveToken(t *testing.T, emails *fakeEmails) string {
+	select {
+	case token := <-emails.resetTokens:
+		return token
+	case <-time.After(time.Second):
+		t.Fatal("no password reset email was sent")
+		return ""
+	}
+}
+
+func TestPasswordReset(t *testing.T) {
+	svc, users, resets, emails := newPasswordService(t, time.Hour)
+	ctx := context.Background()
+
+	if err := svc.RequestPasswordReset(ctx, "ann@example.com", "10.0.0.1"); err != nil {
+		t.Fatalf("RequestPasswordReset: %v", err)
+	}
+	token := receiveToken(t, emails)
+	if _, stored := resets[token]; stored {
+		t.Error("reset token is stored in plain text")
+	}
+
+	if err := svc.ConfirmPasswordReset(ctx, token, "short"); !errors.Is(err, services.ErrWeakPassword) {
+		t.Errorf("expected ErrWeakPassword, got %v", err)
+	}
+	if err := svc.ConfirmPasswordReset(ctx, token, "new-password"); err != nil {
+		t.Fatalf("ConfirmPasswordReset: %v", err)
+	}
+	user := users.users["u1"]
+	if !utils.VerifyPassword(user.Password, "new-password") {
+		t.Error("password was not changed")
+	}
+	if user.SessionsRevokedAt == nil {
+		t.Error("sessions were not revoked")
+	}
+
+	if err := svc.ConfirmPasswordReset(ctx, token, "another-password"); !errors.Is(err, domain.ErrInvalidResetToken) {
+		t.Errorf("expected a used token to be refused, got %v", err)
+	}
+}
+
+func TestPasswordReset_UnknownEmailLooksTheSame(t *testing.T) {
+	svc, _, resets, emails := newPasswordService(t, time.Hour)
+
+	ctx := context.Background()
+
+	if err := svc.RequestPasswordReset(ctx, "nobody@example.com", "10.0.0.1"); err != nil {
+		t.Errorf("expected no error for an unknown email, got %v", err)
+	}
+	// Requests are handled in order, so once Ann's email is sent the
+	// unknown one has been handled too.
+	if err := svc.RequestPasswordReset(ctx, "ann@example.com", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	receiveToken(t, emails)
+	if len(resets) != 1 || len(emails.resetTokens) != 0 {
+		t.Error("a token was issued for an unknown email")
+	}
+}
+
+func TestPasswordReset_LimitedPerEmail(t *testing.T) {
+	svc, _, _, _ := newPasswordService(t, time.Hour)
+	ctx := context.Background()
+
+	for _, email := range []string{"ann@example.com", "nobody@example.com"} {
+		for i := 0; i < 3; i++ {
+			if err := svc.RequestPasswordReset(ctx, email, fmt.Sprintf("10.0.0.%d", i)); err != nil {
+				t.Fatalf("request %d for %s: %v", i+1, email, err)
+			}
+		}
+		if err := svc.RequestPasswordReset(ctx, strings.ToUpper(email), "10.0.0.9"); !errors.Is(err, services.ErrTooManyResetRequests) {
+			t.Errorf("expected ErrTooManyResetRequests for %s, got %v", email, err)
+		}
+	}
+}
+
+func TestPasswordReset_LimitedPerIP(t *testing.T) {
+	svc, _, _, _ := newPasswordService(t, time.Hour)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		if err := svc.RequestPasswordReset(ctx, fmt.Sprintf("user%d@example.com", i), "10.0.0.1"); err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+	}
+	if err := svc.RequestPasswordReset(ctx, "other@example.com", "10.0.0.1"); !errors.Is(err, services.ErrTooManyResetRequests) {
+		t.Errorf("expected ErrTooManyResetRequests, got %v", err)
+	}
+	if err := svc.RequestPasswordReset(ctx, "other@example.com", "10.0.0.2"); err != nil {
+		t.Errorf("expected another address to be allowed, got %v", err)
+	}
+}
+
+func TestPasswordReset_ExpiredAndSupersededTokens(t *testing.T) {
+	svc, _, _, emails := newPasswordService(t, -time.Minute)
+	ctx := context.Background()
+
+	if err := svc.RequestPasswordReset(ctx, "ann@example.com", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.ConfirmPasswordReset(ctx, receiveToken(t, emails), "new-password"); !errors.Is(err, domain.ErrInvalidResetToken) {
+		t.Errorf("expected an expired token to be refused, got %v", err)
+	}
+
+	svc, _, _, emails = newPasswordService(t, time.Hour)
+	if err := svc.RequestPasswordReset(ctx, "ann@example.com", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	first := receiveToken(t, emails)
+	if err := svc.RequestPasswordReset(ctx, "ann@example.com", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	receiveToken(t, emails)
+	if err := svc.ConfirmPasswordReset(ctx, first, "new-password"); !errors.Is(err, domain.ErrInvalidResetToken) {
+		t.Errorf("expected a superseded token to be refused, got %v", err)
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	svc, users, _, _ := newPasswordService(t, time.Hour)
+	ctx := context.Background()
+
+	if err := svc.ChangePassword(ctx, "u1", "wrong-password", "new-password"); !errors.Is(err, services.ErrInvalidPassword) {
+		t.Errorf("expected ErrInvalidPassword, got %v", err)
+	}
+	if err := svc.ChangePassword(ctx, "u1", "old-password", "new-password"); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	if !utils.VerifyPassword(users.users["u1"].Password, "new-password") {
+		t.Error("password was not changed")
+	}
+}
@@ -0,0 +1,255 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"user-service/internal/core/domain"
+	"user-service/internal/core/services"
+	"user-service/internal/core/utils"
+)
+
+func (f *fakeUsers) Delete(_ context.Context, id string) error {
+	delete(f.users, id)
+	return nil
+}
+
+func (f *fakePublisher) PublishUserDeleted(event *domain.UserDeleted) error {
+	f.deletions = append(f.deletions, event)
+	return nil
+}
+
+type fakeErasures map[string]*domain.ErasureRequest
+
+func (f fakeErasures) Create(_ context.Context, request *domain.ErasureRequest) error {
+	f[request.ID] = request
+	return nil
+}
+
+func (f fakeErasures) Get(_ context.Context, id string) (*domain.ErasureRequest, error) {
+	request, ok := f[id]
+	if !ok {
+		return nil, domain.ErrErasureNotFound
+	}
+	return request, nil
+}
+
+func (f fakeErasures) RecordCompletion(_ context.Context, id, service string, at time.Time, errMsg string) (*domain.ErasureRequest, error) {
+	request, ok := f[id]
+	if !ok {
+		return nil, domain.ErrErasureNotFound
+	}
+	for i := range request.Services {
+		if request.Services[i].Name != service {
+			continue
+		}
+		request.Services[i].LastError = errMsg
+		if errMsg == "" {
+			request.Services[i].CompletedAt = &at
+		}
+		if request.CompletedAt == nil && len(request.Pending()) == 0 {
+			request.CompletedAt = &at
+		}
+		return request, nil
+	}
+	return nil, domain.ErrErasureNotFound
+}
+
+func (f fakeErasures) ListPending(_ context.Context, before time.Time) ([]*domain.ErasureRequest, error) {
+	var pending []*domain.ErasureRequest
+	for _, request := range f {
+		if request.CompletedAt == nil && request.RequestedAt.Before(before) {
+			pending = append(pending, request)
+		}
+	}
+	return pending, nil
+}
+
+// fakeCollector answers data export requests with exports.
+type fakeCollector struct {
+	exports []domain.ServiceExport
+	missing []string
+}
+
+func (f *fakeCollector) CollectUserData(_ context.Context, userID string) ([]domain.ServiceExport, []string, error) {
+	return f.exports, f.missing, nil
+}
+
+var testPrivacyConfig = services.PrivacyConfig{
+	DataServices:  []string{"shopping-cart-service", "order-service"},
+	ExportTimeout: time.Second,
+	RetryAfter:    10 * time.Minute,
+}
+
+type privacyFixture struct {
+	svc       services.PrivacyService
+	users     *fakeUsers
+	sessions  *fakeSessions
+	resets    fakeResets
+	erasures  fakeErasures
+	publisher *fakePublisher
+	collector *fakeCollector
+}
+
+func newPrivacyService(t *testing.T) *privacyFixture {
+	hashed, err := utils.HashPassword("old-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &privacyFixture{
+		users: &fakeUsers{users: map[string]*domain.User{
+			"u1": {ID: "u1", Email: "ann@example.com", Name: "Ann", Password: hashed, Roles: []string{domain.RoleCustomer}},
+		}},
+		sessions:  &fakeSessions{sessions: map[string]*domain.Session{}, used: map[string]bool{}},
+		resets:    fakeResets{},
+		erasures:  fakeErasures{},
+		publisher: &fakePublisher{},
+		collector: &fakeCollector{},
+	}
+	f.svc = services.NewPrivacyService(f.users, f.sessions, f.resets, fakeVerifications{}, f.erasures, f.publisher, f.collector, testPrivacyConfig)
+	return f
+}
+
+func TestEraseMyData(t *testing.T) {
+	ctx := context.Background()
+	f := newPrivacyService(t)
+	f.sessions.Create(ctx, &domain.Session{ID: "s1", UserID: "u1"})
+	f.resets.Create(ctx, &domain.PasswordResetToken{TokenHash: "h1", UserID: "u1"})
+
+	if _, err := f.svc.EraseMyData(ctx, "u1", "wrong-password"); !errors.Is(err, services.ErrInvalidPassword) {
+		t.Fatalf("EraseMyData with wrong password: %v", err)
+	}
+	if len(f.publisher.deletions) != 0 {
+		t.Fatal("erasure published without the password")
+	}
+
+	request, err := f.svc.EraseMyData(ctx, "u1", "old-password")
+	if err != nil {
+		t.Fatalf("EraseMyData: %v", err)
+	}
+	if len(f.publisher.deletions) != 1 || f.publisher.deletions[0].ErasureID != request.ID || f.publisher.deletions[0].UserID != "u1" {
+		t.Fatalf("published %+v", f.publisher.deletions)
+	}
+	want := []string{domain.ServiceName, "shopping-cart-service", "order-service"}
+	if got := request.Pending(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("pending = %v, want %v", got, want)
+	}
+
+	// This service erases its own data when the event comes back.
+	if err := f.svc.EraseLocalData(ctx, f.publisher.deletions[0]); err != nil {
+		t.Fatalf("EraseLocalData: %v", err)
+	}
+	if _, ok := f.users.users["u1"]; ok {
+		t.Fatal("user not deleted")
+	}
+	if len(f.sessions.sessions) != 0 || len(f.resets) != 0 {
+		t.Fatal("sessions or reset tokens not deleted")
+	}
+
+	complete := func(service, errMsg string) *domain.ErasureRequest {
+		t.Helper()
+		err := f.svc.RecordCompletion(ctx, &domain.ErasureCompleted{
+			ErasureID: request.ID, UserID: "u1", Service: service, CompletedAt: time.Now(), Error: errMsg,
+		})
+		if err != nil {
+			t.Fatalf("RecordCompletion(%s): %v", service, err)
+		}
+		erasure, _ := f.svc.GetErasure(ctx, request.ID)
+		return erasure
+	}
+	erasure := complete("order-service", "mongo down")
+	if !reflect.DeepEqual(erasure.Pending(), want[1:]) || erasure.Services[2].LastError != "mongo down" {
+		t.Fatalf("failed acknowledgement recorded as %+v", erasure.Services)
+	}
+	complete("order-service", "")
+	if erasure = complete("shopping-cart-service", ""); erasure.CompletedAt == nil {
+		t.Fatalf("erasure not completed: %+v", erasure.Services)
+	}
+	if erasure.Services[2].LastError != "" {
+		t.Fatal("error kept after the service succeeded")
+	}
+}
+
+func TestEraseUnknownService(t *testing.T) {
+	ctx := context.Background()
+	f := newPrivacyService(t)
+	request, err := f.svc.Erase(ctx, "u1")
+	if err != nil {
+		t.Fatalf("Erase: %v", err)
+	}
+
+	err = f.svc.RecordCompletion(ctx, &domain.ErasureCompleted{ErasureID: request.ID, Service: "review-service"})
+	if !errors.Is(err, domain.ErrErasureNotFound) {
+		t.Fatalf("acknowledgement by unknown service: %v", err)
+	}
+	if _, err := f.svc.Erase(ctx, "u2"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("Erase of unknown user: %v", err)
+	}
+}
+
+func TestRetryPendingErasures(t *testing.T) {
+	ctx := context.Background()
+	f := newPrivacyService(t)
+	request, err := f.svc.Erase(ctx, "u1")
+	if err != nil {
+		t.Fatalf("Erase: %v", err)
+	}
+
+	if err := f.svc.RetryPending(ctx, request.RequestedAt.Add(time.Minute)); err != nil {
+		t.Fatalf("RetryPending: %v", err)
+	}
+	if len(f.publisher.deletions) != 1 {
+		t.Fatalf("erasure published again before RetryAfter")
+	}
+
+	later := request.RequestedAt.Add(testPrivacyConfig.RetryAfter + time.Minute)
+	if err := f.svc.RetryPending(ctx, later); err != nil {
+		t.Fatalf("RetryPending: %v", err)
+	}
+	if len(f.publisher.deletions) != 2 || f.publisher.deletions[1].ErasureID != request.ID {
+		t.Fatalf("erasure not published again: %+v", f.publisher.deletions)
+	}
+
+	for _, service := range request.Pending() {
+		f.erasures.RecordCompletion(ctx, request.ID, service, later, "")
+	}
+	f.svc.RetryPending(ctx, later.Add(time.Hour))
+	if len(f.publisher.deletions) != 2 {
+		t.Fatal("completed erasure published again")
+	}
+}
+
+func TestExportData(t *testing.T) {
+	ctx := context.Background()
+	f := newPrivacyService(t)
+	f.sessions.Create(ctx, &domain.Session{ID: "s1", UserID: "u1", UserAgent: "Firefox", IPAddress: "10.0.0.1"})
+	f.collector.exports = []domain.ServiceExport{
+		{Service: "shopping-cart-service", Data: json.RawMessage(`{"cart":{"items":[]}}`)},
+		{Service: "review-service", Error: "database unavailable"},
+	}
+	f.collector.missing = []string{"order-service"}
+
+	export, err := f.svc.ExportData(ctx, "u1")
+	if err != nil {
+		t.Fatalf("ExportData: %v", err)
+	}
+	if !reflect.DeepEqual(export.Missing, []string{"order-service", "review-service"}) {
+		t.Fatalf("missing = %v", export.Missing)
+	}
+	if string(export.Services["shopping-cart-service"]) != `{"cart":{"items":[]}}` {
+		t.Fatalf("cart export = %s", export.Services["shopping-cart-service"])
+	}
+
+	local := string(export.Services[domain.ServiceName])
+	if !strings.Contains(local, `"email":"ann@example.com"`) || !strings.Contains(local, `"user_agent":"Firefox"`) {
+		t.Fatalf("user export = %s", local)
+	}
+	if strings.Contains(local, f.users.users["u1"].Password) || strings.Contains(local, "password") {
+		t.Fatalf("user export contains the password hash: %s", local)
+	}
+}
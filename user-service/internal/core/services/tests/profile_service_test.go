@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"user-service/internal/core/domain"
+	"user-service/internal/core/services"
+)
+
+func (f *fakeUsers) UpdateProfile(_ context.Context, id string, profile *domain.Profile, consent *domain.ConsentRecord) error {
+	user, ok := f.users[id]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	user.Profile = *profile
+	if consent != nil {
+		user.ConsentHistory = append(user.ConsentHistory, *consent)
+	}
+	return nil
+}
+
+func newProfileService() (services.ProfileService, *fakeUsers) {
+	users := &fakeUsers{users: map[string]*domain.User{"u1": {ID: "u1", Email: "ann@example.com"}}}
+	return services.NewProfileService(users), users
+}
+
+func TestUpdateProfile(t *testing.T) {
+	svc, _ := newProfileService()
+
+	user, err := svc.UpdateProfile(context.Background(), "u1", domain.Profile{
+		Phone:              "+7 701 123-45-67",
+		Locale:             "KK-kz",
+		Currency:           "usd",
+		DietaryPreferences: []string{"vegan", "halal", "vegan"},
+		MutedChannels:      []string{"push"},
+	}, services.ConsentSource{})
+	if err != nil {
+		t.Fatalf("UpdateProfile: %v", err)
+	}
+
+	want := domain.Profile{
+		Phone:              "+77011234567",
+		Locale:             "kk-KZ",
+		Currency:           "USD",
+		DietaryPreferences: []string{"halal", "vegan"},
+		MutedChannels:      []string{"push"},
+	}
+	if !reflect.DeepEqual(user.Profile, want) {
+		t.Fatalf("profile = %+v, want %+v", user.Profile, want)
+	}
+	if got := user.NotificationChannels(); !reflect.DeepEqual(got, []string{domain.ChannelEmail, domain.ChannelSMS}) {
+		t.Fatalf("notification channels = %v", got)
+	}
+}
+
+func TestUpdateProfileValidation(t *testing.T) {
+	svc, users := newProfileService()
+
+	for name, profile := range map[string]domain.Profile{
+		"phone":              {Phone: "87011234567"},
+		"locale":             {Locale: "de"},
+		"currency":           {Currency: "BTC"},
+		"dietary preference": {DietaryPreferences: []string{"carnivore"}},
+		"channel":            {MutedChannels: []string{"fax"}},
+	} {
+		_, err := svc.UpdateProfile(context.Background(), "u1", profile, services.ConsentSource{})
+		if !errors.Is(err, domain.ErrInvalidProfile) {
+			t.Errorf("invalid %s: err = %v", name, err)
+		}
+	}
+	if !reflect.DeepEqual(users.users["u1"].Profile, domain.Profile{}) {
+		t.Fatal("an invalid profile was stored")
+	}
+}
+
+func TestMarketingConsentHistory(t *testing.T) {
+	svc, users := newProfileService()
+	ctx := context.Background()
+	source := services.ConsentSource{IPAddress: "10.0.0.1", UserAgent: "firefox"}
+
+	for _, consent := range []bool{true, true, false} {
+		if _, err := svc.UpdateProfile(ctx, "u1", domain.Profile{MarketingConsent: consent}, source); err != nil {
+			t.Fatalf("UpdateProfile: %v", err)
+		}
+	}
+
+	history := users.users["u1"].ConsentHistory
+	if len(history) != 2 || !history[0].Granted || history[1].Granted {
+		t.Fatalf("consent history = %+v, want a grant and a withdrawal", history)
+	}
+	if history[0].Purpose != domain.ConsentMarketing || history[0].IPAddress != "10.0.0.1" || history[0].At.IsZero() {
+		t.Fatalf("consent record = %+v", history[0])
+	}
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"user-service/internal/core/domain"
+	"user-service/internal/core/services"
+)
+
+func (f *fakeUsers) AddRoles(_ context.Context, id string, roles ...string) error {
+	user, ok := f.users[id]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	for _, role := range roles {
+		if !contains(user.Roles, role) {
+			user.Roles = append(user.Roles, role)
+		}
+	}
+	return nil
+}
+
+func (f *fakeUsers) RemoveRole(_ context.Context, id string, role string) error {
+	user, ok := f.users[id]
+	if !ok || !contains(user.Roles, role) {
+		return nil
+	}
+	if len(user.Roles) == 1 {
+		return domain.ErrLastRole
+	}
+	var kept []string
+	for _, r := range user.Roles {
+		if r != role {
+			kept = append(kept, r)
+		}
+	}
+	user.Roles = kept
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sorted(values []string) []string {
+	values = append([]string(nil), values...)
+	sort.Strings(values)
+	return values
+}
+
+func TestRoles(t *testing.T) {
+	// u1 was stored before roles existed.
+	users := &fakeUsers{users: map[string]*domain.User{"u1": {ID: "u1"}}}
+	svc := services.NewRoleService(users)
+	ctx := context.Background()
+
+	if got := users.users["u1"].Permissions(nil); !reflect.DeepEqual(got, []string{domain.PermissionOrdersCreate}) {
+		t.Errorf("expected a user without roles to be a customer, got %v", got)
+	}
+
+	user, err := svc.AssignRole(ctx, "u1", domain.RoleSeller)
+	if err != nil {
+		t.Fatalf("AssignRole: %v", err)
+	}
+	if got := sorted(user.RoleList()); !reflect.DeepEqual(got, []string{domain.RoleCustomer, domain.RoleSeller}) {
+		t.Errorf("expected customer and seller, got %v", got)
+	}
+	if !contains(user.Permissions(nil), domain.PermissionProductsWrite) {
+		t.Error("a seller cannot write products")
+	}
+
+	if _, err := svc.AssignRole(ctx, "u1", "superuser"); !errors.Is(err, domain.ErrUnknownRole) {
+		t.Errorf("expected ErrUnknownRole, got %v", err)
+	}
+	if _, err := svc.AssignRole(ctx, "ghost", domain.RoleAdmin); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("expected ErrUserNotFound, got %v", err)
+	}
+
+	if _, err := svc.RevokeRole(ctx, "u1", domain.RoleCustomer); err != nil {
+		t.Fatalf("RevokeRole: %v", err)
+	}
+	if _, err := svc.RevokeRole(ctx, "u1", domain.RoleSeller); !errors.Is(err, domain.ErrLastRole) {
+		t.Errorf("expected the last role to stay, got %v", err)
+	}
+}
+
+func TestRoles_AdminHasEveryPermission(t *testing.T) {
+	admin := &domain.User{Roles: []string{domain.RoleAdmin}}
+	for _, role := range []string{domain.RoleCustomer, domain.RoleSeller, domain.RoleCourier, domain.RoleSupport} {
+		for _, permission := range (&domain.User{Roles: []string{role}}).Permissions(nil) {
+			if !contains(admin.Permissions(nil), permission) {
+				t.Errorf("admin lacks %s of %s", permission, role)
+			}
+		}
+	}
+}
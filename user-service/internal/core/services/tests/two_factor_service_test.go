@@ -0,0 +1,322 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"user-service/internal/core/domain"
+	"user-service/internal/core/services"
+	"user-service/internal/core/utils"
+	pb "user-service/proto/auth"
+)
+
+func (f *fakeUsers) SetTwoFactor(_ context.Context, id string, twoFactor *domain.TwoFactor) error {
+	user, ok := f.users[id]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	if twoFactor != nil {
+		copied := *twoFactor
+		twoFactor = &copied
+	}
+	user.TwoFactor = twoFactor
+	return nil
+}
+
+func (f *fakeUsers) UseTOTPStep(_ context.Context, id string, step int64) (bool, error) {
+	user, ok := f.users[id]
+	if !ok || user.TwoFactor == nil || user.TwoFactor.LastUsedStep >= step {
+		return false, nil
+	}
+	user.TwoFactor.LastUsedStep = step
+	return true, nil
+}
+
+func (f *fakeUsers) UseRecoveryCode(_ context.Context, id string, codeHash string) (bool, error) {
+	user, ok := f.users[id]
+	if !ok || user.TwoFactor == nil {
+		return false, nil
+	}
+	hashes := user.TwoFactor.RecoveryCodeHashes
+	for i, hash := range hashes {
+		if hash == codeHash {
+			user.TwoFactor.RecoveryCodeHashes = append(hashes[:i:i], hashes[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type fakeChallenge struct {
+	challenge domain.LoginChallenge
+	failures  int
+}
+
+type fakeChallenges map[string]*fakeChallenge
+
+func (f fakeChallenges) Create(_ context.Context, tokenHash string, challenge *domain.LoginChallenge, _ time.Duration) error {
+	f[tokenHash] = &fakeChallenge{challenge: *challenge}
+	return nil
+}
+
+func (f fakeChallenges) Get(_ context.Context, tokenHash string) (*domain.LoginChallenge, error) {
+	stored, ok := f[tokenHash]
+	if !ok {
+		return nil, domain.ErrInvalidLoginChallenge
+	}
+	challenge := stored.challenge
+	return &challenge, nil
+}
+
+func (f fakeChallenges) RecordFailure(_ context.Context, tokenHash string) (int, error) {
+	stored, ok := f[tokenHash]
+	if !ok {
+		return 0, domain.ErrInvalidLoginChallenge
+	}
+	stored.failures++
+	return stored.failures, nil
+}
+
+func (f fakeChallenges) Delete(_ context.Context, tokenHash string) error {
+	if _, ok := f[tokenHash]; !ok {
+		return domain.ErrInvalidLoginChallenge
+	}
+	delete(f, tokenHash)
+	return nil
+}
+
+// enableTwoFactor enrolls the user and returns the secret and recovery codes.
+func enableTwoFactor(t *testing.T, svc services.TwoFactorService, userID string) (string, []string) {
+	ctx := context.Background()
+	secret, _, err := svc.Enroll(ctx, userID)
+	if err != nil {
+		t.Fatalf("Enroll: %v", err)
+	}
+	// The confirmation code uses up the current step, so later codes in the
+	// test come from the next one.
+	code, _ := utils.TOTPCode(secret, utils.TOTPStep(time.Now())-1)
+	recoveryCodes, err := svc.Confirm(ctx, userID, code)
+	if err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+	return secret, recoveryCodes
+}
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238, appendix B: the SHA-1 secret "12345678901234567890" at
+	// T = 59s, truncated to six digits.
+	code, err := utils.TOTPCode("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", utils.TOTPStep(time.Unix(59, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != "287082" {
+		t.Fatalf("code = %s, want 287082", code)
+	}
+}
+
+func TestTwoFactorEnrollment(t *testing.T) {
+	ctx := context.Background()
+	users := &fakeUsers{users: map[string]*domain.User{"u1": {ID: "u1", Email: "ann@example.com"}}}
+	svc := services.NewTwoFactorService(users, "Yurt Mart")
+
+	secret, uri, err := svc.Enroll(ctx, "u1")
+	if err != nil {
+		t.Fatalf("Enroll: %v", err)
+	}
+	if !strings.HasPrefix(uri, "otpauth://totp/Yurt%20Mart:ann@example.com?") || !strings.Contains(uri, "secret="+secret) {
+		t.Fatalf("provisioning URI = %s", uri)
+	}
+	if users.users["u1"].TwoFactorEnabled() {
+		t.Fatal("two-factor authentication is enabled before confirmation")
+	}
+
+	if _, err := svc.Confirm(ctx, "u1", "000000"); !errors.Is(err, domain.ErrInvalidTwoFactorCode) {
+		t.Fatalf("Confirm with a wrong code: err = %v", err)
+	}
+	code, _ := utils.TOTPCode(secret, utils.TOTPStep(time.Now()))
+	recoveryCodes, err := svc.Confirm(ctx, "u1", code)
+	if err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+	if len(recoveryCodes) != 10 {
+		t.Fatalf("got %d recovery codes, want 10", len(recoveryCodes))
+	}
+	for _, hash := range users.users["u1"].TwoFactor.RecoveryCodeHashes {
+		if contains(recoveryCodes, hash) {
+			t.Fatal("recovery codes are stored in the clear")
+		}
+	}
+	if _, _, err := svc.Enroll(ctx, "u1"); !errors.Is(err, domain.ErrTwoFactorAlreadyEnabled) {
+		t.Fatalf("Enroll again: err = %v", err)
+	}
+}
+
+func TestTwoFactorCodesAreSingleUse(t *testing.T) {
+	ctx := context.Background()
+	users := &fakeUsers{users: map[string]*domain.User{"u1": {ID: "u1", Email: "ann@example.com"}}}
+	svc := services.NewTwoFactorService(users, "Yurt Mart")
+	secret, recoveryCodes := enableTwoFactor(t, svc, "u1")
+	user := users.users["u1"]
+
+	code, _ := utils.TOTPCode(secret, utils.TOTPStep(time.Now()))
+	if err := svc.Verify(ctx, user, code); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if err := svc.Verify(ctx, user, code); !errors.Is(err, domain.ErrInvalidTwoFactorCode) {
+		t.Fatalf("replayed code: err = %v", err)
+	}
+
+	if err := svc.Verify(ctx, user, recoveryCodes[0]); err != nil {
+		t.Fatalf("Verify with a recovery code: %v", err)
+	}
+	if err := svc.Verify(ctx, user, recoveryCodes[0]); !errors.Is(err, domain.ErrInvalidTwoFactorCode) {
+		t.Fatalf("reused recovery code: err = %v", err)
+	}
+
+	if err := svc.Disable(ctx, "u1", recoveryCodes[1]); err != nil {
+		t.Fatalf("Disable: %v", err)
+	}
+	if user.TwoFactor != nil {
+		t.Fatal("two-factor setup kept after Disable")
+	}
+}
+
+func TestLoginWithTwoFactor(t *testing.T) {
+	ctx := context.Background()
+	svc, users, sessions := newAuthService(t)
+	twoFactor := services.NewTwoFactorService(users, "Yurt Mart")
+	secret, _ := enableTwoFactor(t, twoFactor, "u1")
+
+	resp := login(t, svc, "firefox")
+	if !resp.TwoFactorRequired || resp.ChallengeToken == "" || resp.Token != "" || len(sessions.sessions) != 0 {
+		t.Fatalf("Login issued tokens without a code: %+v", resp)
+	}
+
+	_, err := svc.VerifyTwoFactor(ctx, &pb.VerifyTwoFactorRequest{ChallengeToken: resp.ChallengeToken, Code: "000000"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("wrong code: err = %v", err)
+	}
+
+	code, _ := utils.TOTPCode(secret, utils.TOTPStep(time.Now()))
+	verified, err := svc.VerifyTwoFactor(ctx, &pb.VerifyTwoFactorRequest{ChallengeToken: resp.ChallengeToken, Code: code})
+	if err != nil {
+		t.Fatalf("VerifyTwoFactor: %v", err)
+	}
+	if !isValid(t, svc, verified.Token) {
+		t.Fatal("token from VerifyTwoFactor is not valid")
+	}
+	if sessions.sessions[verified.SessionId].UserAgent != "firefox" {
+		t.Fatal("session does not describe the client that logged in")
+	}
+
+	_, err = svc.VerifyTwoFactor(ctx, &pb.VerifyTwoFactorRequest{ChallengeToken: resp.ChallengeToken, Code: code})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("reused challenge: err = %v", err)
+	}
+}
+
+func TestLoginChallengeEndsAfterTooManyCodes(t *testing.T) {
+	ctx := context.Background()
+	// A guard that never blocks, so only the challenge limit applies.
+	guard := testLoginGuardConfig
+	guard.BackoffAfter, guard.MaxAccountFailures = 100, 100
+	svc, users, _ := newAuthServiceWithGuard(t, newFakeAttempts(), guard)
+	secret, _ := enableTwoFactor(t, services.NewTwoFactorService(users, "Yurt Mart"), "u1")
+	resp := login(t, svc, "firefox")
+
+	for i := 0; i < 5; i++ {
+		svc.VerifyTwoFactor(ctx, &pb.VerifyTwoFactorRequest{ChallengeToken: resp.ChallengeToken, Code: "000000"})
+	}
+	code, _ := utils.TOTPCode(secret, utils.TOTPStep(time.Now()))
+	_, err := svc.VerifyTwoFactor(ctx, &pb.VerifyTwoFactorRequest{ChallengeToken: resp.ChallengeToken, Code: code})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("right code after too many wrong ones: err = %v", err)
+	}
+}
+
+func TestLoginWithTwoFactor_WrongCodesCountAsFailedLogins(t *testing.T) {
+	ctx := context.Background()
+	attempts := newFakeAttempts()
+	svc, users, _ := newAuthServiceWithGuard(t, attempts, testLoginGuardConfig)
+	secret, _ := enableTwoFactor(t, services.NewTwoFactorService(users, "Yurt Mart"), "u1")
+
+	// A fresh challenge after each wrong code does not bring back tries.
+	for i := 0; i < 3; i++ {
+		resp := login(t, svc, "firefox")
+		_, err := svc.VerifyTwoFactor(ctx, &pb.VerifyTwoFactorRequest{ChallengeToken: resp.ChallengeToken, Code: "000000"})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("wrong code %d: err = %v", i+1, err)
+		}
+	}
+	if failures := attempts.failures["account:ann@example.com"]; failures != 3 {
+		t.Fatalf("failed logins = %d, want 3", failures)
+	}
+
+	code, _ := utils.TOTPCode(secret, utils.TOTPStep(time.Now()))
+	_, err := svc.Login(ctx, &pb.LoginRequest{Email: "ann@example.com", Password: "password"})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("login during backoff: err = %v", err)
+	}
+
+	attempts.waitOut(time.Minute)
+	resp := login(t, svc, "firefox")
+	if _, err := svc.VerifyTwoFactor(ctx, &pb.VerifyTwoFactorRequest{ChallengeToken: resp.ChallengeToken, Code: code}); err != nil {
+		t.Fatalf("VerifyTwoFactor: %v", err)
+	}
+	if failures := attempts.failures["account:ann@example.com"]; failures != 0 {
+		t.Fatalf("failed logins after a complete login = %d, want 0", failures)
+	}
+}
+
+func TestLoginWithTwoFactor_PasswordAloneKeepsFailures(t *testing.T) {
+	ctx := context.Background()
+	attempts := newFakeAttempts()
+	svc, users, _ := newAuthServiceWithGuard(t, attempts, testLoginGuardConfig)
+	enableTwoFactor(t, services.NewTwoFactorService(users, "Yurt Mart"), "u1")
+
+	resp := login(t, svc, "firefox")
+	svc.VerifyTwoFactor(ctx, &pb.VerifyTwoFactorRequest{ChallengeToken: resp.ChallengeToken, Code: "000000"})
+	login(t, svc, "firefox")
+
+	if failures := attempts.failures["account:ann@example.com"]; failures != 1 {
+		t.Fatalf("failed logins = %d, want 1", failures)
+	}
+}
+
+func TestTwoFactorPolicy(t *testing.T) {
+	ctx := context.Background()
+	svc, users, _ := newAuthService(t)
+	users.users["u1"].Roles = []string{domain.RoleCustomer, domain.RoleAdmin}
+
+	resp := login(t, svc, "firefox")
+	if !resp.TwoFactorSetupRequired {
+		t.Fatal("admin without two-factor authentication is not asked to set it up")
+	}
+	validated, err := svc.ValidateToken(ctx, &pb.ValidateTokenRequest{Token: resp.Token})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contains(validated.Permissions, domain.PermissionUsersManageRoles) || !contains(validated.Permissions, domain.PermissionOrdersCreate) {
+		t.Fatalf("permissions without two-factor authentication = %v", validated.Permissions)
+	}
+
+	secret, _ := enableTwoFactor(t, services.NewTwoFactorService(users, "Yurt Mart"), "u1")
+	challenge := login(t, svc, "firefox")
+	code, _ := utils.TOTPCode(secret, utils.TOTPStep(time.Now()))
+	resp, err = svc.VerifyTwoFactor(ctx, &pb.VerifyTwoFactorRequest{ChallengeToken: challenge.ChallengeToken, Code: code})
+	if err != nil {
+		t.Fatalf("VerifyTwoFactor: %v", err)
+	}
+	validated, err = svc.ValidateToken(ctx, &pb.ValidateTokenRequest{Token: resp.Token})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.TwoFactorSetupRequired || !contains(validated.Permissions, domain.PermissionUsersManageRoles) {
+		t.Fatalf("permissions with two-factor authentication = %v", validated.Permissions)
+	}
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"user-service/internal/core/domain"
+	"user-service/internal/core/services"
+)
+
+func (f *fakeUsers) Create(_ context.Context, user *domain.User) error {
+	f.users[user.ID] = user
+	return nil
+}
+
+func (f *fakeUsers) Update(_ context.Context, user *domain.User) error {
+	f.users[user.ID] = user
+	return nil
+}
+
+func (f *fakePublisher) PublishUserCreated(user *domain.User) error {
+	f.created = append(f.created, user)
+	return nil
+}
+
+func (f *fakePublisher) PublishUserUpdated(user *domain.User) error {
+	f.updated = append(f.updated, user)
+	return nil
+}
+
+func TestUserLifecycleEvents(t *testing.T) {
+	ctx := context.Background()
+	users := &fakeUsers{users: map[string]*domain.User{}}
+	publisher := &fakePublisher{}
+	svc := services.NewUserService(users, publisher)
+
+	created, err := svc.Register(ctx, "ann@example.com", "secret-password", "Ann")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if len(publisher.created) != 1 || publisher.created[0].ID != created.ID {
+		t.Fatalf("user.created not published: %+v", publisher.created)
+	}
+
+	if _, err := svc.UpdateUser(ctx, created.ID, "ann@example.com", "Ann Smith"); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if len(publisher.updated) != 1 || publisher.updated[0].Name != "Ann Smith" {
+		t.Fatalf("user.updated not published: %+v", publisher.updated)
+	}
+}
+
+func TestUserEventLeavesOutSecrets(t *testing.T) {
+	user := &domain.User{
+		ID:        "u1",
+		Email:     "ann@example.com",
+		Name:      "Ann",
+		Password:  "$2a$10$hashhashhash",
+		Roles:     []string{domain.RoleCustomer},
+		TwoFactor: &domain.TwoFactor{Secret: "JBSWY3DPEHPK3PXP", Enabled: true},
+	}
+
+	data, err := json.Marshal(domain.NewUserEvent(user, time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{user.Password, user.TwoFactor.Secret} {
+		if strings.Contains(string(data), secret) {
+			t.Fatalf("event %s contains %q", data, secret)
+		}
+	}
+	if !strings.Contains(string(data), `"email":"ann@example.com"`) {
+		t.Fatalf("event %s lacks the email", data)
+	}
+}
@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"user-service/internal/core/domain"
+	"user-service/internal/core/services"
+)
+
+type fakeVerifications map[string]*domain.EmailVerificationToken
+
+func (f fakeVerifications) Create(_ context.Context, token *domain.EmailVerificationToken) error {
+	f[token.TokenHash] = token
+	return nil
+}
+
+func (f fakeVerifications) Consume(_ context.Context, tokenHash string, now time.Time) (*domain.EmailVerificationToken, error) {
+	token, ok := f[tokenHash]
+	if !ok || !token.ExpiresAt.After(now) {
+		return nil, domain.ErrInvalidVerificationToken
+	}
+	delete(f, tokenHash)
+	return token, nil
+}
+
+func (f fakeVerifications) DeleteByUser(_ context.Context, userID string) error {
+	for hash, token := range f {
+		if token.UserID == userID {
+			delete(f, hash)
+		}
+	}
+	return nil
+}
+
+func newVerificationService(ttl time.Duration) (services.VerificationService, *fakeUsers, fakeVerifications, *fakeEmails) {
+	users := &fakeUsers{users: map[string]*domain.User{
+		"u1": {ID: "u1", Email: "ann@example.com"},
+	}}
+	tokens := fakeVerifications{}
+	emails := &fakeEmails{verificationTokens: make(chan string, 4)}
+	return services.NewVerificationService(users, tokens, emails, ttl), users, tokens, emails
+}
+
+func receiveVerificationToken(t *testing.T, emails *fakeEmails) string {
+	select {
+	case token := <-emails.verificationTokens:
+		return token
+	default:
+		t.Fatal("no verification email was sent")
+		return ""
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	svc, users, tokens, emails := newVerificationService(time.Hour)
+	ctx := context.Background()
+
+	if err := svc.SendVerification(ctx, "u1"); err != nil {
+		t.Fatalf("SendVerification: %v", err)
+	}
+	token := receiveVerificationToken(t, emails)
+	if _, stored := tokens[token]; stored {
+		t.Error("verification token is stored in plain text")
+	}
+
+	user, err := svc.VerifyEmail(ctx, token)
+	if err != nil {
+		t.Fatalf("VerifyEmail: %v", err)
+	}
+	if !user.EmailVerified || !users.users["u1"].EmailVerified {
+		t.Error("email was not marked as verified")
+	}
+	if _, err := svc.VerifyEmail(ctx, token); !errors.Is(err, domain.ErrInvalidVerificationToken) {
+		t.Errorf("expected a used token to be refused, got %v", err)
+	}
+
+	if err := svc.SendVerification(ctx, "u1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(emails.verificationTokens) != 0 {
+		t.Error("a verified user was sent another verification email")
+	}
+}
+
+func TestVerifyEmail_ChangedEmail(t *testing.T) {
+	svc, users, _, emails := newVerificationService(time.Hour)
+	ctx := context.Background()
+
+	if err := svc.SendVerification(ctx, "u1"); err != nil {
+		t.Fatal(err)
+	}
+	users.users["u1"].Email = "ann@example.org"
+
+	if _, err := svc.VerifyEmail(ctx, receiveVerificationToken(t, emails)); !errors.Is(err, domain.ErrInvalidVerificationToken) {
+		t.Errorf("expected a token for the old email to be refused, got %v", err)
+	}
+	if users.users["u1"].EmailVerified {
+		t.Error("the new email was marked as verified")
+	}
+}
+
+func TestResendVerification(t *testing.T) {
+	svc, _, tokens, emails := newVerificationService(-time.Minute)
+	ctx := context.Background()
+
+	if err := svc.ResendVerification(ctx, "nobody@example.com"); err != nil {
+		t.Errorf("expected no error for an unknown email, got %v", err)
+	}
+	if len(tokens) != 0 || len(emails.verificationTokens) != 0 {
+		t.Error("a token was issued for an unknown email")
+	}
+
+	if err := svc.ResendVerification(ctx, "ann@example.com"); err != nil {
+		t.Fatalf("ResendVerification: %v", err)
+	}
+	if _, err := svc.VerifyEmail(ctx, receiveVerificationToken(t, emails)); !errors.Is(err, domain.ErrInvalidVerificationToken) {
+		t.Errorf("expected an expired token to be refused, got %v", err)
+	}
+}
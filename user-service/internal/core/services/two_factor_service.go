@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"user-service/internal/core/domain"
+	"user-service/internal/core/utils"
+)
+
+// recoveryCodeCount is how many recovery codes a user gets when enabling
+// two-factor authentication.
+const recoveryCodeCount = 10
+
+type TwoFactorService interface {
+	// Enroll starts a new TOTP setup and returns its secret and provisioning
+	// URI. It replaces an earlier setup that was never confirmed.
+	Enroll(ctx context.Context, userID string) (secret, uri string, err error)
+	// Confirm enables two-factor authentication with a first code from the
+	// authenticator app and returns the recovery codes, which are not stored
+	// in the clear and cannot be shown again.
+	Confirm(ctx context.Context, userID, code string) ([]string, error)
+	// Disable turns two-factor authentication off. It takes a current code
+	// or a recovery code.
+	Disable(ctx context.Context, userID, code string) error
+	// Verify checks a TOTP code or a recovery code for a login. Either can
+	// only be used once.
+	Verify(ctx context.Context, user *domain.User, code string) error
+}
+
+type twoFactorService struct {
+	users domain.UserRepository
+	// issuer names the account in authenticator apps.
+	issuer string
+}
+
+func NewTwoFactorService(users domain.UserRepository, issuer string) TwoFactorService {
+	return &twoFactorService{users: users, issuer: issuer}
+}
+
+func (s *twoFactorService) Enroll(ctx context.Context, userID string) (string, string, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+	if user.TwoFactorEnabled() {
+		return "", "", domain.ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := utils.NewTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+	if err := s.users.SetTwoFactor(ctx, userID, &domain.TwoFactor{Secret: secret}); err != nil {
+		return "", "", err
+	}
+	return secret, utils.TOTPURI(s.issuer, user.Email, secret), nil
+}
+
+func (s *twoFactorService) Confirm(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactor == nil {
+		return nil, domain.ErrTwoFactorNotEnrolled
+	}
+	if user.TwoFactor.Enabled {
+		return nil, domain.ErrTwoFactorAlreadyEnabled
+	}
+
+	now := time.Now()
+	step, ok := utils.VerifyTOTP(user.TwoFactor.Secret, code, now)
+	if !ok {
+		return nil, domain.ErrInvalidTwoFactorCode
+	}
+
+	codes, err := utils.NewRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashToken(code)
+	}
+
+	err = s.users.SetTwoFactor(ctx, userID, &domain.TwoFactor{
+		Secret:             user.TwoFactor.Secret,
+		Enabled:            true,
+		RecoveryCodeHashes: hashes,
+		LastUsedStep:       step,
+		EnabledAt:          &now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *twoFactorService) Disable(ctx context.Context, userID, code string) error {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.Verify(ctx, user, code); err != nil {
+		return err
+	}
+	return s.users.SetTwoFactor(ctx, userID, nil)
+}
+
+func (s *twoFactorService) Verify(ctx context.Context, user *domain.User, code string) error {
+	if !user.TwoFactorEnabled() {
+		return domain.ErrTwoFactorNotEnrolled
+	}
+
+	if step, ok := utils.VerifyTOTP(user.TwoFactor.Secret, code, time.Now()); ok {
+		used, err := s.users.UseTOTPStep(ctx, user.ID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return domain.ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	used, err := s.users.UseRecoveryCode(ctx, user.ID, utils.HashToken(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return domain.ErrInvalidTwoFactorCode
+	}
+	return nil
+}
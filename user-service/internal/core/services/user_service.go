@@ -3,9 +3,11 @@ package services
 import (
 	"context"
 	"errors"
+	"log"
 
 	"github.com/google/uuid"
 	"user-service/internal/core/domain"
+	"user-service/internal/core/ports"
 	"user-service/internal/core/utils"
 )
 
@@ -17,15 +19,19 @@ type UserService interface {
 	DeleteUser(ctx context.Context, id string) error
 	VerifyPassword(hashedPassword, plainPassword string) bool
 	ListUsers(ctx context.Context, page, pageSize int) ([]*domain.User, int64, error)
+	SetCartRemindersOptOut(ctx context.Context, id string, optOut bool) error
+	SetCartUpdateEmailsOptOut(ctx context.Context, id string, optOut bool) error
 }
 
 type userService struct {
-	repo domain.UserRepository
+	repo   domain.UserRepository
+	events ports.UserEventPublisher
 }
 
-func NewUserService(repo domain.UserRepository) UserService {
+func NewUserService(repo domain.UserRepository, events ports.UserEventPublisher) UserService {
 	return &userService{
-		repo: repo,
+		repo:   repo,
+		events: events,
 	}
 }
 
@@ -45,12 +51,19 @@ func (s *userService) Register(ctx context.Context, email, password, name string
 		Email:    email,
 		Name:     name,
 		Password: hashedPassword,
+		Roles:    []string{domain.RoleCustomer},
 	}
 
 	if err := s.repo.Create(ctx, newUser); err != nil {
 		return nil, err
 	}
 
+	// The account exists either way; without the event the user has to ask
+	// for the verification email with ResendVerification.
+	if err := s.events.PublishUserCreated(newUser); err != nil {
+		log.Printf("Failed to publish %s for user %s: %v", domain.UserCreatedEvent, newUser.ID, err)
+	}
+
 	return newUser, nil
 }
 
@@ -60,7 +73,7 @@ func (s *userService) GetByID(ctx context.Context, id string) (*domain.User, err
 		return nil, err
 	}
 	if user == nil {
-		return nil, errors.New("user not found")
+		return nil, domain.ErrUserNotFound
 	}
 	return user, nil
 }
@@ -71,7 +84,7 @@ func (s *userService) GetByEmail(ctx context.Context, email string) (*domain.Use
 		return nil, err
 	}
 	if user == nil {
-		return nil, errors.New("user not found")
+		return nil, domain.ErrUserNotFound
 	}
 	return user, nil
 }
@@ -82,7 +95,7 @@ func (s *userService) UpdateUser(ctx context.Context, id, email, name string) (*
 		return nil, err
 	}
 	if existingUser == nil {
-		return nil, errors.New("user not found")
+		return nil, domain.ErrUserNotFound
 	}
 
 	if email != existingUser.Email {
@@ -102,6 +115,10 @@ func (s *userService) UpdateUser(ctx context.Context, id, email, name string) (*
 		return nil, err
 	}
 
+	if err := s.events.PublishUserUpdated(existingUser); err != nil {
+		log.Printf("Failed to publish %s for user %s: %v", domain.UserUpdatedEvent, existingUser.ID, err)
+	}
+
 	return existingUser, nil
 }
 
@@ -111,7 +128,7 @@ func (s *userService) DeleteUser(ctx context.Context, id string) error {
 		return err
 	}
 	if existingUser == nil {
-		return errors.New("user not found")
+		return domain.ErrUserNotFound
 	}
 
 	return s.repo.Delete(ctx, id)
@@ -136,3 +153,11 @@ func (s *userService) ListUsers(ctx context.Context, page, pageSize int) ([]*dom
 
 	return users, total, nil
 }
+
+func (s *userService) SetCartRemindersOptOut(ctx context.Context, id string, optOut bool) error {
+	return s.repo.SetCartRemindersOptOut(ctx, id, optOut)
+}
+
+func (s *userService) SetCartUpdateEmailsOptOut(ctx context.Context, id string, optOut bool) error {
+	return s.repo.SetCartUpdateEmailsOptOut(ctx, id, optOut)
+}
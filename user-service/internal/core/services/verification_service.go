@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"user-service/internal/core/domain"
+	"user-service/internal/core/ports"
+	"user-service/internal/core/utils"
+)
+
+type VerificationService interface {
+	// SendVerification emails a new verification token to the user, which
+	// replaces any earlier one. Verified users get nothing.
+	SendVerification(ctx context.Context, userID string) error
+	// VerifyEmail verifies the email the token was sent to, as long as it is
+	// still the user's email.
+	VerifyEmail(ctx context.Context, token string) (*domain.User, error)
+	// ResendVerification is SendVerification by email. It returns nil
+	// whether or not such a user exists.
+	ResendVerification(ctx context.Context, email string) error
+}
+
+type verificationService struct {
+	users  domain.UserRepository
+	tokens domain.EmailVerificationRepository
+	emails ports.EmailService
+	// ttl is how long a verification token can be used.
+	ttl time.Duration
+}
+
+func NewVerificationService(users domain.UserRepository, tokens domain.EmailVerificationRepository, emails ports.EmailService, ttl time.Duration) VerificationService {
+	return &verificationService{
+		users:  users,
+		tokens: tokens,
+		emails: emails,
+		ttl:    ttl,
+	}
+}
+
+func (s *verificationService) SendVerification(ctx context.Context, userID string) error {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, user)
+}
+
+func (s *verificationService) send(ctx context.Context, user *domain.User) error {
+	if user.EmailVerified {
+		return nil
+	}
+
+	token, err := utils.NewToken()
+	if err != nil {
+		return err
+	}
+	if err := s.tokens.DeleteByUser(ctx, user.ID); err != nil {
+		return err
+	}
+	now := time.Now()
+	err = s.tokens.Create(ctx, &domain.EmailVerificationToken{
+		TokenHash: utils.HashToken(token),
+		UserID:    user.ID,
+		Email:     user.Email,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	})
+	if err != nil {
+		return err
+	}
+	return s.emails.SendVerificationEmail(user.Email, user.Name, token)
+}
+
+func (s *verificationService) VerifyEmail(ctx context.Context, token string) (*domain.User, error) {
+	verification, err := s.tokens.Consume(ctx, utils.HashToken(token), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.users.MarkEmailVerified(ctx, verification.UserID, verification.Email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		// The user changed their email since the token was sent.
+		return nil, domain.ErrInvalidVerificationToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.users.FindByID(ctx, verification.UserID)
+}
+
+func (s *verificationService) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.users.FindByEmail(ctx, email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, user)
+}
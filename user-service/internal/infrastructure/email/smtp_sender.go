@@ -4,7 +4,9 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net/smtp"
+	"time"
 
+	"user-service/internal/core/domain"
 	"user-service/internal/core/ports"
 )
 
@@ -38,6 +40,27 @@ func (s *SMTPSender) SendPasswordResetEmail(to, token string) error {
 	return s.sendEmail(to, subject, body)
 }
 
+func (s *SMTPSender) SendVerificationEmail(to, name, token string) error {
+	subject := "Confirm your email address"
+	body := "Hello " + name + ",\n\nPlease confirm your email address with this verification token: " + token
+	return s.sendEmail(to, subject, body)
+}
+
+func (s *SMTPSender) SendAbandonedCartEmail(to, name string, cart *domain.CartAbandoned) error {
+	body, err := RenderAbandonedCartEmail(name, cart)
+	if err != nil {
+		return fmt.Errorf("failed to render email: %v", err)
+	}
+	return s.sendEmail(to, "You left something in your cart", body)
+}
+
+func (s *SMTPSender) SendAccountLockedEmail(to, name string, until time.Time) error {
+	subject := "Your account has been locked"
+	body := "Hello " + name + ",\n\nYour account was locked after too many failed logins. " +
+		"You can log in again after " + until.UTC().Format(time.RFC1123) + ". If this was not you, reset your password."
+	return s.sendEmail(to, subject, body)
+}
+
 func (s *SMTPSender) sendEmail(to, subject, body string) error {
 	msg := []byte("To: " + to + "\r\n" +
 		"Subject: " + subject + "\r\n" +
@@ -0,0 +1,32 @@
+package email
+
+import (
+	"strings"
+	"text/template"
+
+	"user-service/internal/core/domain"
+)
+
+var abandonedCartTemplate = template.Must(template.New("abandoned_cart").Parse(`Hello {{.Name}},
+
+You left some items in your cart:
+
+{{range .Cart.Items}}  - {{.Name}} x{{.Quantity}}: {{printf "%.2f" .LineTotal}} {{$.Cart.Currency}}
+{{end}}
+Total: {{printf "%.2f" .Cart.Total}} {{.Cart.Currency}}
+
+Come back to finish your order before the items sell out.
+
+You get this email because you have items in your cart. You can turn these
+reminders off in your account settings.
+`))
+
+// RenderAbandonedCartEmail builds the body of the abandoned cart reminder.
+func RenderAbandonedCartEmail(name string, cart *domain.CartAbandoned) (string, error) {
+	var b strings.Builder
+	err := abandonedCartTemplate.Execute(&b, struct {
+		Name string
+		Cart *domain.CartAbandoned
+	}{name, cart})
+	return b.String(), err
+}
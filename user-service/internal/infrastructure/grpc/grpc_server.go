@@ -2,16 +2,21 @@ package grpc
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net"
 	"net/http"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
-	//"google.golang.org/grpc/codes"
-	//"google.golang.org/grpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"user-service/internal/auth"
+	"user-service/internal/core/domain"
 	"user-service/internal/core/services"
 	"user-service/internal/metrics"
 	authpb "user-service/proto/auth"
@@ -20,14 +25,28 @@ import (
 
 type Server struct {
 	user.UnimplementedUserServiceServer
-	userService services.UserService
-	authService *auth.Service
+	userService         services.UserService
+	passwordService     services.PasswordService
+	verificationService services.VerificationService
+	roleService         services.RoleService
+	twoFactorService    services.TwoFactorService
+	loginGuard          services.LoginGuard
+	profileService      services.ProfileService
+	privacyService      services.PrivacyService
+	authService         *auth.Service
 }
 
-func NewGRPCServer(userService services.UserService, authService *auth.Service) *Server {
+func NewGRPCServer(userService services.UserService, passwordService services.PasswordService, verificationService services.VerificationService, roleService services.RoleService, twoFactorService services.TwoFactorService, loginGuard services.LoginGuard, profileService services.ProfileService, privacyService services.PrivacyService, authService *auth.Service) *Server {
 	return &Server{
-		userService: userService,
-		authService: authService,
+		userService:         userService,
+		passwordService:     passwordService,
+		verificationService: verificationService,
+		roleService:         roleService,
+		twoFactorService:    twoFactorService,
+		loginGuard:          loginGuard,
+		profileService:      profileService,
+		privacyService:      privacyService,
+		authService:         authService,
 	}
 }
 
@@ -68,9 +87,14 @@ func (s *Server) GetUser(ctx context.Context, req *user.GetUserRequest) (*user.G
 	metrics.RequestCount.WithLabelValues("GetUser", "success").Inc()
 
 	return &user.GetUserResponse{
-		UserId: fetchedUser.ID,
-		Email:  fetchedUser.Email,
-		Name:   fetchedUser.Name,
+		UserId:                 fetchedUser.ID,
+		Email:                  fetchedUser.Email,
+		Name:                   fetchedUser.Name,
+		CartRemindersOptOut:    fetchedUser.CartRemindersOptOut,
+		CartUpdateEmailsOptOut: fetchedUser.CartUpdateEmailsOptOut,
+		EmailVerified:          fetchedUser.EmailVerified,
+		Roles:                  fetchedUser.RoleList(),
+		TwoFactorEnabled:       fetchedUser.TwoFactorEnabled(),
 	}, nil
 }
 
@@ -103,10 +127,9 @@ func (s *Server) DeleteUser(ctx context.Context, req *user.DeleteUserRequest) (*
 		metrics.RequestDuration.WithLabelValues("DeleteUser").Observe(duration)
 	}()
 
-	err := s.userService.DeleteUser(ctx, req.UserId)
-	if err != nil {
+	if _, err := s.privacyService.Erase(ctx, req.UserId); err != nil {
 		metrics.ErrorCount.WithLabelValues("DeleteUser", "delete_failed").Inc()
-		return nil, err
+		return nil, toPrivacyStatusError(err)
 	}
 
 	metrics.RequestCount.WithLabelValues("DeleteUser", "success").Inc()
@@ -172,13 +195,514 @@ func (s *Server) ListUsers(ctx context.Context, req *user.ListUsersRequest) (*us
 	}, nil
 }
 
-func StartGRPCServer(port int, userService services.UserService) error {
+func (s *Server) SetCartRemindersOptOut(ctx context.Context, req *user.SetCartRemindersOptOutRequest) (*user.SetCartRemindersOptOutResponse, error) {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start).Seconds()
+		metrics.RequestDuration.WithLabelValues("SetCartRemindersOptOut").Observe(duration)
+	}()
+
+	if err := s.userService.SetCartRemindersOptOut(ctx, req.UserId, req.OptOut); err != nil {
+		metrics.ErrorCount.WithLabelValues("SetCartRemindersOptOut", "update_failed").Inc()
+		return nil, err
+	}
+
+	metrics.RequestCount.WithLabelValues("SetCartRemindersOptOut", "success").Inc()
+
+	return &user.SetCartRemindersOptOutResponse{
+		UserId: req.UserId,
+		OptOut: req.OptOut,
+	}, nil
+}
+
+func (s *Server) SetCartUpdateEmailsOptOut(ctx context.Context, req *user.SetCartUpdateEmailsOptOutRequest) (*user.SetCartUpdateEmailsOptOutResponse, error) {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start).Seconds()
+		metrics.RequestDuration.WithLabelValues("SetCartUpdateEmailsOptOut").Observe(duration)
+	}()
+
+	if err := s.userService.SetCartUpdateEmailsOptOut(ctx, req.UserId, req.OptOut); err != nil {
+		metrics.ErrorCount.WithLabelValues("SetCartUpdateEmailsOptOut", "update_failed").Inc()
+		return nil, err
+	}
+
+	metrics.RequestCount.WithLabelValues("SetCartUpdateEmailsOptOut", "success").Inc()
+
+	return &user.SetCartUpdateEmailsOptOutResponse{
+		UserId: req.UserId,
+		OptOut: req.OptOut,
+	}, nil
+}
+
+func (s *Server) RequestPasswordReset(ctx context.Context, req *user.RequestPasswordResetRequest) (*user.RequestPasswordResetResponse, error) {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start).Seconds()
+		metrics.RequestDuration.WithLabelValues("RequestPasswordReset").Observe(duration)
+	}()
+
+	if req.Email == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	// Failing only for registered emails would tell them apart, so failures
+	// other than the rate limit, which applies to every email, are logged
+	// and answered like successes.
+	err := s.passwordService.RequestPasswordReset(ctx, req.Email, req.IpAddress)
+	if errors.Is(err, services.ErrTooManyResetRequests) {
+		metrics.ErrorCount.WithLabelValues("RequestPasswordReset", "rate_limited").Inc()
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	if err != nil {
+		log.Printf("Password reset request failed: %v", err)
+		metrics.ErrorCount.WithLabelValues("RequestPasswordReset", "request_failed").Inc()
+	} else {
+		metrics.RequestCount.WithLabelValues("RequestPasswordReset", "success").Inc()
+	}
+
+	return &user.RequestPasswordResetResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *Server) ConfirmPasswordReset(ctx context.Context, req *user.ConfirmPasswordResetRequest) (*user.ConfirmPasswordResetResponse, error) {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start).Seconds()
+		metrics.RequestDuration.WithLabelValues("ConfirmPasswordReset").Observe(duration)
+	}()
+
+	if err := s.passwordService.ConfirmPasswordReset(ctx, req.Token, req.NewPassword); err != nil {
+		metrics.ErrorCount.WithLabelValues("ConfirmPasswordReset", "reset_failed").Inc()
+		return nil, toPasswordStatusError(err)
+	}
+
+	metrics.RequestCount.WithLabelValues("ConfirmPasswordReset", "success").Inc()
+
+	return &user.ConfirmPasswordResetResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *Server) ChangePassword(ctx context.Context, req *user.ChangePasswordRequest) (*user.ChangePasswordResponse, error) {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start).Seconds()
+		metrics.RequestDuration.WithLabelValues("ChangePassword").Observe(duration)
+	}()
+
+	if err := s.passwordService.ChangePassword(ctx, req.UserId, req.CurrentPassword, req.NewPassword); err != nil {
+		metrics.ErrorCount.WithLabelValues("ChangePassword", "change_failed").Inc()
+		return nil, toPasswordStatusError(err)
+	}
+
+	metrics.RequestCount.WithLabelValues("ChangePassword", "success").Inc()
+
+	return &user.ChangePasswordResponse{
+		Success: true,
+	}, nil
+}
+
+func toPasswordStatusError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrWeakPassword), errors.Is(err, domain.ErrInvalidResetToken):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, services.ErrInvalidPassword):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, domain.ErrUserNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func (s *Server) VerifyEmail(ctx context.Context, req *user.VerifyEmailRequest) (*user.VerifyEmailResponse, error) {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start).Seconds()
+		metrics.RequestDuration.WithLabelValues("VerifyEmail").Observe(duration)
+	}()
+
+	verifiedUser, err := s.verificationService.VerifyEmail(ctx, req.Token)
+	if err != nil {
+		metrics.ErrorCount.WithLabelValues("VerifyEmail", "verify_failed").Inc()
+		if errors.Is(err, domain.ErrInvalidVerificationToken) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	metrics.RequestCount.WithLabelValues("VerifyEmail", "success").Inc()
+
+	return &user.VerifyEmailResponse{
+		UserId:        verifiedUser.ID,
+		EmailVerified: verifiedUser.EmailVerified,
+	}, nil
+}
+
+func (s *Server) ResendVerification(ctx context.Context, req *user.ResendVerificationRequest) (*user.ResendVerificationResponse, error) {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start).Seconds()
+		metrics.RequestDuration.WithLabelValues("ResendVerification").Observe(duration)
+	}()
+
+	if req.Email == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	// Like RequestPasswordReset, failures are answered like successes.
+	if err := s.verificationService.ResendVerification(ctx, req.Email); err != nil {
+		log.Printf("Verification resend failed: %v", err)
+		metrics.ErrorCount.WithLabelValues("ResendVerification", "resend_failed").Inc()
+	} else {
+		metrics.RequestCount.WithLabelValues("ResendVerification", "success").Inc()
+	}
+
+	return &user.ResendVerificationResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *Server) AssignRole(ctx context.Context, req *user.AssignRoleRequest) (*user.AssignRoleResponse, error) {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start).Seconds()
+		metrics.RequestDuration.WithLabelValues("AssignRole").Observe(duration)
+	}()
+
+	updatedUser, err := s.roleService.AssignRole(ctx, req.UserId, req.Role)
+	if err != nil {
+		metrics.ErrorCount.WithLabelValues("AssignRole", "assign_failed").Inc()
+		return nil, toRoleStatusError(err)
+	}
+
+	metrics.RequestCount.WithLabelValues("AssignRole", "success").Inc()
+
+	return &user.AssignRoleResponse{
+		UserId: updatedUser.ID,
+		Roles:  updatedUser.RoleList(),
+	}, nil
+}
+
+func (s *Server) RevokeRole(ctx context.Context, req *user.RevokeRoleRequest) (*user.RevokeRoleResponse, error) {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start).Seconds()
+		metrics.RequestDuration.WithLabelValues("RevokeRole").Observe(duration)
+	}()
+
+	updatedUser, err := s.roleService.RevokeRole(ctx, req.UserId, req.Role)
+	if err != nil {
+		metrics.ErrorCount.WithLabelValues("RevokeRole", "revoke_failed").Inc()
+		return nil, toRoleStatusError(err)
+	}
+
+	metrics.RequestCount.WithLabelValues("RevokeRole", "success").Inc()
+
+	return &user.RevokeRoleResponse{
+		UserId: updatedUser.ID,
+		Roles:  updatedUser.RoleList(),
+	}, nil
+}
+
+func toRoleStatusError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrUnknownRole):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrLastRole):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrUserNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func (s *Server) EnrollTwoFactor(ctx context.Context, req *user.EnrollTwoFactorRequest) (*user.EnrollTwoFactorResponse, error) {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start).Seconds()
+		metrics.RequestDuration.WithLabelValues("EnrollTwoFactor").Observe(duration)
+	}()
+
+	secret, uri, err := s.twoFactorService.Enroll(ctx, req.UserId)
+	if err != nil {
+		metrics.ErrorCount.WithLabelValues("EnrollTwoFactor", "enroll_failed").Inc()
+		return nil, toTwoFactorStatusError(err)
+	}
+
+	metrics.RequestCount.WithLabelValues("EnrollTwoFactor", "success").Inc()
+
+	return &user.EnrollTwoFactorResponse{
+		Secret:          secret,
+		ProvisioningUri: uri,
+	}, nil
+}
+
+func (s *Server) ConfirmTwoFactor(ctx context.Context, req *user.ConfirmTwoFactorRequest) (*user.ConfirmTwoFactorResponse, error) {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start).Seconds()
+		metrics.RequestDuration.WithLabelValues("ConfirmTwoFactor").Observe(duration)
+	}()
+
+	recoveryCodes, err := s.twoFactorService.Confirm(ctx, req.UserId, req.Code)
+	if err != nil {
+		metrics.ErrorCount.WithLabelValues("ConfirmTwoFactor", "confirm_failed").Inc()
+		return nil, toTwoFactorStatusError(err)
+	}
+
+	metrics.RequestCount.WithLabelValues("ConfirmTwoFactor", "success").Inc()
+
+	return &user.ConfirmTwoFactorResponse{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+func (s *Server) DisableTwoFactor(ctx context.Context, req *user.DisableTwoFactorRequest) (*user.DisableTwoFactorResponse, error) {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start).Seconds()
+		metrics.RequestDuration.WithLabelValues("DisableTwoFactor").Observe(duration)
+	}()
+
+	if err := s.twoFactorService.Disable(ctx, req.UserId, req.Code); err != nil {
+		metrics.ErrorCount.WithLabelValues("DisableTwoFactor", "disable_failed").Inc()
+		return nil, toTwoFactorStatusError(err)
+	}
+
+	metrics.RequestCount.WithLabelValues("DisableTwoFactor", "success").Inc()
+
+	return &user.DisableTwoFactorResponse{
+		Success: true,
+	}, nil
+}
+
+func toTwoFactorStatusError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidTwoFactorCode):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrTwoFactorNotEnrolled), errors.Is(err, domain.ErrTwoFactorAlreadyEnabled):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrUserNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func (s *Server) UnlockAccount(ctx context.Context, req *user.UnlockAccountRequest) (*user.UnlockAccountResponse, error) {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start).Seconds()
+		metrics.RequestDuration.WithLabelValues("UnlockAccount").Observe(duration)
+	}()
+
+	err := s.loginGuard.Unlock(ctx, req.UserId)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		metrics.ErrorCount.WithLabelValues("UnlockAccount", "not_found").Inc()
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		metrics.ErrorCount.WithLabelValues("UnlockAccount", "unlock_failed").Inc()
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	metrics.RequestCount.WithLabelValues("UnlockAccount", "success").Inc()
+
+	return &user.UnlockAccountResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *Server) GetProfile(ctx context.Context, req *user.GetProfileRequest) (*user.GetProfileResponse, error) {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start).Seconds()
+		metrics.RequestDuration.WithLabelValues("GetProfile").Observe(duration)
+	}()
+
+	fetchedUser, err := s.profileService.GetProfile(ctx, req.UserId)
+	if err != nil {
+		metrics.ErrorCount.WithLabelValues("GetProfile", "not_found").Inc()
+		return nil, toProfileStatusError(err)
+	}
+
+	metrics.RequestCount.WithLabelValues("GetProfile", "success").Inc()
+
+	return &user.GetProfileResponse{
+		Profile: toProfile(fetchedUser),
+	}, nil
+}
+
+func (s *Server) UpdateProfile(ctx context.Context, req *user.UpdateProfileRequest) (*user.UpdateProfileResponse, error) {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start).Seconds()
+		metrics.RequestDuration.WithLabelValues("UpdateProfile").Observe(duration)
+	}()
+
+	profile := domain.Profile{
+		Phone:              req.Phone,
+		Locale:             req.Locale,
+		Currency:           req.Currency,
+		DietaryPreferences: req.DietaryPreferences,
+		MutedChannels:      req.MutedChannels,
+		MarketingConsent:   req.MarketingConsent,
+	}
+	source := services.ConsentSource{IPAddress: req.IpAddress, UserAgent: req.UserAgent}
+	updatedUser, err := s.profileService.UpdateProfile(ctx, req.UserId, profile, source)
+	if err != nil {
+		metrics.ErrorCount.WithLabelValues("UpdateProfile", "update_failed").Inc()
+		return nil, toProfileStatusError(err)
+	}
+
+	metrics.RequestCount.WithLabelValues("UpdateProfile", "success").Inc()
+
+	return &user.UpdateProfileResponse{
+		Profile: toProfile(updatedUser),
+	}, nil
+}
+
+func toProfile(u *domain.User) *user.Profile {
+	profile := &user.Profile{
+		UserId:               u.ID,
+		Phone:                u.Profile.Phone,
+		Locale:               u.Profile.Locale,
+		Currency:             u.Profile.Currency,
+		DietaryPreferences:   u.Profile.DietaryPreferences,
+		MutedChannels:        u.Profile.MutedChannels,
+		NotificationChannels: u.NotificationChannels(),
+		MarketingConsent:     u.Profile.MarketingConsent,
+	}
+	for _, record := range u.ConsentHistory {
+		profile.ConsentHistory = append(profile.ConsentHistory, &user.ConsentRecord{
+			Purpose: record.Purpose,
+			Granted: record.Granted,
+			At:      timestamppb.New(record.At),
+		})
+	}
+	return profile
+}
+
+func toProfileStatusError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidProfile):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrUserNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func (s *Server) ExportMyData(ctx context.Context, req *user.ExportMyDataRequest) (*user.ExportMyDataResponse, error) {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start).Seconds()
+		metrics.RequestDuration.WithLabelValues("ExportMyData").Observe(duration)
+	}()
+
+	export, err := s.privacyService.ExportData(ctx, req.UserId)
+	if err != nil {
+		metrics.ErrorCount.WithLabelValues("ExportMyData", "export_failed").Inc()
+		return nil, toPrivacyStatusError(err)
+	}
+	archive, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		metrics.ErrorCount.WithLabelValues("ExportMyData", "export_failed").Inc()
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	metrics.RequestCount.WithLabelValues("ExportMyData", "success").Inc()
+
+	return &user.ExportMyDataResponse{
+		Archive:         archive,
+		MissingServices: export.Missing,
+	}, nil
+}
+
+func (s *Server) EraseMyData(ctx context.Context, req *user.EraseMyDataRequest) (*user.EraseMyDataResponse, error) {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start).Seconds()
+		metrics.RequestDuration.WithLabelValues("EraseMyData").Observe(duration)
+	}()
+
+	request, err := s.privacyService.EraseMyData(ctx, req.UserId, req.Password)
+	if err != nil {
+		metrics.ErrorCount.WithLabelValues("EraseMyData", "erase_failed").Inc()
+		return nil, toPrivacyStatusError(err)
+	}
+
+	metrics.RequestCount.WithLabelValues("EraseMyData", "success").Inc()
+	metrics.ActiveUsers.Dec()
+
+	return &user.EraseMyDataResponse{
+		Erasure: toErasureStatus(request),
+	}, nil
+}
+
+func (s *Server) GetErasureStatus(ctx context.Context, req *user.GetErasureStatusRequest) (*user.ErasureStatus, error) {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start).Seconds()
+		metrics.RequestDuration.WithLabelValues("GetErasureStatus").Observe(duration)
+	}()
+
+	request, err := s.privacyService.GetErasure(ctx, req.ErasureId)
+	if err != nil {
+		metrics.ErrorCount.WithLabelValues("GetErasureStatus", "not_found").Inc()
+		return nil, toPrivacyStatusError(err)
+	}
+
+	metrics.RequestCount.WithLabelValues("GetErasureStatus", "success").Inc()
+
+	return toErasureStatus(request), nil
+}
+
+func toErasureStatus(request *domain.ErasureRequest) *user.ErasureStatus {
+	erasure := &user.ErasureStatus{
+		ErasureId:   request.ID,
+		UserId:      request.UserID,
+		RequestedAt: timestamppb.New(request.RequestedAt),
+	}
+	if request.CompletedAt != nil {
+		erasure.CompletedAt = timestamppb.New(*request.CompletedAt)
+	}
+	for _, service := range request.Services {
+		serviceStatus := &user.ServiceErasureStatus{
+			Service:   service.Name,
+			LastError: service.LastError,
+		}
+		if service.CompletedAt != nil {
+			serviceStatus.CompletedAt = timestamppb.New(*service.CompletedAt)
+		}
+		erasure.Services = append(erasure.Services, serviceStatus)
+	}
+	return erasure
+}
+
+func toPrivacyStatusError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidPassword):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, domain.ErrUserNotFound), errors.Is(err, domain.ErrErasureNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func StartGRPCServer(port, jwksPort int, userService services.UserService, passwordService services.PasswordService, verificationService services.VerificationService, roleService services.RoleService, twoFactorService services.TwoFactorService, loginGuard services.LoginGuard, profileService services.ProfileService, privacyService services.PrivacyService, authService *auth.Service) error {
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
 		return fmt.Errorf("failed to listen: %v", err)
 	}
 
-	// Start metrics HTTP server
+	// Start HTTP server for metrics
 	go func() {
 		metricsMux := http.NewServeMux()
 		metricsMux.Handle("/metrics", promhttp.Handler())
@@ -191,10 +715,21 @@ func StartGRPCServer(port int, userService services.UserService) error {
 		}
 	}()
 
-	authService := auth.NewService("your-secret-key-here", userService)
+	// Start HTTP server for the keys gateways verify tokens with
+	go func() {
+		jwksMux := http.NewServeMux()
+		jwksMux.Handle("/.well-known/jwks.json", authService.JWKS())
+		jwksServer := &http.Server{
+			Addr:    fmt.Sprintf(":%d", jwksPort),
+			Handler: jwksMux,
+		}
+		if err := jwksServer.ListenAndServe(); err != nil {
+			fmt.Printf("Failed to start JWKS server: %v\n", err)
+		}
+	}()
 
 	grpcServer := grpc.NewServer()
-	user.RegisterUserServiceServer(grpcServer, NewGRPCServer(userService, authService))
+	user.RegisterUserServiceServer(grpcServer, NewGRPCServer(userService, passwordService, verificationService, roleService, twoFactorService, loginGuard, profileService, privacyService, authService))
 	authpb.RegisterAuthServiceServer(grpcServer, authService)
 
 	if err := grpcServer.Serve(lis); err != nil {
@@ -0,0 +1,74 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+
+	"github.com/nats-io/nats.go"
+	"user-service/internal/core/domain"
+	"user-service/internal/core/ports"
+)
+
+// DataCollector publishes a data export request and gathers the replies of
+// the services named in services.
+type DataCollector struct {
+	conn     *nats.Conn
+	services []string
+}
+
+func NewDataCollector(conn *nats.Conn, services []string) ports.UserDataCollector {
+	return &DataCollector{conn: conn, services: services}
+}
+
+func (c *DataCollector) CollectUserData(ctx context.Context, userID string) ([]domain.ServiceExport, []string, error) {
+	data, err := json.Marshal(domain.UserExportRequested{UserID: userID})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inbox := c.conn.NewInbox()
+	sub, err := c.conn.SubscribeSync(inbox)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer sub.Unsubscribe()
+	if err := c.conn.PublishRequest(domain.UserExportRequestedEvent, inbox, data); err != nil {
+		return nil, nil, err
+	}
+
+	waiting := make(map[string]bool, len(c.services))
+	for _, service := range c.services {
+		waiting[service] = true
+	}
+
+	var exports []domain.ServiceExport
+	for len(waiting) > 0 {
+		msg, err := sub.NextMsgWithContext(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return nil, nil, err
+		}
+
+		var export domain.ServiceExport
+		if err := json.Unmarshal(msg.Data, &export); err != nil {
+			log.Printf("Invalid data export reply: %v", err)
+			continue
+		}
+		if !waiting[export.Service] {
+			continue
+		}
+		delete(waiting, export.Service)
+		exports = append(exports, export)
+	}
+
+	missing := make([]string, 0, len(waiting))
+	for service := range waiting {
+		missing = append(missing, service)
+	}
+	sort.Strings(missing)
+	return exports, missing, nil
+}
@@ -0,0 +1,79 @@
+package nats
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"user-service/internal/core/domain"
+	"user-service/internal/core/ports"
+)
+
+// StreamName is the JetStream stream keeping the events user-service
+// publishes, so that durable consumers get them even after being down.
+const StreamName = "USERS"
+
+// streamSubjects leaves out user.export.requested: the stream would answer
+// the request with its publish ack.
+var streamSubjects = []string{
+	domain.UserCreatedEvent,
+	domain.UserUpdatedEvent,
+	domain.UserDeletedEvent,
+	domain.LoginFailedEvent,
+	domain.AccountLockedEvent,
+}
+
+type Publisher struct {
+	js nats.JetStreamContext
+}
+
+// NewPublisher creates the USERS stream, or updates it if it exists. Events
+// are kept for retention, as they carry personal data.
+func NewPublisher(conn *nats.Conn, retention time.Duration) (ports.UserEventPublisher, error) {
+	js, err := conn.JetStream()
+	if err != nil {
+		return nil, err
+	}
+	stream := &nats.StreamConfig{
+		Name:     StreamName,
+		Subjects: streamSubjects,
+		MaxAge:   retention,
+	}
+	_, err = js.AddStream(stream)
+	if err == nats.ErrStreamNameAlreadyInUse {
+		_, err = js.UpdateStream(stream)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Publisher{js: js}, nil
+}
+
+func (p *Publisher) publish(subject string, event interface{}) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = p.js.Publish(subject, data)
+	return err
+}
+
+func (p *Publisher) PublishUserCreated(user *domain.User) error {
+	return p.publish(domain.UserCreatedEvent, domain.NewUserEvent(user, time.Now()))
+}
+
+func (p *Publisher) PublishUserUpdated(user *domain.User) error {
+	return p.publish(domain.UserUpdatedEvent, domain.NewUserEvent(user, time.Now()))
+}
+
+func (p *Publisher) PublishUserDeleted(event *domain.UserDeleted) error {
+	return p.publish(domain.UserDeletedEvent, event)
+}
+
+func (p *Publisher) PublishLoginFailed(event *domain.LoginFailed) error {
+	return p.publish(domain.LoginFailedEvent, event)
+}
+
+func (p *Publisher) PublishAccountLocked(event *domain.AccountLocked) error {
+	return p.publish(domain.AccountLockedEvent, event)
+}
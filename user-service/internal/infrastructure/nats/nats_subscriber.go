@@ -2,11 +2,26 @@ package nats
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
 
 	"github.com/nats-io/nats.go"
 	"user-service/internal/core/domain"
 )
 
+const (
+	// maxDeliveries bounds how often a failing event is handled, e.g. while
+	// the SMTP server is down; redeliveryDelay spaces the attempts out.
+	maxDeliveries   = 5
+	redeliveryDelay = 30 * time.Second
+)
+
+// errUnreadable marks events that cannot be decoded, which no redelivery
+// fixes.
+var errUnreadable = errors.New("unreadable event")
+
 type Subscriber struct {
 	conn    *nats.Conn
 	handler domain.EventHandler
@@ -19,36 +34,112 @@ func NewSubscriber(conn *nats.Conn, handler domain.EventHandler) *Subscriber {
 	}
 }
 
+// Subscribe reads the events that send emails or erase data through durable
+// consumers of the USERS stream, acknowledged once handled, so that none is
+// lost while user-service is down. The stream must exist; NewPublisher
+// creates it.
 func (s *Subscriber) Subscribe() error {
-	if _, err := s.conn.Subscribe(domain.UserCreatedEvent, func(msg *nats.Msg) {
-		var user domain.User
-		if err := json.Unmarshal(msg.Data, &user); err != nil {
-			return
+	js, err := s.conn.JetStream()
+	if err != nil {
+		return err
+	}
+
+	if err := s.subscribeDurable(js, domain.UserCreatedEvent, "user-service-user-created", func(data []byte) (string, error) {
+		var event domain.UserEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return "", fmt.Errorf("%w: %v", errUnreadable, err)
+		}
+		return event.UserID, s.handler.HandleUserCreated(&event)
+	}); err != nil {
+		return err
+	}
+
+	if err := s.subscribeDurable(js, domain.UserDeletedEvent, "user-service-user-deleted", func(data []byte) (string, error) {
+		var event domain.UserDeleted
+		if err := json.Unmarshal(data, &event); err != nil {
+			return "", fmt.Errorf("%w: %v", errUnreadable, err)
+		}
+		return event.UserID, s.handler.HandleUserDeleted(&event)
+	}); err != nil {
+		return err
+	}
+
+	if err := s.subscribeDurable(js, domain.AccountLockedEvent, "user-service-user-locked", func(data []byte) (string, error) {
+		var event domain.AccountLocked
+		if err := json.Unmarshal(data, &event); err != nil {
+			return "", fmt.Errorf("%w: %v", errUnreadable, err)
 		}
-		s.handler.HandleUserCreated(&user)
+		return event.UserID, s.handler.HandleAccountLocked(&event)
 	}); err != nil {
 		return err
 	}
 
 	if _, err := s.conn.Subscribe(domain.UserUpdatedEvent, func(msg *nats.Msg) {
-		var user domain.User
-		if err := json.Unmarshal(msg.Data, &user); err != nil {
+		var event domain.UserEvent
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			return
+		}
+		s.handler.HandleUserUpdated(&event)
+	}); err != nil {
+		return err
+	}
+
+	if _, err := s.conn.Subscribe(domain.ErasureCompletedEvent, func(msg *nats.Msg) {
+		var event domain.ErasureCompleted
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			log.Printf("Dropping unreadable %s event: %v", domain.ErasureCompletedEvent, err)
 			return
 		}
-		s.handler.HandleUserUpdated(&user)
+		if err := s.handler.HandleErasureCompleted(&event); err != nil {
+			log.Printf("Failed to record erasure %s by %s: %v", event.ErasureID, event.Service, err)
+		}
 	}); err != nil {
 		return err
 	}
 
-	if _, err := s.conn.Subscribe(domain.UserDeletedEvent, func(msg *nats.Msg) {
-		var userID string
-		if err := json.Unmarshal(msg.Data, &userID); err != nil {
+	if _, err := s.conn.Subscribe(domain.CartAbandonedEvent, func(msg *nats.Msg) {
+		var event domain.CartAbandoned
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			log.Printf("Dropping unreadable %s event: %v", domain.CartAbandonedEvent, err)
 			return
 		}
-		s.handler.HandleUserDeleted(userID)
+		if err := s.handler.HandleCartAbandoned(&event); err != nil {
+			log.Printf("Failed to send cart reminder: %v", err)
+		}
 	}); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// subscribeDurable passes every event on subject to handle, which returns
+// the user the event is about. Events that cannot be decoded are dropped;
+// failed ones are delivered again after redeliveryDelay, up to
+// maxDeliveries times.
+func (s *Subscriber) subscribeDurable(js nats.JetStreamContext, subject, durable string, handle func(data []byte) (string, error)) error {
+	_, err := js.Subscribe(subject, func(msg *nats.Msg) {
+		userID, err := handle(msg.Data)
+		switch {
+		case err == nil:
+			if err := msg.Ack(); err != nil {
+				log.Printf("Failed to ack %s for user %s: %v", subject, userID, err)
+			}
+		case errors.Is(err, errUnreadable):
+			log.Printf("Dropping unreadable %s: %v", subject, err)
+			msg.Term()
+		default:
+			log.Printf("Failed to handle %s for user %s: %v", subject, userID, err)
+			msg.NakWithDelay(redeliveryDelay)
+		}
+	},
+		nats.Durable(durable),
+		nats.ManualAck(),
+		nats.AckExplicit(),
+		nats.MaxDeliver(maxDeliveries),
+		// A new consumer starts at the next event rather than emailing
+		// everyone in the stream again.
+		nats.DeliverNew(),
+	)
+	return err
+}